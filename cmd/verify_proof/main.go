@@ -7,26 +7,33 @@
 // Examples:
 //
 //	# Verify EOA account proof
-//	go run ./cmd/verify_proof/ 0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826
+//	go run ./cmd/verify_proof/ 0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826
 //
 //	# Verify contract with storage slot 0
-//	go run ./cmd/verify_proof/ 0x77045E71a7A2c50903d88e564cD72fab11e82051 0x0
+//	go run ./cmd/verify_proof/ 0x77045e71a7a2c50903d88e564cd72fab11e82051 0x0
 //
 //	# Verify multiple storage slots
-//	go run ./cmd/verify_proof/ 0x77045E71a7A2c50903d88e564cD72fab11e82051 0x0,0x1,0x2
+//	go run ./cmd/verify_proof/ 0x77045e71a7a2c50903d88e564cd72fab11e82051 0x0,0x1,0x2
 //
 //	# Specify block reference
-//	go run ./cmd/verify_proof/ 0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826 "" 0x1234
+//	go run ./cmd/verify_proof/ 0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826 "" 0x1234
+//
+// Mixed-case addresses must carry an RSKIP-60 checksum for the target chain;
+// Ethereum (EIP-55) checksums are rejected. Lowercase addresses are accepted.
 //
 // Flags:
 //
 //	--rpc-url    RPC endpoint URL (default: http://localhost:4444)
+//	--chain-id   Chain ID for RSKIP-60 address checksums (default: ask the node)
 //	--no-verify  Skip proof verification, just fetch and display
+//	--zk-trace   Write the byte-level verification trace of each proof, as
+//	             JSON, to a file for zk circuit builders
 package main
 
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -34,6 +41,7 @@ import (
 	"time"
 
 	"gorsk/rskblocks"
+	"gorsk/rsktrie"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -45,6 +53,9 @@ func main() {
 	rpcURL := flag.String("rpc-url", "http://localhost:4444", "RSKj RPC endpoint URL")
 	noVerify := flag.Bool("no-verify", false, "Skip proof verification")
 	rawJSON := flag.Bool("json", false, "Output raw JSON response")
+	chainID := flag.Uint64("chain-id", 0, "Chain ID for RSKIP-60 address checksums (0 = query the node)")
+	strict := flag.Bool("strict", false, "Reject proofs with duplicate nodes or nodes off the key's path")
+	zkTrace := flag.String("zk-trace", "", "Write the verification trace of every proof, as JSON, to this file")
 	flag.Parse()
 
 	args := flag.Args()
@@ -53,15 +64,12 @@ func main() {
 		fmt.Fprintln(os.Stderr, "\nFlags:")
 		flag.PrintDefaults()
 		fmt.Fprintln(os.Stderr, "\nExamples:")
-		fmt.Fprintln(os.Stderr, "  verify_proof 0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
-		fmt.Fprintln(os.Stderr, "  verify_proof 0x77045E71a7A2c50903d88e564cD72fab11e82051 0x0")
-		fmt.Fprintln(os.Stderr, "  verify_proof 0x77045E71a7A2c50903d88e564cD72fab11e82051 0x0,0x1 latest")
+		fmt.Fprintln(os.Stderr, "  verify_proof 0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826")
+		fmt.Fprintln(os.Stderr, "  verify_proof 0x77045e71a7a2c50903d88e564cd72fab11e82051 0x0")
+		fmt.Fprintln(os.Stderr, "  verify_proof 0x77045e71a7a2c50903d88e564cd72fab11e82051 0x0,0x1 latest")
 		os.Exit(1)
 	}
 
-	// Parse address
-	address := common.HexToAddress(args[0])
-
 	// Parse storage keys (comma-separated)
 	var storageKeys []common.Hash
 	if len(args) > 1 && args[1] != "" {
@@ -89,8 +97,22 @@ func main() {
 	}
 	defer client.Close()
 
+	// Parse address, validating its checksum for the target network
+	if *chainID == 0 {
+		*chainID, err = client.ChainID(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to get chain ID: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	address, err := rsktrie.ParseAddress(args[0], *chainID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid address: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Fetch the proof
-	fmt.Printf("Fetching proof for %s at block %s...\n", address.Hex(), blockRef)
+	fmt.Printf("Fetching proof for %s at block %s...\n", rsktrie.ChecksumAddress(address, *chainID), blockRef)
 	proof, err := client.GetProof(ctx, address, storageKeys, blockRef)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to fetch proof: %v\n", err)
@@ -148,7 +170,13 @@ func main() {
 		os.Exit(1)
 	}
 
-	verifier := rskblocks.NewProofVerifier()
+	verifier := rskblocks.NewProofVerifier(rskblocks.WithStrict(*strict))
+	mapper := rsktrie.NewTrieKeyMapper()
+	var traces []*rsktrie.VerificationTrace
+	if *zkTrace != "" {
+		trace, _ := rsktrie.TraceKeyProof(stateRoot[:], mapper.GetAccountKey(address), accountProofNodes)
+		traces = append(traces, trace)
+	}
 	accountResult, err := verifier.VerifyAccountProof(stateRoot, address, accountProofNodes)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Account proof verification error: %v\n", err)
@@ -156,14 +184,19 @@ func main() {
 	}
 
 	if accountResult.Valid {
-		fmt.Println("\nAccount Proof: VALID")
+		fmt.Printf("\nAccount Proof: VALID (%s)\n", accountResult.Status)
+		if !accountResult.Exists {
+			fmt.Println("  Account does not exist at this block")
+		}
 		if len(accountResult.Value) > 0 {
 			fmt.Printf("  Value (RLP): %s\n", hexutil.Encode(accountResult.Value))
 		}
+		fmt.Printf("  Nonce:   %s\n", accountResult.Nonce)
+		fmt.Printf("  Balance: %s wei\n", accountResult.Balance)
 	} else {
 		fmt.Println("\nAccount Proof: INVALID")
 		if accountResult.Error != nil {
-			fmt.Printf("  Error: %v\n", accountResult.Error)
+			printProofError(accountResult.Error)
 		}
 	}
 
@@ -177,6 +210,10 @@ func main() {
 			continue
 		}
 
+		if *zkTrace != "" {
+			trace, _ := rsktrie.TraceKeyProof(stateRoot[:], mapper.GetAccountStorageKey(address, keyHash), proofNodes)
+			traces = append(traces, trace)
+		}
 		storageResult, err := verifier.VerifyStorageProof(stateRoot, address, keyHash, proofNodes)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Storage proof verification error for %s: %v\n", sp.Key, err)
@@ -184,19 +221,27 @@ func main() {
 		}
 
 		if storageResult.Valid {
-			fmt.Printf("\nStorage Proof [%s]: VALID\n", sp.Key)
+			fmt.Printf("\nStorage Proof [%s]: VALID (%s)\n", sp.Key, storageResult.Status)
 			if len(storageResult.Value) > 0 {
 				fmt.Printf("  Value: %s\n", hexutil.Encode(storageResult.Value))
 			}
 		} else {
 			fmt.Printf("\nStorage Proof [%s]: INVALID\n", sp.Key)
 			if storageResult.Error != nil {
-				fmt.Printf("  Error: %v\n", storageResult.Error)
+				printProofError(storageResult.Error)
 			}
 			allValid = false
 		}
 	}
 
+	if *zkTrace != "" {
+		if err := writeJSON(*zkTrace, traces); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write verification traces: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("\nVerification traces written to %s\n", *zkTrace)
+	}
+
 	// Final summary
 	fmt.Println("\n=== Summary ===")
 	if allValid {
@@ -207,6 +252,28 @@ func main() {
 	}
 }
 
+// writeJSON writes v, indented, to path.
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// printProofError prints err and, for proof failures, the nodes walked.
+func printProofError(err error) {
+	fmt.Printf("  Error: %v\n", err)
+	var perr *rsktrie.ProofError
+	if !errors.As(err, &perr) {
+		return
+	}
+	for _, step := range perr.Traversal {
+		fmt.Printf("    node %2d  bit %3d  shared path %q  hash %x\n", step.NodeIndex, step.KeyPosition, step.SharedPath, step.Hash)
+	}
+	fmt.Printf("  Key: %s\n", rsktrie.FormatKey(perr.Key))
+}
+
 // blockHeader represents the relevant fields from eth_getBlockByNumber response
 type blockHeader struct {
 	StateRoot string `json:"stateRoot"`
@@ -0,0 +1,140 @@
+package rskblocks
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AccountSnapshot is the verified balance and nonce of a list of accounts at
+// one block, as needed for airdrops and proof-of-reserves.
+type AccountSnapshot struct {
+	BlockHash common.Hash
+	StateRoot common.Hash
+	// Entries are in response order, one per response.
+	Entries []SnapshotEntry
+}
+
+// SnapshotEntry is one account of a snapshot. Balance and Nonce are only set
+// if Err is nil; an absent account is verified with zero balance and nonce.
+type SnapshotEntry struct {
+	Address common.Address
+	Exists  bool
+	Balance *big.Int
+	Nonce   *big.Int
+	Err     error
+}
+
+// BalanceCoin returns the entry's balance as a Coin; zero if it did not
+// verify.
+func (e *SnapshotEntry) BalanceCoin() Coin {
+	return NewCoin(e.Balance)
+}
+
+// Balances returns the balance of every verified account.
+func (s *AccountSnapshot) Balances() map[common.Address]*big.Int {
+	balances := make(map[common.Address]*big.Int, len(s.Entries))
+	for _, e := range s.Entries {
+		if e.Err == nil {
+			balances[e.Address] = e.Balance
+		}
+	}
+	return balances
+}
+
+// TotalBalance returns the sum of the verified balances, counting each
+// address once.
+func (s *AccountSnapshot) TotalBalance() *big.Int {
+	total := new(big.Int)
+	for _, balance := range s.Balances() {
+		total.Add(total, balance)
+	}
+	return total
+}
+
+// TotalBalanceCoin returns TotalBalance as a Coin.
+func (s *AccountSnapshot) TotalBalanceCoin() Coin {
+	return NewCoin(s.TotalBalance())
+}
+
+// Failed returns the entries that did not verify.
+func (s *AccountSnapshot) Failed() []SnapshotEntry {
+	var failed []SnapshotEntry
+	for _, e := range s.Entries {
+		if e.Err != nil {
+			failed = append(failed, e)
+		}
+	}
+	return failed
+}
+
+// VerifyAccountSnapshot verifies eth_getProof responses for many accounts
+// against header, each as VerifyEthGetProofResponse does, on up to workers
+// goroutines (GOMAXPROCS if workers <= 0). A response whose proofs fail or
+// whose reported fields mismatch the proven state gets an entry error rather
+// than failing the snapshot. Cancelling ctx stops handing out responses;
+// those not yet verified get ctx's error.
+func (v *ProofVerifier) VerifyAccountSnapshot(ctx context.Context, header *BlockHeader, responses []*ProofResponse, workers int) *AccountSnapshot {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(responses) {
+		workers = len(responses)
+	}
+
+	snapshot := &AccountSnapshot{
+		BlockHash: header.Hash(),
+		StateRoot: header.StateRoot,
+		Entries:   make([]SnapshotEntry, len(responses)),
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				snapshot.Entries[i] = v.snapshotEntry(header, responses[i])
+			}
+		}()
+	}
+
+	i := 0
+feed:
+	for ; i < len(responses) && ctx.Err() == nil; i++ {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	for ; i < len(responses); i++ {
+		snapshot.Entries[i] = SnapshotEntry{Address: responses[i].Address, Err: ctx.Err()}
+	}
+	return snapshot
+}
+
+func (v *ProofVerifier) snapshotEntry(header *BlockHeader, response *ProofResponse) SnapshotEntry {
+	entry := SnapshotEntry{Address: response.Address}
+	result, err := v.VerifyEthGetProofResponse(header, response)
+	if err == nil && !result.Valid {
+		err = result.Err()
+	}
+	if err == nil && (result.Account.Balance == nil || result.Account.Nonce == nil) {
+		err = errors.New("account record not resolved")
+	}
+	if err != nil {
+		entry.Err = err
+		return entry
+	}
+	entry.Exists = result.Account.Exists
+	entry.Balance, entry.Nonce = result.Account.Balance, result.Account.Nonce
+	return entry
+}
@@ -0,0 +1,72 @@
+package rskblocks
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestVerifyAccountSnapshot(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	trie := rsktrie.NewTrie(nil)
+	var holders []common.Address
+	for i := 1; i <= 20; i++ {
+		addr := common.BigToAddress(big.NewInt(int64(i)))
+		account, err := (&rsktrie.AccountState{Nonce: big.NewInt(int64(i)), Balance: big.NewInt(int64(i * 100))}).Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		trie = trie.Put(mapper.GetAccountKey(addr), account)
+		holders = append(holders, addr)
+	}
+	header := &BlockHeader{StateRoot: common.BytesToHash(trie.GetHash()), Number: big.NewInt(100)}
+
+	response := func(addr common.Address, balance, nonce int64) *ProofResponse {
+		return &ProofResponse{
+			Address:      addr,
+			AccountProof: hexProof(buildTestProof(t, trie, mapper.GetAccountKey(addr))),
+			Balance:      (*hexutil.Big)(big.NewInt(balance)),
+			Nonce:        hexutil.Uint64(nonce),
+		}
+	}
+	var responses []*ProofResponse
+	for i, addr := range holders {
+		responses = append(responses, response(addr, int64((i+1)*100), int64(i+1)))
+	}
+	absent := common.BigToAddress(big.NewInt(999))
+	responses = append(responses,
+		response(absent, 0, 0),
+		response(holders[0], 1, 1)) // misreported balance
+
+	snapshot := NewProofVerifier().VerifyAccountSnapshot(context.Background(), header, responses, 4)
+	if snapshot.StateRoot != header.StateRoot || snapshot.BlockHash != header.Hash() {
+		t.Errorf("Unexpected snapshot block %s, root %s", snapshot.BlockHash, snapshot.StateRoot)
+	}
+	for i := range holders {
+		e := snapshot.Entries[i]
+		if e.Err != nil || !e.Exists || e.Balance.Int64() != int64((i+1)*100) || e.Nonce.Int64() != int64(i+1) {
+			t.Errorf("Entry %d: unexpected %+v", i, e)
+		}
+	}
+	if e := snapshot.Entries[20]; e.Err != nil || e.Exists || e.Balance.Sign() != 0 {
+		t.Errorf("Expected the absent account with zero balance, got %+v", e)
+	}
+	if failed := snapshot.Failed(); len(failed) != 1 || failed[0].Address != holders[0] {
+		t.Errorf("Expected the misreported entry to fail, got %+v", failed)
+	}
+	if total := snapshot.TotalBalance(); total.Int64() != 21000 {
+		t.Errorf("Expected total balance 21000, got %s", total)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	snapshot = NewProofVerifier().VerifyAccountSnapshot(ctx, header, responses, 4)
+	if len(snapshot.Failed()) != len(responses) {
+		t.Errorf("Expected every entry to fail after cancellation, got %d", len(snapshot.Failed()))
+	}
+}
@@ -0,0 +1,105 @@
+package rskblocks
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BatchItem is one (key, proof) pair for VerifyBatch. Items may use
+// different roots, e.g. deposits from several blocks.
+type BatchItem struct {
+	Root  common.Hash
+	Key   []byte   // trie key, see AccountBatchItem and StorageBatchItem
+	Proof [][]byte // RLP-encoded proof nodes
+}
+
+// AccountBatchItem returns a batch item for address's account proof.
+func (v *ProofVerifier) AccountBatchItem(root common.Hash, address common.Address, proofNodes [][]byte) BatchItem {
+	return BatchItem{Root: root, Key: v.keyMapper.GetAccountKey(address), Proof: proofNodes}
+}
+
+// StorageBatchItem returns a batch item for a storage slot proof.
+func (v *ProofVerifier) StorageBatchItem(root common.Hash, address common.Address, storageKey common.Hash, proofNodes [][]byte) BatchItem {
+	return BatchItem{Root: root, Key: v.keyMapper.GetAccountStorageKey(address, storageKey), Proof: proofNodes}
+}
+
+// BatchResult is the outcome of one BatchItem. Err is a *rsktrie.ProofError
+// for an invalid proof, or the context's error for items not verified
+// before cancellation.
+type BatchResult struct {
+	Result *rsktrie.KeyProofResult
+	Err    error
+}
+
+// BatchSummary counts batch results by status.
+type BatchSummary struct {
+	Present      int
+	ProvenAbsent int
+	Invalid      int
+}
+
+// AllValid reports whether every item verified, present or absent.
+func (s BatchSummary) AllValid() bool {
+	return s.Invalid == 0
+}
+
+// VerifyBatch verifies items concurrently on up to workers goroutines
+// (GOMAXPROCS if workers <= 0) and returns their results in item order,
+// with a summary. Cancelling ctx stops handing out items; those not yet
+// verified are reported invalid with ctx's error.
+func (v *ProofVerifier) VerifyBatch(ctx context.Context, items []BatchItem, workers int) ([]BatchResult, BatchSummary) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(items) {
+		workers = len(items)
+	}
+
+	results := make([]BatchResult, len(items))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				result, err := v.verifyKey(ctx, items[i].Root, items[i].Key, items[i].Proof)
+				results[i] = BatchResult{Result: result, Err: err}
+			}
+		}()
+	}
+
+	i := 0
+feed:
+	for ; i < len(items) && ctx.Err() == nil; i++ {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	for ; i < len(items); i++ {
+		results[i] = BatchResult{Result: &rsktrie.KeyProofResult{Status: rsktrie.ProofInvalid}, Err: ctx.Err()}
+	}
+
+	var summary BatchSummary
+	for _, r := range results {
+		switch r.Result.Status {
+		case rsktrie.ProofPresent:
+			summary.Present++
+		case rsktrie.ProofProvenAbsent:
+			summary.ProvenAbsent++
+		default:
+			summary.Invalid++
+		}
+	}
+	return results, summary
+}
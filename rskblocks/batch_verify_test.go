@@ -0,0 +1,67 @@
+package rskblocks
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestVerifyBatch(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	verifier := NewProofVerifier()
+
+	// Two blocks' worth of state with different roots.
+	var items []BatchItem
+	var wantStatus []rsktrie.ProofStatus
+	for block := 0; block < 2; block++ {
+		trie := rsktrie.NewTrie(nil)
+		for i := 0; i < 50; i++ {
+			addr := common.BigToAddress(big.NewInt(int64(block*1000 + i)))
+			trie = trie.Put(mapper.GetAccountKey(addr), []byte{byte(i + 1)})
+		}
+		root := common.BytesToHash(trie.GetHash())
+		for i := 0; i < 60; i++ {
+			addr := common.BigToAddress(big.NewInt(int64(block*1000 + i)))
+			items = append(items, verifier.AccountBatchItem(root, addr, buildTestProof(t, trie, mapper.GetAccountKey(addr))))
+			if i < 50 {
+				wantStatus = append(wantStatus, rsktrie.ProofPresent)
+			} else {
+				wantStatus = append(wantStatus, rsktrie.ProofProvenAbsent)
+			}
+		}
+	}
+	// One proof checked against the wrong root.
+	bad := items[0]
+	bad.Root = items[len(items)-1].Root
+	items = append(items, bad)
+	wantStatus = append(wantStatus, rsktrie.ProofInvalid)
+
+	results, summary := verifier.VerifyBatch(context.Background(), items, 4)
+	if len(results) != len(items) {
+		t.Fatalf("Expected %d results, got %d", len(items), len(results))
+	}
+	for i, r := range results {
+		if r.Result.Status != wantStatus[i] {
+			t.Errorf("Item %d: expected %s, got %s (%v)", i, wantStatus[i], r.Result.Status, r.Err)
+		}
+	}
+	if summary.Present != 100 || summary.ProvenAbsent != 20 || summary.Invalid != 1 || summary.AllValid() {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+	var perr *rsktrie.ProofError
+	if !errors.As(results[len(results)-1].Err, &perr) {
+		t.Errorf("Expected *ProofError for the bad item, got %v", results[len(results)-1].Err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, summary = verifier.VerifyBatch(ctx, items, 0)
+	if summary.Invalid == 0 || !errors.Is(results[len(results)-1].Err, context.Canceled) {
+		t.Errorf("Expected cancelled items to be invalid, got %+v", summary)
+	}
+}
@@ -0,0 +1,274 @@
+package rskblocks
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BitcoinTx is a parsed Bitcoin transaction, with just enough structure to
+// find and check an RSK merged mining commitment in a coinbase.
+type BitcoinTx struct {
+	Version  int32
+	Inputs   []BitcoinTxIn
+	Outputs  []BitcoinTxOut
+	LockTime uint32
+	// Witness holds each input's witness stack for a segwit transaction,
+	// nil otherwise.
+	Witness [][][]byte
+}
+
+// BitcoinTxIn is a transaction input.
+type BitcoinTxIn struct {
+	PrevHash  [32]byte
+	PrevIndex uint32
+	Script    []byte
+	Sequence  uint32
+}
+
+// BitcoinTxOut is a transaction output.
+type BitcoinTxOut struct {
+	Value  int64
+	Script []byte
+}
+
+// maxBitcoinTxItems caps input, output and witness item counts so a hostile
+// length prefix cannot force a huge allocation.
+const maxBitcoinTxItems = 1 << 16
+
+// ParseBitcoinTx decodes a serialized transaction in legacy or segwit (BIP
+// 144) format. Trailing bytes are an error.
+func ParseBitcoinTx(data []byte) (*BitcoinTx, error) {
+	r := bytes.NewReader(data)
+	tx := &BitcoinTx{}
+	var version uint32
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return nil, fmt.Errorf("bitcoin tx version: %w", err)
+	}
+	tx.Version = int32(version)
+
+	inputs, err := readCompactSize(r)
+	if err != nil {
+		return nil, err
+	}
+	segwit := false
+	if inputs == 0 {
+		flag, err := r.ReadByte()
+		if err != nil || flag != 1 {
+			return nil, errors.New("bitcoin tx has no inputs")
+		}
+		segwit = true
+		if inputs, err = readCompactSize(r); err != nil {
+			return nil, err
+		}
+	}
+	if inputs > maxBitcoinTxItems {
+		return nil, fmt.Errorf("bitcoin tx has %d inputs", inputs)
+	}
+	for i := uint64(0); i < inputs; i++ {
+		var in BitcoinTxIn
+		if _, err := io.ReadFull(r, in.PrevHash[:]); err != nil {
+			return nil, fmt.Errorf("bitcoin tx input %d: %w", i, err)
+		}
+		if err := binary.Read(r, binary.LittleEndian, &in.PrevIndex); err != nil {
+			return nil, fmt.Errorf("bitcoin tx input %d: %w", i, err)
+		}
+		if in.Script, err = readVarBytes(r); err != nil {
+			return nil, fmt.Errorf("bitcoin tx input %d: %w", i, err)
+		}
+		if err := binary.Read(r, binary.LittleEndian, &in.Sequence); err != nil {
+			return nil, fmt.Errorf("bitcoin tx input %d: %w", i, err)
+		}
+		tx.Inputs = append(tx.Inputs, in)
+	}
+
+	outputs, err := readCompactSize(r)
+	if err != nil {
+		return nil, err
+	}
+	if outputs > maxBitcoinTxItems {
+		return nil, fmt.Errorf("bitcoin tx has %d outputs", outputs)
+	}
+	for i := uint64(0); i < outputs; i++ {
+		var out BitcoinTxOut
+		if err := binary.Read(r, binary.LittleEndian, &out.Value); err != nil {
+			return nil, fmt.Errorf("bitcoin tx output %d: %w", i, err)
+		}
+		if out.Script, err = readVarBytes(r); err != nil {
+			return nil, fmt.Errorf("bitcoin tx output %d: %w", i, err)
+		}
+		tx.Outputs = append(tx.Outputs, out)
+	}
+
+	if segwit {
+		tx.Witness = make([][][]byte, len(tx.Inputs))
+		for i := range tx.Inputs {
+			items, err := readCompactSize(r)
+			if err != nil {
+				return nil, err
+			}
+			if items > maxBitcoinTxItems {
+				return nil, fmt.Errorf("bitcoin tx witness %d has %d items", i, items)
+			}
+			stack := make([][]byte, 0, items)
+			for j := uint64(0); j < items; j++ {
+				item, err := readVarBytes(r)
+				if err != nil {
+					return nil, fmt.Errorf("bitcoin tx witness %d: %w", i, err)
+				}
+				stack = append(stack, item)
+			}
+			tx.Witness[i] = stack
+		}
+	}
+	if err := binary.Read(r, binary.LittleEndian, &tx.LockTime); err != nil {
+		return nil, fmt.Errorf("bitcoin tx lock time: %w", err)
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("bitcoin tx has %d trailing bytes", r.Len())
+	}
+	return tx, nil
+}
+
+// IsCoinbase reports whether tx is a coinbase: a single input spending the
+// null outpoint.
+func (tx *BitcoinTx) IsCoinbase() bool {
+	return len(tx.Inputs) == 1 && tx.Inputs[0].PrevHash == [32]byte{} && tx.Inputs[0].PrevIndex == 0xffffffff
+}
+
+// Serialize returns the transaction without witness data, the form that is
+// hashed into the txid and the merkle tree.
+func (tx *BitcoinTx) Serialize() []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(tx.Version))
+	writeCompactSize(&buf, uint64(len(tx.Inputs)))
+	for _, in := range tx.Inputs {
+		buf.Write(in.PrevHash[:])
+		binary.Write(&buf, binary.LittleEndian, in.PrevIndex)
+		writeVarBytes(&buf, in.Script)
+		binary.Write(&buf, binary.LittleEndian, in.Sequence)
+	}
+	writeCompactSize(&buf, uint64(len(tx.Outputs)))
+	for _, out := range tx.Outputs {
+		binary.Write(&buf, binary.LittleEndian, out.Value)
+		writeVarBytes(&buf, out.Script)
+	}
+	binary.Write(&buf, binary.LittleEndian, tx.LockTime)
+	return buf.Bytes()
+}
+
+// TxID returns the transaction hash in internal byte order, as it appears in
+// merkle proofs (reverse it for display).
+func (tx *BitcoinTx) TxID() []byte {
+	return doubleSHA256(tx.Serialize())
+}
+
+// RskCommitment returns the hash for merged mining the coinbase commits to;
+// see ExtractRskCommitment.
+func (tx *BitcoinTx) RskCommitment() (common.Hash, error) {
+	return ExtractRskCommitment(tx.Serialize())
+}
+
+// ExtractRskCommitment returns the hash that follows the RSK tag in data, a
+// coinbase transaction or its tail, enforcing rskj's placement rules: there
+// is exactly one RSK tag, the full 32-byte hash follows it, and at most
+// MaxBytesAfterMergedMiningHash bytes follow the hash.
+func ExtractRskCommitment(data []byte) (common.Hash, error) {
+	pos, err := rskTagIndex(data)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	start := pos + len(RskTag)
+	end := start + common.HashLength
+	if end > len(data) {
+		return common.Hash{}, errors.New("coinbase transaction is truncated after the RSK tag")
+	}
+	if after := len(data) - end; after > MaxBytesAfterMergedMiningHash {
+		return common.Hash{}, fmt.Errorf("%d bytes follow the RSK commitment, at most %d allowed", after, MaxBytesAfterMergedMiningHash)
+	}
+	return common.BytesToHash(data[start:end]), nil
+}
+
+// CompressCoinbase converts a serialized coinbase transaction (without
+// witness) to the RSKIP-92 header field: the SHA-256 midstate after the
+// whole 64-byte blocks before the RSK tag, followed by the remaining bytes.
+// This is what a merged miner submits alongside the Bitcoin header.
+func CompressCoinbase(tx []byte) ([]byte, error) {
+	pos, err := rskTagIndex(tx)
+	if err != nil {
+		return nil, err
+	}
+	split := pos / 64 * 64
+	digest := sha256.New()
+	digest.Write(tx[:split])
+	state, err := digest.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	field := binary.BigEndian.AppendUint64(make([]byte, 0, coinbaseMidstateLength+len(tx)-split), uint64(split))
+	field = append(field, state[4:4+32]...)
+	return append(field, tx[split:]...), nil
+}
+
+func readCompactSize(r *bytes.Reader) (uint64, error) {
+	prefix, err := r.ReadByte()
+	if err != nil {
+		return 0, fmt.Errorf("bitcoin compact size: %w", err)
+	}
+	var size int
+	switch prefix {
+	case 0xfd:
+		size = 2
+	case 0xfe:
+		size = 4
+	case 0xff:
+		size = 8
+	default:
+		return uint64(prefix), nil
+	}
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[:size]); err != nil {
+		return 0, fmt.Errorf("bitcoin compact size: %w", err)
+	}
+	return binary.LittleEndian.Uint64(buf[:]), nil
+}
+
+func readVarBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := readCompactSize(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(r.Len()) {
+		return nil, fmt.Errorf("length %d exceeds remaining %d bytes", n, r.Len())
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return b, err
+}
+
+func writeCompactSize(buf *bytes.Buffer, n uint64) {
+	switch {
+	case n < 0xfd:
+		buf.WriteByte(byte(n))
+	case n <= 0xffff:
+		buf.WriteByte(0xfd)
+		binary.Write(buf, binary.LittleEndian, uint16(n))
+	case n <= 0xffffffff:
+		buf.WriteByte(0xfe)
+		binary.Write(buf, binary.LittleEndian, uint32(n))
+	default:
+		buf.WriteByte(0xff)
+		binary.Write(buf, binary.LittleEndian, n)
+	}
+}
+
+func writeVarBytes(buf *bytes.Buffer, b []byte) {
+	writeCompactSize(buf, uint64(len(b)))
+	buf.Write(b)
+}
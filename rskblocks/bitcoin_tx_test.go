@@ -0,0 +1,130 @@
+package rskblocks
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestParseBitcoinTxGenesisCoinbase(t *testing.T) {
+	raw, _ := hex.DecodeString("01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000")
+	tx, err := ParseBitcoinTx(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tx.IsCoinbase() || len(tx.Outputs) != 1 || tx.Outputs[0].Value != 5000000000 {
+		t.Errorf("Unexpected genesis coinbase %+v", tx)
+	}
+	if !bytes.Equal(tx.Serialize(), raw) {
+		t.Error("Serialize does not round-trip")
+	}
+	txid := tx.TxID()
+	for i, j := 0, len(txid)-1; i < j; i, j = i+1, j-1 {
+		txid[i], txid[j] = txid[j], txid[i]
+	}
+	if got := hex.EncodeToString(txid); got != "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b" {
+		t.Errorf("TxID %s", got)
+	}
+	if _, err := tx.RskCommitment(); !errors.Is(err, ErrNoRskTag) {
+		t.Errorf("Expected ErrNoRskTag, got %v", err)
+	}
+}
+
+func mergedMiningCoinbase(commitment common.Hash) *BitcoinTx {
+	return &BitcoinTx{
+		Version: 2,
+		Inputs: []BitcoinTxIn{{
+			PrevIndex: 0xffffffff,
+			Script:    []byte{0x03, 0x40, 0x42, 0x0f},
+			Sequence:  0xffffffff,
+		}},
+		Outputs: []BitcoinTxOut{
+			{Value: 625000000, Script: bytes.Repeat([]byte{0x51}, 25)},
+			{Script: append(append([]byte{0x6a, 0x29}, RskTag...), commitment[:]...)},
+		},
+		Witness: [][][]byte{{make([]byte, 32)}},
+	}
+}
+
+func TestParseBitcoinTxSegwit(t *testing.T) {
+	commitment := common.HexToHash("0x1234")
+	tx := mergedMiningCoinbase(commitment)
+	// BIP 144: marker and flag after the version, witnesses before lock time.
+	stripped := tx.Serialize()
+	var segwit bytes.Buffer
+	segwit.Write(stripped[:4])
+	segwit.Write([]byte{0x00, 0x01})
+	segwit.Write(stripped[4 : len(stripped)-4])
+	segwit.Write([]byte{0x01, 0x20})
+	segwit.Write(make([]byte, 32))
+	segwit.Write(stripped[len(stripped)-4:])
+
+	parsed, err := ParseBitcoinTx(segwit.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Witness) != 1 || len(parsed.Witness[0]) != 1 || !parsed.IsCoinbase() {
+		t.Errorf("Unexpected parsed tx %+v", parsed)
+	}
+	if !bytes.Equal(parsed.Serialize(), stripped) {
+		t.Error("Serialize should drop the witness")
+	}
+	got, err := parsed.RskCommitment()
+	if err != nil || got != commitment {
+		t.Errorf("RskCommitment = %s, %v; want %s", got, err, commitment)
+	}
+
+	for name, data := range map[string][]byte{
+		"trailing bytes": append(segwit.Bytes(), 0),
+		"truncated":      segwit.Bytes()[:segwit.Len()-1],
+		"bad script len": append(append([]byte(nil), stripped[:41]...), 0xfd, 0xff, 0xff),
+	} {
+		if _, err := ParseBitcoinTx(data); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestExtractRskCommitment(t *testing.T) {
+	hash := common.HexToHash("0xabcdef")
+	commitment := append(append([]byte(nil), RskTag...), hash[:]...)
+	if got, err := ExtractRskCommitment(append([]byte("prefix"), commitment...)); err != nil || got != hash {
+		t.Errorf("Got %s, %v", got, err)
+	}
+	cases := map[string][]byte{
+		"no tag":      []byte("nothing to see"),
+		"truncated":   commitment[:len(commitment)-1],
+		"trailing":    append(append([]byte(nil), commitment...), make([]byte, MaxBytesAfterMergedMiningHash+1)...),
+		"earlier tag": append(append([]byte(nil), RskTag...), commitment...),
+		"later tag":   append(append([]byte(nil), commitment...), RskTag...),
+	}
+	for name, data := range cases {
+		if _, err := ExtractRskCommitment(data); err == nil {
+			t.Errorf("%s: expected the commitment to be rejected", name)
+		}
+	}
+	if _, err := ExtractRskCommitment(cases["earlier tag"]); !errors.Is(err, ErrMultipleRskTags) {
+		t.Errorf("Expected ErrMultipleRskTags, got %v", err)
+	}
+	if _, err := ExtractRskCommitment(append(append([]byte(nil), commitment...), make([]byte, MaxBytesAfterMergedMiningHash)...)); err != nil {
+		t.Errorf("Expected %d trailing bytes to be allowed: %v", MaxBytesAfterMergedMiningHash, err)
+	}
+}
+
+func TestCompressCoinbase(t *testing.T) {
+	raw := mergedMiningCoinbase(common.HexToHash("0x01")).Serialize()
+	field, err := CompressCoinbase(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txid, err := coinbaseTxHash(field, common.HexToHash("0x01").Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(txid, doubleSHA256(raw)) {
+		t.Error("Compressed coinbase does not hash to the txid")
+	}
+}
@@ -0,0 +1,89 @@
+package rskblocks
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Block is a full RSK block: header, transactions and uncle headers.
+type Block struct {
+	Header       *BlockHeader
+	Transactions []*Transaction
+	Uncles       []*BlockHeader
+}
+
+// blockRLP is the block's wire form, RLP([header, [txs...], [uncles...]]).
+type blockRLP struct {
+	Header       rlp.RawValue
+	Transactions []*Transaction
+	Uncles       []rlp.RawValue
+}
+
+// DecodeBlock decodes a block in rskj's encoding, as carried in devp2p block
+// messages and raw RPC responses. The header and uncles are decoded with
+// DecodeBlockHeader under config; uncles are at most a few blocks older than
+// the block, so they share its activation state.
+func DecodeBlock(data []byte, config BlockHashConfig) (*Block, error) {
+	var dec blockRLP
+	if err := rlp.DecodeBytes(data, &dec); err != nil {
+		return nil, fmt.Errorf("decode block: %w", err)
+	}
+	header, err := DecodeBlockHeader(dec.Header, config)
+	if err != nil {
+		return nil, err
+	}
+	block := &Block{Header: header, Transactions: dec.Transactions}
+	for i, raw := range dec.Uncles {
+		uncle, err := DecodeBlockHeader(raw, config)
+		if err != nil {
+			return nil, fmt.Errorf("uncle %d: %w", i, err)
+		}
+		block.Uncles = append(block.Uncles, uncle)
+	}
+	return block, nil
+}
+
+// EncodeRLP implements rlp.Encoder, writing the block as DecodeBlock reads
+// it.
+func (b *Block) EncodeRLP(w io.Writer) error {
+	enc := blockRLP{Header: b.Header.GetFullEncoded(), Transactions: b.Transactions}
+	if enc.Transactions == nil {
+		enc.Transactions = []*Transaction{}
+	}
+	enc.Uncles = make([]rlp.RawValue, len(b.Uncles))
+	for i, uncle := range b.Uncles {
+		enc.Uncles[i] = uncle.GetFullEncoded()
+	}
+	return rlp.Encode(w, enc)
+}
+
+// Hash returns the block hash, the header's.
+func (b *Block) Hash() common.Hash {
+	return b.Header.Hash()
+}
+
+// UnclesHash returns keccak256 of the RLP list of the uncles' full
+// encodings, which the header commits to as unclesHash.
+func (b *Block) UnclesHash() common.Hash {
+	uncles := make([]rlp.RawValue, len(b.Uncles))
+	for i, uncle := range b.Uncles {
+		uncles[i] = uncle.GetFullEncoded()
+	}
+	encoded, _ := rlp.EncodeToBytes(uncles)
+	return keccak256Hash(encoded)
+}
+
+// VerifyBody reports whether the transactions and uncles are the ones the
+// header commits to, by recomputing txTrieRoot and unclesHash.
+func (b *Block) VerifyBody() error {
+	if err := VerifyTxRoot(b.Header, b.Transactions); err != nil {
+		return err
+	}
+	if hash := b.UnclesHash(); hash != b.Header.UnclesHash {
+		return fmt.Errorf("uncles hash to %s, header has %s", hash.Hex(), b.Header.UnclesHash.Hex())
+	}
+	return nil
+}
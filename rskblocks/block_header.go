@@ -2,6 +2,8 @@ package rskblocks
 
 import (
 	"bytes"
+	"fmt"
+	"io"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -10,7 +12,8 @@ import (
 )
 
 // BlockHeader represents an RSK block header.
-// This is a minimal implementation focused on computing the block hash.
+// It computes the block hash and decodes from rskj's full RLP encoding
+// (see DecodeBlockHeader).
 type BlockHeader struct {
 	ParentHash      common.Hash    // SHA3 256-bit hash of the parent block
 	UnclesHash      common.Hash    // SHA3 256-bit hash of the uncles list
@@ -18,7 +21,7 @@ type BlockHeader struct {
 	StateRoot       common.Hash    // SHA3 256-bit hash of the state trie root
 	TxTrieRoot      common.Hash    // SHA3 256-bit hash of the transactions trie root
 	ReceiptTrieRoot common.Hash    // SHA3 256-bit hash of the receipts trie root
-	LogsBloom       [256]byte      // 256-byte bloom filter
+	LogsBloom       Bloom          // 256-byte bloom filter
 	Difficulty      *big.Int       // Block difficulty
 	Number          *big.Int       // Block number
 	GasLimit        []byte         // Gas limit - stored as minimal raw bytes (no leading zeros)
@@ -42,18 +45,46 @@ type BlockHeader struct {
 	// RSKIP-92 encoding flag
 	UseRskip92Encoding bool
 
+	// RSKIP-110: HashForMergedMining ends in fork detection data. For a
+	// header without merged mining fields yet (being mined), the data is
+	// taken from ForkDetectionData.
+	IncludeForkDetectionData bool
+	ForkDetectionData        []byte
+
 	// RSKIP-351/535: Header version (0 for V0, 1 for V1, 2 for V2)
 	// V1/V2 headers use extensionData instead of raw logsBloom in encoding
 	// V2 adds baseEvent to extensionHash computation
 	Version byte
 }
 
-// Hash computes the block header hash using Keccak256 of the RLP-encoded header.
+// Hash computes the block hash as rskj does: Keccak256 of GetEncodedForHash.
+// Compared with the full encoding, the hashed encoding
+//   - omits the merged mining merkle proof and coinbase transaction once
+//     RSKIP-92 is active (UseRskip92Encoding), keeping only the Bitcoin
+//     header;
+//   - for V1/V2 headers replaces logsBloom with extensionData, which commits
+//     to the bloom, the edges and (V2) the baseEvent by hash, and leaves out
+//     the version, baseEvent and edges fields.
 func (h *BlockHeader) Hash() common.Hash {
 	encoded := h.GetEncodedForHash()
 	return keccak256Hash(encoded)
 }
 
+// CheckHash reports whether the header hashes to expected, authenticating a
+// header fetched from an untrusted source against a known block hash.
+func (h *BlockHeader) CheckHash(expected common.Hash) error {
+	if hash := h.Hash(); hash != expected {
+		return fmt.Errorf("header hashes to %s, want %s", hash.Hex(), expected.Hex())
+	}
+	return nil
+}
+
+// EncodeRLP implements rlp.Encoder, writing the full encoding.
+func (h *BlockHeader) EncodeRLP(w io.Writer) error {
+	_, err := w.Write(h.GetFullEncoded())
+	return err
+}
+
 // GetEncodedForHash returns the RLP encoding used for computing the block hash.
 // This uses compressed encoding with merged mining fields but without
 // merkle proof and coinbase transaction (for RSKIP-92 enabled blocks).
@@ -115,14 +146,17 @@ func (h *BlockHeader) getEncoded(withMergedMiningFields, withMerkleProofAndCoinb
 		if h.TxExecutionSublistsEdges != nil {
 			fields = append(fields, encodeShortsToRLP(h.TxExecutionSublistsEdges))
 		}
-	} else if h.Version == 1 && !compressed {
-		// V1 non-compressed: add version and edges
+	} else if !compressed {
+		// V1/V2 non-compressed: add version, the V2 baseEvent and edges
 		fields = append(fields, []byte{h.Version})
+		if h.Version == 2 {
+			fields = append(fields, nonNilBytes(h.BaseEvent))
+		}
 		if h.TxExecutionSublistsEdges != nil {
 			fields = append(fields, encodeShortsToRLP(h.TxExecutionSublistsEdges))
 		}
 	}
-	// V1 compressed: don't add version or edges (they're in extensionData)
+	// V1/V2 compressed: don't add version, baseEvent or edges (they're in extensionData)
 
 	// Merged mining fields
 	if withMergedMiningFields && h.hasMiningFields() {
@@ -138,58 +172,10 @@ func (h *BlockHeader) getEncoded(withMergedMiningFields, withMerkleProofAndCoinb
 	return buf.Bytes()
 }
 
-// computeExtensionData computes the extensionData for V1/V2 headers.
-// extensionData = RLP([version, extensionHash])
-// V1: extensionHash = Keccak256(RLP([Keccak256(logsBloom), edgesBytes]))
-// V2: extensionHash = Keccak256(RLP([Keccak256(logsBloom), baseEvent, edgesBytes]))
-// Note: logsBloom is HASHED before being included in the extension content!
+// computeExtensionData computes the extensionData for V1/V2 headers,
+// RLP([version, extensionHash]); see BlockHeaderExtension.Hash.
 func (h *BlockHeader) computeExtensionData() []byte {
-	// First, hash the logsBloom (Java: HashUtil.keccak256(this.getLogsBloom()))
-	logsBloomHash := keccak256Hash(h.LogsBloom[:])
-
-	// Convert edges to bytes (little-endian, 2 bytes per short)
-	// Empty edges [] is different from null - empty means include 0x80
-	var edgesBytes []byte
-	if h.TxExecutionSublistsEdges != nil {
-		edgesBytes = make([]byte, len(h.TxExecutionSublistsEdges)*2)
-		for i, edge := range h.TxExecutionSublistsEdges {
-			// Little-endian encoding
-			edgesBytes[i*2] = byte(edge)
-			edgesBytes[i*2+1] = byte(edge >> 8)
-		}
-	}
-	// Note: if edges is nil, edgesBytes stays nil and won't be included
-
-	// Build extension content based on version
-	var extContent bytes.Buffer
-	if h.Version == 2 {
-		// V2: [logsBloomHash, baseEvent, edgesBytes]
-		// baseEvent is included even if empty (encodes as 0x80)
-		baseEvent := h.BaseEvent
-		if baseEvent == nil {
-			baseEvent = []byte{}
-		}
-		if edgesBytes != nil {
-			rlp.Encode(&extContent, []interface{}{logsBloomHash.Bytes(), baseEvent, edgesBytes})
-		} else {
-			rlp.Encode(&extContent, []interface{}{logsBloomHash.Bytes(), baseEvent})
-		}
-	} else {
-		// V1: [logsBloomHash, edgesBytes]
-		if edgesBytes != nil {
-			rlp.Encode(&extContent, []interface{}{logsBloomHash.Bytes(), edgesBytes})
-		} else {
-			rlp.Encode(&extContent, []interface{}{logsBloomHash.Bytes()})
-		}
-	}
-
-	// Hash the extension content to get extensionHash
-	extensionHash := keccak256Hash(extContent.Bytes())
-
-	// Encode extensionData: [version, extensionHash]
-	var extData bytes.Buffer
-	rlp.Encode(&extData, []interface{}{[]byte{h.Version}, extensionHash.Bytes()})
-	return extData.Bytes()
+	return h.Extension().extensionData()
 }
 
 // hasMiningFields returns true if this header has bitcoin merged mining data.
@@ -270,6 +256,15 @@ func encodeShortsToRLP(shorts []int16) []byte {
 	return buf.Bytes()
 }
 
+// nonNilBytes returns b, or an empty slice if b is nil, so that RLP encodes
+// an absent value as an empty string (0x80).
+func nonNilBytes(b []byte) []byte {
+	if b == nil {
+		return []byte{}
+	}
+	return b
+}
+
 // keccak256Hash computes the Keccak256 hash of the input.
 func keccak256Hash(data []byte) common.Hash {
 	h := sha3.NewLegacyKeccak256()
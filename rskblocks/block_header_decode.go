@@ -0,0 +1,149 @@
+package rskblocks
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// coreHeaderFields is the number of fields every RSK header starts with,
+// parentHash through uncleCount.
+const coreHeaderFields = 16
+
+// DecodeBlockHeader decodes a header in its full RLP encoding, as returned by
+// GetFullEncoded and as rskj stores and relays it (BlockFactory.decodeHeader).
+//
+// Which optional fields follow uncleCount depends on activated RSKIPs, not
+// on anything in the encoding, so config says what to expect: the ummRoot
+// when IncludeUmmRoot is set, and for V1/V2 headers the version byte (and
+// for V2 the baseEvent). The parallel-execution edges and the three merged
+// mining fields are recognised by the number of fields left. The decoded
+// header's UseRskip92Encoding, IncludeForkDetectionData and, for V0,
+// Version come from config. V1/V2 headers relayed in the compressed
+// encoding are decoded with DecodeCompressedBlockHeader.
+func DecodeBlockHeader(data []byte, config BlockHashConfig) (*BlockHeader, error) {
+	fields, err := decodeHeaderFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &BlockHeader{
+		GasLimit:                 fields[9],
+		ExtraData:                fields[12],
+		UseRskip92Encoding:       config.UseRskip92Encoding,
+		IncludeForkDetectionData: config.IncludeForkDetectionData,
+	}
+	for i, dst := range []*common.Hash{&h.ParentHash, &h.UnclesHash, nil, &h.StateRoot, &h.TxTrieRoot, &h.ReceiptTrieRoot} {
+		if dst == nil {
+			continue
+		}
+		if *dst, err = decodeHeaderHash(fields[i]); err != nil {
+			return nil, fmt.Errorf("header field %d: %w", i, err)
+		}
+	}
+	switch len(fields[2]) {
+	case 0:
+	case common.AddressLength:
+		h.Coinbase = common.BytesToAddress(fields[2])
+	default:
+		return nil, fmt.Errorf("coinbase has %d bytes", len(fields[2]))
+	}
+	if len(fields[6]) != len(h.LogsBloom) {
+		return nil, fmt.Errorf("logsBloom has %d bytes, want %d", len(fields[6]), len(h.LogsBloom))
+	}
+	copy(h.LogsBloom[:], fields[6])
+	h.Difficulty = new(big.Int).SetBytes(fields[7])
+	h.Number = new(big.Int).SetBytes(fields[8])
+	h.GasUsed = new(big.Int).SetBytes(fields[10])
+	h.Timestamp = new(big.Int).SetBytes(fields[11])
+	h.PaidFees = new(big.Int).SetBytes(fields[13])
+	if len(fields[14]) > 0 {
+		h.MinimumGasPrice = new(big.Int).SetBytes(fields[14])
+	}
+	uncleCount := new(big.Int).SetBytes(fields[15])
+	if !uncleCount.IsInt64() || uncleCount.Int64() > math.MaxInt32 {
+		return nil, errors.New("uncleCount out of range")
+	}
+	h.UncleCount = int(uncleCount.Int64())
+
+	rest := fields[coreHeaderFields:]
+	next := func(name string) ([]byte, error) {
+		if len(rest) == 0 {
+			return nil, fmt.Errorf("header is missing %s", name)
+		}
+		field := rest[0]
+		rest = rest[1:]
+		return field, nil
+	}
+	if config.IncludeUmmRoot {
+		umm, err := next("ummRoot")
+		if err != nil {
+			return nil, err
+		}
+		h.UmmRoot = &umm
+		if err := h.ValidateUmmRoot(); err != nil {
+			return nil, err
+		}
+	}
+	if config.Version >= 1 {
+		version, err := next("version")
+		if err != nil {
+			return nil, err
+		}
+		if len(version) != 1 || version[0] != config.Version {
+			return nil, fmt.Errorf("header version %x, want %d", version, config.Version)
+		}
+		h.Version = config.Version
+		if config.Version == 2 {
+			if h.BaseEvent, err = next("baseEvent"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	switch len(rest) {
+	case 0, 3:
+	case 1, 4:
+		if h.TxExecutionSublistsEdges, err = decodeShortsFromRLP(rest[0]); err != nil {
+			return nil, fmt.Errorf("txExecutionSublistsEdges: %w", err)
+		}
+		rest = rest[1:]
+	default:
+		return nil, fmt.Errorf("header has %d unexpected trailing fields", len(rest))
+	}
+	if len(rest) == 3 {
+		h.BitcoinMergedMiningHeader = rest[0]
+		h.BitcoinMergedMiningMerkleProof = rest[1]
+		h.BitcoinMergedMiningCoinbaseTransaction = rest[2]
+	}
+	return h, nil
+}
+
+func decodeHeaderHash(b []byte) (common.Hash, error) {
+	if len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("hash has %d bytes", len(b))
+	}
+	return common.BytesToHash(b), nil
+}
+
+// decodeShortsFromRLP reverses encodeShortsToRLP.
+func decodeShortsFromRLP(b []byte) ([]int16, error) {
+	if len(b) == 0 {
+		return []int16{}, nil
+	}
+	var values []uint64
+	if err := rlp.DecodeBytes(b, &values); err != nil {
+		return nil, err
+	}
+	shorts := make([]int16, len(values))
+	for i, v := range values {
+		if v > math.MaxInt16 {
+			return nil, fmt.Errorf("edge %d out of range", v)
+		}
+		shorts[i] = int16(v)
+	}
+	return shorts, nil
+}
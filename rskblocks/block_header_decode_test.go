@@ -0,0 +1,112 @@
+package rskblocks
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func testHeaderInput() *BlockHeaderInput {
+	input := &BlockHeaderInput{
+		ParentHash:      common.HexToHash("0x01"),
+		UnclesHash:      common.HexToHash("0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347"),
+		Coinbase:        common.HexToAddress("0xec4ddeb4380ad69b3e509baad9f158cdf4e4681d"),
+		StateRoot:       common.HexToHash("0x02"),
+		TxTrieRoot:      common.HexToHash("0x03"),
+		ReceiptTrieRoot: common.HexToHash("0x04"),
+		Difficulty:      big.NewInt(0x1234567),
+		Number:          big.NewInt(7139700),
+		GasLimit:        big.NewInt(6800000),
+		GasUsed:         big.NewInt(21000),
+		Timestamp:       big.NewInt(0x69824213),
+		ExtraData:       []byte("extra"),
+		PaidFees:        big.NewInt(1000),
+		MinimumGasPrice: big.NewInt(0),
+		UncleCount:      2,
+	}
+	input.LogsBloom[3] = 0x40
+	return input
+}
+
+func TestDecodeBlockHeader(t *testing.T) {
+	mm := testHeaderInput()
+	mm.BitcoinMergedMiningHeader = bytes.Repeat([]byte{0xaa}, 80)
+	mm.BitcoinMergedMiningMerkleProof = bytes.Repeat([]byte{0xbb}, 64)
+	mm.BitcoinMergedMiningCoinbaseTransaction = bytes.Repeat([]byte{0xcc}, 100)
+	edges := testHeaderInput()
+	edges.TxExecutionSublistsEdges = []int16{3, 7}
+	umm := []byte{}
+	mmEdges := *mm
+	mmEdges.TxExecutionSublistsEdges = []int16{}
+	mmEdges.UmmRoot = &umm
+
+	cases := []struct {
+		name   string
+		input  *BlockHeaderInput
+		config BlockHashConfig
+	}{
+		{"pre-orchid", testHeaderInput(), ConfigForBlockNumber(1000, "mainnet")},
+		{"merged mining", mm, ConfigForBlockNumber(3000000, "mainnet")},
+		{"edges", edges, ConfigForBlockNumber(3000000, "mainnet")},
+		{"merged mining and edges", &mmEdges, ConfigForBlockNumber(3000000, "mainnet")},
+		{"v1", mm, ConfigForBlockNumber(7139700, "testnet")},
+		{"v1 edges", edges, ConfigForBlockNumber(7139700, "testnet")},
+	}
+	for _, c := range cases {
+		want := InputToBlockHeader(c.input, c.config)
+		got, err := DecodeBlockHeader(want.GetFullEncoded(), c.config)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if got.Hash() != want.Hash() {
+			t.Errorf("%s: hash %s, want %s", c.name, got.Hash(), want.Hash())
+		}
+		if !bytes.Equal(got.GetFullEncoded(), want.GetFullEncoded()) {
+			t.Errorf("%s: full encoding does not round-trip", c.name)
+		}
+		if got.Number.Cmp(want.Number) != 0 || got.UncleCount != want.UncleCount || got.Coinbase != want.Coinbase ||
+			got.MinimumGasPrice.Sign() != 0 || !bytes.Equal(got.BitcoinMergedMiningHeader, want.BitcoinMergedMiningHeader) {
+			t.Errorf("%s: decoded fields differ: %+v", c.name, got)
+		}
+		if (got.TxExecutionSublistsEdges == nil) != (want.TxExecutionSublistsEdges == nil) ||
+			len(got.TxExecutionSublistsEdges) != len(want.TxExecutionSublistsEdges) {
+			t.Errorf("%s: edges %v, want %v", c.name, got.TxExecutionSublistsEdges, want.TxExecutionSublistsEdges)
+		}
+	}
+}
+
+func TestDecodeBlockHeaderErrors(t *testing.T) {
+	config := ConfigForBlockNumber(3000000, "mainnet")
+	full := InputToBlockHeader(testHeaderInput(), config).GetFullEncoded()
+	var fields [][]byte
+	if err := rlp.DecodeBytes(full, &fields); err != nil {
+		t.Fatal(err)
+	}
+	encode := func(fields [][]byte) []byte {
+		b, err := rlp.EncodeToBytes(fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	short := append([][]byte(nil), fields...)
+	short[3] = short[3][:31]
+	cases := map[string][]byte{
+		"not a list":      {0x80},
+		"too few fields":  encode(fields[:10]),
+		"missing ummRoot": encode(fields[:coreHeaderFields]),
+		"short hash":      encode(short),
+		"trailing fields": encode(append(append([][]byte(nil), fields...), nil, nil)),
+	}
+	for name, data := range cases {
+		if _, err := DecodeBlockHeader(data, config); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := DecodeBlockHeader(full, ConfigForBlockNumber(7139700, "testnet")); err == nil {
+		t.Error("Expected error decoding a V0 header as V1")
+	}
+}
@@ -86,75 +86,25 @@ type BlockHashConfig struct {
 
 	// Use4ByteGasLimit: If true, pad gasLimit to 4 bytes (regtest). If false, use minimal bytes (mainnet/testnet).
 	Use4ByteGasLimit bool
+
+	// IncludeForkDetectionData: If true, the merged mining hash ends in RSKIP-110 fork detection data (post-wasabi100)
+	IncludeForkDetectionData bool
 }
 
 // DefaultRegtestConfig returns the default configuration for regtest mode.
 // All RSKIPs are active from block 0 in regtest, including RSKIP-535 (V2 headers).
 func DefaultRegtestConfig() BlockHashConfig {
-	return BlockHashConfig{
-		UseRskip92Encoding: true,
-		Version:            2, // V2 for RSKIP-535 (baseEvent support)
-		IncludeUmmRoot:     true,
-		Use4ByteGasLimit:   true, // Regtest uses 4-byte gasLimit
-	}
+	return RegtestChainConfig().BlockHashConfig(0)
 }
 
 // ConfigForBlockNumber returns the appropriate config based on block number and network.
-// These values are from RSKj's main.conf, testnet.conf, and reference.conf.
+// The activation heights are those of ChainConfigForNetwork(network); see
+// ChainConfig.BlockHashConfig.
 //
 // IMPORTANT: IncludeUmmRoot only controls whether UMM activation is enabled for the network.
 // The actual ummRoot should only be included if the block has one (check RPC response).
-//
-// Mainnet activation heights (from main.conf):
-//   - orchid = 729000 (RSKIP-92)
-//   - papyrus200 = 2392700 (UMM)
-//   - reed810 = -1 (RSKIP-144, RSKIP-351/V1 - NOT YET ACTIVATED)
-//   - vetiver900 = -1 (RSKIP-535/V2 - NOT YET ACTIVATED)
-//
-// Testnet activation heights (from testnet.conf):
-//   - orchid = 0 (RSKIP-92)
-//   - papyrus200 = 863000 (UMM)
-//   - reed810 = 7139600 (RSKIP-144, RSKIP-351/V1)
-//   - vetiver900 = -1 (RSKIP-535/V2 - NOT YET ACTIVATED)
-//
-// Regtest: All RSKIPs active from genesis, uses V2 headers
 func ConfigForBlockNumber(blockNum int64, network string) BlockHashConfig {
-	switch network {
-	case "regtest":
-		// In regtest, all RSKIPs are active from genesis (block 0)
-		// This includes RSKIP-535 (V2 headers with baseEvent)
-		return BlockHashConfig{
-			UseRskip92Encoding: true,
-			Version:            2, // V2 for RSKIP-535
-			IncludeUmmRoot:     true,
-			Use4ByteGasLimit:   true, // Regtest uses 4-byte gasLimit
-		}
-	case "mainnet":
-		// Mainnet: RSKIP-351 (V1) and RSKIP-535 (V2) are NOT YET ACTIVE
-		// UMM is active from papyrus200 (2392700)
-		return BlockHashConfig{
-			UseRskip92Encoding: blockNum >= 729000,  // orchid
-			Version:            0,                   // RSKIP-351 NOT active (reed810 = -1)
-			IncludeUmmRoot:     blockNum >= 2392700, // UMM active from papyrus200
-			Use4ByteGasLimit:   false,               // Mainnet uses minimal gasLimit
-		}
-	case "testnet":
-		// Testnet: RSKIP-351 (V1) activated at reed810 = 7139600
-		// RSKIP-535 (V2) NOT YET ACTIVE (vetiver900 = -1)
-		var version byte = 0
-		if blockNum >= 7139600 {
-			version = 1 // V1 after reed810
-		}
-		return BlockHashConfig{
-			UseRskip92Encoding: true, // orchid = 0
-			Version:            version,
-			IncludeUmmRoot:     blockNum >= 863000, // UMM active from papyrus200
-			Use4ByteGasLimit:   false,              // Testnet uses minimal gasLimit
-		}
-	default:
-		// Default to regtest behavior
-		return DefaultRegtestConfig()
-	}
+	return ChainConfigForNetwork(network).BlockHashConfig(uint64(max(blockNum, 0)))
 }
 
 // ComputeBlockHash computes the block hash from the given input and configuration.
@@ -206,8 +156,9 @@ func InputToBlockHeader(input *BlockHeaderInput, config BlockHashConfig) *BlockH
 		BitcoinMergedMiningCoinbaseTransaction: input.BitcoinMergedMiningCoinbaseTransaction,
 
 		// Configuration
-		UseRskip92Encoding: config.UseRskip92Encoding,
-		Version:            config.Version,
+		UseRskip92Encoding:       config.UseRskip92Encoding,
+		IncludeForkDetectionData: config.IncludeForkDetectionData,
+		Version:                  config.Version,
 	}
 
 	// UmmRoot handling:
@@ -0,0 +1,96 @@
+package rskblocks
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// regtestBlock1 is block 1 of a fresh regtest node with V2 headers; see
+// TestComputeBlockHashBlock1.
+func regtestBlock1() (*BlockHeader, common.Hash) {
+	input := &BlockHeaderInput{
+		ParentHash:               common.HexToHash("0x8ea789fabef0dd4946ed53f001e7b6f8a8d0c22a612a6099fc7f93c990af68fe"),
+		UnclesHash:               common.HexToHash("0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347"),
+		Coinbase:                 common.HexToAddress("0xec4ddeb4380ad69b3e509baad9f158cdf4e4681d"),
+		StateRoot:                common.HexToHash("0xf276a3a8c9c4eb4dcbbfb9bf6965f36dc611b815614c0d7cd06e15b8890c272c"),
+		TxTrieRoot:               common.HexToHash("0x8c9664a30670ddc67aa13992fdd8751b7b797bbe172506ffd5cda10ebbf97952"),
+		ReceiptTrieRoot:          common.HexToHash("0x66cfdb731f620cd96e2c2cb0f7d3c3a2879c29b40014aa27efbbf3cf9cd3b0f6"),
+		Difficulty:               big.NewInt(1),
+		Number:                   big.NewInt(1),
+		GasLimit:                 big.NewInt(10000000),
+		GasUsed:                  big.NewInt(0),
+		Timestamp:                big.NewInt(0x69824213),
+		ExtraData:                hexToBytes("d40192534e415053484f542d343031373966623937"),
+		PaidFees:                 big.NewInt(0),
+		MinimumGasPrice:          big.NewInt(0),
+		TxExecutionSublistsEdges: []int16{},
+	}
+	return InputToBlockHeader(input, DefaultRegtestConfig()),
+		common.HexToHash("0x90299cad077d0759beee6c9625be98114874d9ae65ede6979752a97112043b63")
+}
+
+func TestBlockHeaderEncodeRLP(t *testing.T) {
+	header, hash := regtestBlock1()
+	header.BaseEvent = []byte{0x01, 0x02}
+	encoded, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encoded, header.GetFullEncoded()) {
+		t.Fatal("EncodeRLP does not write the full encoding")
+	}
+	decoded, err := DecodeBlockHeader(encoded, DefaultRegtestConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.BaseEvent, header.BaseEvent) || decoded.Hash() != header.Hash() {
+		t.Error("V2 header does not round-trip")
+	}
+	if decoded.Hash() == hash {
+		t.Error("Expected baseEvent to change the hash")
+	}
+}
+
+func TestBlockHeaderCheckHash(t *testing.T) {
+	header, hash := regtestBlock1()
+	decoded, err := DecodeBlockHeader(header.GetFullEncoded(), DefaultRegtestConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.CheckHash(hash); err != nil {
+		t.Fatalf("Decoded header failed authentication: %v", err)
+	}
+	decoded.StateRoot[0] ^= 1
+	if err := decoded.CheckHash(hash); err == nil {
+		t.Error("Expected a tampered header to fail authentication")
+	}
+}
+
+// Once RSKIP-92 is active the merkle proof and coinbase transaction are
+// excluded from the hash, but the Bitcoin header is not.
+func TestBlockHeaderHashExcludedFields(t *testing.T) {
+	header, _ := regtestBlock1()
+	header.BitcoinMergedMiningHeader = bytes.Repeat([]byte{0xaa}, 80)
+	header.BitcoinMergedMiningMerkleProof = bytes.Repeat([]byte{0xbb}, 32)
+	header.BitcoinMergedMiningCoinbaseTransaction = bytes.Repeat([]byte{0xcc}, 64)
+	hash := header.Hash()
+
+	header.BitcoinMergedMiningMerkleProof = bytes.Repeat([]byte{0xdd}, 32)
+	header.BitcoinMergedMiningCoinbaseTransaction = bytes.Repeat([]byte{0xee}, 64)
+	if header.Hash() != hash {
+		t.Error("Merkle proof and coinbase changed the RSKIP-92 hash")
+	}
+	header.UseRskip92Encoding = false
+	if header.Hash() == hash {
+		t.Error("Merkle proof and coinbase should be hashed without RSKIP-92")
+	}
+	header.UseRskip92Encoding = true
+	header.BitcoinMergedMiningHeader[0] = 0
+	if header.Hash() == hash {
+		t.Error("Bitcoin header should be hashed")
+	}
+}
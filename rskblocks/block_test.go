@@ -0,0 +1,104 @@
+package rskblocks
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func testBlock(t *testing.T) *Block {
+	t.Helper()
+	config := DefaultRegtestConfig()
+	to := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	remasc := common.HexToAddress("0x0000000000000000000000000000000001000008")
+	txs := []*Transaction{
+		NewSignedTransaction(7, &to, big.NewInt(1000), 21000, big.NewInt(60_000_000), nil,
+			big.NewInt(0x62), big.NewInt(1234567), big.NewInt(7654321)),
+		NewSignedTransaction(1, &remasc, big.NewInt(0), 0, big.NewInt(0), nil, big.NewInt(0), big.NewInt(0), big.NewInt(0)),
+	}
+	uncleInput := testHeaderInput()
+	uncleInput.Number = big.NewInt(7139699)
+	uncle := InputToBlockHeader(uncleInput, config)
+
+	block := &Block{Transactions: txs, Uncles: []*BlockHeader{uncle}}
+	input := testHeaderInput()
+	input.UncleCount = 1
+	input.UnclesHash = block.UnclesHash()
+	input.TxTrieRoot = common.BytesToHash(GetTxTrieRoot(txs))
+	block.Header = InputToBlockHeader(input, config)
+	return block
+}
+
+func TestDecodeBlock(t *testing.T) {
+	block := testBlock(t)
+	if err := block.VerifyBody(); err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeBlock(encoded, DefaultRegtestConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Hash() != block.Hash() || len(decoded.Transactions) != 2 || len(decoded.Uncles) != 1 {
+		t.Fatalf("Decoded block differs: %+v", decoded)
+	}
+	for i, tx := range decoded.Transactions {
+		if tx.Hash() != block.Transactions[i].Hash() {
+			t.Errorf("Transaction %d hash %s, want %s", i, tx.Hash(), block.Transactions[i].Hash())
+		}
+	}
+	if err := decoded.VerifyBody(); err != nil {
+		t.Errorf("Decoded body does not verify: %v", err)
+	}
+	reencoded, _ := rlp.EncodeToBytes(decoded)
+	if !bytes.Equal(reencoded, encoded) {
+		t.Error("Block does not round-trip")
+	}
+}
+
+func TestBlockVerifyBody(t *testing.T) {
+	empty := &Block{Header: &BlockHeader{UnclesHash: common.HexToHash("0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347")}}
+	if got := empty.UnclesHash(); got != empty.Header.UnclesHash {
+		t.Errorf("Empty uncles hash %s", got)
+	}
+
+	block := testBlock(t)
+	block.Transactions = block.Transactions[1:]
+	if err := block.VerifyBody(); err == nil {
+		t.Error("Expected a missing transaction to fail")
+	}
+	block = testBlock(t)
+	block.Uncles = nil
+	if err := block.VerifyBody(); err == nil {
+		t.Error("Expected a missing uncle to fail")
+	}
+}
+
+func TestDecodeBlockErrors(t *testing.T) {
+	block := testBlock(t)
+	encoded, _ := rlp.EncodeToBytes(block)
+	for name, data := range map[string][]byte{
+		"not a list": {0x80},
+		"truncated":  encoded[:len(encoded)-1],
+		"bad header": mustEncode(t, []interface{}{[]byte{1}, []interface{}{}, []interface{}{}}),
+	} {
+		if _, err := DecodeBlock(data, DefaultRegtestConfig()); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func mustEncode(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	b, err := rlp.EncodeToBytes(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
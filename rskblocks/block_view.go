@@ -0,0 +1,139 @@
+package rskblocks
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// HeaderView presents a BlockHeader in the types explorer and analytics code
+// works with. It is a copy: changing it does not change the header, which
+// stays available as Header.
+type HeaderView struct {
+	Hash            common.Hash
+	ParentHash      common.Hash
+	Number          uint64
+	Time            time.Time
+	Difficulty      *big.Int
+	GasLimit        uint64
+	GasUsed         uint64
+	PaidFees        Coin
+	MinimumGasPrice Coin
+	// Miner is the coinbase; MinerChecksum is its RSKIP-60 checksummed
+	// form for the view's chain.
+	Miner         common.Address
+	MinerChecksum string
+	ExtraData     []byte
+	UncleCount    int
+	// MergeMined reports whether the header carries a Bitcoin merged mining
+	// header.
+	MergeMined bool
+	Version    byte
+
+	Header *BlockHeader
+}
+
+// TransactionView presents a Transaction with its sender resolved.
+type TransactionView struct {
+	Hash     common.Hash
+	From     common.Address // zero for the REMASC transaction
+	To       *common.Address
+	Nonce    uint64
+	Value    Coin
+	GasPrice Coin
+	Gas      uint64
+	Data     []byte
+	IsRemasc bool
+
+	Transaction *Transaction
+}
+
+// BlockView presents a Block with every header and transaction viewed.
+type BlockView struct {
+	*HeaderView
+	Transactions []*TransactionView
+	Uncles       []*HeaderView
+}
+
+// NewHeaderView returns a view of header on the chain with chainID, which
+// checksums the miner address.
+func NewHeaderView(header *BlockHeader, chainID uint64) (*HeaderView, error) {
+	number, timestamp := bigOrZero(header.Number), bigOrZero(header.Timestamp)
+	gasLimit, gasUsed := new(big.Int).SetBytes(header.GasLimit), bigOrZero(header.GasUsed)
+	for _, f := range []struct {
+		name string
+		v    *big.Int
+	}{{"number", number}, {"timestamp", timestamp}, {"gas limit", gasLimit}, {"gas used", gasUsed}} {
+		if !f.v.IsUint64() {
+			return nil, fmt.Errorf("block %v: %s %v out of range", header.Number, f.name, f.v)
+		}
+	}
+	return &HeaderView{
+		Hash:            header.Hash(),
+		ParentHash:      header.ParentHash,
+		Number:          number.Uint64(),
+		Time:            time.Unix(timestamp.Int64(), 0).UTC(),
+		Difficulty:      new(big.Int).Set(bigOrZero(header.Difficulty)),
+		GasLimit:        gasLimit.Uint64(),
+		GasUsed:         gasUsed.Uint64(),
+		PaidFees:        NewCoin(header.PaidFees),
+		MinimumGasPrice: NewCoin(header.MinimumGasPrice),
+		Miner:           header.Coinbase,
+		MinerChecksum:   rsktrie.ChecksumAddress(header.Coinbase, chainID),
+		ExtraData:       common.CopyBytes(header.ExtraData),
+		UncleCount:      header.UncleCount,
+		MergeMined:      len(header.BitcoinMergedMiningHeader) > 0,
+		Version:         header.Version,
+		Header:          header,
+	}, nil
+}
+
+// NewTransactionView returns a view of tx, recovering its sender with
+// signer.
+func NewTransactionView(tx *Transaction, signer Signer) (*TransactionView, error) {
+	from, err := signer.Sender(tx)
+	if err != nil {
+		return nil, fmt.Errorf("transaction %s: %w", tx.Hash(), err)
+	}
+	return &TransactionView{
+		Hash:        tx.Hash(),
+		From:        from,
+		To:          tx.To(),
+		Nonce:       tx.Nonce(),
+		Value:       NewCoin(tx.Value()),
+		GasPrice:    NewCoin(tx.GasPrice()),
+		Gas:         tx.Gas(),
+		Data:        tx.Data(),
+		IsRemasc:    tx.IsRemasc(),
+		Transaction: tx,
+	}, nil
+}
+
+// NewBlockView returns a view of block on the chain with chainID.
+func NewBlockView(block *Block, chainID uint64) (*BlockView, error) {
+	header, err := NewHeaderView(block.Header, chainID)
+	if err != nil {
+		return nil, err
+	}
+	view := &BlockView{HeaderView: header}
+	signer := NewEIP155Signer(chainID)
+	for _, tx := range block.Transactions {
+		txView, err := NewTransactionView(tx, signer)
+		if err != nil {
+			return nil, err
+		}
+		view.Transactions = append(view.Transactions, txView)
+	}
+	for _, uncle := range block.Uncles {
+		uncleView, err := NewHeaderView(uncle, chainID)
+		if err != nil {
+			return nil, err
+		}
+		view.Uncles = append(view.Uncles, uncleView)
+	}
+	return view, nil
+}
@@ -0,0 +1,70 @@
+package rskblocks
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestNewBlockView(t *testing.T) {
+	key, err := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	signed, err := SignTx(NewTransaction(3, to, big.NewInt(1000), 21000, big.NewInt(60_000_000), nil), NewEIP155Signer(RegtestChainID), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := testBlock(t)
+	block.Transactions = []*Transaction{signed, NewRemascTransaction(7139700)}
+
+	view, err := NewBlockView(block, RegtestChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := testHeaderInput()
+	if view.Number != 7139700 || view.Hash != block.Header.Hash() || view.GasLimit != 6800000 || view.GasUsed != 21000 {
+		t.Errorf("Header fields %+v", view.HeaderView)
+	}
+	if !view.Time.Equal(time.Unix(input.Timestamp.Int64(), 0)) || view.Time.Location() != time.UTC {
+		t.Errorf("Time %v", view.Time)
+	}
+	if view.Difficulty.Cmp(input.Difficulty) != 0 || !view.PaidFees.Equal(CoinFromWei(1000)) || !view.MinimumGasPrice.IsZero() {
+		t.Errorf("Quantities %v %v %v", view.Difficulty, view.PaidFees, view.MinimumGasPrice)
+	}
+	if view.Miner != input.Coinbase || view.MinerChecksum != rsktrie.ChecksumAddress(input.Coinbase, RegtestChainID) || view.MergeMined {
+		t.Errorf("Miner %s %s", view.Miner, view.MinerChecksum)
+	}
+	if len(view.Uncles) != 1 || view.Uncles[0].Number != 7139699 {
+		t.Error("Uncle not viewed")
+	}
+
+	if len(view.Transactions) != 2 {
+		t.Fatalf("Got %d transactions", len(view.Transactions))
+	}
+	tx := view.Transactions[0]
+	if tx.From != crypto.PubkeyToAddress(key.PublicKey) || *tx.To != to || tx.Nonce != 3 || !tx.Value.Equal(CoinFromWei(1000)) || tx.GasPrice.Gwei() != "0.06" || tx.IsRemasc {
+		t.Errorf("Transaction %+v", tx)
+	}
+	if remasc := view.Transactions[1]; !remasc.IsRemasc || remasc.From != (common.Address{}) {
+		t.Errorf("REMASC transaction %+v", remasc)
+	}
+
+	// Changing the view leaves the header alone.
+	view.Difficulty.SetInt64(1)
+	if block.Header.Difficulty.Cmp(input.Difficulty) != 0 {
+		t.Error("View shares the header's difficulty")
+	}
+
+	// A transaction signed for another chain has no sender here.
+	if _, err := NewBlockView(block, MainnetChainID); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Wrong chain: %v", err)
+	}
+}
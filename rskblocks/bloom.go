@@ -0,0 +1,77 @@
+package rskblocks
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// BloomByteLength is the size of a logs bloom filter: 2048 bits.
+const BloomByteLength = 256
+
+// Bloom is the 2048-bit filter of log addresses and topics that RSK headers
+// and receipts carry. Each item sets three bits chosen from its Keccak-256
+// hash, as rskj's Bloom.create does; the layout is the same as Ethereum's.
+type Bloom [BloomByteLength]byte
+
+// Add sets the bits for data, a log address or topic.
+func (b *Bloom) Add(data []byte) {
+	for _, bit := range bloomBits(data) {
+		b[BloomByteLength-1-bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// Or adds all items of other to b.
+func (b *Bloom) Or(other Bloom) {
+	for i := range b {
+		b[i] |= other[i]
+	}
+}
+
+// Matches reports whether data may have been added to b. A false result is
+// definite; a true one can be a false positive.
+func (b Bloom) Matches(data []byte) bool {
+	for _, bit := range bloomBits(data) {
+		if b[BloomByteLength-1-bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Bytes returns the filter as a byte slice.
+func (b Bloom) Bytes() []byte {
+	return b[:]
+}
+
+// MarshalText encodes the filter as 0x-prefixed hex, as logsBloom appears
+// in RPC responses.
+func (b Bloom) MarshalText() ([]byte, error) {
+	return hexutil.Bytes(b[:]).MarshalText()
+}
+
+// UnmarshalText decodes a 0x-prefixed hex filter of exactly
+// BloomByteLength bytes.
+func (b *Bloom) UnmarshalText(input []byte) error {
+	var raw hexutil.Bytes
+	if err := raw.UnmarshalText(input); err != nil {
+		return fmt.Errorf("logs bloom: %w", err)
+	}
+	if len(raw) != BloomByteLength {
+		return fmt.Errorf("logs bloom has %d bytes, want %d", len(raw), BloomByteLength)
+	}
+	copy(b[:], raw)
+	return nil
+}
+
+// bloomBits returns the three bit positions for data: the low 11 bits of
+// each of the first three big-endian 16-bit words of its hash.
+func bloomBits(data []byte) [3]uint {
+	hash := keccak256Hash(data)
+	var bits [3]uint
+	for i := range bits {
+		bits[i] = uint(binary.BigEndian.Uint16(hash[2*i:]) & 0x7ff)
+	}
+	return bits
+}
@@ -0,0 +1,70 @@
+package rskblocks
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBloomMatchesEthereumLayout(t *testing.T) {
+	items := [][]byte{
+		common.HexToAddress("0x0000000000000000000000000000000001000008").Bytes(),
+		common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef").Bytes(),
+		[]byte("RSK"),
+	}
+	var bloom Bloom
+	var want types.Bloom
+	for _, item := range items {
+		bloom.Add(item)
+		want.Add(item)
+	}
+	if bloom != Bloom(want) {
+		t.Fatalf("Bloom differs from the Ethereum layout:\n got %x\nwant %x", bloom, want)
+	}
+	for _, item := range items {
+		if !bloom.Matches(item) {
+			t.Errorf("Bloom does not match %x", item)
+		}
+	}
+	if bloom.Matches([]byte("absent")) {
+		t.Error("Bloom matches an absent item")
+	}
+	if (Bloom{}).Matches(items[0]) {
+		t.Error("Empty bloom matches an item")
+	}
+}
+
+func TestBloomOr(t *testing.T) {
+	var a, b Bloom
+	a.Add([]byte("a"))
+	b.Add([]byte("b"))
+	a.Or(b)
+	if !a.Matches([]byte("a")) || !a.Matches([]byte("b")) {
+		t.Error("Union does not match both items")
+	}
+}
+
+func TestBloomJSON(t *testing.T) {
+	var bloom Bloom
+	bloom.Add([]byte("topic"))
+	encoded, err := json.Marshal(bloom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(encoded), `"0x`) || len(encoded) != 2+2+2*BloomByteLength {
+		t.Fatalf("Unexpected encoding %s", encoded)
+	}
+	var decoded Bloom
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != bloom {
+		t.Error("Bloom did not round trip")
+	}
+	if err := json.Unmarshal([]byte(`"0x00ff"`), &decoded); err == nil {
+		t.Error("Expected a short bloom to fail")
+	}
+}
@@ -0,0 +1,125 @@
+package rskblocks
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// AttestationVersion is the encoding version written by
+// SignedProofBundle.Marshal.
+const AttestationVersion = 1
+
+// attestationDomain separates bundle signatures from signatures over any
+// other payload, such as transactions, made with the same key.
+const attestationDomain = "\x19RSK proof bundle attestation:\n"
+
+// SignedProofBundle is a ProofBundle with an attestor's secp256k1 signature,
+// so relayers can pass around state claims that are both verifiable and
+// attributable: the attestor vouches that BlockHash is canonical, which the
+// proofs themselves cannot show.
+type SignedProofBundle struct {
+	Bundle *ProofBundle
+	// Signature is the 65-byte [R || S || V] signature, V being 0 or 1, over
+	// the bundle's AttestationHash.
+	Signature []byte
+}
+
+// AttestationHash returns the hash an attestor signs: the keccak256 hash of
+// the bundle's Marshal encoding under a domain prefix.
+func (b *ProofBundle) AttestationHash() (common.Hash, error) {
+	data, err := b.Marshal()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash([]byte(attestationDomain), data), nil
+}
+
+// SignProofBundle signs b with key.
+func SignProofBundle(b *ProofBundle, key *ecdsa.PrivateKey) (*SignedProofBundle, error) {
+	hash, err := b.AttestationHash()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := crypto.Sign(hash[:], key)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedProofBundle{Bundle: b, Signature: sig}, nil
+}
+
+// Attestor recovers the address that signed the bundle.
+func (s *SignedProofBundle) Attestor() (common.Address, error) {
+	if len(s.Signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("attestation signature is %d bytes, want %d", len(s.Signature), crypto.SignatureLength)
+	}
+	hash, err := s.Bundle.AttestationHash()
+	if err != nil {
+		return common.Address{}, err
+	}
+	pub, err := crypto.SigToPub(hash[:], s.Signature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("recover attestor: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// VerifyAttestor checks that the bundle was signed by one of trusted, and
+// returns the attestor. It does not verify the proofs; call Bundle.Verify
+// for that.
+func (s *SignedProofBundle) VerifyAttestor(trusted ...common.Address) (common.Address, error) {
+	attestor, err := s.Attestor()
+	if err != nil {
+		return common.Address{}, err
+	}
+	for _, addr := range trusted {
+		if addr == attestor {
+			return attestor, nil
+		}
+	}
+	return attestor, fmt.Errorf("bundle attested by untrusted %s", attestor.Hex())
+}
+
+// signedProofBundleRLP is the wire form: the bundle's own encoding, so the
+// signed bytes travel verbatim, and the signature.
+type signedProofBundleRLP struct {
+	Bundle    []byte
+	Signature []byte
+}
+
+// Marshal encodes the signed bundle as a version byte followed by an RLP
+// list.
+func (s *SignedProofBundle) Marshal() ([]byte, error) {
+	bundle, err := s.Bundle.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	body, err := rlp.EncodeToBytes(&signedProofBundleRLP{Bundle: bundle, Signature: s.Signature})
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{AttestationVersion}, body...), nil
+}
+
+// UnmarshalSignedProofBundle decodes a signed bundle written by Marshal. It
+// checks neither the signature nor the proofs.
+func UnmarshalSignedProofBundle(data []byte) (*SignedProofBundle, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty attestation")
+	}
+	if data[0] != AttestationVersion {
+		return nil, fmt.Errorf("unsupported attestation version %d", data[0])
+	}
+	var dec signedProofBundleRLP
+	if err := rlp.DecodeBytes(data[1:], &dec); err != nil {
+		return nil, fmt.Errorf("decode attestation: %w", err)
+	}
+	bundle, err := UnmarshalProofBundle(dec.Bundle)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedProofBundle{Bundle: bundle, Signature: dec.Signature}, nil
+}
@@ -0,0 +1,58 @@
+package rskblocks
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSignedProofBundle(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045e71a7a2c50903d88e564cd72fab11e82051")
+	key := mapper.GetAccountStorageKey(contract, common.Hash{0x01})
+	trie := rsktrie.NewTrie(nil).Put(key, []byte{0x2a})
+	bundle := NewProofBundle(&BlockHeader{StateRoot: common.BytesToHash(trie.GetHash()), Number: big.NewInt(7)})
+	bundle.Add(key, buildTestProof(t, trie, key))
+
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.PubkeyToAddress(priv.PublicKey)
+	signed, err := SignProofBundle(bundle, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := signed.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := UnmarshalSignedProofBundle(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attestor, err := decoded.VerifyAttestor(common.Address{0x01}, signer); err != nil || attestor != signer {
+		t.Errorf("Expected attestor %s, got %s, %v", signer.Hex(), attestor.Hex(), err)
+	}
+	if _, err := decoded.VerifyAttestor(common.Address{0x01}); err == nil {
+		t.Error("Expected an untrusted attestor to be rejected")
+	}
+	if _, err := decoded.Bundle.Verify(); err != nil {
+		t.Errorf("Expected the bundle's proofs to verify: %v", err)
+	}
+
+	// Any change to the bundle changes the recovered attestor
+	decoded.Bundle.BlockNumber++
+	if attestor, err := decoded.Attestor(); err == nil && attestor == signer {
+		t.Error("Expected a tampered bundle not to recover the signer")
+	}
+	decoded.Signature = decoded.Signature[:64]
+	if _, err := decoded.Attestor(); err == nil {
+		t.Error("Expected a short signature to be rejected")
+	}
+}
@@ -0,0 +1,100 @@
+package rskblocks
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// VerifierFunctionSignature is the on-chain verifier function ProofCalldata
+// targets by default. The contract is expected to hold the trusted state
+// root itself (e.g. from a header relay) and to walk nodes root first,
+// hashing each serialized node with keccak256.
+const VerifierFunctionSignature = "verifyProof(bytes[],bytes,bytes)"
+
+// ProofCalldata is a key proof in the layout an EVM verifier contract
+// consumes: the path's serialized nodes root first, without the RLP string
+// header of the eth_getProof format, the trie key, and the value proven (empty
+// for an absent key).
+type ProofCalldata struct {
+	Nodes [][]byte
+	Key   []byte
+	Value []byte
+}
+
+var calldataArguments = func() abi.Arguments {
+	bytesArray, _ := abi.NewType("bytes[]", "", nil)
+	bytesType, _ := abi.NewType("bytes", "", nil)
+	return abi.Arguments{{Type: bytesArray}, {Type: bytesType}, {Type: bytesType}}
+}()
+
+// NewProofCalldata converts a key proof in eth_getProof format (leaf first)
+// for on-chain verification of value. It does not verify the proof.
+func NewProofCalldata(key, value []byte, proofNodes [][]byte) (*ProofCalldata, error) {
+	c := &ProofCalldata{Nodes: make([][]byte, len(proofNodes)), Key: key, Value: value}
+	for i, node := range proofNodes {
+		if err := rlp.DecodeBytes(node, &c.Nodes[len(proofNodes)-1-i]); err != nil {
+			return nil, fmt.Errorf("proof node %d: %w", i, err)
+		}
+	}
+	return c, nil
+}
+
+// Pack ABI-encodes the arguments (nodes, key, value), without a selector.
+func (c *ProofCalldata) Pack() ([]byte, error) {
+	return calldataArguments.Pack(c.Nodes, c.Key, c.Value)
+}
+
+// Calldata returns the call data for VerifierFunctionSignature.
+func (c *ProofCalldata) Calldata() ([]byte, error) {
+	return c.CalldataFor(VerifierFunctionSignature)
+}
+
+// CalldataFor returns the call data for the function with signature, e.g.
+// "verifyStorage(bytes[],bytes,bytes)", which must take the arguments
+// (nodes, key, value).
+func (c *ProofCalldata) CalldataFor(signature string) ([]byte, error) {
+	args, err := c.Pack()
+	if err != nil {
+		return nil, err
+	}
+	return append(crypto.Keccak256([]byte(signature))[:4], args...), nil
+}
+
+// AccountProofCalldata verifies an account proof and converts it for
+// on-chain verification, with the RLP-encoded account record as value.
+// Proofs that commit to the record by hash only (Orchid) cannot be
+// converted, since the contract could not check the value.
+func (v *ProofVerifier) AccountProofCalldata(stateRoot common.Hash, address common.Address, proofNodes [][]byte) (*ProofCalldata, error) {
+	result, err := v.VerifyAccountProof(stateRoot, address, proofNodes)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Valid {
+		return nil, result.Error
+	}
+	if result.ValueHash != nil {
+		return nil, fmt.Errorf("account record of %s is committed by hash only", address.Hex())
+	}
+	return NewProofCalldata(v.keyMapper.GetAccountKey(address), result.Value, proofNodes)
+}
+
+// StorageProofCalldata verifies a storage proof and converts it for on-chain
+// verification. Long values (over 32 bytes) are committed by hash only, so
+// they cannot be converted.
+func (v *ProofVerifier) StorageProofCalldata(stateRoot common.Hash, address common.Address, storageKey common.Hash, proofNodes [][]byte) (*ProofCalldata, error) {
+	result, err := v.VerifyStorageProof(stateRoot, address, storageKey, proofNodes)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Valid {
+		return nil, result.Error
+	}
+	if result.ValueHash != nil {
+		return nil, fmt.Errorf("storage slot %s is committed by hash only", storageKey.Hex())
+	}
+	return NewProofCalldata(v.keyMapper.GetAccountStorageKey(address, storageKey), result.Value, proofNodes)
+}
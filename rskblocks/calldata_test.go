@@ -0,0 +1,54 @@
+package rskblocks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestStorageProofCalldata(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045e71a7a2c50903d88e564cd72fab11e82051")
+	slot := common.Hash{0x01}
+	trie := rsktrie.NewTrie(nil).
+		Put(mapper.GetAccountKey(contract), []byte{0x01}).
+		Put(mapper.GetAccountStorageKey(contract, slot), []byte{0x2a}).
+		Put(mapper.GetAccountStorageKey(contract, common.Hash{0x02}), []byte{0x07})
+	stateRoot := common.BytesToHash(trie.GetHash())
+	proof := buildTestProof(t, trie, mapper.GetAccountStorageKey(contract, slot))
+
+	c, err := NewProofVerifier().StorageProofCalldata(stateRoot, contract, slot, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(crypto.Keccak256(c.Nodes[0]), stateRoot[:]) {
+		t.Error("Expected the root node first")
+	}
+	if !bytes.Equal(c.Value, []byte{0x2a}) || !bytes.Equal(c.Key, mapper.GetAccountStorageKey(contract, slot)) {
+		t.Errorf("Unexpected key or value: %x, %x", c.Key, c.Value)
+	}
+
+	data, err := c.Calldata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data[:4], crypto.Keccak256([]byte(VerifierFunctionSignature))[:4]) {
+		t.Errorf("Unexpected selector %x", data[:4])
+	}
+	values, err := calldataArguments.Unpack(data[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := values[0].([][]byte)
+	if len(nodes) != len(proof) || !bytes.Equal(values[2].([]byte), c.Value) {
+		t.Errorf("Unexpected decoded arguments %x", values)
+	}
+
+	if _, err := NewProofVerifier().StorageProofCalldata(common.Hash{0x01}, contract, slot, proof); err == nil {
+		t.Error("Expected an invalid proof not to be converted")
+	}
+}
@@ -0,0 +1,290 @@
+package rskblocks
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// NotActivated is the activation height of a network upgrade a network has
+// not scheduled, as -1 is in rskj's configuration files.
+const NotActivated int64 = -1
+
+// ForkHeights are the block numbers at which a network activated the
+// upgrades whose rules gorsk implements, or NotActivated.
+type ForkHeights struct {
+	// Orchid activated the unitrie (RSKIP-107) and the compact merged
+	// mining encoding (RSKIP-92).
+	Orchid int64
+	// Wasabi100 activated fork detection data (RSKIP-110).
+	Wasabi100 int64
+	// Papyrus200 activated the ummRoot header field and the larger
+	// difficulty bound divisor (RSKIP-156).
+	Papyrus200 int64
+	// Reed810 activated V1 headers (RSKIP-351) and parallel execution
+	// edges (RSKIP-144).
+	Reed810 int64
+	// Vetiver900 activated V2 headers (RSKIP-535).
+	Vetiver900 int64
+}
+
+// BridgeConstants are the network's Bridge contract parameters.
+type BridgeConstants struct {
+	Address common.Address
+	// BtcNetwork is the Bitcoin network the Bridge follows: "mainnet",
+	// "testnet" or "regtest".
+	BtcNetwork string
+	// BtcToRskMinConfirmations is how deep a peg-in must be in Bitcoin
+	// before the Bridge accepts it.
+	BtcToRskMinConfirmations uint64
+	// RskToBtcMinConfirmations is how deep a peg-out request must be in RSK
+	// before the federation releases it.
+	RskToBtcMinConfirmations uint64
+}
+
+// ChainConfig gathers the parameters that differ between RSK networks, so
+// code that validates headers or verifies state can serve any of them.
+type ChainConfig struct {
+	// Network is "mainnet", "testnet" or "regtest".
+	Network string
+	ChainID uint64
+	// GenesisHash is the hash of block 0; zero for regtest, whose genesis
+	// depends on the node's configuration.
+	GenesisHash common.Hash
+	// GenesisStateRoot is the state root of block 0, checked by
+	// CheckGenesis unless zero.
+	GenesisStateRoot common.Hash
+	Forks            ForkHeights
+	Difficulty       DifficultyParams
+	Bridge           BridgeConstants
+	// Use4ByteGasLimit pads header gas limits to 4 bytes, as regtest
+	// encodes them; mainnet and testnet use minimal bytes.
+	Use4ByteGasLimit bool
+}
+
+// MainnetChainConfig returns the configuration of RSK mainnet.
+func MainnetChainConfig() *ChainConfig {
+	config := &ChainConfig{
+		Network:     "mainnet",
+		ChainID:     MainnetChainID,
+		GenesisHash: MainnetGenesisHash,
+		Forks: ForkHeights{
+			Orchid:     729000,
+			Wasabi100:  1591000,
+			Papyrus200: 2392700,
+			Reed810:    NotActivated,
+			Vetiver900: NotActivated,
+		},
+		Difficulty: DifficultyParams{
+			DurationLimit:        14,
+			BoundDivisor:         big.NewInt(50),
+			Rskip156BoundDivisor: big.NewInt(400),
+			MinimumDifficulty:    big.NewInt(7_000_000_000_000_000),
+		},
+		Bridge: BridgeConstants{
+			Address:                  rsktrie.BridgeAddress,
+			BtcNetwork:               "mainnet",
+			BtcToRskMinConfirmations: 100,
+			RskToBtcMinConfirmations: 4000,
+		},
+	}
+	config.Difficulty.Rskip156Height = config.Forks.Papyrus200
+	return config
+}
+
+// TestnetChainConfig returns the configuration of RSK testnet.
+func TestnetChainConfig() *ChainConfig {
+	config := &ChainConfig{
+		Network:     "testnet",
+		ChainID:     TestnetChainID,
+		GenesisHash: TestnetGenesisHash,
+		Forks: ForkHeights{
+			Orchid:     0,
+			Wasabi100:  0,
+			Papyrus200: 863000,
+			Reed810:    7139600,
+			Vetiver900: NotActivated,
+		},
+		Difficulty: DifficultyParams{
+			DurationLimit:        14,
+			BoundDivisor:         big.NewInt(50),
+			Rskip156BoundDivisor: big.NewInt(400),
+			MinimumDifficulty:    big.NewInt(131072),
+		},
+		Bridge: BridgeConstants{
+			Address:                  rsktrie.BridgeAddress,
+			BtcNetwork:               "testnet",
+			BtcToRskMinConfirmations: 10,
+			RskToBtcMinConfirmations: 10,
+		},
+	}
+	config.Difficulty.Rskip156Height = config.Forks.Papyrus200
+	return config
+}
+
+// RegtestChainConfig returns the configuration of a regtest node, which
+// activates every upgrade at genesis.
+func RegtestChainConfig() *ChainConfig {
+	return &ChainConfig{
+		Network: "regtest",
+		ChainID: RegtestChainID,
+		Difficulty: DifficultyParams{
+			DurationLimit:     14,
+			BoundDivisor:      big.NewInt(2048),
+			MinimumDifficulty: big.NewInt(1),
+		},
+		Bridge: BridgeConstants{
+			Address:                  rsktrie.BridgeAddress,
+			BtcNetwork:               "regtest",
+			BtcToRskMinConfirmations: 3,
+			RskToBtcMinConfirmations: 3,
+		},
+		Use4ByteGasLimit: true,
+	}
+}
+
+// ChainConfigForNetwork returns the configuration of network ("mainnet",
+// "testnet" or "regtest"); anything else gets regtest's, as in
+// ConfigForBlockNumber.
+func ChainConfigForNetwork(network string) *ChainConfig {
+	switch network {
+	case "mainnet":
+		return MainnetChainConfig()
+	case "testnet":
+		return TestnetChainConfig()
+	default:
+		return RegtestChainConfig()
+	}
+}
+
+// ChainConfigForChainID returns the configuration of the network with
+// chainID, as reported by eth_chainId.
+func ChainConfigForChainID(chainID uint64) (*ChainConfig, error) {
+	switch chainID {
+	case MainnetChainID:
+		return MainnetChainConfig(), nil
+	case TestnetChainID:
+		return TestnetChainConfig(), nil
+	case RegtestChainID:
+		return RegtestChainConfig(), nil
+	default:
+		return nil, fmt.Errorf("unknown RSK chain ID %d", chainID)
+	}
+}
+
+// IsActive reports whether an upgrade activated at height is in force at
+// blockNum.
+func IsActive(height int64, blockNum uint64) bool {
+	return height != NotActivated && blockNum >= uint64(height)
+}
+
+// BlockHashConfig returns the header rules in force at blockNum:
+//   - orchid: RSKIP-92 merged mining encoding;
+//   - wasabi100: fork detection data in the merged mining hash;
+//   - papyrus200: the ummRoot field;
+//   - reed810: V1 headers, vetiver900: V2 headers.
+func (c *ChainConfig) BlockHashConfig(blockNum uint64) BlockHashConfig {
+	config := BlockHashConfig{
+		UseRskip92Encoding:       IsActive(c.Forks.Orchid, blockNum),
+		IncludeUmmRoot:           IsActive(c.Forks.Papyrus200, blockNum),
+		Use4ByteGasLimit:         c.Use4ByteGasLimit,
+		IncludeForkDetectionData: IsActive(c.Forks.Wasabi100, blockNum),
+	}
+	switch {
+	case IsActive(c.Forks.Vetiver900, blockNum):
+		config.Version = 2
+	case IsActive(c.Forks.Reed810, blockNum):
+		config.Version = 1
+	}
+	return config
+}
+
+// DecodeBlockHeader decodes a header in its full encoding under the rules
+// in force at its own block number, which it reads from the encoding first.
+func (c *ChainConfig) DecodeBlockHeader(data []byte) (*BlockHeader, error) {
+	number, err := encodedHeaderNumber(data)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBlockHeader(data, c.BlockHashConfig(number))
+}
+
+// DecodeBlock decodes a block under the rules in force at its number.
+func (c *ChainConfig) DecodeBlock(data []byte) (*Block, error) {
+	var dec blockRLP
+	if err := rlp.DecodeBytes(data, &dec); err != nil {
+		return nil, fmt.Errorf("decode block: %w", err)
+	}
+	number, err := encodedHeaderNumber(dec.Header)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBlock(data, c.BlockHashConfig(number))
+}
+
+// VerifyProofOfWork checks header's merged mining proof after checking that
+// the header was built with the merged mining rules of its block number, so
+// the tag and commitment are checked as the network checked them.
+func (c *ChainConfig) VerifyProofOfWork(header *BlockHeader) error {
+	if !bigOrZero(header.Number).IsUint64() {
+		return fmt.Errorf("block number %v out of range", header.Number)
+	}
+	rules := c.BlockHashConfig(header.Number.Uint64())
+	if header.UseRskip92Encoding != rules.UseRskip92Encoding || header.IncludeForkDetectionData != rules.IncludeForkDetectionData {
+		return fmt.Errorf("block %v was not built with %s merged mining rules", header.Number, c.Network)
+	}
+	return header.VerifyProofOfWork()
+}
+
+// encodedHeaderNumber reads the block number from a header's RLP encoding.
+func encodedHeaderNumber(data []byte) (uint64, error) {
+	fields, err := decodeHeaderFields(data)
+	if err != nil {
+		return 0, err
+	}
+	number := new(big.Int).SetBytes(fields[8])
+	if !number.IsUint64() {
+		return 0, fmt.Errorf("block number %v out of range", number)
+	}
+	return number.Uint64(), nil
+}
+
+// KeyMapperVersion returns the state key scheme at blockNum: Orchid before
+// the unitrie, unitrie after.
+func (c *ChainConfig) KeyMapperVersion(blockNum uint64) rsktrie.KeyMapperVersion {
+	if IsActive(c.Forks.Orchid, blockNum) {
+		return rsktrie.KeyMapperUnitrie
+	}
+	return rsktrie.KeyMapperOrchid
+}
+
+// NodeFormat returns the trie node format of state at blockNum.
+func (c *ChainConfig) NodeFormat(blockNum uint64) rsktrie.NodeFormat {
+	return rsktrie.NodeFormatFor(c.KeyMapperVersion(blockNum))
+}
+
+// CheckGenesis reports whether header is the network's genesis block. A
+// config without a genesis hash accepts any block 0, and one without a
+// genesis state root any state root.
+func (c *ChainConfig) CheckGenesis(header *BlockHeader) error {
+	if bigOrZero(header.Number).Sign() != 0 {
+		return fmt.Errorf("block %v is not a genesis block", header.Number)
+	}
+	if c.GenesisStateRoot != (common.Hash{}) && header.StateRoot != c.GenesisStateRoot {
+		return fmt.Errorf("%s genesis state root is %s, got %s", c.Network, c.GenesisStateRoot, header.StateRoot)
+	}
+	if c.GenesisHash == (common.Hash{}) {
+		return nil
+	}
+	return header.CheckHash(c.GenesisHash)
+}
+
+// WithChainConfig derives trie keys, and requires proof nodes in the
+// format, of state at blockNum on the network of config.
+func WithChainConfig(config *ChainConfig, blockNum uint64) Option {
+	return WithKeyMapperVersion(config.KeyMapperVersion(blockNum))
+}
@@ -0,0 +1,175 @@
+package rskblocks
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestChainConfigLookup(t *testing.T) {
+	for _, network := range []string{"mainnet", "testnet", "regtest"} {
+		config := ChainConfigForNetwork(network)
+		if config.Network != network {
+			t.Errorf("ChainConfigForNetwork(%q) returned %q", network, config.Network)
+		}
+		byID, err := ChainConfigForChainID(config.ChainID)
+		if err != nil || byID.Network != network {
+			t.Errorf("Chain ID %d: got %v, %v", config.ChainID, byID, err)
+		}
+		if config.Bridge.Address != rsktrie.BridgeAddress {
+			t.Errorf("%s: unexpected bridge address %s", network, config.Bridge.Address.Hex())
+		}
+	}
+	if ChainConfigForNetwork("devnet").Network != "regtest" {
+		t.Error("Unknown networks should get regtest's configuration")
+	}
+	if _, err := ChainConfigForChainID(1); err == nil {
+		t.Error("Expected an unknown chain ID to fail")
+	}
+
+	// Configs are fresh copies callers may adjust.
+	MainnetChainConfig().Difficulty.MinimumDifficulty.SetInt64(1)
+	if MainnetChainConfig().Difficulty.MinimumDifficulty.Int64() == 1 {
+		t.Error("Mutating a returned config changed the next one")
+	}
+}
+
+func TestChainConfigForks(t *testing.T) {
+	mainnet, testnet := MainnetChainConfig(), TestnetChainConfig()
+	tests := []struct {
+		config   *ChainConfig
+		blockNum uint64
+		want     rsktrie.KeyMapperVersion
+	}{
+		{mainnet, 0, rsktrie.KeyMapperOrchid},
+		{mainnet, 728999, rsktrie.KeyMapperOrchid},
+		{mainnet, 729000, rsktrie.KeyMapperUnitrie},
+		{testnet, 0, rsktrie.KeyMapperUnitrie},
+		{RegtestChainConfig(), 0, rsktrie.KeyMapperUnitrie},
+	}
+	for _, test := range tests {
+		if got := test.config.KeyMapperVersion(test.blockNum); got != test.want {
+			t.Errorf("%s block %d: key mapper %s, want %s", test.config.Network, test.blockNum, got, test.want)
+		}
+		if got := KeyMapperForBlockNumber(test.blockNum, test.config.Network).Version(); got != test.want {
+			t.Errorf("KeyMapperForBlockNumber(%d, %q) = %s, want %s", test.blockNum, test.config.Network, got, test.want)
+		}
+	}
+	if mainnet.NodeFormat(1) != rsktrie.NodeFormatOrchid || mainnet.NodeFormat(729000) != rsktrie.NodeFormatRSKIP107 {
+		t.Error("Unexpected mainnet node formats")
+	}
+	if IsActive(mainnet.Forks.Reed810, 1<<40) {
+		t.Error("An unscheduled upgrade is never active")
+	}
+	if !IsActive(testnet.Forks.Reed810, 7139600) || IsActive(testnet.Forks.Reed810, 7139599) {
+		t.Error("Unexpected testnet reed810 activation")
+	}
+
+	v := NewProofVerifier(WithChainConfig(mainnet, 100))
+	if v.keyMapper.Version() != rsktrie.KeyMapperOrchid || v.config.Format != rsktrie.NodeFormatOrchid {
+		t.Error("WithChainConfig did not select the Orchid key scheme")
+	}
+	if NewChainValidatorForConfig(testnet).Difficulty.MinimumDifficulty.Cmp(testnet.Difficulty.MinimumDifficulty) != 0 {
+		t.Error("Validator does not use the config's difficulty constants")
+	}
+}
+
+func TestChainConfigCheckGenesis(t *testing.T) {
+	genesis := InputToBlockHeader(testHeaderInput(), DefaultRegtestConfig())
+	genesis.Number.SetInt64(0)
+	if err := RegtestChainConfig().CheckGenesis(genesis); err != nil {
+		t.Errorf("Regtest should accept any genesis: %v", err)
+	}
+	config := RegtestChainConfig()
+	config.GenesisHash = genesis.Hash()
+	if err := config.CheckGenesis(genesis); err != nil {
+		t.Error(err)
+	}
+	config.GenesisHash = common.Hash{1}
+	if err := config.CheckGenesis(genesis); err == nil {
+		t.Error("Expected a different genesis hash to fail")
+	}
+	config.GenesisHash = genesis.Hash()
+	config.GenesisStateRoot = common.Hash{2}
+	if err := config.CheckGenesis(genesis); err == nil {
+		t.Error("Expected a different genesis state root to fail")
+	}
+	genesis.Number.SetInt64(1)
+	if err := RegtestChainConfig().CheckGenesis(genesis); err == nil {
+		t.Error("Expected a non-zero block number to fail")
+	}
+}
+
+func TestChainConfigBlockHashConfig(t *testing.T) {
+	tests := []struct {
+		network  string
+		blockNum uint64
+		want     BlockHashConfig
+	}{
+		{"mainnet", 728999, BlockHashConfig{}},
+		{"mainnet", 729000, BlockHashConfig{UseRskip92Encoding: true}},
+		{"mainnet", 1591000, BlockHashConfig{UseRskip92Encoding: true, IncludeForkDetectionData: true}},
+		{"mainnet", 2392700, BlockHashConfig{UseRskip92Encoding: true, IncludeUmmRoot: true, IncludeForkDetectionData: true}},
+		{"testnet", 7139600, BlockHashConfig{UseRskip92Encoding: true, Version: 1, IncludeUmmRoot: true, IncludeForkDetectionData: true}},
+		{"regtest", 0, BlockHashConfig{UseRskip92Encoding: true, Version: 2, IncludeUmmRoot: true, Use4ByteGasLimit: true, IncludeForkDetectionData: true}},
+	}
+	for _, test := range tests {
+		if got := ChainConfigForNetwork(test.network).BlockHashConfig(test.blockNum); got != test.want {
+			t.Errorf("%s block %d: %+v, want %+v", test.network, test.blockNum, got, test.want)
+		}
+	}
+	if ConfigForBlockNumber(-1, "mainnet") != MainnetChainConfig().BlockHashConfig(0) {
+		t.Error("A negative block number should get genesis rules")
+	}
+}
+
+func TestChainConfigDecodeBlockHeader(t *testing.T) {
+	tests := []struct {
+		config   *ChainConfig
+		blockNum int64
+	}{
+		{MainnetChainConfig(), 500000},
+		{MainnetChainConfig(), 3000000},
+		{TestnetChainConfig(), 7139700},
+		{RegtestChainConfig(), 10},
+	}
+	for _, test := range tests {
+		input := testHeaderInput()
+		input.Number = big.NewInt(test.blockNum)
+		rules := test.config.BlockHashConfig(uint64(test.blockNum))
+		header := InputToBlockHeader(input, rules)
+
+		decoded, err := test.config.DecodeBlockHeader(header.GetFullEncoded())
+		if err != nil {
+			t.Fatalf("%s block %d: %v", test.config.Network, test.blockNum, err)
+		}
+		if decoded.Version != rules.Version || decoded.UseRskip92Encoding != rules.UseRskip92Encoding || decoded.Hash() != header.Hash() {
+			t.Errorf("%s block %d decoded with the wrong rules", test.config.Network, test.blockNum)
+		}
+	}
+	if _, err := MainnetChainConfig().DecodeBlockHeader([]byte{0xc0}); err == nil {
+		t.Error("Expected a truncated header to fail")
+	}
+}
+
+func TestChainConfigVerifyProofOfWork(t *testing.T) {
+	header := powTestHeader()
+	mergeMine(t, header, bytes.Repeat([]byte{0x01}, 150), nil)
+	mainnet := MainnetChainConfig()
+	if err := mainnet.VerifyProofOfWork(header); err != nil {
+		t.Fatal(err)
+	}
+	header.IncludeForkDetectionData = false
+	if err := mainnet.VerifyProofOfWork(header); err == nil {
+		t.Error("Expected a header without fork detection data after wasabi100 to fail")
+	}
+	header.IncludeForkDetectionData = true
+	header.Number = big.NewInt(1000)
+	if err := mainnet.VerifyProofOfWork(header); err == nil {
+		t.Error("Expected RSKIP-92 fields before orchid to fail")
+	}
+}
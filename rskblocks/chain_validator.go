@@ -0,0 +1,141 @@
+package rskblocks
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Rules checked by ChainValidator, as reported in ChainViolation.Rule.
+const (
+	RuleParentHash = "parent-hash"
+	RuleNumber     = "number"
+	RuleTimestamp  = "timestamp"
+	RuleGasLimit   = "gas-limit"
+	RuleDifficulty = "difficulty"
+	// RuleMinimumGasPrice is the minimumGasPrice movement bound; see
+	// VerifyMinimumGasPrice.
+	RuleMinimumGasPrice = "minimum-gas-price"
+	// RuleUmmRoot is the ummRoot shape and, if set, ChainValidator.UmmRoot.
+	RuleUmmRoot = "umm-root"
+	// RuleCheckpoint is the first header matching the checkpoint a chain is
+	// validated from; see ValidateFromCheckpoint.
+	RuleCheckpoint = "checkpoint"
+)
+
+// ChainViolation is the first rule a header chain breaks.
+type ChainViolation struct {
+	// Index is the offending header's position in the validated slice.
+	Index  int
+	Number *big.Int
+	Hash   common.Hash
+	Rule   string
+	Err    error
+}
+
+func (v *ChainViolation) Error() string {
+	return fmt.Sprintf("header %d (block %v, %s): %s: %v", v.Index, v.Number, v.Hash.Hex(), v.Rule, v.Err)
+}
+
+func (v *ChainViolation) Unwrap() error {
+	return v.Err
+}
+
+// ChainValidator checks the contextual rules that tie each header to its
+// parent, which a header's own proof of work cannot establish.
+type ChainValidator struct {
+	Difficulty DifficultyParams
+	// GasLimitBoundDivisor bounds gas limit movement: a child's gas limit
+	// may differ from its parent's by at most parent/GasLimitBoundDivisor.
+	GasLimitBoundDivisor int64
+	// MinGasLimit is the lowest gas limit a block may declare.
+	MinGasLimit *big.Int
+	// Timestamps decides which timestamps are accepted.
+	Timestamps TimestampPolicy
+	// UmmRoot, if set, validates the commitment of each UMM block (see
+	// BlockHeader.IsUmm), for example against the other chains it
+	// aggregates. The ummRoot's length is checked regardless.
+	UmmRoot func(header *BlockHeader) error
+}
+
+// NewChainValidator returns a validator with the consensus constants of
+// network ("mainnet", "testnet" or "regtest").
+func NewChainValidator(network string) *ChainValidator {
+	return NewChainValidatorForConfig(ChainConfigForNetwork(network))
+}
+
+// NewChainValidatorForConfig returns a validator with the consensus
+// constants of config's network.
+func NewChainValidatorForConfig(config *ChainConfig) *ChainValidator {
+	return &ChainValidator{
+		Difficulty:           config.Difficulty,
+		GasLimitBoundDivisor: 1024,
+		MinGasLimit:          big.NewInt(3_000_000),
+		Timestamps:           DefaultTimestampPolicy(),
+	}
+}
+
+// Validate checks each header against the one before it. headers must be in
+// ascending order; the first is taken as trusted and only serves as parent.
+// The first violation is returned as a *ChainViolation.
+func (v *ChainValidator) Validate(headers []*BlockHeader) error {
+	for i := 1; i < len(headers); i++ {
+		if err := v.ValidateChild(headers[i], headers[i-1]); err != nil {
+			var violation *ChainViolation
+			if errors.As(err, &violation) {
+				violation.Index = i
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateChild checks header against its parent.
+func (v *ChainValidator) ValidateChild(header, parent *BlockHeader) error {
+	violation := func(rule string, err error) error {
+		return &ChainViolation{Number: header.Number, Hash: header.Hash(), Rule: rule, Err: err}
+	}
+	if parentHash := parent.Hash(); header.ParentHash != parentHash {
+		return violation(RuleParentHash, fmt.Errorf("parent hash %s, parent hashes to %s", header.ParentHash.Hex(), parentHash.Hex()))
+	}
+	if want := new(big.Int).Add(bigOrZero(parent.Number), big.NewInt(1)); bigOrZero(header.Number).Cmp(want) != 0 {
+		return violation(RuleNumber, fmt.Errorf("number %v follows %v", header.Number, parent.Number))
+	}
+	if err := v.Timestamps.Check(header, parent); err != nil {
+		return violation(RuleTimestamp, err)
+	}
+	if err := v.checkGasLimit(header, parent); err != nil {
+		return violation(RuleGasLimit, err)
+	}
+	if err := v.Difficulty.VerifyDifficulty(header, parent); err != nil {
+		return violation(RuleDifficulty, err)
+	}
+	if err := VerifyMinimumGasPrice(header, parent); err != nil {
+		return violation(RuleMinimumGasPrice, err)
+	}
+	if err := header.ValidateUmmRoot(); err != nil {
+		return violation(RuleUmmRoot, err)
+	}
+	if v.UmmRoot != nil && header.IsUmm() {
+		if err := v.UmmRoot(header); err != nil {
+			return violation(RuleUmmRoot, err)
+		}
+	}
+	return nil
+}
+
+func (v *ChainValidator) checkGasLimit(header, parent *BlockHeader) error {
+	gasLimit := new(big.Int).SetBytes(header.GasLimit)
+	parentLimit := new(big.Int).SetBytes(parent.GasLimit)
+	if gasLimit.Cmp(v.MinGasLimit) < 0 {
+		return fmt.Errorf("gas limit %v is below the minimum %v", gasLimit, v.MinGasLimit)
+	}
+	bound := new(big.Int).Div(parentLimit, big.NewInt(v.GasLimitBoundDivisor))
+	if diff := new(big.Int).Sub(gasLimit, parentLimit); diff.Abs(diff).Cmp(bound) > 0 {
+		return fmt.Errorf("gas limit %v moves more than %v from parent's %v", gasLimit, bound, parentLimit)
+	}
+	return nil
+}
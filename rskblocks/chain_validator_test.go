@@ -0,0 +1,109 @@
+package rskblocks
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// testChain returns n linked regtest headers starting at block 100, 10
+// seconds apart. mutate, if not nil, edits each header's input before it is
+// built.
+func testChain(n int, mutate func(i int, input *BlockHeaderInput)) []*BlockHeader {
+	var headers []*BlockHeader
+	for i := 0; i < n; i++ {
+		input := testHeaderInput()
+		input.Number = big.NewInt(int64(100 + i))
+		input.Timestamp = big.NewInt(int64(1_700_000_000 + 10*i))
+		input.Difficulty = big.NewInt(1)
+		if i > 0 {
+			input.ParentHash = headers[i-1].Hash()
+		}
+		if mutate != nil {
+			mutate(i, input)
+		}
+		headers = append(headers, InputToBlockHeader(input, DefaultRegtestConfig()))
+	}
+	return headers
+}
+
+func testChainValidator() *ChainValidator {
+	v := NewChainValidator("regtest")
+	v.Timestamps.Now = func() time.Time { return time.Unix(1_700_000_100, 0) }
+	return v
+}
+
+func TestChainValidatorValid(t *testing.T) {
+	if err := testChainValidator().Validate(testChain(5, nil)); err != nil {
+		t.Fatalf("Valid chain rejected: %v", err)
+	}
+}
+
+func TestChainValidatorViolations(t *testing.T) {
+	cases := []struct {
+		rule   string
+		index  int
+		mutate func(i int, input *BlockHeaderInput)
+	}{
+		{RuleParentHash, 3, func(i int, input *BlockHeaderInput) {
+			if i == 3 {
+				input.ParentHash[0] ^= 1
+			}
+		}},
+		{RuleNumber, 3, func(i int, input *BlockHeaderInput) {
+			if i >= 3 {
+				input.Number = big.NewInt(int64(101 + i))
+			}
+		}},
+		{RuleTimestamp, 3, func(i int, input *BlockHeaderInput) {
+			if i == 3 {
+				input.Timestamp = big.NewInt(1_700_000_020)
+			}
+		}},
+		{RuleTimestamp, 3, func(i int, input *BlockHeaderInput) {
+			if i == 3 {
+				input.Timestamp = big.NewInt(1_700_001_000)
+			}
+		}},
+		{RuleGasLimit, 3, func(i int, input *BlockHeaderInput) {
+			if i >= 3 {
+				input.GasLimit = big.NewInt(6_900_000)
+			}
+		}},
+		{RuleGasLimit, 1, func(i int, input *BlockHeaderInput) {
+			input.GasLimit = big.NewInt(2_000_000)
+		}},
+		{RuleDifficulty, 3, func(i int, input *BlockHeaderInput) {
+			if i == 3 {
+				input.Difficulty = big.NewInt(2)
+			}
+		}},
+	}
+	for _, c := range cases {
+		err := testChainValidator().Validate(testChain(5, c.mutate))
+		var violation *ChainViolation
+		if !errors.As(err, &violation) {
+			t.Errorf("%s: expected a ChainViolation, got %v", c.rule, err)
+			continue
+		}
+		if violation.Rule != c.rule || violation.Index != c.index {
+			t.Errorf("%s: got %v", c.rule, violation)
+		}
+	}
+}
+
+func TestChainValidatorGasLimitBound(t *testing.T) {
+	v := testChainValidator()
+	// 6,800,000 / 1024 = 6640.
+	for delta, ok := range map[int64]bool{6640: true, -6640: true, 6641: false} {
+		headers := testChain(2, func(i int, input *BlockHeaderInput) {
+			if i == 1 {
+				input.GasLimit = big.NewInt(6_800_000 + delta)
+			}
+		})
+		if err := v.Validate(headers); (err == nil) != ok {
+			t.Errorf("Gas limit delta %d: got %v", delta, err)
+		}
+	}
+}
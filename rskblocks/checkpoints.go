@@ -0,0 +1,127 @@
+package rskblocks
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Checkpoint is a block a light client trusts without validating the chain
+// up to it. TotalDifficulty is the chain's cumulative difficulty up to and
+// including the block, the starting point for fork choice.
+type Checkpoint struct {
+	Number          hexutil.Uint64 `json:"number"`
+	Hash            common.Hash    `json:"hash"`
+	TotalDifficulty *hexutil.Big   `json:"totalDifficulty"`
+}
+
+// checkpointFile is the layout of the files in checkpoints/.
+type checkpointFile struct {
+	Network     string       `json:"network"`
+	Checkpoints []Checkpoint `json:"checkpoints"`
+}
+
+// ErrNoCheckpoint is returned when a network has no checkpoint at or below
+// the requested height.
+var ErrNoCheckpoint = errors.New("no checkpoint available")
+
+// The shipped checkpoints. Entries are only added once their hash and total
+// difficulty have been read from more than one independently operated node,
+// together with the block's raw header under testdata/checkpoints for
+// TestShippedCheckpointsMatchHeaders. None has been vetted yet, so the
+// mainnet and testnet files are empty and LatestCheckpoint returns
+// ErrNoCheckpoint: shipping checkpoints is still open. Until then, load
+// checkpoints vetted by the operator with LoadCheckpoints.
+//
+//go:embed checkpoints/*.json
+var embeddedCheckpoints embed.FS
+
+// LoadCheckpoints reads checkpoints in the embedded files' JSON layout, for
+// operators who vet their own. It returns the network they are for and the
+// checkpoints in ascending order.
+func LoadCheckpoints(r io.Reader) (string, []Checkpoint, error) {
+	var file checkpointFile
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return "", nil, fmt.Errorf("decode checkpoints: %w", err)
+	}
+	for i, cp := range file.Checkpoints {
+		if cp.Hash == (common.Hash{}) || cp.TotalDifficulty == nil || cp.TotalDifficulty.ToInt().Sign() <= 0 {
+			return "", nil, fmt.Errorf("checkpoint %d (block %d) is incomplete", i, cp.Number)
+		}
+	}
+	sort.Slice(file.Checkpoints, func(i, j int) bool { return file.Checkpoints[i].Number < file.Checkpoints[j].Number })
+	for i := 1; i < len(file.Checkpoints); i++ {
+		if file.Checkpoints[i].Number == file.Checkpoints[i-1].Number {
+			return "", nil, fmt.Errorf("two checkpoints for block %d", file.Checkpoints[i].Number)
+		}
+	}
+	return file.Network, file.Checkpoints, nil
+}
+
+// Checkpoints returns the checkpoints shipped for the config's network, in
+// ascending order. Regtest has none, and no network has any yet (see
+// embeddedCheckpoints).
+func (c *ChainConfig) Checkpoints() ([]Checkpoint, error) {
+	f, err := embeddedCheckpoints.Open("checkpoints/" + c.Network + ".json")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	network, checkpoints, err := LoadCheckpoints(f)
+	if err != nil {
+		return nil, err
+	}
+	if network != c.Network {
+		return nil, fmt.Errorf("checkpoints file for %s is labelled %q", c.Network, network)
+	}
+	return checkpoints, nil
+}
+
+// LatestCheckpoint returns the highest shipped checkpoint at or below
+// maxNumber, or ErrNoCheckpoint.
+func (c *ChainConfig) LatestCheckpoint(maxNumber uint64) (*Checkpoint, error) {
+	checkpoints, err := c.Checkpoints()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		if uint64(checkpoints[i].Number) <= maxNumber {
+			return &checkpoints[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %w at or below block %d", c.Network, ErrNoCheckpoint, maxNumber)
+}
+
+// CheckCheckpoint reports whether header is the checkpointed block.
+func (cp *Checkpoint) CheckCheckpoint(header *BlockHeader) error {
+	if n := bigOrZero(header.Number); !n.IsUint64() || n.Uint64() != uint64(cp.Number) {
+		return fmt.Errorf("header is block %v, checkpoint is block %d", header.Number, cp.Number)
+	}
+	return header.CheckHash(cp.Hash)
+}
+
+// ValidateFromCheckpoint validates a header chain that starts at a
+// checkpoint instead of at genesis: headers[0] must be the checkpointed
+// block, authenticated by its hash, and each later header is validated
+// against the one before it as in Validate.
+func (v *ChainValidator) ValidateFromCheckpoint(cp *Checkpoint, headers []*BlockHeader) error {
+	if len(headers) == 0 {
+		return errors.New("no headers to validate")
+	}
+	if err := cp.CheckCheckpoint(headers[0]); err != nil {
+		return &ChainViolation{Number: headers[0].Number, Hash: headers[0].Hash(), Rule: RuleCheckpoint, Err: err}
+	}
+	return v.Validate(headers)
+}
@@ -0,0 +1,122 @@
+package rskblocks
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestShippedCheckpoints(t *testing.T) {
+	for _, config := range []*ChainConfig{MainnetChainConfig(), TestnetChainConfig(), RegtestChainConfig()} {
+		checkpoints, err := config.Checkpoints()
+		if err != nil {
+			t.Fatalf("%s: %v", config.Network, err)
+		}
+		for i := 1; i < len(checkpoints); i++ {
+			if checkpoints[i].Number <= checkpoints[i-1].Number {
+				t.Errorf("%s checkpoints are not ascending", config.Network)
+			}
+		}
+	}
+	if _, err := RegtestChainConfig().LatestCheckpoint(1 << 40); !errors.Is(err, ErrNoCheckpoint) {
+		t.Errorf("Expected ErrNoCheckpoint for regtest, got %v", err)
+	}
+}
+
+// TestShippedCheckpointsMatchHeaders checks each shipped checkpoint against
+// the raw header it was taken from (rsk_getRawBlockHeaderByNumber), kept hex
+// encoded in testdata/checkpoints/<network>-<number>.hex. The cumulative
+// difficulty cannot be recomputed from one header, only bounded by it.
+func TestShippedCheckpointsMatchHeaders(t *testing.T) {
+	for _, config := range []*ChainConfig{MainnetChainConfig(), TestnetChainConfig()} {
+		checkpoints, err := config.Checkpoints()
+		if err != nil {
+			t.Fatalf("%s: %v", config.Network, err)
+		}
+		for _, cp := range checkpoints {
+			name := fmt.Sprintf("testdata/checkpoints/%s-%d.hex", config.Network, cp.Number)
+			data, err := os.ReadFile(name)
+			if err != nil {
+				t.Errorf("%s checkpoint %d has no recorded header: %v", config.Network, cp.Number, err)
+				continue
+			}
+			raw, err := hexutil.Decode(strings.TrimSpace(string(data)))
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			header, err := config.DecodeBlockHeader(raw)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if bigOrZero(header.Number).Uint64() != uint64(cp.Number) {
+				t.Errorf("%s checkpoint %d: header is block %v", config.Network, cp.Number, header.Number)
+			}
+			if err := header.CheckHash(cp.Hash); err != nil {
+				t.Errorf("%s checkpoint %d: %v", config.Network, cp.Number, err)
+			}
+			if cp.TotalDifficulty.ToInt().Cmp(bigOrZero(header.Difficulty)) < 0 {
+				t.Errorf("%s checkpoint %d: total difficulty is below the block's own", config.Network, cp.Number)
+			}
+		}
+	}
+}
+
+func TestLoadCheckpoints(t *testing.T) {
+	data := `{"network": "testnet", "checkpoints": [
+		{"number": "0x200", "hash": "0x0000000000000000000000000000000000000000000000000000000000000002", "totalDifficulty": "0x2000"},
+		{"number": "0x100", "hash": "0x0000000000000000000000000000000000000000000000000000000000000001", "totalDifficulty": "0x1000"}
+	]}`
+	network, checkpoints, err := LoadCheckpoints(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if network != "testnet" || len(checkpoints) != 2 || checkpoints[0].Number != 0x100 || checkpoints[1].TotalDifficulty.ToInt().Int64() != 0x2000 {
+		t.Errorf("Unexpected checkpoints %q %+v", network, checkpoints)
+	}
+
+	for name, bad := range map[string]string{
+		"no hash":       `{"network": "testnet", "checkpoints": [{"number": "0x1", "totalDifficulty": "0x1"}]}`,
+		"no difficulty": `{"network": "testnet", "checkpoints": [{"number": "0x1", "hash": "0x01"}]}`,
+		"duplicate": `{"network": "testnet", "checkpoints": [{"number": "0x1", "hash": "0x01", "totalDifficulty": "0x1"},` +
+			`{"number": "0x1", "hash": "0x02", "totalDifficulty": "0x1"}]}`,
+		"unknown field": `{"network": "testnet", "checkpoints": [], "extra": 1}`,
+	} {
+		if _, _, err := LoadCheckpoints(strings.NewReader(bad)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestValidateFromCheckpoint(t *testing.T) {
+	headers := testChain(4, nil)
+	v := testChainValidator()
+	cp := &Checkpoint{Number: 100, Hash: headers[0].Hash(), TotalDifficulty: (*hexutil.Big)(big.NewInt(1000))}
+	if err := v.ValidateFromCheckpoint(cp, headers); err != nil {
+		t.Fatal(err)
+	}
+
+	wrong := *cp
+	wrong.Hash = common.Hash{1}
+	err := v.ValidateFromCheckpoint(&wrong, headers)
+	var violation *ChainViolation
+	if !errors.As(err, &violation) || violation.Rule != RuleCheckpoint {
+		t.Errorf("Expected a checkpoint violation, got %v", err)
+	}
+	wrong = *cp
+	wrong.Number = 101
+	if err := v.ValidateFromCheckpoint(&wrong, headers); err == nil {
+		t.Error("Expected a checkpoint at another height to fail")
+	}
+	if err := v.ValidateFromCheckpoint(cp, headers[1:]); err == nil {
+		t.Error("Expected a chain not starting at the checkpoint to fail")
+	}
+	if err := v.ValidateFromCheckpoint(cp, nil); err == nil {
+		t.Error("Expected an empty chain to fail")
+	}
+}
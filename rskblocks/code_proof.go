@@ -0,0 +1,36 @@
+package rskblocks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// VerifyCodeProof verifies a proof for address's code key and checks that the
+// proven code equals code, as an extcodecopy-style read would return it.
+//
+// Contract code is usually longer than 32 bytes, in which case the trie node
+// only commits to its keccak256 hash and length; code is checked against
+// those. Shorter code is compared byte for byte. An account without code is
+// proven by the absence of the code key and matches empty code.
+//
+// Returns false with a nil error if the proof is valid but the code differs,
+// and an error if the proof itself does not verify.
+func (v *ProofVerifier) VerifyCodeProof(
+	stateRoot common.Hash,
+	address common.Address,
+	code []byte,
+	proofNodes [][]byte,
+) (bool, error) {
+	codeKey := v.keyMapper.GetCodeKey(address)
+	if codeKey == nil {
+		return false, fmt.Errorf("code is not stored in the state trie for key mapper version %s", v.keyMapper.Version())
+	}
+
+	result, err := v.verifyKey(context.Background(), stateRoot, codeKey, proofNodes)
+	if err != nil {
+		return false, err
+	}
+	return result.Matches(code), nil
+}
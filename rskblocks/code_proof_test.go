@@ -0,0 +1,66 @@
+package rskblocks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestVerifyCodeProof(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045e71a7a2c50903d88e564cd72fab11e82051")
+	small := common.HexToAddress("0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826")
+	eoa := common.HexToAddress("0x0000000000000000000000000000000000001234")
+
+	code := bytes.Repeat([]byte{0x60, 0x80, 0x60, 0x40, 0x52}, 40)
+	smallCode := []byte{0x60, 0x00, 0xf3}
+	trie := rsktrie.NewTrie(nil).
+		Put(mapper.GetAccountKey(contract), []byte{0xc0}).
+		Put(mapper.GetCodeKey(contract), code).
+		Put(mapper.GetAccountKey(small), []byte{0xc0}).
+		Put(mapper.GetCodeKey(small), smallCode).
+		Put(mapper.GetAccountKey(eoa), []byte{0xc0})
+	stateRoot := common.BytesToHash(trie.GetHash())
+	verifier := NewProofVerifier()
+
+	tampered := append([]byte(nil), code...)
+	tampered[10] ^= 0xff
+	tests := []struct {
+		name    string
+		address common.Address
+		code    []byte
+		want    bool
+	}{
+		{"long code", contract, code, true},
+		{"tampered long code", contract, tampered, false},
+		{"truncated long code", contract, code[:len(code)-1], false},
+		{"short code", small, smallCode, true},
+		{"wrong short code", small, []byte{0x00}, false},
+		{"no code", eoa, nil, true},
+		{"code claimed for EOA", eoa, smallCode, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proof := buildTestProof(t, trie, mapper.GetCodeKey(tt.address))
+			got, err := verifier.VerifyCodeProof(stateRoot, tt.address, tt.code, proof)
+			if err != nil {
+				t.Fatalf("VerifyCodeProof failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	proof := buildTestProof(t, trie, mapper.GetCodeKey(contract))
+	if _, err := verifier.VerifyCodeProof(common.Hash{}, contract, code, proof); err == nil {
+		t.Error("Expected error against wrong state root")
+	}
+	orchid := NewProofVerifierWithKeyMapper(rsktrie.NewOrchidKeyMapper())
+	if _, err := orchid.VerifyCodeProof(stateRoot, contract, code, proof); err == nil {
+		t.Error("Expected error for orchid key mapper")
+	}
+}
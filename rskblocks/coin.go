@@ -0,0 +1,177 @@
+package rskblocks
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Decimal places of the RBTC and gwei units relative to wei.
+const (
+	RBTCDecimals = 18
+	GweiDecimals = 9
+)
+
+// Coin is an amount of RBTC in wei, as rskj's Coin is. The zero value is
+// zero; operations return new values and never modify their operands.
+//
+// The views (BlockView, TransactionView) hold Coins, and verified balances
+// are available as Coins through AccountProofResult.BalanceCoin,
+// SnapshotEntry.BalanceCoin and AccountSnapshot.TotalBalanceCoin.
+// BlockHeader keeps *big.Int fields, mirroring the encoding, and the
+// rsktrie results do too, since rsktrie cannot depend on this package.
+type Coin struct {
+	wei *big.Int
+}
+
+// NewCoin returns wei as a Coin; nil is zero.
+func NewCoin(wei *big.Int) Coin {
+	if wei == nil {
+		return Coin{}
+	}
+	return Coin{wei: new(big.Int).Set(wei)}
+}
+
+// CoinFromWei returns wei as a Coin.
+func CoinFromWei(wei uint64) Coin {
+	return Coin{wei: new(big.Int).SetUint64(wei)}
+}
+
+// ParseCoin parses an amount of wei, hex with a 0x prefix, as RPC responses
+// carry it ("0x" is zero, as RSKj sends it), or decimal.
+func ParseCoin(s string) (Coin, error) {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		wei, ok := parseStorageValue(s)
+		if !ok || strings.ContainsAny(s, "+-") {
+			return Coin{}, fmt.Errorf("invalid hex amount %q", s)
+		}
+		return Coin{wei: wei}, nil
+	}
+	wei, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return Coin{}, fmt.Errorf("invalid amount %q", s)
+	}
+	return Coin{wei: wei}, nil
+}
+
+// ParseRBTC parses a decimal amount of RBTC such as "0.5", with at most 18
+// decimal places.
+func ParseRBTC(s string) (Coin, error) {
+	return parseUnits(s, RBTCDecimals)
+}
+
+// ParseGwei parses a decimal amount of gwei such as "0.06", with at most 9
+// decimal places.
+func ParseGwei(s string) (Coin, error) {
+	return parseUnits(s, GweiDecimals)
+}
+
+// Wei returns the amount in wei.
+func (c Coin) Wei() *big.Int {
+	return new(big.Int).Set(c.big())
+}
+
+// Add returns c + o.
+func (c Coin) Add(o Coin) Coin {
+	return Coin{wei: new(big.Int).Add(c.big(), o.big())}
+}
+
+// Sub returns c - o, which may be negative.
+func (c Coin) Sub(o Coin) Coin {
+	return Coin{wei: new(big.Int).Sub(c.big(), o.big())}
+}
+
+// MulUint64 returns c * n, such as a gas price times gas used.
+func (c Coin) MulUint64(n uint64) Coin {
+	return Coin{wei: new(big.Int).Mul(c.big(), new(big.Int).SetUint64(n))}
+}
+
+// Cmp compares c and o as big.Int.Cmp does.
+func (c Coin) Cmp(o Coin) int {
+	return c.big().Cmp(o.big())
+}
+
+// Equal reports whether c and o are the same amount.
+func (c Coin) Equal(o Coin) bool {
+	return c.Cmp(o) == 0
+}
+
+// Sign returns -1, 0 or 1 as c is negative, zero or positive.
+func (c Coin) Sign() int {
+	return c.big().Sign()
+}
+
+// IsZero reports whether c is zero.
+func (c Coin) IsZero() bool {
+	return c.Sign() == 0
+}
+
+// String returns the amount in wei, in decimal.
+func (c Coin) String() string {
+	return c.big().String()
+}
+
+// RBTC formats the amount in RBTC, without trailing zeros: "1.5", "0".
+func (c Coin) RBTC() string {
+	return formatUnits(c.big(), RBTCDecimals)
+}
+
+// Gwei formats the amount in gwei, without trailing zeros.
+func (c Coin) Gwei() string {
+	return formatUnits(c.big(), GweiDecimals)
+}
+
+// MarshalText implements encoding.TextMarshaler, as a hex quantity.
+func (c Coin) MarshalText() ([]byte, error) {
+	return []byte(hexutil.EncodeBig(c.big())), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting what
+// ParseCoin does.
+func (c *Coin) UnmarshalText(text []byte) error {
+	parsed, err := ParseCoin(string(text))
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
+
+func (c Coin) big() *big.Int {
+	if c.wei == nil {
+		return new(big.Int)
+	}
+	return c.wei
+}
+
+func formatUnits(wei *big.Int, decimals int) string {
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	whole, frac := new(big.Int).QuoRem(new(big.Int).Abs(wei), unit, new(big.Int))
+	s := whole.String()
+	if frac.Sign() != 0 {
+		digits := fmt.Sprintf("%0*s", decimals, frac.String())
+		s += "." + strings.TrimRight(digits, "0")
+	}
+	if wei.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+func parseUnits(s string, decimals int) (Coin, error) {
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(frac) > decimals {
+		return Coin{}, fmt.Errorf("amount %q has more than %d decimal places", s, decimals)
+	}
+	digits := whole + frac + strings.Repeat("0", decimals-len(frac))
+	if whole == "" || whole == "-" || strings.ContainsAny(digits[1:], "+-") {
+		return Coin{}, fmt.Errorf("invalid amount %q", s)
+	}
+	wei, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Coin{}, fmt.Errorf("invalid amount %q", s)
+	}
+	return Coin{wei: wei}, nil
+}
@@ -0,0 +1,116 @@
+package rskblocks
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestCoinArithmetic(t *testing.T) {
+	a, b := CoinFromWei(1500), CoinFromWei(500)
+	if got := a.Add(b); got.String() != "2000" {
+		t.Errorf("Add: %s", got)
+	}
+	if got := b.Sub(a); got.String() != "-1000" || got.Sign() != -1 {
+		t.Errorf("Sub: %s", got)
+	}
+	if got := b.MulUint64(21000); got.String() != "10500000" {
+		t.Errorf("MulUint64: %s", got)
+	}
+	if a.Cmp(b) != 1 || !a.Equal(CoinFromWei(1500)) || a.IsZero() || !(Coin{}).IsZero() {
+		t.Error("Comparisons")
+	}
+
+	// Coins do not alias the values they are built from or return.
+	wei := big.NewInt(7)
+	c := NewCoin(wei)
+	wei.SetInt64(8)
+	c.Wei().SetInt64(9)
+	if c.String() != "7" {
+		t.Errorf("Coin changed to %s", c)
+	}
+	if a.String() != "1500" {
+		t.Error("Operation changed its operand")
+	}
+	if NewCoin(nil).String() != "0" {
+		t.Error("nil is not zero")
+	}
+}
+
+func TestCoinParseAndFormat(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		wei  string
+		rbtc string
+		gwei string
+	}{
+		{"0x", "0", "0", "0"},
+		{"0x3b9aca00", "1000000000", "0.000000001", "1"},
+		{"60000000", "60000000", "0.00000000006", "0.06"},
+		{"1500000000000000000", "1500000000000000000", "1.5", "1500000000"},
+		{"-250000000", "-250000000", "-0.00000000025", "-0.25"},
+	} {
+		coin, err := ParseCoin(c.in)
+		if err != nil {
+			t.Errorf("ParseCoin(%q): %v", c.in, err)
+			continue
+		}
+		if coin.String() != c.wei || coin.RBTC() != c.rbtc || coin.Gwei() != c.gwei {
+			t.Errorf("%q: wei %s, RBTC %s, gwei %s", c.in, coin, coin.RBTC(), coin.Gwei())
+		}
+	}
+	for _, bad := range []string{"", "0xzz", "0x-1", "1.5", "abc"} {
+		if _, err := ParseCoin(bad); err == nil {
+			t.Errorf("ParseCoin(%q) accepted", bad)
+		}
+	}
+
+	if c, err := ParseRBTC("1.5"); err != nil || c.String() != "1500000000000000000" {
+		t.Errorf("ParseRBTC: %s, %v", c, err)
+	}
+	if c, err := ParseGwei("0.06"); err != nil || c.String() != "60000000" {
+		t.Errorf("ParseGwei: %s, %v", c, err)
+	}
+	if c, err := ParseRBTC("2"); err != nil || c.RBTC() != "2" {
+		t.Errorf("ParseRBTC whole: %s, %v", c, err)
+	}
+	for _, bad := range []string{"", ".5", "1.0000000000000000001", "1.-5", "1.2.3", "x"} {
+		if _, err := ParseRBTC(bad); err == nil {
+			t.Errorf("ParseRBTC(%q) accepted", bad)
+		}
+	}
+}
+
+func TestCoinJSON(t *testing.T) {
+	var v struct {
+		Fee Coin `json:"fee"`
+	}
+	if err := json.Unmarshal([]byte(`{"fee":"0x3e8"}`), &v); err != nil || v.Fee.String() != "1000" {
+		t.Fatalf("Unmarshal: %s, %v", v.Fee, err)
+	}
+	data, err := json.Marshal(v)
+	if err != nil || string(data) != `{"fee":"0x3e8"}` {
+		t.Errorf("Marshal: %s, %v", data, err)
+	}
+}
+
+func TestBalanceCoins(t *testing.T) {
+	result := &AccountProofResult{Balance: big.NewInt(5)}
+	if !result.BalanceCoin().Equal(CoinFromWei(5)) || !(&AccountProofResult{}).BalanceCoin().IsZero() {
+		t.Error("AccountProofResult.BalanceCoin")
+	}
+	snapshot := &AccountSnapshot{Entries: []SnapshotEntry{
+		{Address: common.Address{1}, Balance: big.NewInt(2)},
+		{Address: common.Address{2}, Balance: big.NewInt(3)},
+		{Address: common.Address{3}, Err: errors.New("invalid")},
+	}}
+	if !snapshot.Entries[0].BalanceCoin().Equal(CoinFromWei(2)) || !snapshot.Entries[2].BalanceCoin().IsZero() {
+		t.Error("SnapshotEntry.BalanceCoin")
+	}
+	if got := snapshot.TotalBalanceCoin(); !got.Equal(CoinFromWei(5)) {
+		t.Errorf("TotalBalanceCoin = %s, want 5", got)
+	}
+}
@@ -0,0 +1,65 @@
+package rskblocks
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Confirmations returns how many validated headers of the best chain,
+// counting the block itself, confirm hash: 1 for the head, 0 for a block that
+// is unknown or not on the best chain.
+func (t *HeadTracker) Confirmations(hash common.Hash) (uint64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.confirmations(hash)
+}
+
+func (t *HeadTracker) confirmations(hash common.Hash) (uint64, error) {
+	header, err := t.store.Header(hash)
+	if errors.Is(err, ErrHeaderNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	number, headNumber := header.Number.Uint64(), t.head.Number.Uint64()
+	if number > headNumber {
+		return 0, nil
+	}
+	canonical, err := t.store.canonicalHash(number)
+	if err != nil && !errors.Is(err, ErrHeaderNotFound) {
+		return 0, err
+	}
+	if canonical != hash {
+		return 0, nil
+	}
+	return headNumber - number + 1, nil
+}
+
+// WaitForConfirmations blocks until hash has at least depth confirmations
+// (see Confirmations) and returns the count reached, or the context's error
+// if it is done first. A block that is reorganized out simply stops counting
+// until it is on the best chain again.
+func (t *HeadTracker) WaitForConfirmations(ctx context.Context, hash common.Hash, depth uint64) (uint64, error) {
+	changed := make(chan struct{}, 1)
+	unsubscribe := t.Subscribe(func(*HeadChange) {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	defer unsubscribe()
+	for {
+		n, err := t.Confirmations(hash)
+		if err != nil || n >= depth {
+			return n, err
+		}
+		select {
+		case <-ctx.Done():
+			return n, ctx.Err()
+		case <-changed:
+		}
+	}
+}
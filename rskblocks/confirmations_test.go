@@ -0,0 +1,88 @@
+package rskblocks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+)
+
+func TestConfirmations(t *testing.T) {
+	s, anchor := testHeaderStore(t, rsktrie.NewMemKeyValueStore())
+	tracker, err := NewHeadTracker(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a1 := testChild(anchor, nil)
+	a2 := testChild(a1, nil)
+	for _, h := range []*BlockHeader{a1, a2} {
+		if _, err := tracker.Insert(h, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fork := func(input *BlockHeaderInput) { input.ExtraData = []byte("fork") }
+	side := testChild(anchor, fork)
+	if _, err := tracker.Insert(side, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name   string
+		header *BlockHeader
+		want   uint64
+	}{
+		{"anchor", anchor, 3},
+		{"a1", a1, 2},
+		{"head", a2, 1},
+		{"side branch", side, 0},
+		{"unknown", testChild(a2, nil), 0},
+	} {
+		if got, err := tracker.Confirmations(tt.header.Hash()); err != nil || got != tt.want {
+			t.Errorf("%s: %d confirmations, %v; want %d", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestWaitForConfirmations(t *testing.T) {
+	s, anchor := testHeaderStore(t, rsktrie.NewMemKeyValueStore())
+	tracker, err := NewHeadTracker(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a1 := testChild(anchor, nil)
+	if _, err := tracker.Insert(a1, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		n, err := tracker.WaitForConfirmations(ctx, a1.Hash(), 3)
+		if err == nil && n != 3 {
+			err = errors.New("returned before depth 3")
+		}
+		done <- err
+	}()
+	parent := a1
+	for range 2 {
+		parent = testChild(parent, nil)
+		if _, err := tracker.Insert(parent, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// Already confirmed blocks return at once; unreachable depths time out.
+	if n, err := tracker.WaitForConfirmations(context.Background(), anchor.Hash(), 2); err != nil || n != 4 {
+		t.Errorf("Confirmed block: %d, %v", n, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := tracker.WaitForConfirmations(ctx, a1.Hash(), 100); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Unreachable depth returned %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package rskblocks
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// DifficultyParams are the network constants of RSK's difficulty adjustment
+// (rskj's Constants).
+type DifficultyParams struct {
+	// DurationLimit is the target block interval in seconds; a block with n
+	// uncles targets (1+n)*DurationLimit.
+	DurationLimit int64
+	// BoundDivisor sets the step: difficulty moves by parent/BoundDivisor.
+	BoundDivisor *big.Int
+	// Rskip156BoundDivisor, if set, replaces BoundDivisor from block
+	// Rskip156Height on: papyrus200 raised mainnet's and testnet's divisor
+	// from 50 to 400 (RSKIP-156).
+	Rskip156BoundDivisor *big.Int
+	Rskip156Height       int64
+	// MinimumDifficulty is the floor the adjustment never goes below.
+	MinimumDifficulty *big.Int
+}
+
+// DifficultyParamsForNetwork returns the difficulty constants for "mainnet",
+// "testnet" or "regtest"; anything else gets regtest's, as in
+// ConfigForBlockNumber.
+func DifficultyParamsForNetwork(network string) DifficultyParams {
+	return ChainConfigForNetwork(network).Difficulty
+}
+
+// BoundDivisorAt returns the bound divisor in force at blockNum.
+func (p DifficultyParams) BoundDivisorAt(blockNum uint64) *big.Int {
+	if p.Rskip156BoundDivisor != nil && IsActive(p.Rskip156Height, blockNum) {
+		return p.Rskip156BoundDivisor
+	}
+	return p.BoundDivisor
+}
+
+// CalcDifficulty returns the difficulty header must have given its parent,
+// following rskj's DifficultyCalculator: the parent's difficulty moves by
+// parent/BoundDivisorAt(header's number), up if the block came sooner than (1+uncleCount)
+// DurationLimits after its parent and down if later, and stays put if
+// exactly on target. The result is never below MinimumDifficulty.
+func (p DifficultyParams) CalcDifficulty(header, parent *BlockHeader) *big.Int {
+	pd := bigOrZero(parent.Difficulty)
+	delta := new(big.Int).Sub(bigOrZero(header.Timestamp), bigOrZero(parent.Timestamp))
+	target := big.NewInt((1 + int64(header.UncleCount)) * p.DurationLimit)
+
+	number := bigOrZero(header.Number)
+	if !number.IsUint64() {
+		number = new(big.Int).SetUint64(^uint64(0))
+	}
+	step := new(big.Int).Div(pd, p.BoundDivisorAt(number.Uint64()))
+	difficulty := new(big.Int).Set(pd)
+	switch target.Cmp(delta) {
+	case 0:
+		return difficulty
+	case 1:
+		difficulty.Add(difficulty, step)
+	default:
+		difficulty.Sub(difficulty, step)
+	}
+	if difficulty.Cmp(p.MinimumDifficulty) < 0 {
+		difficulty.Set(p.MinimumDifficulty)
+	}
+	return difficulty
+}
+
+// VerifyDifficulty reports whether header's difficulty is the one
+// CalcDifficulty expects after parent.
+func (p DifficultyParams) VerifyDifficulty(header, parent *BlockHeader) error {
+	want := p.CalcDifficulty(header, parent)
+	if got := bigOrZero(header.Difficulty); got.Cmp(want) != 0 {
+		return fmt.Errorf("block %v has difficulty %v, want %v", header.Number, got, want)
+	}
+	return nil
+}
+
+func bigOrZero(x *big.Int) *big.Int {
+	if x == nil {
+		return new(big.Int)
+	}
+	return x
+}
@@ -0,0 +1,77 @@
+package rskblocks
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestCalcDifficulty(t *testing.T) {
+	params := DifficultyParamsForNetwork("testnet")
+	parent := &BlockHeader{Number: big.NewInt(99), Difficulty: big.NewInt(5_000_000), Timestamp: big.NewInt(1000)}
+	block := func(seconds int64, uncles int) *BlockHeader {
+		return &BlockHeader{Number: big.NewInt(100), Timestamp: big.NewInt(1000 + seconds), UncleCount: uncles}
+	}
+	cases := []struct {
+		name   string
+		header *BlockHeader
+		want   int64
+	}{
+		{"fast", block(10, 0), 5_100_000},
+		{"on target", block(14, 0), 5_000_000},
+		{"slow", block(20, 0), 4_900_000},
+		{"uncles widen the target", block(20, 1), 5_100_000},
+		{"on target with uncles", block(28, 1), 5_000_000},
+		{"out of order timestamps", block(-5, 0), 5_100_000},
+	}
+	for _, c := range cases {
+		if got := params.CalcDifficulty(c.header, parent); got.Int64() != c.want {
+			t.Errorf("%s: difficulty %v, want %d", c.name, got, c.want)
+		}
+	}
+
+	low := &BlockHeader{Difficulty: big.NewInt(131100), Timestamp: big.NewInt(0)}
+	if got := params.CalcDifficulty(block(60, 0), low); got.Cmp(params.MinimumDifficulty) != 0 {
+		t.Errorf("Expected the minimum difficulty, got %v", got)
+	}
+}
+
+func TestCalcDifficultyRskip156(t *testing.T) {
+	for _, config := range []*ChainConfig{MainnetChainConfig(), TestnetChainConfig()} {
+		params := config.Difficulty
+		activation := config.Forks.Papyrus200
+		pair := func(number int64) (*BlockHeader, *BlockHeader) {
+			parent := &BlockHeader{Number: big.NewInt(number - 1), Difficulty: big.NewInt(8_000_000_000_000_000), Timestamp: big.NewInt(1000)}
+			return &BlockHeader{Number: big.NewInt(number), Timestamp: big.NewInt(1010)}, parent
+		}
+		cases := []struct {
+			number int64
+			want   int64
+		}{
+			{activation - 1, 8_160_000_000_000_000},
+			{activation, 8_020_000_000_000_000},
+			{activation + 1, 8_020_000_000_000_000},
+		}
+		for _, c := range cases {
+			header, parent := pair(c.number)
+			if got := params.CalcDifficulty(header, parent); got.Int64() != c.want {
+				t.Errorf("%s block %d: difficulty %v, want %d", config.Network, c.number, got, c.want)
+			}
+		}
+	}
+	if RegtestChainConfig().Difficulty.BoundDivisorAt(1).Int64() != 2048 {
+		t.Error("Expected regtest to keep its bound divisor")
+	}
+}
+
+func TestVerifyDifficulty(t *testing.T) {
+	params := DifficultyParamsForNetwork("regtest")
+	parent := &BlockHeader{Difficulty: big.NewInt(1), Timestamp: big.NewInt(0)}
+	header := &BlockHeader{Number: big.NewInt(1), Difficulty: big.NewInt(1), Timestamp: big.NewInt(3)}
+	if err := params.VerifyDifficulty(header, parent); err != nil {
+		t.Errorf("Regtest difficulty should stay at 1: %v", err)
+	}
+	header.Difficulty = big.NewInt(2)
+	if err := params.VerifyDifficulty(header, parent); err == nil {
+		t.Error("Expected a wrong difficulty to be rejected")
+	}
+}
@@ -0,0 +1,121 @@
+package rskblocks
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// AccountResult is a typed EIP-1186 eth_getProof result. Unlike
+// ProofResponse, which keeps the raw strings of the RPC response, proof nodes
+// are decoded to bytes and storage keys and values to numbers, so a parsed
+// result can be passed straight to the verifier.
+type AccountResult struct {
+	Address      common.Address  `json:"address"`
+	AccountProof []hexutil.Bytes `json:"accountProof"`
+	Balance      *hexutil.Big    `json:"balance"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Nonce        hexutil.Uint64  `json:"nonce"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageProof []StorageResult `json:"storageProof"`
+}
+
+// StorageResult is one storageProof entry of an AccountResult.
+type StorageResult struct {
+	Key   common.Hash     `json:"key"`
+	Value *big.Int        `json:"value"`
+	Proof []hexutil.Bytes `json:"proof"`
+}
+
+// storageResultJSON is the wire form of StorageResult. RSKj returns keys and
+// values as minimal or zero-padded hex, and "0x" for an empty value, which
+// the strict hexutil types reject.
+type storageResultJSON struct {
+	Key   string          `json:"key"`
+	Value string          `json:"value"`
+	Proof []hexutil.Bytes `json:"proof"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *StorageResult) UnmarshalJSON(data []byte) error {
+	var dec storageResultJSON
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	key, ok := parseStorageValue(dec.Key)
+	if !ok || key.BitLen() > 256 {
+		return fmt.Errorf("invalid storage key %q", dec.Key)
+	}
+	value, ok := parseStorageValue(dec.Value)
+	if !ok || value.BitLen() > 256 {
+		return fmt.Errorf("invalid storage value %q", dec.Value)
+	}
+	s.Key = common.BigToHash(key)
+	s.Value = value
+	s.Proof = dec.Proof
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s StorageResult) MarshalJSON() ([]byte, error) {
+	value := s.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	return json.Marshal(&storageResultJSON{
+		Key:   s.Key.Hex(),
+		Value: hexutil.EncodeBig(value),
+		Proof: s.Proof,
+	})
+}
+
+// ParseAccountResult parses the JSON result of an eth_getProof call.
+func ParseAccountResult(data []byte) (*AccountResult, error) {
+	var r AccountResult
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("parse eth_getProof result: %w", err)
+	}
+	return &r, nil
+}
+
+// ProofResponse converts r to the raw response form used by
+// ProofVerifier.VerifyEthGetProofResponse.
+func (r *AccountResult) ProofResponse() *ProofResponse {
+	resp := &ProofResponse{
+		Address:      r.Address,
+		AccountProof: encodeHexNodes(r.AccountProof),
+		Balance:      r.Balance,
+		CodeHash:     r.CodeHash,
+		Nonce:        r.Nonce,
+		StorageHash:  r.StorageHash,
+	}
+	for _, s := range r.StorageProof {
+		value := s.Value
+		if value == nil {
+			value = new(big.Int)
+		}
+		resp.StorageProof = append(resp.StorageProof, StorageProof{
+			Key:    s.Key.Hex(),
+			Value:  hexutil.EncodeBig(value),
+			Proofs: encodeHexNodes(s.Proof),
+		})
+	}
+	return resp
+}
+
+// VerifyAccountResult verifies a parsed eth_getProof result against header,
+// as VerifyEthGetProofResponse does.
+func (v *ProofVerifier) VerifyAccountResult(header *BlockHeader, r *AccountResult) (*ResponseVerification, error) {
+	return v.VerifyEthGetProofResponse(header, r.ProofResponse())
+}
+
+func encodeHexNodes(nodes []hexutil.Bytes) []string {
+	out := make([]string, len(nodes))
+	for i, n := range nodes {
+		out[i] = n.String()
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+package rskblocks
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestParseAccountResult(t *testing.T) {
+	data := []byte(`{
+		"address": "0x77045e71a7a2c50903d88e564cd72fab11e82051",
+		"accountProof": ["0x4c01", "0x0d02"],
+		"balance": "0x1e240",
+		"codeHash": "0xc5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470",
+		"nonce": "0x5",
+		"storageHash": "0x0000000000000000000000000000000000000000000000000000000000000000",
+		"storageProof": [
+			{"key": "0x3", "value": "0x002a", "proof": ["0x4c03"]},
+			{"key": "0x0000000000000000000000000000000000000000000000000000000000000004", "value": "0x", "proof": []}
+		]
+	}`)
+	r, err := ParseAccountResult(data)
+	if err != nil {
+		t.Fatalf("ParseAccountResult failed: %v", err)
+	}
+	if r.Balance.ToInt().Int64() != 123456 || r.Nonce != 5 || len(r.AccountProof) != 2 || r.AccountProof[1][0] != 0x0d {
+		t.Errorf("Unexpected account fields %+v", r)
+	}
+	if len(r.StorageProof) != 2 {
+		t.Fatalf("Expected 2 storage proofs, got %d", len(r.StorageProof))
+	}
+	if s := r.StorageProof[0]; s.Key != common.BigToHash(big.NewInt(3)) || s.Value.Int64() != 0x2a || len(s.Proof) != 1 {
+		t.Errorf("Unexpected storage proof %+v", s)
+	}
+	if s := r.StorageProof[1]; s.Value.Sign() != 0 {
+		t.Errorf("Expected empty value to parse as zero, got %s", s.Value)
+	}
+
+	// Marshaling round-trips.
+	enc, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := ParseAccountResult(enc)
+	if err != nil || again.StorageProof[0].Value.Int64() != 0x2a {
+		t.Errorf("Round trip failed: %v", err)
+	}
+
+	if _, err := ParseAccountResult([]byte(`{"storageProof":[{"key":"0xzz","value":"0x1"}]}`)); err == nil {
+		t.Error("Expected error for malformed storage key")
+	}
+}
+
+func TestVerifyAccountResult(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	addr := common.HexToAddress("0x77045e71a7a2c50903d88e564cd72fab11e82051")
+	slot := common.BigToHash(big.NewInt(3))
+
+	account, err := (&rsktrie.AccountState{Nonce: big.NewInt(1), Balance: big.NewInt(10)}).Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	trie := rsktrie.NewTrie(nil).
+		Put(mapper.GetAccountKey(addr), account).
+		Put(mapper.GetAccountStorageKey(addr, slot), []byte{0x2a})
+	header := &BlockHeader{StateRoot: common.BytesToHash(trie.GetHash())}
+
+	toBytes := func(nodes [][]byte) []hexutil.Bytes {
+		out := make([]hexutil.Bytes, len(nodes))
+		for i, n := range nodes {
+			out[i] = n
+		}
+		return out
+	}
+	r := &AccountResult{
+		Address:      addr,
+		AccountProof: toBytes(buildTestProof(t, trie, mapper.GetAccountKey(addr))),
+		Balance:      (*hexutil.Big)(big.NewInt(10)),
+		Nonce:        1,
+		StorageProof: []StorageResult{{
+			Key:   slot,
+			Value: big.NewInt(0x2a),
+			Proof: toBytes(buildTestProof(t, trie, mapper.GetAccountStorageKey(addr, slot))),
+		}},
+	}
+	result, err := NewProofVerifier().VerifyAccountResult(header, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Valid {
+		t.Errorf("Expected valid result, got %v", result.Err())
+	}
+}
@@ -0,0 +1,22 @@
+package rskblocks
+
+import (
+	"bytes"
+	"math/big"
+)
+
+// IsBetterChain reports whether the chain ending at header, with total
+// difficulty td, should replace the best chain ending at head, with total
+// difficulty headTD. As in rskj's SelectionRule.shouldWeAddThisBlock the
+// heavier chain wins and a tie goes to the block with the smaller hash, so
+// every node picks the same head whatever order it hears of the two.
+func IsBetterChain(header *BlockHeader, td *big.Int, head *BlockHeader, headTD *big.Int) bool {
+	switch td.Cmp(headTD) {
+	case 1:
+		return true
+	case -1:
+		return false
+	}
+	hash, headHash := header.Hash(), head.Hash()
+	return bytes.Compare(hash[:], headHash[:]) < 0
+}
@@ -0,0 +1,76 @@
+package rskblocks
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+)
+
+func TestIsBetterChain(t *testing.T) {
+	a := testChain(1, nil)[0]
+	b := testChild(a, nil)
+	ha, hb := a.Hash(), b.Hash()
+	smaller, larger := a, b
+	if bytes.Compare(ha[:], hb[:]) > 0 {
+		smaller, larger = b, a
+	}
+
+	if !IsBetterChain(larger, big.NewInt(11), smaller, big.NewInt(10)) {
+		t.Error("The heavier chain should win")
+	}
+	if IsBetterChain(smaller, big.NewInt(9), larger, big.NewInt(10)) {
+		t.Error("The lighter chain should lose")
+	}
+	if !IsBetterChain(smaller, big.NewInt(10), larger, big.NewInt(10)) || IsBetterChain(larger, big.NewInt(10), smaller, big.NewInt(10)) {
+		t.Error("A tie should go to the smaller hash")
+	}
+	if IsBetterChain(a, big.NewInt(10), a, big.NewInt(10)) {
+		t.Error("A chain is not better than itself")
+	}
+}
+
+func TestForkChoiceIsOrderIndependent(t *testing.T) {
+	// Branches of equal length and difficulty from two providers, plus a
+	// heavier one, inserted in every order.
+	_, anchor := testHeaderStore(t, rsktrie.NewMemKeyValueStore())
+	branch := func(tag string, n int) []*BlockHeader {
+		headers := []*BlockHeader{}
+		parent := anchor
+		for i := 0; i < n; i++ {
+			parent = testChild(parent, func(input *BlockHeaderInput) { input.ExtraData = []byte(tag) })
+			headers = append(headers, parent)
+		}
+		return headers
+	}
+	x, y := branch("provider x", 3), branch("provider y", 3)
+
+	heads := make(map[string]bool)
+	for _, order := range [][][]*BlockHeader{{x, y}, {y, x}} {
+		s, _ := testHeaderStore(t, rsktrie.NewMemKeyValueStore())
+		for _, headers := range order {
+			for _, h := range headers {
+				if err := s.Insert(h, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		head, _ := s.Head()
+		heads[head.Hash().Hex()] = true
+	}
+	if len(heads) != 1 {
+		t.Errorf("Insertion order changed the head: %v", heads)
+	}
+
+	s, _ := testHeaderStore(t, rsktrie.NewMemKeyValueStore())
+	heavy := branch("heavy", 4)
+	for _, h := range append(append(heavy, x...), y...) {
+		if err := s.Insert(h, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if head, _ := s.Head(); head.Hash() != heavy[3].Hash() {
+		t.Error("The heaviest branch should stay the head")
+	}
+}
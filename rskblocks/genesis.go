@@ -0,0 +1,53 @@
+package rskblocks
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Genesis block hashes of the public networks.
+var (
+	MainnetGenesisHash = common.HexToHash("0xf88529d4ab262c0f4d042e9d8d3f2472848eaafe1a9b7213f57617eb40a9f9e0")
+	TestnetGenesisHash = common.HexToHash("0xcabb7fbe88cd6d922042a32ffc08ce8b1fbb37d650b9d4e7dbfe2a7469adfa42")
+)
+
+// RegtestGenesisHash is the genesis hash of a regtest node with rskj's
+// default genesis and every upgrade active from block 0, as
+// RegtestChainConfig assumes; it is the parent hash of such a node's
+// block 1. A node with another genesis file or activation heights has
+// another genesis, so RegtestChainConfig does not require it.
+var RegtestGenesisHash = common.HexToHash("0x8ea789fabef0dd4946ed53f001e7b6f8a8d0c22a612a6099fc7f93c990af68fe")
+
+// ErrUnknownGenesis is returned by VerifyGenesis for a block 0 that is not
+// a known genesis, such as that of a regtest node with its own genesis
+// configuration.
+var ErrUnknownGenesis = errors.New("not a known genesis block")
+
+// VerifyGenesis returns the configuration of the network whose genesis
+// block header is, so a client can check which network an endpoint serves.
+// The header must hash to the network's genesis hash and pass its
+// CheckGenesis. A default regtest genesis (see RegtestGenesisHash) gets
+// RegtestChainConfig with its GenesisHash set.
+func VerifyGenesis(header *BlockHeader) (*ChainConfig, error) {
+	if bigOrZero(header.Number).Sign() != 0 {
+		return nil, fmt.Errorf("block %v is not a genesis block", header.Number)
+	}
+	hash := header.Hash()
+	for _, config := range []*ChainConfig{MainnetChainConfig(), TestnetChainConfig()} {
+		if hash != config.GenesisHash {
+			continue
+		}
+		if err := config.CheckGenesis(header); err != nil {
+			return nil, err
+		}
+		return config, nil
+	}
+	if hash == RegtestGenesisHash {
+		config := RegtestChainConfig()
+		config.GenesisHash = RegtestGenesisHash
+		return config, nil
+	}
+	return nil, fmt.Errorf("%w: block 0 hash %s", ErrUnknownGenesis, hash)
+}
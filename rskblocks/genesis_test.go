@@ -0,0 +1,27 @@
+package rskblocks
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestVerifyGenesis(t *testing.T) {
+	if MainnetChainConfig().GenesisHash != MainnetGenesisHash || TestnetChainConfig().GenesisHash != TestnetGenesisHash {
+		t.Fatal("Network configs do not use the genesis constants")
+	}
+	if RegtestChainConfig().GenesisHash != (common.Hash{}) {
+		t.Error("Regtest config should accept any genesis")
+	}
+
+	genesis := InputToBlockHeader(testHeaderInput(), DefaultRegtestConfig())
+	genesis.Number.SetInt64(0)
+	if _, err := VerifyGenesis(genesis); !errors.Is(err, ErrUnknownGenesis) {
+		t.Errorf("Custom regtest genesis: %v", err)
+	}
+	genesis.Number.SetInt64(1)
+	if _, err := VerifyGenesis(genesis); err == nil || errors.Is(err, ErrUnknownGenesis) {
+		t.Errorf("Block 1: %v", err)
+	}
+}
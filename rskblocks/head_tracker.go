@@ -0,0 +1,180 @@
+package rskblocks
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// HeadChange describes a move of the best chain's head. For a plain
+// extension Dropped is empty; otherwise it is a reorganization and the
+// headers in Dropped, once verified against, must be treated as replaced.
+type HeadChange struct {
+	OldHead *BlockHeader
+	NewHead *BlockHeader
+	// CommonAncestor is the newest header both chains share.
+	CommonAncestor *BlockHeader
+	// Dropped are the old chain's headers above CommonAncestor, and Added
+	// the new chain's, both in ascending order.
+	Dropped []*BlockHeader
+	Added   []*BlockHeader
+}
+
+// IsReorg reports whether the change replaced any header.
+func (c *HeadChange) IsReorg() bool {
+	return len(c.Dropped) > 0
+}
+
+// Depth returns how many best chain headers were replaced.
+func (c *HeadChange) Depth() int {
+	return len(c.Dropped)
+}
+
+// HeadTracker inserts headers into a HeaderStore and tells subscribers
+// whenever the best chain's head moves, with the headers dropped and added,
+// so state verified against replaced blocks can be invalidated.
+//
+// Subscribers are called synchronously, in subscription order, before the
+// call that moved the head returns; they must not call back into the
+// tracker.
+type HeadTracker struct {
+	store *HeaderStore
+
+	mu          sync.Mutex
+	head        *BlockHeader
+	subscribers map[int]func(*HeadChange)
+	nextID      int
+}
+
+// NewHeadTracker tracks the head of store, which must be initialized.
+func NewHeadTracker(store *HeaderStore) (*HeadTracker, error) {
+	head, _ := store.Head()
+	if head == nil {
+		return nil, fmt.Errorf("header store is not initialized")
+	}
+	return &HeadTracker{store: store, head: head, subscribers: make(map[int]func(*HeadChange))}, nil
+}
+
+// Subscribe registers fn for head changes and returns a function that
+// unregisters it.
+func (t *HeadTracker) Subscribe(fn func(*HeadChange)) (unsubscribe func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	id := t.nextID
+	t.nextID++
+	t.subscribers[id] = fn
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.subscribers, id)
+	}
+}
+
+// Head returns the head subscribers were last told about.
+func (t *HeadTracker) Head() *BlockHeader {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.head
+}
+
+// Insert inserts header and its uncles into the store (see
+// HeaderStore.Insert) and returns the resulting head change, or nil if the
+// head did not move.
+func (t *HeadTracker) Insert(header *BlockHeader, uncles []*BlockHeader) (*HeadChange, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.store.Insert(header, uncles); err != nil {
+		return nil, err
+	}
+	newHead, _ := t.store.Head()
+	if newHead.Hash() == t.head.Hash() {
+		return nil, nil
+	}
+	change, err := t.diff(newHead)
+	if err != nil {
+		return nil, err
+	}
+	t.publish(change)
+	return change, nil
+}
+
+// Rollback rolls the store back to number (see HeaderStore.Rollback) and
+// reports the dropped headers as a reorganization.
+func (t *HeadTracker) Rollback(number uint64) (*HeadChange, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	headNumber := t.head.Number.Uint64()
+	if number >= headNumber {
+		if number == headNumber {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cannot roll back to block %d above head %d", number, headNumber)
+	}
+	// The store discards the rolled back headers, so read them first.
+	change := &HeadChange{OldHead: t.head}
+	for n := number + 1; n <= headNumber; n++ {
+		header, err := t.store.HeaderByNumber(n)
+		if err != nil {
+			return nil, err
+		}
+		change.Dropped = append(change.Dropped, header)
+	}
+	if err := t.store.Rollback(number); err != nil {
+		return nil, err
+	}
+	change.NewHead, _ = t.store.Head()
+	change.CommonAncestor = change.NewHead
+	t.publish(change)
+	return change, nil
+}
+
+// diff walks back from the tracked head and newHead to their common
+// ancestor.
+func (t *HeadTracker) diff(newHead *BlockHeader) (*HeadChange, error) {
+	change := &HeadChange{OldHead: t.head, NewHead: newHead}
+	oldSide, newSide := t.head, newHead
+	for oldSide.Hash() != newSide.Hash() {
+		var err error
+		switch oldNum, newNum := oldSide.Number.Uint64(), newSide.Number.Uint64(); {
+		case oldNum > newNum:
+			change.Dropped = append(change.Dropped, oldSide)
+			oldSide, err = t.parent(oldSide)
+		case newNum > oldNum:
+			change.Added = append(change.Added, newSide)
+			newSide, err = t.parent(newSide)
+		default:
+			change.Dropped = append(change.Dropped, oldSide)
+			change.Added = append(change.Added, newSide)
+			if oldSide, err = t.parent(oldSide); err == nil {
+				newSide, err = t.parent(newSide)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	change.CommonAncestor = oldSide
+	slices.Reverse(change.Dropped)
+	slices.Reverse(change.Added)
+	return change, nil
+}
+
+func (t *HeadTracker) parent(header *BlockHeader) (*BlockHeader, error) {
+	parent, err := t.store.Header(header.ParentHash)
+	if err != nil {
+		return nil, fmt.Errorf("no common ancestor with block %v: %w", header.Number, err)
+	}
+	return parent, nil
+}
+
+func (t *HeadTracker) publish(change *HeadChange) {
+	t.head = change.NewHead
+	ids := make([]int, 0, len(t.subscribers))
+	for id := range t.subscribers {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		t.subscribers[id](change)
+	}
+}
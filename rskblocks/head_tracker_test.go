@@ -0,0 +1,127 @@
+package rskblocks
+
+import (
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+)
+
+func hashesOf(headers []*BlockHeader) []string {
+	out := make([]string, len(headers))
+	for i, h := range headers {
+		out[i] = h.Hash().Hex()
+	}
+	return out
+}
+
+func sameHeaders(got, want []*BlockHeader) bool {
+	g, w := hashesOf(got), hashesOf(want)
+	if len(g) != len(w) {
+		return false
+	}
+	for i := range g {
+		if g[i] != w[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestHeadTrackerReorg(t *testing.T) {
+	s, anchor := testHeaderStore(t, rsktrie.NewMemKeyValueStore())
+	tracker, err := NewHeadTracker(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changes []*HeadChange
+	tracker.Subscribe(func(c *HeadChange) { changes = append(changes, c) })
+
+	a1 := testChild(anchor, nil)
+	a2 := testChild(a1, nil)
+	for _, h := range []*BlockHeader{a1, a2} {
+		if _, err := tracker.Insert(h, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(changes) != 2 || changes[1].IsReorg() || !sameHeaders(changes[1].Added, []*BlockHeader{a2}) {
+		t.Fatalf("Extensions reported as %+v", changes)
+	}
+
+	// A longer fork from the anchor replaces a1 and a2.
+	fork := func(input *BlockHeaderInput) { input.ExtraData = []byte("fork") }
+	b1 := testChild(anchor, fork)
+	b2 := testChild(b1, fork)
+	b3 := testChild(b2, fork)
+	changes = nil
+	for _, h := range []*BlockHeader{b1, b2, b3} {
+		if _, err := tracker.Insert(h, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	last := changes[len(changes)-1]
+	if last.NewHead.Hash() != b3.Hash() || tracker.Head().Hash() != b3.Hash() {
+		t.Fatal("Tracker did not follow the heavier fork")
+	}
+	// The reorg may happen at b2 (tie broken by hash) or at b3.
+	var reorg *HeadChange
+	for _, c := range changes {
+		if c.IsReorg() {
+			reorg = c
+		}
+	}
+	if reorg == nil {
+		t.Fatal("No reorganization reported")
+	}
+	if reorg.CommonAncestor.Hash() != anchor.Hash() || reorg.Depth() != 2 ||
+		!sameHeaders(reorg.Dropped, []*BlockHeader{a1, a2}) {
+		t.Errorf("Reorg from %v: ancestor %v, dropped %v", reorg.OldHead.Number, reorg.CommonAncestor.Number, hashesOf(reorg.Dropped))
+	}
+	if want := []*BlockHeader{b1, b2, b3}[:len(reorg.Added)]; !sameHeaders(reorg.Added, want) {
+		t.Errorf("Added %v, want %v", hashesOf(reorg.Added), hashesOf(want))
+	}
+
+	// Inserting a side header that does not move the head is not reported.
+	changes = nil
+	if c, err := tracker.Insert(testChild(a1, fork), nil); err != nil || c != nil || len(changes) != 0 {
+		t.Errorf("Side insert reported %v, %v", c, err)
+	}
+}
+
+func TestHeadTrackerRollbackAndUnsubscribe(t *testing.T) {
+	s, anchor := testHeaderStore(t, rsktrie.NewMemKeyValueStore())
+	tracker, err := NewHeadTracker(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a1 := testChild(anchor, nil)
+	a2 := testChild(a1, nil)
+	for _, h := range []*BlockHeader{a1, a2} {
+		if _, err := tracker.Insert(h, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var first, second int
+	unsubscribe := tracker.Subscribe(func(*HeadChange) { first++ })
+	tracker.Subscribe(func(*HeadChange) { second++ })
+
+	c, err := tracker.Rollback(anchor.Number.Uint64())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.NewHead.Hash() != anchor.Hash() || c.CommonAncestor.Hash() != anchor.Hash() ||
+		!sameHeaders(c.Dropped, []*BlockHeader{a1, a2}) || len(c.Added) != 0 {
+		t.Errorf("Rollback reported %+v", c)
+	}
+	if _, err := tracker.Rollback(anchor.Number.Uint64() + 1); err == nil {
+		t.Error("Rolling back above the head succeeded")
+	}
+
+	unsubscribe()
+	if _, err := tracker.Insert(a1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if first != 1 || second != 2 {
+		t.Errorf("Notifications: first %d, second %d", first, second)
+	}
+}
@@ -0,0 +1,188 @@
+package rskblocks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// BlockHeaderExtension holds the fields a V1 or V2 header (RSKIP-351,
+// RSKIP-535) moves out of its compressed encoding. The compressed header
+// carries extensionData, RLP([version, extensionHash]), in place of
+// logsBloom; the extension travels alongside it and must hash to
+// extensionHash.
+type BlockHeaderExtension struct {
+	Version                  byte
+	LogsBloom                Bloom
+	TxExecutionSublistsEdges []int16
+	// BaseEvent is only part of V2 extensions.
+	BaseEvent []byte
+}
+
+// Extension returns the header's extension, or nil for a V0 header.
+func (h *BlockHeader) Extension() *BlockHeaderExtension {
+	if h.Version == 0 {
+		return nil
+	}
+	return &BlockHeaderExtension{
+		Version:                  h.Version,
+		LogsBloom:                h.LogsBloom,
+		TxExecutionSublistsEdges: h.TxExecutionSublistsEdges,
+		BaseEvent:                h.BaseEvent,
+	}
+}
+
+// GetCompressedEncoded returns the compressed encoding rskj relays V1/V2
+// headers in: extensionData instead of logsBloom and no version, baseEvent
+// or edges fields. For V0 headers it is the full encoding.
+func (h *BlockHeader) GetCompressedEncoded() []byte {
+	return h.getEncoded(true, true, true)
+}
+
+// Hash returns the extensionHash:
+//   - V1: Keccak256(RLP([Keccak256(logsBloom), edgesBytes]))
+//   - V2: Keccak256(RLP([Keccak256(logsBloom), baseEvent, edgesBytes]))
+//
+// The bloom is hashed before it is included, and edgesBytes, two
+// little-endian bytes per edge, is left out when the edges are absent.
+func (e *BlockHeaderExtension) Hash() common.Hash {
+	logsBloomHash := keccak256Hash(e.LogsBloom[:])
+
+	// Empty edges [] is different from null - empty means include 0x80
+	var edgesBytes []byte
+	if e.TxExecutionSublistsEdges != nil {
+		edgesBytes = make([]byte, len(e.TxExecutionSublistsEdges)*2)
+		for i, edge := range e.TxExecutionSublistsEdges {
+			edgesBytes[i*2] = byte(edge)
+			edgesBytes[i*2+1] = byte(edge >> 8)
+		}
+	}
+
+	content := []interface{}{logsBloomHash.Bytes()}
+	if e.Version == 2 {
+		// baseEvent is included even if empty (encodes as 0x80)
+		content = append(content, nonNilBytes(e.BaseEvent))
+	}
+	if edgesBytes != nil {
+		content = append(content, edgesBytes)
+	}
+	encoded, _ := rlp.EncodeToBytes(content)
+	return keccak256Hash(encoded)
+}
+
+// extensionData returns RLP([version, extensionHash]), the field that
+// replaces logsBloom in the compressed encoding.
+func (e *BlockHeaderExtension) extensionData() []byte {
+	encoded, _ := rlp.EncodeToBytes([]interface{}{[]byte{e.Version}, e.Hash().Bytes()})
+	return encoded
+}
+
+// EncodeRLP implements rlp.Encoder, writing the extension as rskj relays it:
+// RLP([version, logsBloom, baseEvent (V2 only), edges]), edges omitted when
+// absent.
+func (e *BlockHeaderExtension) EncodeRLP(w io.Writer) error {
+	fields := []interface{}{[]byte{e.Version}, e.LogsBloom[:]}
+	if e.Version == 2 {
+		fields = append(fields, nonNilBytes(e.BaseEvent))
+	}
+	if e.TxExecutionSublistsEdges != nil {
+		fields = append(fields, encodeShortsToRLP(e.TxExecutionSublistsEdges))
+	}
+	return rlp.Encode(w, fields)
+}
+
+// DecodeBlockHeaderExtension decodes an extension written by EncodeRLP.
+func DecodeBlockHeaderExtension(data []byte) (*BlockHeaderExtension, error) {
+	var fields [][]byte
+	if err := rlp.DecodeBytes(data, &fields); err != nil {
+		return nil, fmt.Errorf("decode header extension: %w", err)
+	}
+	if len(fields) < 2 || len(fields[0]) != 1 {
+		return nil, fmt.Errorf("malformed header extension with %d fields", len(fields))
+	}
+	e := &BlockHeaderExtension{Version: fields[0][0]}
+	if e.Version != 1 && e.Version != 2 {
+		return nil, fmt.Errorf("unsupported header extension version %d", e.Version)
+	}
+	if len(fields[1]) != BloomByteLength {
+		return nil, fmt.Errorf("extension logsBloom has %d bytes, want %d", len(fields[1]), BloomByteLength)
+	}
+	copy(e.LogsBloom[:], fields[1])
+	rest := fields[2:]
+	if e.Version == 2 {
+		if len(rest) == 0 {
+			return nil, fmt.Errorf("V2 header extension is missing baseEvent")
+		}
+		e.BaseEvent, rest = rest[0], rest[1:]
+	}
+	switch len(rest) {
+	case 0:
+	case 1:
+		edges, err := decodeShortsFromRLP(rest[0])
+		if err != nil {
+			return nil, fmt.Errorf("extension txExecutionSublistsEdges: %w", err)
+		}
+		e.TxExecutionSublistsEdges = edges
+	default:
+		return nil, fmt.Errorf("header extension has %d unexpected trailing fields", len(rest))
+	}
+	return e, nil
+}
+
+// DecodeCompressedBlockHeader decodes a header in the compressed encoding
+// (GetCompressedEncoded) together with its extension, checking that the
+// header's extensionData commits to ext. config is as for
+// DecodeBlockHeader; a V0 config decodes data as a full encoding and
+// ignores ext.
+func DecodeCompressedBlockHeader(data []byte, ext *BlockHeaderExtension, config BlockHashConfig) (*BlockHeader, error) {
+	if config.Version == 0 {
+		return DecodeBlockHeader(data, config)
+	}
+	if ext == nil {
+		return nil, fmt.Errorf("V%d header needs its extension", config.Version)
+	}
+	if ext.Version != config.Version {
+		return nil, fmt.Errorf("extension version %d, want %d", ext.Version, config.Version)
+	}
+	var fields [][]byte
+	if err := rlp.DecodeBytes(data, &fields); err != nil {
+		return nil, fmt.Errorf("decode header: %w", err)
+	}
+	if len(fields) < coreHeaderFields {
+		return nil, fmt.Errorf("header has %d fields, need at least %d", len(fields), coreHeaderFields)
+	}
+	if !bytes.Equal(fields[6], ext.extensionData()) {
+		return nil, fmt.Errorf("header extensionData %x does not commit to the extension", fields[6])
+	}
+
+	// Rebuild the full encoding: the bloom back in place, and version,
+	// baseEvent and edges after the ummRoot, ahead of any merged mining
+	// fields.
+	split := coreHeaderFields
+	if config.IncludeUmmRoot {
+		split++
+	}
+	if len(fields) < split || (len(fields)-split != 0 && len(fields)-split != 3) {
+		return nil, fmt.Errorf("compressed header has %d fields", len(fields))
+	}
+	full := make([][]byte, 0, len(fields)+3)
+	full = append(full, fields[:6]...)
+	full = append(full, ext.LogsBloom[:])
+	full = append(full, fields[7:split]...)
+	full = append(full, []byte{ext.Version})
+	if ext.Version == 2 {
+		full = append(full, nonNilBytes(ext.BaseEvent))
+	}
+	if ext.TxExecutionSublistsEdges != nil {
+		full = append(full, encodeShortsToRLP(ext.TxExecutionSublistsEdges))
+	}
+	full = append(full, fields[split:]...)
+	encoded, err := rlp.EncodeToBytes(full)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBlockHeader(encoded, config)
+}
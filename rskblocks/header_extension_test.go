@@ -0,0 +1,111 @@
+package rskblocks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestCompressedHeaderRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  BlockHashConfig
+		mutate  func(input *BlockHeaderInput)
+		version byte
+	}{
+		{"V1", ConfigForBlockNumber(7139700, "testnet"), nil, 1},
+		{"V1 with edges and merged mining", ConfigForBlockNumber(7139700, "testnet"), func(input *BlockHeaderInput) {
+			input.TxExecutionSublistsEdges = []int16{3, 7}
+			input.BitcoinMergedMiningHeader = bytes.Repeat([]byte{0xaa}, BitcoinHeaderLength)
+			input.BitcoinMergedMiningMerkleProof = bytes.Repeat([]byte{0xbb}, 64)
+			input.BitcoinMergedMiningCoinbaseTransaction = bytes.Repeat([]byte{0xcc}, 100)
+		}, 1},
+		{"V2", DefaultRegtestConfig(), func(input *BlockHeaderInput) {
+			input.BaseEvent = []byte{0x0e}
+			input.TxExecutionSublistsEdges = []int16{}
+		}, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			input := testHeaderInput()
+			if test.mutate != nil {
+				test.mutate(input)
+			}
+			header := InputToBlockHeader(input, test.config)
+			ext := header.Extension()
+			if ext == nil || ext.Version != test.version {
+				t.Fatalf("Unexpected extension %+v", ext)
+			}
+
+			encodedExt, err := rlp.EncodeToBytes(ext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ext, err = DecodeBlockHeaderExtension(encodedExt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ext.Hash() != header.Extension().Hash() {
+				t.Error("Extension did not round trip")
+			}
+
+			compressed := header.GetCompressedEncoded()
+			if bytes.Contains(compressed, header.LogsBloom[:]) {
+				t.Error("Compressed encoding still carries the bloom")
+			}
+			decoded, err := DecodeCompressedBlockHeader(compressed, ext, test.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decoded.GetFullEncoded(), header.GetFullEncoded()) {
+				t.Error("Decoded header differs from the original")
+			}
+			if decoded.Hash() != header.Hash() {
+				t.Error("Decoded header hashes differently")
+			}
+
+			forged := *ext
+			forged.LogsBloom[0] ^= 1
+			if _, err := DecodeCompressedBlockHeader(compressed, &forged, test.config); err == nil {
+				t.Error("Expected an extension the header does not commit to to fail")
+			}
+			if _, err := DecodeCompressedBlockHeader(compressed, nil, test.config); err == nil {
+				t.Error("Expected a missing extension to fail")
+			}
+		})
+	}
+}
+
+func TestHeaderExtensionV0(t *testing.T) {
+	config := ConfigForBlockNumber(5_000_000, "mainnet")
+	header := InputToBlockHeader(testHeaderInput(), config)
+	if header.Extension() != nil {
+		t.Error("V0 headers have no extension")
+	}
+	if !bytes.Equal(header.GetCompressedEncoded(), header.GetFullEncoded()) {
+		t.Error("V0 compressed encoding should be the full encoding")
+	}
+	decoded, err := DecodeCompressedBlockHeader(header.GetCompressedEncoded(), nil, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Hash() != header.Hash() {
+		t.Error("Decoded V0 header hashes differently")
+	}
+}
+
+func TestDecodeBlockHeaderExtensionErrors(t *testing.T) {
+	bloom := make([]byte, BloomByteLength)
+	for name, fields := range map[string][]interface{}{
+		"version 3":      {[]byte{3}, bloom},
+		"short bloom":    {[]byte{1}, bloom[:10]},
+		"no baseEvent":   {[]byte{2}, bloom},
+		"trailing field": {[]byte{1}, bloom, []byte{}, []byte{}},
+	} {
+		encoded, _ := rlp.EncodeToBytes(fields)
+		if _, err := DecodeBlockHeaderExtension(encoded); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package rskblocks
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ErrHeaderNotFound is returned for a header the store does not hold.
+var ErrHeaderNotFound = errors.New("header not found")
+
+// Key layout of a HeaderStore in its KeyValueStore.
+var (
+	headerKeyPrefix = []byte("h") // h + hash -> headerRecord
+	numberKeyPrefix = []byte("n") // n + number (8 bytes, big-endian) -> best chain hash
+	headKey         = []byte("head")
+)
+
+// headerRecord is a stored header with the total difficulty of the chain it
+// ends.
+type headerRecord struct {
+	Header          rlp.RawValue
+	TotalDifficulty *big.Int
+}
+
+// HeaderStore keeps validated headers in a KeyValueStore, for example an
+// rsktrie.FileKeyValueStore to survive restarts. It indexes the best chain,
+// as chosen by IsBetterChain, by number. The chain is
+// anchored at a trusted header, genesis or a checkpoint, given to Init;
+// every later header must extend a stored one and pass Validator.
+//
+// A HeaderStore is safe for concurrent use.
+type HeaderStore struct {
+	// Validator checks each inserted header against its parent; nil skips
+	// validation, for headers validated elsewhere.
+	Validator *ChainValidator
+
+	mu     sync.RWMutex
+	kv     rsktrie.KeyValueStore
+	config *ChainConfig
+	head   *BlockHeader
+	headTD *big.Int
+}
+
+// NewHeaderStore opens a header store over kv for the network of config,
+// resuming from the head kv holds, if any.
+func NewHeaderStore(kv rsktrie.KeyValueStore, config *ChainConfig) (*HeaderStore, error) {
+	s := &HeaderStore{Validator: NewChainValidatorForConfig(config), kv: kv, config: config}
+	headHash, err := kv.Get(headKey)
+	if err != nil {
+		return nil, err
+	}
+	if headHash != nil {
+		if s.head, s.headTD, err = s.get(common.BytesToHash(headHash)); err != nil {
+			return nil, fmt.Errorf("load head: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// Init anchors an empty store at header, trusted to have totalDifficulty.
+func (s *HeaderStore) Init(header *BlockHeader, totalDifficulty *big.Int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.head != nil {
+		return errors.New("header store is already initialized")
+	}
+	batch := s.kv.NewBatch()
+	if err := s.putRecord(batch, header, totalDifficulty); err != nil {
+		return err
+	}
+	s.setHead(batch, header)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	s.head, s.headTD = header, new(big.Int).Set(totalDifficulty)
+	return nil
+}
+
+// InitFromCheckpoint anchors an empty store at cp, whose header is
+// authenticated against the checkpoint hash.
+func (s *HeaderStore) InitFromCheckpoint(cp *Checkpoint, header *BlockHeader) error {
+	if err := cp.CheckCheckpoint(header); err != nil {
+		return err
+	}
+	return s.Init(header, cp.TotalDifficulty.ToInt())
+}
+
+// Insert validates header against its stored parent and stores it. uncles
+// are the block's uncle headers, checked against the header's unclesHash:
+// RSK counts their difficulty in the chain's total difficulty, as rskj's
+// Block.getCumulativeDifficulty does. If the new chain is better than the
+// best chain (see IsBetterChain) it becomes the best chain. Inserting a
+// stored header is a no-op.
+func (s *HeaderStore) Insert(header *BlockHeader, uncles []*BlockHeader) error {
+	difficulty, err := CumulativeDifficulty(header, uncles)
+	if err != nil {
+		return err
+	}
+	hash := header.Hash()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.head == nil {
+		return errors.New("header store is not initialized")
+	}
+	if has, err := s.has(hash); err != nil || has {
+		return err
+	}
+	parent, parentTD, err := s.get(header.ParentHash)
+	if err != nil {
+		return fmt.Errorf("parent of block %v: %w", header.Number, err)
+	}
+	if s.Validator != nil {
+		if err := s.Validator.ValidateChild(header, parent); err != nil {
+			return err
+		}
+	}
+
+	td := new(big.Int).Add(parentTD, difficulty)
+	batch := s.kv.NewBatch()
+	if err := s.putRecord(batch, header, td); err != nil {
+		return err
+	}
+	better := IsBetterChain(header, td, s.head, s.headTD)
+	if better {
+		if err := s.reorg(batch, header); err != nil {
+			return err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	if better {
+		s.head, s.headTD = header, td
+	}
+	return nil
+}
+
+// InsertBlock inserts a block's header with its uncles.
+func (s *HeaderStore) InsertBlock(block *Block) error {
+	return s.Insert(block.Header, block.Uncles)
+}
+
+// CumulativeDifficulty returns the difficulty a block adds to its chain: its
+// own plus its uncles', after checking uncles against the header.
+func CumulativeDifficulty(header *BlockHeader, uncles []*BlockHeader) (*big.Int, error) {
+	if len(uncles) != header.UncleCount {
+		return nil, fmt.Errorf("block %v declares %d uncles, got %d", header.Number, header.UncleCount, len(uncles))
+	}
+	if hash := (&Block{Uncles: uncles}).UnclesHash(); hash != header.UnclesHash {
+		return nil, fmt.Errorf("uncles hash to %s, header has %s", hash.Hex(), header.UnclesHash.Hex())
+	}
+	difficulty := new(big.Int).Set(bigOrZero(header.Difficulty))
+	for _, uncle := range uncles {
+		difficulty.Add(difficulty, bigOrZero(uncle.Difficulty))
+	}
+	return difficulty, nil
+}
+
+// Head returns the best chain's head and total difficulty, or nil before
+// Init.
+func (s *HeaderStore) Head() (*BlockHeader, *big.Int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.head == nil {
+		return nil, nil
+	}
+	return s.head, new(big.Int).Set(s.headTD)
+}
+
+// Header returns the stored header with hash, on any branch.
+func (s *HeaderStore) Header(hash common.Hash) (*BlockHeader, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	header, _, err := s.get(hash)
+	return header, err
+}
+
+// TotalDifficulty returns the total difficulty of the chain ending at hash.
+func (s *HeaderStore) TotalDifficulty(hash common.Hash) (*big.Int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, td, err := s.get(hash)
+	return td, err
+}
+
+// HeaderByNumber returns the best chain's header at number.
+func (s *HeaderStore) HeaderByNumber(number uint64) (*BlockHeader, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	hash, err := s.canonicalHash(number)
+	if err != nil {
+		return nil, err
+	}
+	header, _, err := s.get(hash)
+	return header, err
+}
+
+// Rollback makes the best chain's block at number the head, discarding the
+// best chain's headers above it, for example after they were found to
+// commit to invalid state. Headers on other branches are kept.
+func (s *HeaderStore) Rollback(number uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.head == nil {
+		return errors.New("header store is not initialized")
+	}
+	headNumber := s.head.Number.Uint64()
+	if number > headNumber {
+		return fmt.Errorf("cannot roll back to block %d above head %d", number, headNumber)
+	}
+	hash, err := s.canonicalHash(number)
+	if err != nil {
+		return err
+	}
+	header, td, err := s.get(hash)
+	if err != nil {
+		return err
+	}
+	batch := s.kv.NewBatch()
+	for n := number + 1; n <= headNumber; n++ {
+		dropped, err := s.canonicalHash(n)
+		if err != nil {
+			return err
+		}
+		batch.Delete(headerKey(dropped))
+		batch.Delete(numberKey(n))
+	}
+	batch.Put(headKey, hash.Bytes())
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	s.head, s.headTD = header, td
+	return nil
+}
+
+// reorg stages making header the head in batch: the number index is
+// rewritten from header back to where it meets the old best chain, and
+// entries above header's number are removed. The caller updates the
+// in-memory head once batch is written.
+func (s *HeaderStore) reorg(batch rsktrie.KeyValueBatch, header *BlockHeader) error {
+	number := header.Number.Uint64()
+	for n := s.head.Number.Uint64(); n > number; n-- {
+		batch.Delete(numberKey(n))
+	}
+	for current := header; ; {
+		n := current.Number.Uint64()
+		hash := current.Hash()
+		if canonical, err := s.canonicalHash(n); err == nil && canonical == hash {
+			break
+		}
+		batch.Put(numberKey(n), hash.Bytes())
+		if n == 0 {
+			break
+		}
+		parent, _, err := s.get(current.ParentHash)
+		if errors.Is(err, ErrHeaderNotFound) {
+			// Below the anchor.
+			break
+		}
+		if err != nil {
+			return err
+		}
+		current = parent
+	}
+	s.setHead(batch, header)
+	return nil
+}
+
+// setHead stages header as the head in batch.
+func (s *HeaderStore) setHead(batch rsktrie.KeyValueBatch, header *BlockHeader) {
+	hash := header.Hash()
+	batch.Put(numberKey(header.Number.Uint64()), hash.Bytes())
+	batch.Put(headKey, hash.Bytes())
+}
+
+func (s *HeaderStore) putRecord(batch rsktrie.KeyValueBatch, header *BlockHeader, td *big.Int) error {
+	if !bigOrZero(header.Number).IsUint64() {
+		return fmt.Errorf("block number %v out of range", header.Number)
+	}
+	// Stored headers are decoded with the network's rules for their number,
+	// so a header built with other rules would not read back the same.
+	full := header.GetFullEncoded()
+	if decoded, err := s.config.DecodeBlockHeader(full); err != nil || decoded.Hash() != header.Hash() {
+		return fmt.Errorf("block %v was not built with %s header rules", header.Number, s.config.Network)
+	}
+	encoded, err := rlp.EncodeToBytes(&headerRecord{Header: full, TotalDifficulty: td})
+	if err != nil {
+		return err
+	}
+	batch.Put(headerKey(header.Hash()), encoded)
+	return nil
+}
+
+func (s *HeaderStore) get(hash common.Hash) (*BlockHeader, *big.Int, error) {
+	data, err := s.kv.Get(headerKey(hash))
+	if err != nil {
+		return nil, nil, err
+	}
+	if data == nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrHeaderNotFound, hash.Hex())
+	}
+	var record headerRecord
+	if err := rlp.DecodeBytes(data, &record); err != nil {
+		return nil, nil, fmt.Errorf("stored header %s: %w", hash.Hex(), err)
+	}
+	header, err := s.config.DecodeBlockHeader(record.Header)
+	if err != nil {
+		return nil, nil, fmt.Errorf("stored header %s: %w", hash.Hex(), err)
+	}
+	return header, record.TotalDifficulty, nil
+}
+
+func (s *HeaderStore) has(hash common.Hash) (bool, error) {
+	data, err := s.kv.Get(headerKey(hash))
+	return data != nil, err
+}
+
+func (s *HeaderStore) canonicalHash(number uint64) (common.Hash, error) {
+	data, err := s.kv.Get(numberKey(number))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if data == nil {
+		return common.Hash{}, fmt.Errorf("%w: no best chain block %d", ErrHeaderNotFound, number)
+	}
+	return common.BytesToHash(data), nil
+}
+
+func headerKey(hash common.Hash) []byte {
+	return append(append([]byte{}, headerKeyPrefix...), hash[:]...)
+}
+
+func numberKey(number uint64) []byte {
+	return binary.BigEndian.AppendUint64(append([]byte{}, numberKeyPrefix...), number)
+}
@@ -0,0 +1,261 @@
+package rskblocks
+
+import (
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// testChild returns a regtest header extending parent, 10 seconds later.
+// mutate, if not nil, edits the input before the header is built.
+func testChild(parent *BlockHeader, mutate func(input *BlockHeaderInput)) *BlockHeader {
+	input := testHeaderInput()
+	input.ParentHash = parent.Hash()
+	input.Number = new(big.Int).Add(parent.Number, big.NewInt(1))
+	input.Timestamp = new(big.Int).Add(parent.Timestamp, big.NewInt(10))
+	input.Difficulty = big.NewInt(1)
+	input.UncleCount = 0
+	if mutate != nil {
+		mutate(input)
+	}
+	return InputToBlockHeader(input, DefaultRegtestConfig())
+}
+
+// testHeaderStore returns a store over kv anchored at a regtest block 100
+// with total difficulty 100.
+func testHeaderStore(t *testing.T, kv rsktrie.KeyValueStore) (*HeaderStore, *BlockHeader) {
+	t.Helper()
+	s, err := NewHeaderStore(kv, RegtestChainConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Validator = testChainValidator()
+	anchor := testChain(1, func(i int, input *BlockHeaderInput) { input.UncleCount = 0 })[0]
+	if err := s.Init(anchor, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+	return s, anchor
+}
+
+// failingWriteKV is a key-value store whose batches fail to write while
+// failWrites is set.
+type failingWriteKV struct {
+	*rsktrie.MemKeyValueStore
+	failWrites bool
+}
+
+func (kv *failingWriteKV) NewBatch() rsktrie.KeyValueBatch {
+	return &failingWriteBatch{KeyValueBatch: kv.MemKeyValueStore.NewBatch(), kv: kv}
+}
+
+type failingWriteBatch struct {
+	rsktrie.KeyValueBatch
+	kv *failingWriteKV
+}
+
+func (b *failingWriteBatch) Write() error {
+	if b.kv.failWrites {
+		return errors.New("disk full")
+	}
+	return b.KeyValueBatch.Write()
+}
+
+func TestHeaderStoreBestChain(t *testing.T) {
+	s, anchor := testHeaderStore(t, rsktrie.NewMemKeyValueStore())
+
+	// Main chain: anchor, a1, a2. Fork: anchor, b1, b2, b3.
+	a1 := testChild(anchor, nil)
+	a2 := testChild(a1, nil)
+	for _, h := range []*BlockHeader{a1, a2} {
+		if err := s.Insert(h, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if head, td := s.Head(); head.Hash() != a2.Hash() || td.Int64() != 102 {
+		t.Fatalf("Head %v with difficulty %v, want block 102 with 102", head.Number, td)
+	}
+
+	fork := func(input *BlockHeaderInput) { input.ExtraData = []byte("fork") }
+	b1 := testChild(anchor, fork)
+	b2 := testChild(b1, fork)
+	for _, h := range []*BlockHeader{b1, b2} {
+		if err := s.Insert(h, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Equal total difficulty: the smaller hash wins.
+	want := a2
+	if IsBetterChain(b2, big.NewInt(102), a2, big.NewInt(102)) {
+		want = b2
+	}
+	if head, _ := s.Head(); head.Hash() != want.Hash() {
+		t.Error("A tie was not resolved by hash")
+	}
+	b3 := testChild(b2, fork)
+	if err := s.Insert(b3, nil); err != nil {
+		t.Fatal(err)
+	}
+	if head, _ := s.Head(); head.Hash() != b3.Hash() {
+		t.Fatal("The heavier branch did not become the best chain")
+	}
+	for _, h := range []*BlockHeader{anchor, b1, b2, b3} {
+		got, err := s.HeaderByNumber(h.Number.Uint64())
+		if err != nil || got.Hash() != h.Hash() {
+			t.Errorf("Block %v: best chain has %v, %v", h.Number, got, err)
+		}
+	}
+	// The old branch stays reachable by hash.
+	if got, err := s.Header(a2.Hash()); err != nil || got.Hash() != a2.Hash() {
+		t.Errorf("Side branch header lost: %v", err)
+	}
+	if td, err := s.TotalDifficulty(a1.Hash()); err != nil || td.Int64() != 101 {
+		t.Errorf("Total difficulty of a1 %v, %v", td, err)
+	}
+
+	// Switching back to a now-longer main chain shortens the index again.
+	a3 := testChild(a2, nil)
+	a4 := testChild(a3, nil)
+	for _, h := range []*BlockHeader{a3, a4} {
+		if err := s.Insert(h, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, _ := s.HeaderByNumber(101); got.Hash() != a1.Hash() {
+		t.Error("Number index still points at the fork")
+	}
+}
+
+func TestHeaderStoreInsertErrors(t *testing.T) {
+	s, anchor := testHeaderStore(t, rsktrie.NewMemKeyValueStore())
+	if err := s.Insert(testChild(testChild(anchor, nil), nil), nil); !errors.Is(err, ErrHeaderNotFound) {
+		t.Errorf("Expected an orphan to fail with ErrHeaderNotFound, got %v", err)
+	}
+	bad := testChild(anchor, func(input *BlockHeaderInput) { input.Timestamp = anchor.Timestamp })
+	var violation *ChainViolation
+	if err := s.Insert(bad, nil); !errors.As(err, &violation) || violation.Rule != RuleTimestamp {
+		t.Errorf("Expected a timestamp violation, got %v", err)
+	}
+	wrongRules := testChild(anchor, nil)
+	wrongRules.Version = 0
+	if err := s.Insert(wrongRules, nil); err == nil {
+		t.Error("Expected a header built with other rules to fail")
+	}
+	if err := s.Init(anchor, big.NewInt(1)); err == nil {
+		t.Error("Expected a second Init to fail")
+	}
+	child := testChild(anchor, nil)
+	if err := s.Insert(child, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Insert(child, nil); err != nil {
+		t.Errorf("Reinserting a header should be a no-op: %v", err)
+	}
+}
+
+func TestHeaderStoreUncles(t *testing.T) {
+	s, anchor := testHeaderStore(t, rsktrie.NewMemKeyValueStore())
+	uncle := testChild(anchor, func(input *BlockHeaderInput) {
+		input.ExtraData = []byte("uncle")
+		input.Difficulty = big.NewInt(5)
+	})
+	encoded, _ := rlp.EncodeToBytes([]rlp.RawValue{uncle.GetFullEncoded()})
+	a1 := testChild(anchor, nil)
+	a2 := testChild(a1, func(input *BlockHeaderInput) {
+		input.UncleCount = 1
+		input.UnclesHash = keccak256Hash(encoded)
+	})
+	if err := s.Insert(a1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Insert(a2, nil); err == nil {
+		t.Error("Expected missing uncles to fail")
+	}
+	if err := s.Insert(a2, []*BlockHeader{anchor}); err == nil {
+		t.Error("Expected the wrong uncle to fail")
+	}
+	if err := s.Insert(a2, []*BlockHeader{uncle}); err != nil {
+		t.Fatal(err)
+	}
+	if _, td := s.Head(); td.Int64() != 100+1+1+5 {
+		t.Errorf("Total difficulty %v, want uncle difficulty included", td)
+	}
+}
+
+func TestHeaderStoreRollbackAndReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "headers")
+	kv, err := rsktrie.OpenFileKeyValueStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, anchor := testHeaderStore(t, kv)
+	headers := []*BlockHeader{anchor}
+	for i := 0; i < 4; i++ {
+		headers = append(headers, testChild(headers[len(headers)-1], nil))
+		if err := s.Insert(headers[len(headers)-1], nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Rollback(102); err != nil {
+		t.Fatal(err)
+	}
+	if head, td := s.Head(); head.Hash() != headers[2].Hash() || td.Int64() != 102 {
+		t.Errorf("Head after rollback is block %v", head.Number)
+	}
+	if _, err := s.HeaderByNumber(103); !errors.Is(err, ErrHeaderNotFound) {
+		t.Error("Rolled back block is still indexed")
+	}
+	if err := s.Rollback(110); err == nil {
+		t.Error("Expected a rollback above the head to fail")
+	}
+	if err := kv.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	kv, err = rsktrie.OpenFileKeyValueStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kv.Close()
+	reopened, err := NewHeaderStore(kv, RegtestChainConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head, td := reopened.Head(); head == nil || head.Hash() != headers[2].Hash() || td.Int64() != 102 {
+		t.Fatalf("Reopened store has head %v", head)
+	}
+	reopened.Validator = testChainValidator()
+	if err := reopened.Insert(headers[3], nil); err != nil {
+		t.Errorf("Re-extending after rollback failed: %v", err)
+	}
+	if _, err := reopened.Header(common.Hash{1}); !errors.Is(err, ErrHeaderNotFound) {
+		t.Error("Expected ErrHeaderNotFound for an unknown hash")
+	}
+}
+
+func TestHeaderStoreKeepsHeadWhenWriteFails(t *testing.T) {
+	kv := &failingWriteKV{MemKeyValueStore: rsktrie.NewMemKeyValueStore()}
+	s, anchor := testHeaderStore(t, kv)
+
+	kv.failWrites = true
+	child := testChild(anchor, nil)
+	if err := s.Insert(child, nil); err == nil {
+		t.Fatal("Expected the failed write to be reported")
+	}
+	if head, td := s.Head(); head.Hash() != anchor.Hash() || td.Int64() != 100 {
+		t.Errorf("Head moved to block %v with difficulty %v despite the failed write", head.Number, td)
+	}
+
+	kv.failWrites = false
+	if err := s.Insert(child, nil); err != nil {
+		t.Fatal(err)
+	}
+	if head, _ := s.Head(); head.Hash() != child.Hash() {
+		t.Errorf("Head is block %v, want the retried child", head.Number)
+	}
+}
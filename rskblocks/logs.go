@@ -0,0 +1,60 @@
+package rskblocks
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BlockLog is a log with its position in the block, as eth_getLogs reports
+// it.
+type BlockLog struct {
+	*Log
+	// TxIndex is the position of the transaction that emitted the log.
+	TxIndex int
+	// Index is the log's position among all logs of the block.
+	Index int
+	// TxHash is taken from the receipt; receipts do not commit to it.
+	TxHash common.Hash
+}
+
+// ExtractLogs returns the logs of a block's receipts, in order.
+func ExtractLogs(receipts []*TransactionReceipt) []*BlockLog {
+	var logs []*BlockLog
+	for i, receipt := range receipts {
+		for _, log := range receipt.Logs {
+			logs = append(logs, &BlockLog{Log: log, TxIndex: i, Index: len(logs), TxHash: receipt.TxHash})
+		}
+	}
+	return logs
+}
+
+// BlockBloom returns the block's logsBloom: the union of the receipts'
+// blooms, each rebuilt from the receipt's logs.
+func BlockBloom(receipts []*TransactionReceipt) Bloom {
+	var bloom Bloom
+	for _, receipt := range receipts {
+		bloom.Or(CreateBloom(receipt.Logs))
+	}
+	return bloom
+}
+
+// VerifyLogsBloom reports whether receipts, as an RPC node returned them,
+// carry the logs the header commits to: each receipt's bloom must match its
+// logs and together they must rebuild the header's logsBloom. V1 and V2
+// headers commit to the bloom through the extension hash rather than
+// directly, but it is still authenticated by the block hash, so the same
+// check applies once the header is. Receipts should be proven against the
+// receipts root too; the bloom alone cannot rule out dropped logs whose
+// bits are set by others.
+func VerifyLogsBloom(header *BlockHeader, receipts []*TransactionReceipt) error {
+	for i, receipt := range receipts {
+		if err := receipt.VerifyBloom(); err != nil {
+			return fmt.Errorf("receipt %d: %w", i, err)
+		}
+	}
+	if bloom := BlockBloom(receipts); bloom != header.LogsBloom {
+		return fmt.Errorf("block %v: receipt logs do not rebuild the header's logsBloom", header.Number)
+	}
+	return nil
+}
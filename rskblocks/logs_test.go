@@ -0,0 +1,59 @@
+package rskblocks
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestExtractLogs(t *testing.T) {
+	receipts := testReceipts(3)
+	receipts[1].Logs = nil
+	receipts[2].Logs = append(receipts[2].Logs, &Log{Address: common.Address{9}})
+	receipts[2].TxHash = common.Hash{2}
+
+	logs := ExtractLogs(receipts)
+	if len(logs) != 3 {
+		t.Fatalf("Expected 3 logs, got %d", len(logs))
+	}
+	for i, txIndex := range []int{0, 2, 2} {
+		if logs[i].Index != i || logs[i].TxIndex != txIndex {
+			t.Errorf("Log %d: index %d, tx index %d", i, logs[i].Index, logs[i].TxIndex)
+		}
+	}
+	if logs[2].Address != (common.Address{9}) || logs[2].TxHash != (common.Hash{2}) {
+		t.Errorf("Unexpected last log %+v", logs[2])
+	}
+}
+
+func TestVerifyLogsBloom(t *testing.T) {
+	receipts := testReceipts(4)
+	header := &BlockHeader{Number: big.NewInt(7), LogsBloom: BlockBloom(receipts)}
+	if err := VerifyLogsBloom(header, receipts); err != nil {
+		t.Fatal(err)
+	}
+	if header.LogsBloom == ([256]byte{}) {
+		t.Fatal("Expected a non-empty block bloom")
+	}
+
+	if err := VerifyLogsBloom(header, receipts[:3]); err == nil {
+		t.Error("Expected a missing receipt to fail")
+	}
+
+	forged := testReceipts(4)
+	forged[2].Logs[0].Address = common.Address{0xee}
+	if err := VerifyLogsBloom(header, forged); err == nil {
+		t.Error("Expected a receipt whose bloom does not match its logs to fail")
+	}
+	forged[2].Bloom = CreateBloom(forged[2].Logs)
+	if err := VerifyLogsBloom(header, forged); err == nil {
+		t.Error("Expected a substituted log to fail")
+	}
+
+	// V1 headers hash the bloom into the extension; it is still checked.
+	header.Version = 1
+	if err := VerifyLogsBloom(header, receipts); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,251 @@
+package rskblocks
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// RPCLog is a log as eth_getLogs returns it.
+type RPCLog struct {
+	Address          common.Address `json:"address"`
+	Topics           []common.Hash  `json:"topics"`
+	Data             hexutil.Bytes  `json:"data"`
+	BlockNumber      hexutil.Uint64 `json:"blockNumber"`
+	BlockHash        common.Hash    `json:"blockHash"`
+	TransactionHash  common.Hash    `json:"transactionHash"`
+	TransactionIndex hexutil.Uint   `json:"transactionIndex"`
+	LogIndex         hexutil.Uint   `json:"logIndex"`
+}
+
+// LogFilter is the address and topic criteria of an eth_getLogs request.
+// An empty Addresses matches any address; Topics[i] lists the accepted
+// values for the i-th topic, and an empty entry matches any topic.
+type LogFilter struct {
+	Addresses []common.Address
+	Topics    [][]common.Hash
+}
+
+// Matches reports whether log satisfies the filter.
+func (f *LogFilter) Matches(log *Log) bool {
+	if len(f.Addresses) > 0 && !slices.Contains(f.Addresses, log.Address) {
+		return false
+	}
+	if len(f.Topics) > len(log.Topics) {
+		return false
+	}
+	for i, accepted := range f.Topics {
+		if len(accepted) > 0 && !slices.Contains(accepted, log.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchesBloom reports whether a block or receipt with bloom may contain a
+// log satisfying the filter. A false result rules the block out.
+func (f *LogFilter) MatchesBloom(bloom Bloom) bool {
+	if len(f.Addresses) > 0 {
+		found := false
+		for _, address := range f.Addresses {
+			found = found || bloom.Matches(address[:])
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, accepted := range f.Topics {
+		if len(accepted) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range accepted {
+			found = found || bloom.Matches(topic[:])
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ProvenReceipt is a receipt with its proof in the receipts trie of the
+// block with BlockHash; see GenerateReceiptProof.
+type ProvenReceipt struct {
+	BlockHash common.Hash
+	Index     int
+	Receipt   *TransactionReceipt
+	Proof     [][]byte
+}
+
+// LogsVerification is the result of cross-checking an eth_getLogs response.
+type LogsVerification struct {
+	// Verified is the number of reported logs found in a proven receipt.
+	Verified int
+
+	// Mismatches lists fabricated, unprovable and censored logs.
+	Mismatches []error
+
+	// Valid is true when every reported log is proven and no proven
+	// receipt has a matching log the response left out.
+	Valid bool
+}
+
+// Err returns the mismatches joined, or nil if the response is valid.
+func (r *LogsVerification) Err() error {
+	return errors.Join(r.Mismatches...)
+}
+
+// VerifyGetLogsResponse cross-checks logs, an eth_getLogs response to
+// filter, without trusting the node that served it. headers holds the
+// validated headers of the blocks in range by hash, and receipts the
+// receipts of the transactions the logs name, each proven against its
+// block's receipts root. A reported log must
+//   - satisfy the filter and belong to a block in headers whose logsBloom
+//     matches it;
+//   - be, with the same address, topics and data, the log its logIndex
+//     designates in the proven receipt at its transaction index. Each
+//     receipt log can be reported only once.
+//
+// logIndex counts logs across the block. When the receipts of all earlier
+// transactions of the block are proven, the first log index of a receipt
+// follows from them; otherwise it is the offset that places the most
+// reported logs of the receipt, and every other log of the receipt must
+// agree with it.
+//
+// Every log of a proven receipt that satisfies the filter must also be
+// reported, so dropping some of a transaction's logs is detected. Omitting
+// whole transactions is only detected for receipts the caller proves, for
+// example all receipts of blocks whose bloom matches the filter.
+func VerifyGetLogsResponse(filter *LogFilter, headers map[common.Hash]*BlockHeader, logs []*RPCLog, receipts []*ProvenReceipt) *LogsVerification {
+	result := &LogsVerification{}
+	mismatch := func(format string, args ...interface{}) {
+		result.Mismatches = append(result.Mismatches, fmt.Errorf(format, args...))
+	}
+
+	type txKey struct {
+		block common.Hash
+		index int
+	}
+	proven := make(map[txKey]*TransactionReceipt)
+	var order []txKey
+	for _, pr := range receipts {
+		header, ok := headers[pr.BlockHash]
+		if !ok {
+			mismatch("receipt %d of block %s: no validated header", pr.Index, pr.BlockHash.Hex())
+			continue
+		}
+		if err := VerifyReceiptProof(header.ReceiptTrieRoot, pr.Index, pr.Receipt, pr.Proof); err != nil {
+			mismatch("block %s: %v", pr.BlockHash.Hex(), err)
+			continue
+		}
+		key := txKey{pr.BlockHash, pr.Index}
+		if _, ok := proven[key]; !ok {
+			order = append(order, key)
+		}
+		proven[key] = pr.Receipt
+	}
+
+	// firstLogIndex returns the block-wide index of the first log of the
+	// receipt at key, if the receipts before it are all proven.
+	firstLogIndex := func(key txKey) (int, bool) {
+		first := 0
+		for i := 0; i < key.index; i++ {
+			receipt, ok := proven[txKey{key.block, i}]
+			if !ok {
+				return 0, false
+			}
+			first += len(receipt.Logs)
+		}
+		return first, true
+	}
+
+	reported := make(map[txKey][]int)
+	for i, rl := range logs {
+		log := &Log{Address: rl.Address, Topics: rl.Topics, Data: rl.Data}
+		if !filter.Matches(log) {
+			mismatch("log %d does not satisfy the filter", i)
+			continue
+		}
+		header, ok := headers[rl.BlockHash]
+		if !ok {
+			mismatch("log %d: no validated header for block %s", i, rl.BlockHash.Hex())
+			continue
+		}
+		if !header.LogsBloom.Matches(log.Address[:]) {
+			mismatch("log %d: block %s logsBloom does not contain its address", i, rl.BlockHash.Hex())
+			continue
+		}
+		key := txKey{rl.BlockHash, int(rl.TransactionIndex)}
+		if _, ok := proven[key]; !ok {
+			mismatch("log %d: no proven receipt for transaction %d of block %s", i, key.index, rl.BlockHash.Hex())
+			continue
+		}
+		reported[key] = append(reported[key], i)
+	}
+
+	for _, key := range order {
+		receipt := proven[key]
+		first, ok := firstLogIndex(key)
+		if !ok {
+			first = inferFirstLogIndex(receipt.Logs, logs, reported[key])
+		}
+		used := make([]bool, len(receipt.Logs))
+		for _, i := range reported[key] {
+			rl := logs[i]
+			pos := int(rl.LogIndex) - first
+			if pos < 0 || pos >= len(receipt.Logs) || !sameLog(receipt.Logs[pos], rl) {
+				mismatch("log %d: not log %d of the proven receipt of transaction %d of block %s", i, rl.LogIndex, key.index, key.block.Hex())
+				continue
+			}
+			if used[pos] {
+				mismatch("log %d: log %d of transaction %d of block %s reported twice", i, rl.LogIndex, key.index, key.block.Hex())
+				continue
+			}
+			used[pos] = true
+			result.Verified++
+		}
+		for pos, log := range receipt.Logs {
+			if !used[pos] && filter.Matches(log) {
+				mismatch("transaction %d of block %s: matching log %d not reported", key.index, key.block.Hex(), first+pos)
+			}
+		}
+	}
+
+	result.Valid = len(result.Mismatches) == 0
+	return result
+}
+
+// inferFirstLogIndex returns the block-wide index of the first of
+// receiptLogs that places the most of the reported logs, picked by their
+// indexes in logs, on an identical receipt log. Ties go to the lowest index.
+func inferFirstLogIndex(receiptLogs []*Log, logs []*RPCLog, reported []int) int {
+	best, bestCount := 0, -1
+	for _, i := range reported {
+		for pos, log := range receiptLogs {
+			first := int(logs[i].LogIndex) - pos
+			if first < 0 || !sameLog(log, logs[i]) {
+				continue
+			}
+			count := 0
+			for _, j := range reported {
+				p := int(logs[j].LogIndex) - first
+				if p >= 0 && p < len(receiptLogs) && sameLog(receiptLogs[p], logs[j]) {
+					count++
+				}
+			}
+			if count > bestCount || (count == bestCount && first < best) {
+				best, bestCount = first, count
+			}
+		}
+	}
+	return best
+}
+
+func sameLog(log *Log, rl *RPCLog) bool {
+	return log.Address == rl.Address && bytes.Equal(log.Data, rl.Data) && slices.Equal(log.Topics, rl.Topics)
+}
@@ -0,0 +1,155 @@
+package rskblocks
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestLogFilter(t *testing.T) {
+	log := &Log{Address: common.Address{1}, Topics: []common.Hash{{0xa}, {0xb}}}
+	tests := []struct {
+		name   string
+		filter LogFilter
+		want   bool
+	}{
+		{"empty", LogFilter{}, true},
+		{"address", LogFilter{Addresses: []common.Address{{2}, {1}}}, true},
+		{"other address", LogFilter{Addresses: []common.Address{{2}}}, false},
+		{"wildcard first topic", LogFilter{Topics: [][]common.Hash{nil, {{0xb}}}}, true},
+		{"wrong topic", LogFilter{Topics: [][]common.Hash{{{0xb}}}}, false},
+		{"too many topics", LogFilter{Topics: [][]common.Hash{nil, nil, nil}}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.filter.Matches(log); got != test.want {
+				t.Errorf("Matches = %v, want %v", got, test.want)
+			}
+			if test.want && !test.filter.MatchesBloom(CreateBloom([]*Log{log})) {
+				t.Error("MatchesBloom rejects a matching log")
+			}
+		})
+	}
+}
+
+func TestVerifyGetLogsResponse(t *testing.T) {
+	receipts := testReceipts(4)
+	target := receipts[2].Logs[0].Address
+	receipts[2].Logs = append(receipts[2].Logs, &Log{Address: target, Topics: []common.Hash{{0xff}}, Data: []byte{1}})
+	receipts[2].Bloom = CreateBloom(receipts[2].Logs)
+	header := &BlockHeader{
+		Number:          big.NewInt(10),
+		ReceiptTrieRoot: common.BytesToHash(CalculateReceiptsTrieRoot(receipts)),
+		LogsBloom:       BlockBloom(receipts),
+	}
+	blockHash := common.Hash{0xbb}
+	headers := map[common.Hash]*BlockHeader{blockHash: header}
+	filter := &LogFilter{Addresses: []common.Address{target}}
+
+	proof, err := GenerateReceiptProof(receipts, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proven := []*ProvenReceipt{{BlockHash: blockHash, Index: 2, Receipt: receipts[2], Proof: proof}}
+	response := func() []*RPCLog {
+		var logs []*RPCLog
+		for i, log := range receipts[2].Logs {
+			logs = append(logs, &RPCLog{
+				Address: log.Address, Topics: log.Topics, Data: log.Data,
+				BlockNumber: 10, BlockHash: blockHash, TransactionIndex: 2, LogIndex: hexutil.Uint(2 + i),
+			})
+		}
+		return logs
+	}
+
+	result := VerifyGetLogsResponse(filter, headers, response(), proven)
+	if err := result.Err(); err != nil || !result.Valid || result.Verified != 2 {
+		t.Fatalf("Expected a valid response, got %+v: %v", result, err)
+	}
+
+	t.Run("censored", func(t *testing.T) {
+		if result := VerifyGetLogsResponse(filter, headers, response()[:1], proven); result.Valid {
+			t.Error("Expected a dropped log to be detected")
+		}
+	})
+	t.Run("fabricated", func(t *testing.T) {
+		logs := response()
+		logs[1].Data = []byte{2}
+		if result := VerifyGetLogsResponse(filter, headers, logs, proven); result.Valid {
+			t.Error("Expected a modified log to be detected")
+		}
+	})
+	t.Run("outside filter", func(t *testing.T) {
+		logs := response()
+		logs[0].Address = common.Address{0xee}
+		if result := VerifyGetLogsResponse(filter, headers, logs, proven); result.Valid {
+			t.Error("Expected a log outside the filter to be detected")
+		}
+	})
+	t.Run("unknown block", func(t *testing.T) {
+		logs := response()
+		logs[0].BlockHash = common.Hash{0xcc}
+		if result := VerifyGetLogsResponse(filter, headers, logs, proven); result.Valid {
+			t.Error("Expected a log of an unvalidated block to be detected")
+		}
+	})
+	t.Run("repeated instead of dropped", func(t *testing.T) {
+		logs := response()
+		repeated := *logs[0]
+		logs[1] = &repeated
+		if result := VerifyGetLogsResponse(filter, headers, logs, proven); result.Valid || result.Verified != 1 {
+			t.Errorf("Expected a repeated log standing in for a dropped one to be detected, got %+v", result)
+		}
+	})
+	t.Run("wrong log index", func(t *testing.T) {
+		logs := response()
+		logs[0].LogIndex, logs[1].LogIndex = logs[1].LogIndex, logs[0].LogIndex
+		if result := VerifyGetLogsResponse(filter, headers, logs, proven); result.Valid {
+			t.Error("Expected logs with swapped indexes to be detected")
+		}
+	})
+	t.Run("index from earlier receipts", func(t *testing.T) {
+		all := append([]*ProvenReceipt(nil), proven...)
+		for i := 0; i < 2; i++ {
+			proof, err := GenerateReceiptProof(receipts, i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			all = append(all, &ProvenReceipt{BlockHash: blockHash, Index: i, Receipt: receipts[i], Proof: proof})
+		}
+		if result := VerifyGetLogsResponse(filter, headers, response(), all); !result.Valid {
+			t.Errorf("Expected a valid response, got %v", result.Err())
+		}
+		logs := response()
+		for _, log := range logs {
+			log.LogIndex++
+		}
+		if result := VerifyGetLogsResponse(filter, headers, logs, all); result.Valid {
+			t.Error("Expected shifted log indexes to be detected once earlier receipts are proven")
+		}
+	})
+	t.Run("unproven receipt", func(t *testing.T) {
+		forged := *receipts[2]
+		forged.Logs = forged.Logs[:1]
+		bad := []*ProvenReceipt{{BlockHash: blockHash, Index: 2, Receipt: &forged, Proof: proof}}
+		if result := VerifyGetLogsResponse(filter, headers, response()[:1], bad); result.Valid {
+			t.Error("Expected a receipt that fails its proof to be rejected")
+		}
+	})
+}
+
+func TestRPCLogJSON(t *testing.T) {
+	data := `{"address":"0x0000000000000000000000000000000001000008","topics":["0x0000000000000000000000000000000000000000000000000000000000000001"],` +
+		`"data":"0x01","blockNumber":"0xa","blockHash":"0x00000000000000000000000000000000000000000000000000000000000000bb",` +
+		`"transactionHash":"0x00000000000000000000000000000000000000000000000000000000000000cc","transactionIndex":"0x2","logIndex":"0x3"}`
+	var log RPCLog
+	if err := json.Unmarshal([]byte(data), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.BlockNumber != 10 || log.TransactionIndex != 2 || log.LogIndex != 3 || len(log.Topics) != 1 {
+		t.Errorf("Unexpected log %+v", log)
+	}
+}
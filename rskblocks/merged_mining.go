@@ -0,0 +1,116 @@
+package rskblocks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// ummLeafLength is the number of bytes of the header's own hash that
+	// are paired with ummRoot in a UMM block.
+	ummLeafLength = 20
+	// ForkDetectionDataLength is the size of the RSKIP-110 fork detection
+	// data that replaces the tail of the merged mining hash.
+	ForkDetectionDataLength = 12
+	cpvLength               = 7
+)
+
+// RskTag prefixes the merged mining commitment in a Bitcoin coinbase
+// transaction: "RSKBLOCK:" followed by hashForMergedMining.
+var RskTag = []byte("RSKBLOCK:")
+
+// ErrNoRskTag is returned when a coinbase transaction carries no RSK merged
+// mining tag.
+var ErrNoRskTag = errors.New("coinbase transaction has no RSK tag")
+
+// ErrMultipleRskTags is returned when a coinbase transaction carries more
+// than one RSK merged mining tag; rskj rejects such coinbases.
+var ErrMultipleRskTags = errors.New("coinbase transaction has more than one RSK tag")
+
+// rskTagIndex returns the position of the one RSK tag in data.
+func rskTagIndex(data []byte) (int, error) {
+	pos := bytes.Index(data, RskTag)
+	if pos < 0 {
+		return 0, ErrNoRskTag
+	}
+	if bytes.LastIndex(data, RskTag) != pos {
+		return 0, ErrMultipleRskTags
+	}
+	return pos, nil
+}
+
+// BaseHashForMergedMining returns the header hash that merged mining commits
+// to before fork detection data is applied: Keccak256 of the header encoded
+// without any merged mining fields. In a UMM block (non-empty ummRoot) that
+// hash is folded with the ummRoot: keccak256(hash[:20] || ummRoot).
+func (h *BlockHeader) BaseHashForMergedMining() (common.Hash, error) {
+	hash := keccak256Hash(h.getEncoded(false, false, true))
+	if err := h.ValidateUmmRoot(); err != nil {
+		return common.Hash{}, err
+	}
+	if !h.IsUmm() {
+		return hash, nil
+	}
+	leaves := make([]byte, 0, 2*ummLeafLength)
+	leaves = append(leaves, hash[:ummLeafLength]...)
+	leaves = append(leaves, *h.UmmRoot...)
+	return keccak256Hash(leaves), nil
+}
+
+// HashForMergedMining returns the hash a miner commits to after the RSK tag
+// in the Bitcoin coinbase transaction (rskj's getHashForMergedMining).
+//
+// Once RSKIP-110 is active (IncludeForkDetectionData), its last 12 bytes are
+// the fork detection data instead of the base hash's: for a mined header the
+// bytes that follow the first 20 of the commitment in the coinbase, for one
+// still being mined the header's ForkDetectionData.
+func (h *BlockHeader) HashForMergedMining() (common.Hash, error) {
+	hash, err := h.BaseHashForMergedMining()
+	if err != nil || !h.IncludeForkDetectionData {
+		return hash, err
+	}
+	data := h.ForkDetectionData
+	if h.hasMiningFields() {
+		if data, err = CoinbaseForkDetectionData(h.BitcoinMergedMiningCoinbaseTransaction); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	if len(data) != ForkDetectionDataLength {
+		return common.Hash{}, fmt.Errorf("fork detection data has %d bytes, want %d", len(data), ForkDetectionDataLength)
+	}
+	copy(hash[common.HashLength-ForkDetectionDataLength:], data)
+	return hash, nil
+}
+
+// CoinbaseForkDetectionData returns the fork detection data a coinbase
+// transaction commits to: the last 12 bytes of the 32 that follow the RSK
+// tag.
+func CoinbaseForkDetectionData(coinbase []byte) ([]byte, error) {
+	pos, err := rskTagIndex(coinbase)
+	if err != nil {
+		return nil, err
+	}
+	end := pos + len(RskTag) + common.HashLength
+	if end > len(coinbase) {
+		return nil, errors.New("coinbase transaction is truncated after the RSK tag")
+	}
+	return common.CopyBytes(coinbase[end-ForkDetectionDataLength : end]), nil
+}
+
+// NewForkDetectionData builds RSKIP-110 fork detection data: the 7-byte
+// commitment to parent versions (CPV), the number of uncles in the last
+// blocks, and the block height as a 4-byte big-endian suffix.
+func NewForkDetectionData(cpv []byte, uncles byte, height uint32) ([]byte, error) {
+	if len(cpv) != cpvLength {
+		return nil, fmt.Errorf("CPV has %d bytes, want %d", len(cpv), cpvLength)
+	}
+	data := make([]byte, ForkDetectionDataLength)
+	copy(data, cpv)
+	data[cpvLength] = uncles
+	binary.BigEndian.PutUint32(data[cpvLength+1:], height)
+	return data, nil
+}
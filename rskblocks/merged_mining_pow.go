@@ -0,0 +1,169 @@
+package rskblocks
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+)
+
+const (
+	// BitcoinHeaderLength is the size of a serialized Bitcoin block header.
+	BitcoinHeaderLength = 80
+	// coinbaseMidstateLength is the size of the SHA-256 midstate that
+	// prefixes the RSKIP-92 coinbase field: the number of bytes hashed so
+	// far (8, big-endian) followed by the eight state words.
+	coinbaseMidstateLength = 40
+	// MaxBytesAfterMergedMiningHash is how many coinbase bytes may follow
+	// the RSK commitment.
+	MaxBytesAfterMergedMiningHash = 128
+	// minCoinbaseLength bounds the coinbase from below: a 64-byte
+	// transaction could be passed off as an inner merkle node.
+	minCoinbaseLength = 64
+	// maxRskTagPosition bounds the RSK tag's offset in the coinbase tail.
+	// The midstate must be taken at the last whole block before the tag,
+	// so one coinbase has one encoding and one header hash.
+	maxRskTagPosition = 64
+)
+
+// ErrInsufficientWork is returned when the Bitcoin header does not meet the
+// RSK difficulty target.
+var ErrInsufficientWork = errors.New("bitcoin header does not meet the difficulty target")
+
+var maxTarget = new(big.Int).Lsh(big.NewInt(1), 256)
+
+// DifficultyToTarget returns the highest Bitcoin header hash, as a number,
+// that satisfies difficulty: 2^256 / difficulty.
+func DifficultyToTarget(difficulty *big.Int) (*big.Int, error) {
+	if difficulty == nil || difficulty.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid difficulty %v", difficulty)
+	}
+	return new(big.Int).Div(maxTarget, difficulty), nil
+}
+
+// VerifyProofOfWork checks the header's merged mining proof as rskj's
+// ProofOfWorkRule does, so a header can be trusted without trusting the node
+// that served it:
+//   - the Bitcoin header's hash meets the target for the RSK difficulty;
+//   - the coinbase transaction commits to HashForMergedMining, placed as
+//     ExtractRskCommitment requires, with the RSK tag within the first 64
+//     bytes after the midstate;
+//   - the merkle proof links the coinbase to the Bitcoin header's merkle
+//     root.
+//
+// Only the RSKIP-92 encoding is supported: the coinbase field is a SHA-256
+// midstate followed by the transaction's tail, and the merkle proof is the
+// list of sibling hashes from the coinbase up.
+func (h *BlockHeader) VerifyProofOfWork() error {
+	if !h.UseRskip92Encoding {
+		return errors.New("proof of work verification requires RSKIP-92 merged mining fields")
+	}
+	if len(h.BitcoinMergedMiningHeader) != BitcoinHeaderLength {
+		return fmt.Errorf("bitcoin header has %d bytes, want %d", len(h.BitcoinMergedMiningHeader), BitcoinHeaderLength)
+	}
+	target, err := DifficultyToTarget(h.Difficulty)
+	if err != nil {
+		return err
+	}
+	if bitcoinWork(h.BitcoinMergedMiningHeader).Cmp(target) > 0 {
+		return ErrInsufficientWork
+	}
+
+	mmHash, err := h.HashForMergedMining()
+	if err != nil {
+		return err
+	}
+	coinbaseHash, err := coinbaseTxHash(h.BitcoinMergedMiningCoinbaseTransaction, mmHash[:])
+	if err != nil {
+		return err
+	}
+	proof := h.BitcoinMergedMiningMerkleProof
+	if len(proof)%32 != 0 {
+		return fmt.Errorf("merkle proof length %d is not a multiple of 32", len(proof))
+	}
+	node := coinbaseHash
+	for i := 0; i < len(proof); i += 32 {
+		node = doubleSHA256(node, proof[i:i+32])
+	}
+	if merkleRoot := h.BitcoinMergedMiningHeader[36:68]; !bytes.Equal(node, merkleRoot) {
+		return errors.New("merkle proof does not link the coinbase to the bitcoin header")
+	}
+	return nil
+}
+
+// bitcoinWork returns the Bitcoin header hash as the number compared against
+// the target; Bitcoin reads hashes little-endian.
+func bitcoinWork(header []byte) *big.Int {
+	digest := doubleSHA256(header)
+	for i, j := 0, len(digest)-1; i < j; i, j = i+1, j-1 {
+		digest[i], digest[j] = digest[j], digest[i]
+	}
+	return new(big.Int).SetBytes(digest)
+}
+
+// coinbaseTxHash checks the commitment in an RSKIP-92 coinbase field and
+// returns the coinbase transaction's hash in internal byte order.
+func coinbaseTxHash(field, hashForMergedMining []byte) ([]byte, error) {
+	if len(field) < coinbaseMidstateLength {
+		return nil, fmt.Errorf("coinbase field has %d bytes, need a %d-byte midstate", len(field), coinbaseMidstateLength)
+	}
+	byteCount := binary.BigEndian.Uint64(field[:8])
+	tail := field[coinbaseMidstateLength:]
+
+	if pos, err := rskTagIndex(tail); err == nil && pos >= maxRskTagPosition {
+		return nil, fmt.Errorf("RSK tag at %d in the coinbase tail, must be before %d", pos, maxRskTagPosition)
+	}
+	commitment, err := ExtractRskCommitment(tail)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(commitment[:], hashForMergedMining) {
+		return nil, errors.New("coinbase transaction does not commit to the header's hash for merged mining")
+	}
+	if byteCount%64 != 0 {
+		return nil, fmt.Errorf("midstate byte count %d is not a whole number of blocks", byteCount)
+	}
+	if total := byteCount + uint64(len(tail)); total <= minCoinbaseLength {
+		return nil, fmt.Errorf("coinbase transaction must be longer than %d bytes, got %d", minCoinbaseLength, total)
+	}
+
+	digest, err := resumeSHA256(field[8:coinbaseMidstateLength], byteCount)
+	if err != nil {
+		return nil, err
+	}
+	digest.Write(tail)
+	return sha256Sum(digest.Sum(nil)), nil
+}
+
+// resumeSHA256 returns a SHA-256 digest continuing from state words after
+// byteCount bytes, using crypto/sha256's marshaled state layout: magic,
+// state, 64-byte block buffer, length.
+func resumeSHA256(state []byte, byteCount uint64) (hash.Hash, error) {
+	marshaled := make([]byte, 0, 4+32+64+8)
+	marshaled = append(marshaled, "sha\x03"...)
+	marshaled = append(marshaled, state...)
+	marshaled = append(marshaled, make([]byte, 64)...)
+	marshaled = binary.BigEndian.AppendUint64(marshaled, byteCount)
+	digest := sha256.New()
+	if err := digest.(encoding.BinaryUnmarshaler).UnmarshalBinary(marshaled); err != nil {
+		return nil, fmt.Errorf("restore coinbase midstate: %w", err)
+	}
+	return digest, nil
+}
+
+func doubleSHA256(parts ...[]byte) []byte {
+	first := sha256.New()
+	for _, p := range parts {
+		first.Write(p)
+	}
+	return sha256Sum(first.Sum(nil))
+}
+
+func sha256Sum(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
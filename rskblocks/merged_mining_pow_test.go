@@ -0,0 +1,139 @@
+package rskblocks
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// mergeMine fills in header's merged mining fields for a coinbase
+// transaction of prefix, the RSK commitment and suffix, and grinds the
+// Bitcoin nonce until the header meets the difficulty.
+func mergeMine(t *testing.T, header *BlockHeader, prefix, suffix []byte) {
+	t.Helper()
+	mergeMineAt(t, header, prefix, suffix, -1)
+}
+
+// mergeMineAt is mergeMine with the coinbase midstate taken after split
+// bytes, or as CompressCoinbase takes it if split is negative. Unlike
+// CompressCoinbase it does not check the coinbase, so tests can build
+// headers rskj would reject.
+func mergeMineAt(t *testing.T, header *BlockHeader, prefix, suffix []byte, split int) {
+	t.Helper()
+	// Placeholder fields so HashForMergedMining reads the fork detection
+	// data the coinbase will commit to.
+	header.BitcoinMergedMiningHeader = make([]byte, BitcoinHeaderLength)
+	header.IncludeForkDetectionData = false
+	hash, err := header.HashForMergedMining()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header.IncludeForkDetectionData = true
+	coinbase := append(append(append(append([]byte(nil), prefix...), RskTag...), hash[:]...), suffix...)
+
+	if split < 0 {
+		split = bytes.LastIndex(coinbase, RskTag) / 64 * 64
+	}
+	digest := sha256.New()
+	digest.Write(coinbase[:split])
+	state, err := digest.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	field := binary.BigEndian.AppendUint64(nil, uint64(split))
+	field = append(append(field, state[4:4+32]...), coinbase[split:]...)
+	header.BitcoinMergedMiningCoinbaseTransaction = field
+
+	sibling := bytes.Repeat([]byte{0x77}, 32)
+	header.BitcoinMergedMiningMerkleProof = sibling
+	root := doubleSHA256(doubleSHA256(coinbase), sibling)
+
+	btc := make([]byte, BitcoinHeaderLength)
+	copy(btc[36:68], root)
+	target, err := DifficultyToTarget(header.Difficulty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for nonce := uint32(0); ; nonce++ {
+		binary.LittleEndian.PutUint32(btc[76:], nonce)
+		if bitcoinWork(btc).Cmp(target) <= 0 {
+			break
+		}
+	}
+	header.BitcoinMergedMiningHeader = btc
+}
+
+func powTestHeader() *BlockHeader {
+	input := testHeaderInput()
+	input.Difficulty = big.NewInt(16)
+	return InputToBlockHeader(input, ConfigForBlockNumber(3000000, "mainnet"))
+}
+
+func TestVerifyProofOfWork(t *testing.T) {
+	header := powTestHeader()
+	mergeMine(t, header, bytes.Repeat([]byte{0x01}, 150), []byte{0xff, 0xff, 0xff, 0xff})
+	if err := header.VerifyProofOfWork(); err != nil {
+		t.Fatalf("VerifyProofOfWork failed: %v", err)
+	}
+
+	decoded, err := DecodeBlockHeader(header.GetFullEncoded(), ConfigForBlockNumber(3000000, "mainnet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.VerifyProofOfWork(); err != nil {
+		t.Fatalf("Decoded header failed verification: %v", err)
+	}
+
+	decoded.StateRoot[0] ^= 1
+	if err := decoded.VerifyProofOfWork(); err == nil {
+		t.Error("Expected a header the coinbase does not commit to to fail")
+	}
+}
+
+func TestVerifyProofOfWorkRejects(t *testing.T) {
+	mined := func(prefix, suffix []byte) *BlockHeader {
+		header := powTestHeader()
+		mergeMine(t, header, prefix, suffix)
+		return header
+	}
+	prefix := bytes.Repeat([]byte{0x01}, 150)
+
+	hard := mined(prefix, nil)
+	hard.Difficulty = new(big.Int).Lsh(big.NewInt(1), 255)
+	if err := hard.VerifyProofOfWork(); !errors.Is(err, ErrInsufficientWork) {
+		t.Errorf("Expected ErrInsufficientWork, got %v", err)
+	}
+
+	if err := mined(prefix, make([]byte, MaxBytesAfterMergedMiningHash+1)).VerifyProofOfWork(); err == nil {
+		t.Error("Expected error for too many bytes after the commitment")
+	}
+	if err := mined(append(append([]byte(nil), prefix...), RskTag...), nil).VerifyProofOfWork(); !errors.Is(err, ErrMultipleRskTags) {
+		t.Errorf("Expected ErrMultipleRskTags for an RSK tag before the commitment, got %v", err)
+	}
+
+	late := powTestHeader()
+	mergeMineAt(t, late, prefix, nil, 64)
+	if err := late.VerifyProofOfWork(); err == nil {
+		t.Error("Expected error for an RSK tag 64 or more bytes after the midstate")
+	}
+	early := powTestHeader()
+	mergeMineAt(t, early, prefix, nil, 128)
+	if err := early.VerifyProofOfWork(); err != nil {
+		t.Errorf("RSK tag within 64 bytes of the midstate: %v", err)
+	}
+
+	tampered := mined(prefix, nil)
+	tampered.BitcoinMergedMiningMerkleProof[0] ^= 1
+	if err := tampered.VerifyProofOfWork(); err == nil {
+		t.Error("Expected error for a broken merkle proof")
+	}
+
+	short := mined(nil, nil)
+	if err := short.VerifyProofOfWork(); err == nil {
+		t.Error("Expected error for a coinbase of at most 64 bytes")
+	}
+}
@@ -0,0 +1,100 @@
+package rskblocks
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBaseHashForMergedMining(t *testing.T) {
+	header := InputToBlockHeader(testHeaderInput(), ConfigForBlockNumber(1000, "mainnet"))
+	base, err := header.BaseHashForMergedMining()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base != header.Hash() {
+		t.Error("Without merged mining fields the base hash should be the block hash")
+	}
+	header.BitcoinMergedMiningHeader = bytes.Repeat([]byte{0xaa}, 80)
+	if got, _ := header.BaseHashForMergedMining(); got != base {
+		t.Error("Merged mining fields changed the base hash")
+	}
+
+	umm := bytes.Repeat([]byte{0x11}, ummLeafLength)
+	header.UmmRoot = &umm
+	plain := keccak256Hash(header.getEncoded(false, false, true))
+	got, err := header.BaseHashForMergedMining()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := keccak256Hash(append(plain[:ummLeafLength:ummLeafLength], umm...)); got != want {
+		t.Errorf("UMM base hash %s, want %s", got, want)
+	}
+	bad := []byte{1, 2, 3}
+	header.UmmRoot = &bad
+	if _, err := header.BaseHashForMergedMining(); err == nil {
+		t.Error("Expected error for a malformed ummRoot")
+	}
+}
+
+func TestHashForMergedMining(t *testing.T) {
+	header := InputToBlockHeader(testHeaderInput(), ConfigForBlockNumber(3000000, "mainnet"))
+	base, err := header.BaseHashForMergedMining()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Being mined: fork detection data comes from the header.
+	data, err := NewForkDetectionData([]byte{1, 2, 3, 4, 5, 6, 7}, 9, 3000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data[8:], []byte{0x00, 0x2d, 0xc6, 0xc0}) {
+		t.Errorf("Height suffix %x", data[8:])
+	}
+	header.ForkDetectionData = data
+	unmined, err := header.HashForMergedMining()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(unmined[:20], base[:20]) || !bytes.Equal(unmined[20:], data) {
+		t.Errorf("Hash %s does not combine base %s with %x", unmined, base, data)
+	}
+
+	// Mined: fork detection data comes from the coinbase commitment.
+	commitment := common.BytesToHash(append(base[:20:20], bytes.Repeat([]byte{0x5a}, ForkDetectionDataLength)...))
+	coinbase := append([]byte("prefix"), RskTag...)
+	coinbase = append(append(coinbase, commitment[:]...), 0xff, 0xff, 0xff, 0xff)
+	header.BitcoinMergedMiningCoinbaseTransaction = coinbase
+	mined, err := header.HashForMergedMining()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mined != commitment {
+		t.Errorf("Mined hash %s, want %s", mined, commitment)
+	}
+
+	header.IncludeForkDetectionData = false
+	if got, _ := header.HashForMergedMining(); got != base {
+		t.Error("Before RSKIP-110 the hash should be the base hash")
+	}
+}
+
+func TestCoinbaseForkDetectionDataErrors(t *testing.T) {
+	if _, err := CoinbaseForkDetectionData([]byte("no tag here")); !errors.Is(err, ErrNoRskTag) {
+		t.Errorf("Expected ErrNoRskTag, got %v", err)
+	}
+	if _, err := CoinbaseForkDetectionData(append(RskTag, make([]byte, 31)...)); err == nil {
+		t.Error("Expected error for a truncated commitment")
+	}
+	twoTags := append(append(append([]byte(nil), RskTag...), make([]byte, 32)...), RskTag...)
+	twoTags = append(twoTags, make([]byte, 32)...)
+	if _, err := CoinbaseForkDetectionData(twoTags); !errors.Is(err, ErrMultipleRskTags) {
+		t.Errorf("Expected ErrMultipleRskTags, got %v", err)
+	}
+	if _, err := NewForkDetectionData(make([]byte, 6), 0, 1); err == nil {
+		t.Error("Expected error for a short CPV")
+	}
+}
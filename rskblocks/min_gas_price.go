@@ -0,0 +1,34 @@
+package rskblocks
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// MinimumGasPriceVariation is how far, in percent of the parent's value, a
+// block's minimumGasPrice may move.
+const MinimumGasPriceVariation = 1
+
+// MinimumGasPriceRange returns the lowest and highest minimumGasPrice a
+// child of a block with minimumGasPrice parent may declare, as rskj's
+// BlockGasPriceRange computes it: parent*(100∓1)/100, rounded down.
+func MinimumGasPriceRange(parent *big.Int) (lower, upper *big.Int) {
+	parent = bigOrZero(parent)
+	hundred := big.NewInt(100)
+	lower = new(big.Int).Mul(parent, big.NewInt(100-MinimumGasPriceVariation))
+	lower.Div(lower, hundred)
+	upper = new(big.Int).Mul(parent, big.NewInt(100+MinimumGasPriceVariation))
+	upper.Div(upper, hundred)
+	return lower, upper
+}
+
+// VerifyMinimumGasPrice reports whether header's minimumGasPrice is within
+// MinimumGasPriceRange of its parent's.
+func VerifyMinimumGasPrice(header, parent *BlockHeader) error {
+	lower, upper := MinimumGasPriceRange(parent.MinimumGasPrice)
+	mgp := bigOrZero(header.MinimumGasPrice)
+	if mgp.Cmp(lower) < 0 || mgp.Cmp(upper) > 0 {
+		return fmt.Errorf("minimum gas price %v is outside [%v, %v] allowed after parent's %v", mgp, lower, upper, bigOrZero(parent.MinimumGasPrice))
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package rskblocks
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestMinimumGasPriceRange(t *testing.T) {
+	lower, upper := MinimumGasPriceRange(big.NewInt(60_000_000))
+	if lower.Int64() != 59_400_000 || upper.Int64() != 60_600_000 {
+		t.Errorf("Range [%v, %v]", lower, upper)
+	}
+	lower, upper = MinimumGasPriceRange(nil)
+	if lower.Sign() != 0 || upper.Sign() != 0 {
+		t.Errorf("Range after nil [%v, %v]", lower, upper)
+	}
+}
+
+func TestVerifyMinimumGasPrice(t *testing.T) {
+	parent := &BlockHeader{MinimumGasPrice: big.NewInt(60_000_000)}
+	for mgp, ok := range map[int64]bool{
+		60_000_000: true,
+		60_600_000: true,
+		59_400_000: true,
+		60_600_001: false,
+		59_399_999: false,
+	} {
+		err := VerifyMinimumGasPrice(&BlockHeader{MinimumGasPrice: big.NewInt(mgp)}, parent)
+		if (err == nil) != ok {
+			t.Errorf("minimumGasPrice %d: got %v", mgp, err)
+		}
+	}
+
+	headers := testChain(4, func(i int, input *BlockHeaderInput) {
+		input.MinimumGasPrice = big.NewInt(60_000_000)
+		if i == 2 {
+			input.MinimumGasPrice = big.NewInt(61_000_000)
+		}
+	})
+	var violation *ChainViolation
+	if err := testChainValidator().Validate(headers); !errors.As(err, &violation) ||
+		violation.Rule != RuleMinimumGasPrice || violation.Index != 2 {
+		t.Errorf("Expected a minimum gas price violation at header 2, got %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package rskblocks
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// orchidNode serializes a node in the pre-RSKIP-107 format: arity, flags,
+// child bitmap, shared path bit length, encoded shared path, child hashes,
+// then the value or, for values over 32 bytes, its hash.
+func orchidNode(shared []byte, left, right, value []byte) []byte {
+	flags, children := byte(0), byte(0)
+	if len(value) > 32 {
+		flags = 0x02
+	}
+	if left != nil {
+		children |= 0x01
+	}
+	if right != nil {
+		children |= 0x02
+	}
+	msg := []byte{2, flags, 0, children, byte(len(shared) >> 8), byte(len(shared))}
+	if len(shared) > 0 {
+		msg = append(msg, rsktrie.PathEncoderEncode(shared)...)
+	}
+	msg = append(append(msg, left...), right...)
+	if len(value) > 32 {
+		return append(msg, crypto.Keccak256(value)...)
+	}
+	return append(msg, value...)
+}
+
+func keyBits(key []byte) []byte {
+	bits := make([]byte, 0, len(key)*8)
+	for _, b := range key {
+		for i := 7; i >= 0; i-- {
+			bits = append(bits, b>>i&1)
+		}
+	}
+	return bits
+}
+
+// orchidPair builds a two-key Orchid trie and returns its root and each
+// key's proof, leaf to root.
+func orchidPair(t *testing.T, k1, v1, k2, v2 []byte) (common.Hash, [][]byte, [][]byte) {
+	t.Helper()
+	b1, b2 := keyBits(k1), keyBits(k2)
+	d := 0
+	for b1[d] == b2[d] {
+		d++
+	}
+	leaf1, leaf2 := orchidNode(b1[d+1:], nil, nil, v1), orchidNode(b2[d+1:], nil, nil, v2)
+	left, right := leaf1, leaf2
+	if b1[d] == 1 {
+		left, right = leaf2, leaf1
+	}
+	root := orchidNode(b1[:d], crypto.Keccak256(left), crypto.Keccak256(right), nil)
+
+	enc := func(msgs ...[]byte) [][]byte {
+		var out [][]byte
+		for _, m := range msgs {
+			node, err := rlp.EncodeToBytes(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, node)
+		}
+		return out
+	}
+	return crypto.Keccak256Hash(root), enc(leaf1, root), enc(leaf2, root)
+}
+
+func TestVerifyOrchidProofs(t *testing.T) {
+	mapper := rsktrie.NewOrchidKeyMapper()
+	contract := common.HexToAddress("0x77045e71a7a2c50903d88e564cd72fab11e82051")
+	other := common.HexToAddress("0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826")
+	slot, otherSlot := common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(2))
+
+	storageRoot, slotProof, _ := orchidPair(t,
+		mapper.GetAccountStorageKey(contract, slot), []byte{0x2a},
+		mapper.GetAccountStorageKey(contract, otherSlot), []byte{0x07})
+
+	state := &rsktrie.AccountState{
+		Nonce:       big.NewInt(1),
+		Balance:     big.NewInt(5000),
+		StorageRoot: storageRoot,
+		CodeHash:    crypto.Keccak256Hash([]byte{0x60, 0x00}),
+	}
+	record, err := state.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherRecord, err := (&rsktrie.AccountState{
+		Nonce: big.NewInt(0), Balance: big.NewInt(1), CodeHash: crypto.Keccak256Hash(nil),
+	}).Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stateRoot, accountProof, _ := orchidPair(t,
+		mapper.GetAccountKey(contract), record,
+		mapper.GetAccountKey(other), otherRecord)
+
+	verifier := NewProofVerifierForBlock(100_000, "mainnet")
+
+	// The record is committed by hash only, so the bare proof is valid but
+	// carries no balance until the record is supplied.
+	account, err := verifier.VerifyAccountProof(stateRoot, contract, accountProof)
+	if err != nil || !account.Valid || account.ValueHash == nil || account.Balance != nil {
+		t.Fatalf("Unexpected bare account result %+v, %v", account, err)
+	}
+	account, err = verifier.VerifyAccountProofWithValue(stateRoot, contract, record, accountProof)
+	if err != nil || !account.Valid || account.Balance.Int64() != 5000 || account.StorageRoot != storageRoot {
+		t.Fatalf("Unexpected resolved account result %+v, %v", account, err)
+	}
+
+	response := func() *ProofResponse {
+		return &ProofResponse{
+			Address:      contract,
+			AccountProof: hexProof(accountProof),
+			Balance:      (*hexutil.Big)(big.NewInt(5000)),
+			Nonce:        1,
+			CodeHash:     state.CodeHash,
+			StorageHash:  storageRoot,
+			StorageProof: []StorageProof{{Key: slot.Hex(), Value: "0x2a", Proofs: hexProof(slotProof)}},
+		}
+	}
+	header := &BlockHeader{StateRoot: stateRoot}
+	result, err := verifier.VerifyEthGetProofResponse(header, response())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Valid {
+		t.Fatalf("Expected valid Orchid response, got %v", result.Err())
+	}
+
+	// A misreported balance no longer matches the committed record.
+	lying := response()
+	lying.Balance = (*hexutil.Big)(big.NewInt(6000))
+	if result, err := verifier.VerifyEthGetProofResponse(header, lying); err != nil || result.Valid || result.Account.Valid {
+		t.Errorf("Expected misreported balance to fail, got %+v, %v", result, err)
+	}
+
+	// The era's node format is enforced: a unitrie proof is rejected.
+	unitrie := rsktrie.NewTrieKeyMapper()
+	trie := rsktrie.NewTrie(nil).Put(unitrie.GetAccountKey(contract), []byte{0xc0})
+	proof := buildTestProof(t, trie, unitrie.GetAccountKey(contract))
+	orchid := NewProofVerifier(WithKeyMapper(unitrie), WithNodeFormat(rsktrie.NodeFormatOrchid))
+	if result, _ := orchid.VerifyAccountProof(common.BytesToHash(trie.GetHash()), contract, proof); result.Valid {
+		t.Error("Expected unitrie node to be rejected in Orchid format")
+	}
+}
+
+func TestVerifyOrchidStorageMultiproof(t *testing.T) {
+	mapper := rsktrie.NewOrchidKeyMapper()
+	contract := common.HexToAddress("0x77045e71a7a2c50903d88e564cd72fab11e82051")
+	slots := []common.Hash{common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(2))}
+
+	storageRoot, proof1, proof2 := orchidPair(t,
+		mapper.GetAccountStorageKey(contract, slots[0]), []byte{0x2a},
+		mapper.GetAccountStorageKey(contract, slots[1]), []byte{0x07})
+	nodes := rsktrie.MergeProofNodes(proof1, proof2)
+
+	verifier := NewProofVerifierForBlock(100_000, "mainnet")
+	results, err := verifier.VerifyStorageMultiproof(storageRoot, contract, slots, nodes)
+	if err != nil {
+		t.Fatalf("VerifyStorageMultiproof failed: %v", err)
+	}
+	for i, want := range [][]byte{{0x2a}, {0x07}} {
+		if r := results[i]; !r.Valid || r.Status != rsktrie.ProofPresent || !bytes.Equal(r.Value, want) {
+			t.Errorf("Slot %d: unexpected result %+v", i, r)
+		}
+	}
+
+	// The era's node format is enforced on the shared set too.
+	unitrie := NewProofVerifier(WithKeyMapper(mapper), WithNodeFormat(rsktrie.NodeFormatRSKIP107))
+	if _, err := unitrie.VerifyStorageMultiproof(storageRoot, contract, slots, nodes); err == nil {
+		t.Error("Expected Orchid nodes to be rejected in RSKIP-107 format")
+	}
+	trie := rsktrie.NewTrie(nil)
+	for i, slot := range slots {
+		trie = trie.Put(mapper.GetAccountStorageKey(contract, slot), []byte{byte(i + 1)})
+	}
+	unitrieNodes := rsktrie.MergeProofNodes(
+		buildTestProof(t, trie, mapper.GetAccountStorageKey(contract, slots[0])),
+		buildTestProof(t, trie, mapper.GetAccountStorageKey(contract, slots[1])))
+	if _, err := verifier.VerifyStorageMultiproof(common.BytesToHash(trie.GetHash()), contract, slots, unitrieNodes); err == nil {
+		t.Error("Expected unitrie nodes to be rejected in Orchid format")
+	}
+}
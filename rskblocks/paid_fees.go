@@ -0,0 +1,35 @@
+package rskblocks
+
+import (
+	"fmt"
+)
+
+// CalculatePaidFees returns the fees a block's transactions paid: the sum
+// over transactions of gas used, from the matching receipt, times gas
+// price. RSK has no base fee, so the gas price is the effective price; the
+// REMASC transaction uses no gas and pays nothing.
+func CalculatePaidFees(txs []*Transaction, receipts []*TransactionReceipt) (Coin, error) {
+	if len(txs) != len(receipts) {
+		return Coin{}, fmt.Errorf("%d transactions but %d receipts", len(txs), len(receipts))
+	}
+	var total Coin
+	for i, tx := range txs {
+		total = total.Add(NewCoin(tx.GasPrice()).MulUint64(receipts[i].GasUsed))
+	}
+	return total, nil
+}
+
+// VerifyPaidFees reports whether the header's paidFees matches the fees
+// computed from the block's transactions and receipts. Check the receipts
+// against the receipts root first; the receipts' gasUsed is what makes the
+// two agree.
+func VerifyPaidFees(header *BlockHeader, txs []*Transaction, receipts []*TransactionReceipt) error {
+	fees, err := CalculatePaidFees(txs, receipts)
+	if err != nil {
+		return err
+	}
+	if paid := NewCoin(header.PaidFees); !paid.Equal(fees) {
+		return fmt.Errorf("block %v declares paid fees %v, transactions paid %v", header.Number, paid, fees)
+	}
+	return nil
+}
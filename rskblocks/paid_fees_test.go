@@ -0,0 +1,45 @@
+package rskblocks
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestVerifyPaidFees(t *testing.T) {
+	txs := []*Transaction{
+		NewTransaction(0, common.Address{1}, big.NewInt(1), 21000, big.NewInt(60_000_000), nil),
+		NewTransaction(1, common.Address{2}, big.NewInt(1), 50000, big.NewInt(65_000_000), nil),
+		NewRemascTransaction(5),
+	}
+	receipts := []*TransactionReceipt{{GasUsed: 21000}, {GasUsed: 42000}, {GasUsed: 0}}
+	want := big.NewInt(21000*60_000_000 + 42000*65_000_000)
+
+	fees, err := CalculatePaidFees(txs, receipts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fees.Cmp(NewCoin(want)) != 0 {
+		t.Fatalf("Paid fees %v, want %v", fees, want)
+	}
+
+	header := &BlockHeader{Number: big.NewInt(5), PaidFees: want}
+	if err := VerifyPaidFees(header, txs, receipts); err != nil {
+		t.Error(err)
+	}
+	header.PaidFees = new(big.Int).Add(want, big.NewInt(1))
+	if err := VerifyPaidFees(header, txs, receipts); err == nil {
+		t.Error("Expected inflated paid fees to fail")
+	}
+	header.PaidFees = nil
+	if err := VerifyPaidFees(header, txs, receipts); err == nil {
+		t.Error("Expected missing paid fees to fail")
+	}
+	if err := VerifyPaidFees(&BlockHeader{}, nil, nil); err != nil {
+		t.Errorf("Expected an empty block to pay nothing: %v", err)
+	}
+	if _, err := CalculatePaidFees(txs, receipts[:2]); err == nil {
+		t.Error("Expected a missing receipt to fail")
+	}
+}
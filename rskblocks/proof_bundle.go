@@ -0,0 +1,158 @@
+package rskblocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ProofBundleVersion is the encoding version written by ProofBundle.Marshal.
+const ProofBundleVersion = 1
+
+// ProofBundle packages key proofs with the block they were taken at, so one
+// service can produce proofs and another verify them without an ad-hoc
+// format. Keys are trie keys, as derived by a rsktrie.KeyMapper.
+type ProofBundle struct {
+	BlockHash   common.Hash
+	BlockNumber uint64
+	StateRoot   common.Hash
+	Proofs      []KeyProof
+}
+
+// KeyProof is a proof of one trie key.
+type KeyProof struct {
+	Key []byte
+	// Proof is in eth_getProof format (RLP strings of serialized nodes).
+	Proof [][]byte
+}
+
+// NewProofBundle returns an empty bundle for the state of header's block.
+func NewProofBundle(header *BlockHeader) *ProofBundle {
+	b := &ProofBundle{BlockHash: header.Hash(), StateRoot: header.StateRoot}
+	if header.Number != nil {
+		b.BlockNumber = header.Number.Uint64()
+	}
+	return b
+}
+
+// Add appends a proof of key to the bundle.
+func (b *ProofBundle) Add(key []byte, proof [][]byte) {
+	b.Proofs = append(b.Proofs, KeyProof{Key: key, Proof: proof})
+}
+
+// CheckHeader reports whether header is the block the bundle claims to be
+// taken at. Verify alone only ties the proofs to StateRoot; a consumer that
+// trusts header should check the bundle against it first.
+func (b *ProofBundle) CheckHeader(header *BlockHeader) error {
+	if hash := header.Hash(); hash != b.BlockHash {
+		return fmt.Errorf("bundle is for block %s, header hashes to %s", b.BlockHash.Hex(), hash.Hex())
+	}
+	number := header.Number
+	if number == nil {
+		number = new(big.Int)
+	}
+	if !number.IsUint64() || number.Uint64() != b.BlockNumber {
+		return fmt.Errorf("bundle is for block number %d, header has %v", b.BlockNumber, header.Number)
+	}
+	if header.StateRoot != b.StateRoot {
+		return fmt.Errorf("bundle has state root %s, header has %s", b.StateRoot.Hex(), header.StateRoot.Hex())
+	}
+	return nil
+}
+
+// Verify verifies every proof in the bundle against its state root with a
+// default ProofVerifier. See ProofVerifier.VerifyBundle.
+func (b *ProofBundle) Verify() ([]*rsktrie.KeyProofResult, error) {
+	return NewProofVerifier().VerifyBundle(b)
+}
+
+// VerifyBundle verifies every proof in b against b's state root, returning
+// results in proof order. Invalid proofs have Status ProofInvalid, and their
+// errors are joined into the returned error.
+func (v *ProofVerifier) VerifyBundle(b *ProofBundle) ([]*rsktrie.KeyProofResult, error) {
+	results := make([]*rsktrie.KeyProofResult, len(b.Proofs))
+	var errs []error
+	for i, p := range b.Proofs {
+		result, err := v.verifyKey(context.Background(), b.StateRoot, p.Key, p.Proof)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("proof %d: %w", i, err))
+		}
+		results[i] = result
+	}
+	return results, errors.Join(errs...)
+}
+
+// proofBundleRLP is the wire form. As in rsktrie.Witness, nodes are stored as
+// their serialized messages, without the per-node RLP string header.
+type proofBundleRLP struct {
+	BlockHash   common.Hash
+	BlockNumber uint64
+	StateRoot   common.Hash
+	Proofs      []keyProofRLP
+}
+
+type keyProofRLP struct {
+	Key   []byte
+	Nodes [][]byte
+}
+
+// Marshal encodes the bundle as a version byte followed by an RLP list.
+func (b *ProofBundle) Marshal() ([]byte, error) {
+	enc := proofBundleRLP{
+		BlockHash:   b.BlockHash,
+		BlockNumber: b.BlockNumber,
+		StateRoot:   b.StateRoot,
+		Proofs:      make([]keyProofRLP, len(b.Proofs)),
+	}
+	for i, p := range b.Proofs {
+		enc.Proofs[i] = keyProofRLP{Key: p.Key, Nodes: make([][]byte, len(p.Proof))}
+		for j, node := range p.Proof {
+			if err := rlp.DecodeBytes(node, &enc.Proofs[i].Nodes[j]); err != nil {
+				return nil, fmt.Errorf("proof %d node %d: %w", i, j, err)
+			}
+		}
+	}
+	body, err := rlp.EncodeToBytes(&enc)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{ProofBundleVersion}, body...), nil
+}
+
+// UnmarshalProofBundle decodes a bundle written by Marshal. It does not
+// verify it; call Verify for that.
+func UnmarshalProofBundle(data []byte) (*ProofBundle, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty proof bundle")
+	}
+	if data[0] != ProofBundleVersion {
+		return nil, fmt.Errorf("unsupported proof bundle version %d", data[0])
+	}
+	var dec proofBundleRLP
+	if err := rlp.DecodeBytes(data[1:], &dec); err != nil {
+		return nil, fmt.Errorf("decode proof bundle: %w", err)
+	}
+	b := &ProofBundle{
+		BlockHash:   dec.BlockHash,
+		BlockNumber: dec.BlockNumber,
+		StateRoot:   dec.StateRoot,
+		Proofs:      make([]KeyProof, len(dec.Proofs)),
+	}
+	for i, p := range dec.Proofs {
+		b.Proofs[i] = KeyProof{Key: p.Key, Proof: make([][]byte, len(p.Nodes))}
+		for j, node := range p.Nodes {
+			enc, err := rlp.EncodeToBytes(node)
+			if err != nil {
+				return nil, err
+			}
+			b.Proofs[i].Proof[j] = enc
+		}
+	}
+	return b, nil
+}
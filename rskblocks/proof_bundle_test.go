@@ -0,0 +1,60 @@
+package rskblocks
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestProofBundle(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045e71a7a2c50903d88e564cd72fab11e82051")
+	slot, absent := common.Hash{0x01}, common.Hash{0x02}
+	trie := rsktrie.NewTrie(nil).
+		Put(mapper.GetAccountKey(contract), []byte{0x01}).
+		Put(mapper.GetAccountStorageKey(contract, slot), []byte{0x2a})
+	header := &BlockHeader{StateRoot: common.BytesToHash(trie.GetHash()), Number: big.NewInt(4000)}
+
+	bundle := NewProofBundle(header)
+	for _, key := range [][]byte{mapper.GetAccountStorageKey(contract, slot), mapper.GetAccountStorageKey(contract, absent)} {
+		bundle.Add(key, buildTestProof(t, trie, key))
+	}
+
+	data, err := bundle.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := UnmarshalProofBundle(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.CheckHeader(header); err != nil {
+		t.Errorf("Expected the header to match: %v", err)
+	}
+	results, err := decoded.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Status != rsktrie.ProofPresent || !bytes.Equal(results[0].Value, []byte{0x2a}) {
+		t.Errorf("Expected slot 1 present, got %+v", results[0])
+	}
+	if results[1].Status != rsktrie.ProofProvenAbsent {
+		t.Errorf("Expected slot 2 absent, got %+v", results[1])
+	}
+
+	other := &BlockHeader{StateRoot: header.StateRoot, Number: big.NewInt(4001)}
+	if err := decoded.CheckHeader(other); err == nil {
+		t.Error("Expected another block's header to be rejected")
+	}
+	decoded.StateRoot = common.Hash{0x01}
+	if results, err := decoded.Verify(); err == nil || results[0].Status != rsktrie.ProofInvalid {
+		t.Errorf("Expected a wrong root to fail, got %v", err)
+	}
+	if _, err := UnmarshalProofBundle(append([]byte{ProofBundleVersion + 1}, data[1:]...)); err == nil {
+		t.Error("Expected an unknown version to be rejected")
+	}
+}
@@ -24,6 +24,8 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -123,6 +125,36 @@ func (c *ProofClient) GetProof(
 	return &result, nil
 }
 
+// ChainID calls eth_chainId and returns the node's chain ID (30 on mainnet,
+// 31 on testnet).
+func (c *ProofClient) ChainID(ctx context.Context) (uint64, error) {
+	var id hexutil.Uint64
+	if err := c.rpc.CallContext(ctx, &id, "eth_chainId"); err != nil {
+		return 0, fmt.Errorf("eth_chainId RPC call failed: %w", err)
+	}
+	return uint64(id), nil
+}
+
+// GetProofForAddress is like GetProof but takes the address as hex text and
+// validates its RSKIP-60 checksum against the node's chain ID first, so an
+// address checksummed for another network is rejected.
+func (c *ProofClient) GetProofForAddress(
+	ctx context.Context,
+	address string,
+	storageKeys []common.Hash,
+	blockRef string,
+) (*ProofResponse, error) {
+	chainID, err := c.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := rsktrie.ParseAddress(address, chainID)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetProof(ctx, addr, storageKeys, blockRef)
+}
+
 // GetRSKProof calls rsk_getProof on the RSKj node for RSK-native proof format.
 // This endpoint returns proofs in RSK's native unified trie format.
 func (c *ProofClient) GetRSKProof(
@@ -178,7 +210,7 @@ func (c *ProofClient) GetAndVerifyAccountProof(
 	}
 
 	// Verify the proof
-	result, err := c.verifier.VerifyAccountProof(stateRoot, address, proofNodes)
+	result, err := c.verifier.VerifyAccountProofContext(ctx, stateRoot, address, proofNodes)
 	if err != nil {
 		return nil, fmt.Errorf("proof verification error: %w", err)
 	}
@@ -235,7 +267,7 @@ func (c *ProofClient) GetAndVerifyStorageProof(
 	}
 
 	// Verify the proof
-	result, err := c.verifier.VerifyStorageProof(stateRoot, address, storageKey, proofNodes)
+	result, err := c.verifier.VerifyStorageProofContext(ctx, stateRoot, address, storageKey, proofNodes)
 	if err != nil {
 		return nil, fmt.Errorf("storage proof verification error: %w", err)
 	}
@@ -289,7 +321,7 @@ func (c *ProofClient) GetAndVerifyFullProof(
 		return nil, fmt.Errorf("failed to decode account proof nodes: %w", err)
 	}
 
-	accountResult, err := c.verifier.VerifyAccountProof(stateRoot, address, accountProofNodes)
+	accountResult, err := c.verifier.VerifyAccountProofContext(ctx, stateRoot, address, accountProofNodes)
 	if err != nil {
 		return nil, fmt.Errorf("account proof verification error: %w", err)
 	}
@@ -307,7 +339,7 @@ func (c *ProofClient) GetAndVerifyFullProof(
 			return nil, fmt.Errorf("failed to decode storage proof nodes for key %s: %w", sp.Key, err)
 		}
 
-		storageResult, err := c.verifier.VerifyStorageProof(stateRoot, address, keyHash, proofNodes)
+		storageResult, err := c.verifier.VerifyStorageProofContext(ctx, stateRoot, address, keyHash, proofNodes)
 		if err != nil {
 			return nil, fmt.Errorf("storage proof verification error for key %s: %w", sp.Key, err)
 		}
@@ -355,3 +387,23 @@ func (p *ProofResponse) GetStorageValue(key common.Hash) *big.Int {
 	}
 	return nil
 }
+
+// MultiproofNodes decodes the account proof and every storage proof in the
+// response and merges them into one deduplicated node set, for
+// ProofVerifier.VerifyStorageMultiproof or caching.
+func (p *ProofResponse) MultiproofNodes() ([][]byte, error) {
+	proofs := make([][][]byte, 0, len(p.StorageProof)+1)
+	accountNodes, err := DecodeRLPProofNodes(p.AccountProof)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode account proof nodes: %w", err)
+	}
+	proofs = append(proofs, accountNodes)
+	for _, sp := range p.StorageProof {
+		nodes, err := DecodeRLPProofNodes(sp.Proofs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode storage proof nodes for %s: %w", sp.Key, err)
+		}
+		proofs = append(proofs, nodes)
+	}
+	return rsktrie.MergeProofNodes(proofs...), nil
+}
@@ -3,6 +3,7 @@ package rskblocks
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"math/big"
 	"net/http"
 	"net/http/httptest"
@@ -424,3 +425,51 @@ func TestIntegration_GetAndVerifyAccountProof(t *testing.T) {
 	t.Logf("IsContract: %v", proof.IsContract())
 	t.Logf("AccountProof nodes: %d", len(proof.AccountProof))
 }
+
+// TestGetProofForAddress_Checksum tests that addresses are validated against the node's chain ID
+func TestGetProofForAddress_Checksum(t *testing.T) {
+	var proofCalls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+			ID     int    `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+			return
+		}
+
+		var result string
+		switch req.Method {
+		case "eth_chainId":
+			result = `"0x1e"`
+		case "eth_getProof":
+			proofCalls++
+			result = `{"address": "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "accountProof": [], "nonce": "0x0", "storageProof": []}`
+		default:
+			t.Errorf("Unexpected method %s", req.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": %d, "result": %s}`, req.ID, result)
+	}))
+	defer server.Close()
+
+	client, err := NewProofClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if _, err := client.GetProofForAddress(ctx, "0x5aaEB6053f3e94c9b9a09f33669435E7ef1bEAeD", nil, "latest"); err != nil {
+		t.Fatalf("GetProofForAddress failed for mainnet checksum: %v", err)
+	}
+
+	// Testnet checksum on a mainnet node.
+	if _, err := client.GetProofForAddress(ctx, "0x5aAeb6053F3e94c9b9A09F33669435E7EF1BEaEd", nil, "latest"); err == nil {
+		t.Error("Expected testnet checksum to be rejected by a mainnet node")
+	}
+	if proofCalls != 1 {
+		t.Errorf("Expected 1 eth_getProof call, got %d", proofCalls)
+	}
+}
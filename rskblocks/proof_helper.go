@@ -12,53 +12,145 @@
 //
 // Account key: DomainPrefix(0x00) + SecureKeyPrefix(keccak256(address)[:10]) + address
 // Storage key: AccountKey + StoragePrefix(0x00) + SecureKeyPrefix(keccak256(slot)[:10]) + stripLeadingZeros(slot)
+// (slot 0 keeps a single 0x00 byte, as in rskj)
 // Code key: AccountKey + CodePrefix(0x80)
 //
 // # Usage Example
 //
 //	verifier := rskblocks.NewProofVerifier()
 //	result, err := verifier.VerifyAccountProof(stateRoot, address, proofNodes)
-//	if result.Valid {
+//	switch result.Status {
+//	case rsktrie.ProofPresent:
 //	    fmt.Println("Account exists with value:", result.Value)
+//	case rsktrie.ProofProvenAbsent:
+//	    fmt.Println("Account does not exist")
+//	default:
+//	    fmt.Println("Proof invalid:", result.Error)
 //	}
 package rskblocks
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"math/big"
 
 	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/rlp"
 )
 
+// ErrInvalidArgument is returned for a check rejected before its proof is
+// verified.
+var ErrInvalidArgument = errors.New("invalid argument")
+
 // ProofVerifier verifies Merkle proofs from eth_getProof for RSK's binary trie
 type ProofVerifier struct {
-	keyMapper *rsktrie.TrieKeyMapper
+	keyMapper rsktrie.KeyMapper
+	config    rsktrie.ProofConfig
+	cache     ResultCache
+	logf      func(format string, args ...any)
 }
 
-// NewProofVerifier creates a new proof verifier for RSK state proofs
-func NewProofVerifier() *ProofVerifier {
-	return &ProofVerifier{
+// NewProofVerifier creates a new proof verifier for RSK state proofs. By
+// default it uses the unitrie key scheme and rsktrie.DefaultProofLimits;
+// opts change that.
+func NewProofVerifier(opts ...Option) *ProofVerifier {
+	v := &ProofVerifier{
 		keyMapper: rsktrie.NewTrieKeyMapper(),
+		config:    rsktrie.ProofConfig{Limits: rsktrie.DefaultProofLimits},
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// NewProofVerifierWithKeyMapper creates a proof verifier that derives trie keys
+// with keyMapper, e.g. one from KeyMapperForBlockNumber for historical blocks.
+// With an Orchid mapper, storage proofs verify against the contract's storage
+// root rather than the state root. It is NewProofVerifier(WithKeyMapper(keyMapper)).
+func NewProofVerifierWithKeyMapper(keyMapper rsktrie.KeyMapper) *ProofVerifier {
+	return NewProofVerifier(WithKeyMapper(keyMapper))
+}
+
+// NewProofVerifierForBlock creates a proof verifier for state at blockNum on
+// network, using the key scheme and node format of that block's era.
+func NewProofVerifierForBlock(blockNum uint64, network string) *ProofVerifier {
+	return NewProofVerifierWithKeyMapper(KeyMapperForBlockNumber(blockNum, network))
+}
+
+// KeyMapperForBlockNumber returns the key mapper for state at blockNum on
+// network. The unitrie was activated with orchid (mainnet 729000; testnet and
+// regtest from genesis); see ChainConfig.KeyMapperVersion.
+func KeyMapperForBlockNumber(blockNum uint64, network string) rsktrie.KeyMapper {
+	if ChainConfigForNetwork(network).KeyMapperVersion(blockNum) == rsktrie.KeyMapperOrchid {
+		return rsktrie.NewOrchidKeyMapper()
 	}
+	return rsktrie.NewTrieKeyMapper()
+}
+
+// verifyKey verifies a key proof with the verifier's configuration,
+// consulting and filling the result cache if there is one.
+func (v *ProofVerifier) verifyKey(ctx context.Context, root common.Hash, key []byte, proofNodes [][]byte) (*rsktrie.KeyProofResult, error) {
+	if v.cache != nil {
+		if cached, ok := v.cache.Get(root, key); ok {
+			result := *cached
+			return &result, nil
+		}
+	}
+	result, err := v.config.VerifyKeyProofContext(ctx, root[:], key, proofNodes)
+	if err != nil {
+		if v.logf != nil && ctx.Err() == nil {
+			v.logf("proof for key %s against root %x is invalid: %v", rsktrie.FormatKey(key), root, err)
+		}
+		return result, err
+	}
+	if v.cache != nil {
+		cached := *result
+		v.cache.Add(root, key, &cached)
+	}
+	return result, nil
 }
 
 // AccountProofResult contains the result of account proof verification
 type AccountProofResult struct {
-	Valid   bool           // Whether the proof is valid
-	Address common.Address // The verified address
-	Value   []byte         // RLP-encoded account state (nonce, balance)
-	Error   error          // Error if verification failed
+	Valid   bool                // Whether the proof is valid
+	Status  rsktrie.ProofStatus // Present, ProvenAbsent or Invalid
+	Address common.Address      // The verified address
+	Exists  bool                // Proven present; false if proven absent or invalid
+	Value   []byte              // RLP-encoded account state (nonce, balance)
+	Nonce   *big.Int            // Decoded nonce; zero if absent, nil if invalid
+	Balance *big.Int            // Decoded balance; zero if absent, nil if invalid
+	Error   error               // Error if verification failed
+
+	// ValueHash is set instead of Value when the proof commits to the
+	// account record by hash only, as Orchid proofs always do. Nonce and
+	// Balance are then nil; see VerifyAccountProofWithValue.
+	ValueHash []byte
+	// StorageRoot is the root of the contract's storage trie, for Orchid
+	// records; storage proofs of that era verify against it.
+	StorageRoot common.Hash
+	// Audit records the nodes and key bits the result rests on.
+	Audit *rsktrie.ProofAudit
+}
+
+// BalanceCoin returns the verified balance as a Coin; zero if the balance
+// was not decoded.
+func (r *AccountProofResult) BalanceCoin() Coin {
+	return NewCoin(r.Balance)
 }
 
 // StorageProofResult contains the result of storage proof verification
 type StorageProofResult struct {
-	Valid      bool        // Whether the proof is valid
-	StorageKey common.Hash // The verified storage key
-	Value      []byte      // The storage value
-	Error      error       // Error if verification failed
+	Valid      bool                // Whether the proof is valid
+	Status     rsktrie.ProofStatus // Present, ProvenAbsent or Invalid
+	StorageKey common.Hash         // The verified storage key
+	Value      []byte              // The storage value; nil for a long value
+	ValueHash  []byte              // keccak256 of a long value (over 32 bytes)
+	Audit      *rsktrie.ProofAudit // Nodes and key bits the result rests on
+	Error      error               // Error if verification failed
 }
 
 // VerifyAccountProof verifies an account proof against a state root.
@@ -74,25 +166,102 @@ func (v *ProofVerifier) VerifyAccountProof(
 	stateRoot common.Hash,
 	address common.Address,
 	proofNodes [][]byte,
+) (*AccountProofResult, error) {
+	return v.VerifyAccountProofContext(context.Background(), stateRoot, address, proofNodes)
+}
+
+// VerifyAccountProofContext is VerifyAccountProof bounded by ctx: once ctx is
+// done it returns ctx.Err() rather than a result.
+func (v *ProofVerifier) VerifyAccountProofContext(
+	ctx context.Context,
+	stateRoot common.Hash,
+	address common.Address,
+	proofNodes [][]byte,
 ) (*AccountProofResult, error) {
 	// Generate the trie key for this account
 	trieKey := v.keyMapper.GetAccountKey(address)
 
 	// Verify the proof path
-	value, err := v.verifyProof(stateRoot[:], trieKey, proofNodes)
+	result, err := v.verifyKey(ctx, stateRoot, trieKey, proofNodes)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
 	if err != nil {
 		return &AccountProofResult{
 			Valid:   false,
+			Status:  rsktrie.ProofInvalid,
 			Address: address,
 			Error:   err,
 		}, nil
 	}
 
+	return accountProofResult(address, result), nil
+}
+
+// VerifyAccountProofWithValue verifies an account proof whose record is
+// committed by hash only, checking record, the RLP-encoded account state
+// supplied out of band, against it. On success the result is decoded as for
+// a short record; a mismatch makes the result invalid.
+func (v *ProofVerifier) VerifyAccountProofWithValue(
+	stateRoot common.Hash,
+	address common.Address,
+	record []byte,
+	proofNodes [][]byte,
+) (*AccountProofResult, error) {
+	trieKey := v.keyMapper.GetAccountKey(address)
+	result, err := v.verifyKey(context.Background(), stateRoot, trieKey, proofNodes)
+	if err == nil {
+		err = result.ResolveValue(record)
+	}
+	if err != nil {
+		return &AccountProofResult{
+			Valid:   false,
+			Status:  rsktrie.ProofInvalid,
+			Address: address,
+			Error:   err,
+		}, nil
+	}
+	return accountProofResult(address, result), nil
+}
+
+// accountProofResult builds the result for a verified account key, decoding
+// the account record unless it is committed by hash only. A record that does
+// not decode makes the proof invalid.
+func accountProofResult(address common.Address, result *rsktrie.KeyProofResult) *AccountProofResult {
+	if result.HasLongValue() {
+		return &AccountProofResult{
+			Valid:     true,
+			Status:    result.Status,
+			Address:   address,
+			Exists:    true,
+			ValueHash: result.ValueHash,
+			Audit:     result.Audit,
+		}
+	}
+
+	// Decode the account record (nonce, balance)
+	state, err := result.AccountState()
+	if err != nil {
+		return &AccountProofResult{
+			Valid:   false,
+			Status:  rsktrie.ProofInvalid,
+			Address: address,
+			Value:   result.Value,
+			Error:   err,
+		}
+	}
+
 	return &AccountProofResult{
-		Valid:   true,
-		Address: address,
-		Value:   value,
-	}, nil
+		Valid:       true,
+		Status:      result.Status,
+		Address:     address,
+		Exists:      result.Status == rsktrie.ProofPresent,
+		Value:       result.Value,
+		Nonce:       state.Nonce,
+		Balance:     state.Balance,
+		StorageRoot: state.StorageRoot,
+		Audit:       result.Audit,
+	}
 }
 
 // VerifyStorageProof verifies a storage proof for a contract.
@@ -112,26 +281,79 @@ func (v *ProofVerifier) VerifyStorageProof(
 	address common.Address,
 	storageKey common.Hash,
 	proofNodes [][]byte,
+) (*StorageProofResult, error) {
+	return v.VerifyStorageProofContext(context.Background(), stateRoot, address, storageKey, proofNodes)
+}
+
+// VerifyStorageProofContext is VerifyStorageProof bounded by ctx: once ctx is
+// done it returns ctx.Err() rather than a result.
+func (v *ProofVerifier) VerifyStorageProofContext(
+	ctx context.Context,
+	stateRoot common.Hash,
+	address common.Address,
+	storageKey common.Hash,
+	proofNodes [][]byte,
 ) (*StorageProofResult, error) {
 	// In RSK, storage is in the unified trie
 	// Key: accountKey + storagePrefix + secureKey(slot) + slot
 	trieKey := v.keyMapper.GetAccountStorageKey(address, storageKey)
 
 	// Verify the proof path
-	value, err := v.verifyProof(stateRoot[:], trieKey, proofNodes)
+	result, err := v.verifyKey(ctx, stateRoot, trieKey, proofNodes)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
 	if err != nil {
 		return &StorageProofResult{
 			Valid:      false,
+			Status:     rsktrie.ProofInvalid,
 			StorageKey: storageKey,
 			Error:      err,
 		}, nil
 	}
 
-	return &StorageProofResult{
+	return storageProofResult(storageKey, result), nil
+}
+
+// VerifyStorageProofWithValue verifies a storage proof whose value is long
+// (over 32 bytes) and so only committed to by hash, checking value, supplied
+// out of band, against the proven hash and length. On success the result's
+// Value is value; a mismatch makes the result invalid.
+func (v *ProofVerifier) VerifyStorageProofWithValue(
+	stateRoot common.Hash,
+	address common.Address,
+	storageKey common.Hash,
+	value []byte,
+	proofNodes [][]byte,
+) (*StorageProofResult, error) {
+	trieKey := v.keyMapper.GetAccountStorageKey(address, storageKey)
+	result, err := v.verifyKey(context.Background(), stateRoot, trieKey, proofNodes)
+	if err == nil {
+		err = result.ResolveValue(value)
+	}
+	if err != nil {
+		return &StorageProofResult{
+			Valid:      false,
+			Status:     rsktrie.ProofInvalid,
+			StorageKey: storageKey,
+			Error:      err,
+		}, nil
+	}
+	return storageProofResult(storageKey, result), nil
+}
+
+func storageProofResult(storageKey common.Hash, result *rsktrie.KeyProofResult) *StorageProofResult {
+	r := &StorageProofResult{
 		Valid:      true,
+		Status:     result.Status,
 		StorageKey: storageKey,
-		Value:      value,
-	}, nil
+		Value:      result.Value,
+		Audit:      result.Audit,
+	}
+	if result.HasLongValue() {
+		r.ValueHash = result.ValueHash
+	}
+	return r
 }
 
 // VerifyStorageValue verifies a storage proof and checks the expected value
@@ -152,124 +374,59 @@ func (v *ProofVerifier) VerifyStorageValue(
 	return bytes.Equal(result.Value, expectedValue), nil
 }
 
-// verifyProof walks through the proof nodes and verifies the path
-func (v *ProofVerifier) verifyProof(expectedHash []byte, key []byte, proofNodes [][]byte) ([]byte, error) {
-	if len(proofNodes) == 0 {
-		return nil, fmt.Errorf("empty proof")
+// VerifyBalanceAtLeast verifies an account proof and checks that the
+// account's balance is at least minBalance. It returns the proven balance
+// (zero for an absent account) and whether the check holds; an invalid proof
+// is an error, as is a nil or negative minBalance (ErrInvalidArgument).
+func (v *ProofVerifier) VerifyBalanceAtLeast(
+	stateRoot common.Hash,
+	address common.Address,
+	minBalance *big.Int,
+	proofNodes [][]byte,
+) (*big.Int, bool, error) {
+	if minBalance == nil || minBalance.Sign() < 0 {
+		return nil, false, fmt.Errorf("%w: minimum balance %v", ErrInvalidArgument, minBalance)
 	}
-
-	// RSK proof nodes are RLP-encoded. The hash is Keccak256 of the serialized (not RLP) content.
-	// Proof order is leaf-to-root (last node is root).
-	type nodeEntry struct {
-		node           *rsktrie.Trie
-		serializedHash []byte
+	result, err := v.VerifyAccountProof(stateRoot, address, proofNodes)
+	if err != nil {
+		return nil, false, err
 	}
-	nodeMap := make(map[string]nodeEntry)
-
-	for i, rlpNode := range proofNodes {
-		// RLP decode to get serialized node
-		var serializedNode []byte
-		if err := rlp.DecodeBytes(rlpNode, &serializedNode); err != nil {
-			return nil, fmt.Errorf("failed to RLP decode proof node %d: %w", i, err)
-		}
-
-		// Hash of serialized content
-		nodeHash := rsktrie.Keccak256(serializedNode)
-
-		// Parse the node
-		node, err := rsktrie.FromMessage(serializedNode, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse proof node %d: %w", i, err)
-		}
-
-		nodeMap[string(nodeHash)] = nodeEntry{node: node, serializedHash: nodeHash}
-	}
-
-	// Convert key to bit representation for traversal
-	keySlice := rsktrie.TrieKeySliceFromKey(key)
-
-	// Find the root node (should match expectedHash)
-	rootEntry, ok := nodeMap[string(expectedHash)]
-	if !ok {
-		return nil, fmt.Errorf("root hash %x not found in proof nodes", expectedHash)
-	}
-	currentNode := rootEntry.node
-
-	// Walk the path
-	keyPos := 0
-	for {
-		// Check shared path
-		sharedPath := currentNode.GetSharedPath()
-		if sharedPath.Length() > 0 {
-			// Verify shared path matches
-			remaining := keySlice.Length() - keyPos
-			if remaining < sharedPath.Length() {
-				return nil, fmt.Errorf("key too short for shared path at position %d", keyPos)
-			}
-
-			for i := 0; i < sharedPath.Length(); i++ {
-				keyBit := keySlice.Get(keyPos + i)
-				pathBit := sharedPath.Get(i)
-				if keyBit != pathBit {
-					// Key diverges from path - value doesn't exist
-					return nil, nil
-				}
-			}
-			keyPos += sharedPath.Length()
-		}
-
-		// Check if we've consumed the entire key
-		if keyPos >= keySlice.Length() {
-			// Found the node - return its value
-			return currentNode.GetValue(), nil
-		}
-
-		// Get next bit and follow child
-		nextBit := keySlice.Get(keyPos)
-		keyPos++
-
-		var childRef *rsktrie.NodeReference
-		if nextBit == 0 {
-			childRef = currentNode.GetLeft()
-		} else {
-			childRef = currentNode.GetRight()
-		}
-
-		if childRef.IsEmpty() {
-			// No child - value doesn't exist
-			return nil, nil
-		}
-
-		// Get child hash
-		childHash := childRef.GetHash()
-		if childHash == nil {
-			// Embedded node - get directly
-			childNode := childRef.GetNode()
-			if childNode == nil {
-				return nil, fmt.Errorf("missing embedded child node")
-			}
-			currentNode = childNode
-			continue
-		}
+	if !result.Valid {
+		return nil, false, result.Error
+	}
+	if result.Balance == nil {
+		return nil, false, fmt.Errorf("account record of %s is committed by hash only", address)
+	}
+	return result.Balance, result.Balance.Cmp(minBalance) >= 0, nil
+}
 
-		// Look up child in proof nodes
-		childEntry, ok := nodeMap[string(childHash)]
-		if !ok {
-			return nil, fmt.Errorf("missing proof node for hash %x", childHash)
-		}
-		currentNode = childEntry.node
+// VerifyNonceEquals verifies an account proof and checks that the account's
+// nonce is nonce. It returns the proven nonce (zero for an absent account)
+// and whether the check holds; an invalid proof is an error.
+func (v *ProofVerifier) VerifyNonceEquals(
+	stateRoot common.Hash,
+	address common.Address,
+	nonce uint64,
+	proofNodes [][]byte,
+) (*big.Int, bool, error) {
+	result, err := v.VerifyAccountProof(stateRoot, address, proofNodes)
+	if err != nil {
+		return nil, false, err
 	}
+	if !result.Valid {
+		return nil, false, result.Error
+	}
+	if result.Nonce == nil {
+		return nil, false, fmt.Errorf("account record of %s is committed by hash only", address)
+	}
+	return result.Nonce, result.Nonce.IsUint64() && result.Nonce.Uint64() == nonce, nil
 }
 
 // DecodeRLPProofNodes decodes hex-encoded RLP proof nodes from eth_getProof response
 func DecodeRLPProofNodes(hexNodes []string) ([][]byte, error) {
 	nodes := make([][]byte, len(hexNodes))
 	for i, hexNode := range hexNodes {
-		// Remove 0x prefix if present
-		if len(hexNode) >= 2 && hexNode[:2] == "0x" {
-			hexNode = hexNode[2:]
-		}
-		node, err := hexDecode(hexNode)
+		node, err := decodeProofNode(hexNode)
 		if err != nil {
 			return nil, fmt.Errorf("decode proof node %d: %w", i, err)
 		}
@@ -278,6 +435,15 @@ func DecodeRLPProofNodes(hexNodes []string) ([][]byte, error) {
 	return nodes, nil
 }
 
+// decodeProofNode decodes one hex-encoded proof node, with or without 0x.
+func decodeProofNode(hexNode string) ([]byte, error) {
+	// Remove 0x prefix if present
+	if len(hexNode) >= 2 && hexNode[:2] == "0x" {
+		hexNode = hexNode[2:]
+	}
+	return hexDecode(hexNode)
+}
+
 // hexDecode decodes a hex string to bytes
 func hexDecode(s string) ([]byte, error) {
 	if len(s)%2 != 0 {
@@ -311,3 +477,40 @@ func hexByte(s string) (byte, error) {
 	}
 	return result, nil
 }
+
+// VerifyStorageMultiproof verifies several storage slots of address against a
+// single deduplicated node set (see rsktrie.MergeProofNodes), decoding each
+// node once. Results are in storageKeys order and report failures per slot as
+// VerifyStorageProof does; an error is returned only if the node set cannot
+// be decoded or exceeds the verifier's limits.
+func (v *ProofVerifier) VerifyStorageMultiproof(
+	stateRoot common.Hash,
+	address common.Address,
+	storageKeys []common.Hash,
+	proofNodes [][]byte,
+) ([]*StorageProofResult, error) {
+	nodes, err := v.config.DecodeProofNodes(proofNodes)
+	if err != nil {
+		if v.logf != nil {
+			v.logf("storage multiproof against root %x is invalid: %v", stateRoot, err)
+		}
+		return nil, err
+	}
+
+	results := make([]*StorageProofResult, len(storageKeys))
+	for i, storageKey := range storageKeys {
+		trieKey := v.keyMapper.GetAccountStorageKey(address, storageKey)
+		result, err := v.config.VerifyNodeSet(nodes, stateRoot[:], trieKey)
+		if err != nil && v.logf != nil {
+			v.logf("proof for key %s against root %x is invalid: %v", rsktrie.FormatKey(trieKey), stateRoot, err)
+		}
+		results[i] = &StorageProofResult{
+			Valid:      err == nil,
+			Status:     result.Status,
+			StorageKey: storageKey,
+			Value:      result.Value,
+			Error:      err,
+		}
+	}
+	return results, nil
+}
@@ -1,8 +1,15 @@
 package rskblocks
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
 	"testing"
 
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -52,6 +59,24 @@ func TestNewProofVerifier(t *testing.T) {
 	}
 }
 
+func TestKeyMapperForBlockNumber(t *testing.T) {
+	if v := KeyMapperForBlockNumber(728999, "mainnet").Version(); v != rsktrie.KeyMapperOrchid {
+		t.Errorf("Expected orchid mapper before activation, got %s", v)
+	}
+	if v := KeyMapperForBlockNumber(729000, "mainnet").Version(); v != rsktrie.KeyMapperUnitrie {
+		t.Errorf("Expected unitrie mapper at activation, got %s", v)
+	}
+	if v := KeyMapperForBlockNumber(0, "testnet").Version(); v != rsktrie.KeyMapperUnitrie {
+		t.Errorf("Expected unitrie mapper on testnet genesis, got %s", v)
+	}
+
+	address := common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
+	orchid := KeyMapperForBlockNumber(1, "mainnet")
+	if len(orchid.GetAccountKey(address)) != 32 || orchid.GetCodeKey(address) != nil {
+		t.Error("Expected orchid account key to be keccak256(address) and no code key")
+	}
+}
+
 func TestVerifyAccountProof_EmptyProof(t *testing.T) {
 	verifier := NewProofVerifier()
 
@@ -98,3 +123,192 @@ func TestVerifyAccountProof_RealData(t *testing.T) {
 	verifier := NewProofVerifier()
 	_ = verifier // Would use with real data
 }
+
+func TestVerifyAccountProof_Status(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	present := common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
+	missing := common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051")
+
+	account, err := (&rsktrie.AccountState{Nonce: big.NewInt(1), Balance: big.NewInt(2)}).Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	trie := rsktrie.NewTrie(nil).
+		Put(mapper.GetAccountKey(present), account).
+		Put(mapper.GetAccountKey(common.HexToAddress("0x01")), []byte{0x03})
+	stateRoot := common.BytesToHash(trie.GetHash())
+	verifier := NewProofVerifier()
+
+	result, err := verifier.VerifyAccountProof(stateRoot, present, buildTestProof(t, trie, mapper.GetAccountKey(present)))
+	if err != nil || result.Status != rsktrie.ProofPresent || !result.Valid || !result.Exists {
+		t.Fatalf("Expected present, got %s, %v", result.Status, err)
+	}
+
+	result, err = verifier.VerifyAccountProof(stateRoot, missing, buildTestProof(t, trie, mapper.GetAccountKey(missing)))
+	if err != nil || result.Status != rsktrie.ProofProvenAbsent || !result.Valid || result.Exists {
+		t.Fatalf("Expected proven absent, got %s, %v", result.Status, err)
+	}
+	if result.Value != nil {
+		t.Errorf("Expected no value for absent account, got %x", result.Value)
+	}
+	if result.Balance.Sign() != 0 || result.Nonce.Sign() != 0 {
+		t.Errorf("Expected zero balance and nonce for absent account, got %s, %s", result.Balance, result.Nonce)
+	}
+
+	result, err = verifier.VerifyAccountProof(common.Hash{}, present, buildTestProof(t, trie, mapper.GetAccountKey(present)))
+	if err != nil || result.Status != rsktrie.ProofInvalid || result.Valid || result.Exists {
+		t.Fatalf("Expected invalid, got %s, %v", result.Status, err)
+	}
+}
+
+func TestVerifyStorageMultiproof(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051")
+
+	trie := rsktrie.NewTrie(nil).Put(mapper.GetAccountKey(contract), []byte{0x01})
+	var slots []common.Hash
+	for i := 0; i < 10; i++ {
+		slot := common.BigToHash(big.NewInt(int64(i)))
+		slots = append(slots, slot)
+		trie = trie.Put(mapper.GetAccountStorageKey(contract, slot), []byte{byte(i + 1)})
+	}
+	slots = append(slots, common.BigToHash(big.NewInt(99)))
+	stateRoot := common.BytesToHash(trie.GetHash())
+
+	var proofs [][][]byte
+	for _, slot := range slots {
+		proofs = append(proofs, buildTestProof(t, trie, mapper.GetAccountStorageKey(contract, slot)))
+	}
+	verifier := NewProofVerifier()
+	results, err := verifier.VerifyStorageMultiproof(stateRoot, contract, slots, rsktrie.MergeProofNodes(proofs...))
+	if err != nil {
+		t.Fatalf("VerifyStorageMultiproof failed: %v", err)
+	}
+	for i, r := range results[:10] {
+		if !r.Valid || r.Status != rsktrie.ProofPresent || !bytes.Equal(r.Value, []byte{byte(i + 1)}) {
+			t.Errorf("Slot %d: unexpected result %+v", i, r)
+		}
+	}
+	if r := results[10]; !r.Valid || r.Status != rsktrie.ProofProvenAbsent {
+		t.Errorf("Expected slot 99 proven absent, got %+v", r)
+	}
+}
+
+func TestVerifyStorageMultiproofLimits(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051")
+
+	trie := rsktrie.NewTrie(nil).Put(mapper.GetAccountKey(contract), []byte{0x01})
+	var slots []common.Hash
+	var proofs [][][]byte
+	for i := 0; i < 10; i++ {
+		slot := common.BigToHash(big.NewInt(int64(i)))
+		slots = append(slots, slot)
+		trie = trie.Put(mapper.GetAccountStorageKey(contract, slot), []byte{byte(i + 1)})
+	}
+	for _, slot := range slots {
+		proofs = append(proofs, buildTestProof(t, trie, mapper.GetAccountStorageKey(contract, slot)))
+	}
+	stateRoot := common.BytesToHash(trie.GetHash())
+	nodes := rsktrie.MergeProofNodes(proofs...)
+
+	for _, limits := range []rsktrie.ProofLimits{
+		{MaxNodes: len(nodes) - 1},
+		{MaxProofBytes: len(nodes[0])},
+	} {
+		verifier := NewProofVerifier(WithLimits(limits))
+		var perr *rsktrie.ProofError
+		if _, err := verifier.VerifyStorageMultiproof(stateRoot, contract, slots, nodes); !errors.As(err, &perr) {
+			t.Errorf("Limits %+v: expected a *ProofError, got %v", limits, err)
+		}
+	}
+
+	verifier := NewProofVerifier(WithLimits(rsktrie.ProofLimits{MaxDepth: 2}))
+	results, err := verifier.VerifyStorageMultiproof(stateRoot, contract, slots, nodes)
+	if err != nil {
+		t.Fatalf("VerifyStorageMultiproof failed: %v", err)
+	}
+	for i, r := range results {
+		var perr *rsktrie.ProofError
+		if r.Valid || !errors.As(r.Error, &perr) || !strings.Contains(perr.Reason, "depth limit") {
+			t.Errorf("Slot %d: expected the depth limit error, got %+v", i, r)
+		}
+	}
+}
+
+func TestVerifyBalanceAndNonce(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	holder := common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
+	missing := common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051")
+
+	account, err := (&rsktrie.AccountState{Nonce: big.NewInt(9), Balance: big.NewInt(5000)}).Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	trie := rsktrie.NewTrie(nil).
+		Put(mapper.GetAccountKey(holder), account).
+		Put(mapper.GetAccountKey(common.HexToAddress("0x01")), account)
+	stateRoot := common.BytesToHash(trie.GetHash())
+	verifier := NewProofVerifier()
+	proof := buildTestProof(t, trie, mapper.GetAccountKey(holder))
+
+	for _, tt := range []struct {
+		min  int64
+		want bool
+	}{{4999, true}, {5000, true}, {5001, false}} {
+		balance, ok, err := verifier.VerifyBalanceAtLeast(stateRoot, holder, big.NewInt(tt.min), proof)
+		if err != nil || ok != tt.want || balance.Int64() != 5000 {
+			t.Errorf("VerifyBalanceAtLeast(%d) = %v, %v, %v", tt.min, balance, ok, err)
+		}
+	}
+
+	nonce, ok, err := verifier.VerifyNonceEquals(stateRoot, holder, 9, proof)
+	if err != nil || !ok || nonce.Int64() != 9 {
+		t.Errorf("VerifyNonceEquals(9) = %v, %v, %v", nonce, ok, err)
+	}
+	if _, ok, _ := verifier.VerifyNonceEquals(stateRoot, holder, 8, proof); ok {
+		t.Error("Expected nonce 8 not to match")
+	}
+
+	// An absent account has zero balance and nonce.
+	absent := buildTestProof(t, trie, mapper.GetAccountKey(missing))
+	if balance, ok, err := verifier.VerifyBalanceAtLeast(stateRoot, missing, big.NewInt(1), absent); err != nil || ok || balance.Sign() != 0 {
+		t.Errorf("Absent account: %v, %v, %v", balance, ok, err)
+	}
+	if _, ok, err := verifier.VerifyNonceEquals(stateRoot, missing, 0, absent); err != nil || !ok {
+		t.Errorf("Absent account nonce: %v, %v", ok, err)
+	}
+
+	if _, _, err := verifier.VerifyBalanceAtLeast(common.Hash{}, holder, big.NewInt(1), proof); err == nil {
+		t.Error("Expected error against wrong state root")
+	}
+	for _, bad := range []*big.Int{nil, big.NewInt(-1)} {
+		if _, ok, err := verifier.VerifyBalanceAtLeast(stateRoot, holder, bad, proof); ok || !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("VerifyBalanceAtLeast(%v) = %v, %v, expected ErrInvalidArgument", bad, ok, err)
+		}
+	}
+}
+
+func TestVerifyProofContext(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051")
+	slot := common.Hash{0x01}
+	trie := rsktrie.NewTrie(nil).Put(mapper.GetAccountStorageKey(contract, slot), []byte{0x2a})
+	stateRoot := common.BytesToHash(trie.GetHash())
+	proof := buildTestProof(t, trie, mapper.GetAccountStorageKey(contract, slot))
+	verifier := NewProofVerifier()
+
+	result, err := verifier.VerifyStorageProofContext(context.Background(), stateRoot, contract, slot, proof)
+	if err != nil || !result.Valid {
+		t.Fatalf("Expected valid result, got %+v, %v", result, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if result, err := verifier.VerifyStorageProofContext(ctx, stateRoot, contract, slot, proof); !errors.Is(err, context.Canceled) || result != nil {
+		t.Errorf("Expected context.Canceled, got %+v, %v", result, err)
+	}
+	if _, err := verifier.VerifyAccountProofContext(ctx, stateRoot, contract, proof); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
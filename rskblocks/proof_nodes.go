@@ -0,0 +1,42 @@
+package rskblocks
+
+import (
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ProofTrieNode is a proof node parsed into a trie node.
+type ProofTrieNode struct {
+	Hash common.Hash   // keccak256 of the serialized node, as parents reference it
+	Node *rsktrie.Trie // The parsed node
+}
+
+// DecodeProofNodesToTries decodes hex-encoded RLP proof nodes from an
+// eth_getProof response, as DecodeRLPProofNodes does, and parses each
+// serialized node with rsktrie.FromMessage, which resolves references to
+// children and long values through store (which may be nil). The nodes keep
+// their proof order.
+func DecodeProofNodesToTries(hexNodes []string, store rsktrie.TrieStore) ([]ProofTrieNode, error) {
+	rlpNodes, err := DecodeRLPProofNodes(hexNodes)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]ProofTrieNode, len(rlpNodes))
+	for i, rlpNode := range rlpNodes {
+		var message []byte
+		if err := rlp.DecodeBytes(rlpNode, &message); err != nil {
+			return nil, fmt.Errorf("decode proof node %d: %w", i, err)
+		}
+		node, err := rsktrie.FromMessage(message, store)
+		if err != nil {
+			return nil, fmt.Errorf("parse proof node %d: %w", i, err)
+		}
+		nodes[i] = ProofTrieNode{Hash: crypto.Keccak256Hash(message), Node: node}
+	}
+	return nodes, nil
+}
@@ -0,0 +1,51 @@
+package rskblocks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestDecodeProofNodesToTries(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051")
+	key := mapper.GetAccountStorageKey(contract, common.HexToHash("0x01"))
+	trie := rsktrie.NewTrie(nil).
+		Put(mapper.GetAccountKey(contract), []byte{0x01}).
+		Put(key, []byte{0x2a}).
+		Put(mapper.GetAccountStorageKey(contract, common.HexToHash("0x02")), []byte{0x07})
+
+	proof := buildTestProof(t, trie, key)
+	hexNodes := make([]string, len(proof))
+	for i, node := range proof {
+		hexNodes[i] = hexutil.Encode(node)
+	}
+	nodes, err := DecodeProofNodesToTries(hexNodes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != len(proof) {
+		t.Fatalf("Got %d nodes, want %d", len(nodes), len(proof))
+	}
+	for i, n := range nodes {
+		if !bytes.Equal(n.Node.GetHash(), n.Hash[:]) {
+			t.Errorf("Node %d: hash %s, node hashes to %x", i, n.Hash, n.Node.GetHash())
+		}
+	}
+	if root := nodes[len(nodes)-1]; !bytes.Equal(root.Hash[:], trie.GetHash()) {
+		t.Errorf("Last node %s is not the root %x", root.Hash, trie.GetHash())
+	}
+	if !bytes.Equal(nodes[0].Node.GetValue(), []byte{0x2a}) {
+		t.Errorf("Leaf value %x", nodes[0].Node.GetValue())
+	}
+
+	for _, bad := range [][]string{{"0xzz"}, {"0xc0"}, {"0x80"}} {
+		if _, err := DecodeProofNodesToTries(bad, nil); err == nil {
+			t.Errorf("%v accepted", bad)
+		}
+	}
+}
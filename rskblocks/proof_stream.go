@@ -0,0 +1,154 @@
+package rskblocks
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ProofNodeLocation says where in an eth_getProof response a proof node was
+// found.
+type ProofNodeLocation struct {
+	// StorageProof is the index of the storageProof entry whose proof holds
+	// the node, or -1 for the accountProof or a bare proof array.
+	StorageProof int
+	// Index is the node's position in its proof.
+	Index int
+}
+
+// StreamRLPProofNodes reads from r either a JSON array of hex-encoded proof
+// nodes, as DecodeRLPProofNodes takes, or a whole eth_getProof response, bare
+// or in its JSON-RPC envelope, and calls fn with each node as it is decoded.
+// Only one node's hex text is held at a time, so archives of any size can be
+// processed; an error from fn stops the stream and is returned.
+func StreamRLPProofNodes(r io.Reader, fn func(loc ProofNodeLocation, node []byte) error) error {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("read proof JSON: %w", err)
+	}
+	switch tok {
+	case json.Delim('['):
+		return streamProofArray(dec, -1, fn)
+	case json.Delim('{'):
+		return streamProofObject(dec, fn)
+	}
+	return fmt.Errorf("proof JSON starts with %v, want an array or object", tok)
+}
+
+// DecodeRLPProofNodesFrom is DecodeRLPProofNodes for a JSON array read from
+// r. Given a whole eth_getProof response, it returns the accountProof nodes
+// wherever the storageProof field appears, skipping storage proof nodes.
+func DecodeRLPProofNodesFrom(r io.Reader) ([][]byte, error) {
+	var nodes [][]byte
+	err := StreamRLPProofNodes(r, func(loc ProofNodeLocation, node []byte) error {
+		if loc.StorageProof >= 0 {
+			return nil
+		}
+		nodes = append(nodes, node)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// streamProofObject streams the proofs of an eth_getProof result or JSON-RPC
+// response whose opening brace has been read, skipping every other field.
+func streamProofObject(dec *json.Decoder, fn func(ProofNodeLocation, []byte) error) error {
+	return streamObject(dec, func(key string) error {
+		switch key {
+		case "result":
+			if err := expectDelim(dec, '{'); err != nil {
+				return err
+			}
+			return streamProofObject(dec, fn)
+		case "accountProof":
+			if err := expectDelim(dec, '['); err != nil {
+				return err
+			}
+			return streamProofArray(dec, -1, fn)
+		case "storageProof":
+			if err := expectDelim(dec, '['); err != nil {
+				return err
+			}
+			for i := 0; dec.More(); i++ {
+				if err := expectDelim(dec, '{'); err != nil {
+					return err
+				}
+				err := streamObject(dec, func(key string) error {
+					if key != "proof" {
+						return skipValue(dec)
+					}
+					if err := expectDelim(dec, '['); err != nil {
+						return err
+					}
+					return streamProofArray(dec, i, fn)
+				})
+				if err != nil {
+					return fmt.Errorf("storageProof %d: %w", i, err)
+				}
+			}
+			return expectDelim(dec, ']')
+		}
+		return skipValue(dec)
+	})
+}
+
+// streamProofArray decodes the nodes of a proof array whose opening bracket
+// has been read, through its closing bracket.
+func streamProofArray(dec *json.Decoder, storageProof int, fn func(ProofNodeLocation, []byte) error) error {
+	for i := 0; dec.More(); i++ {
+		var hexNode string
+		if err := dec.Decode(&hexNode); err != nil {
+			return fmt.Errorf("read proof node %d: %w", i, err)
+		}
+		node, err := decodeProofNode(hexNode)
+		if err != nil {
+			return fmt.Errorf("decode proof node %d: %w", i, err)
+		}
+		if err := fn(ProofNodeLocation{StorageProof: storageProof, Index: i}, node); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+// streamObject calls field with each key of an object whose opening brace
+// has been read; field must consume the value. It reads the closing brace.
+func streamObject(dec *json.Decoder, field func(key string) error) error {
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("read proof JSON: %w", err)
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("proof JSON has key %v", tok)
+		}
+		if err := field(key); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("read proof JSON: %w", err)
+	}
+	if tok != want {
+		return fmt.Errorf("proof JSON has %v, want %v", tok, want)
+	}
+	return nil
+}
+
+func skipValue(dec *json.Decoder) error {
+	var skipped json.RawMessage
+	if err := dec.Decode(&skipped); err != nil {
+		return fmt.Errorf("read proof JSON: %w", err)
+	}
+	return nil
+}
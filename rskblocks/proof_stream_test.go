@@ -0,0 +1,91 @@
+package rskblocks
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecodeRLPProofNodesFrom(t *testing.T) {
+	nodes, err := DecodeRLPProofNodesFrom(strings.NewReader(`["0x0102", "ff", "0x"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := DecodeRLPProofNodes([]string{"0x0102", "ff", "0x"})
+	if len(nodes) != len(want) {
+		t.Fatalf("Got %d nodes, want %d", len(nodes), len(want))
+	}
+	for i := range want {
+		if !bytes.Equal(nodes[i], want[i]) {
+			t.Errorf("Node %d: %x, want %x", i, nodes[i], want[i])
+		}
+	}
+
+	for _, bad := range []string{`["0xzz"]`, `[1]`, `"0x01"`, `["0x01"`} {
+		if _, err := DecodeRLPProofNodesFrom(strings.NewReader(bad)); err == nil {
+			t.Errorf("%s accepted", bad)
+		}
+	}
+}
+
+func TestStreamRLPProofNodes(t *testing.T) {
+	response := `{"jsonrpc":"2.0","id":1,"result":{
+		"address":"0x77045e71a7a2c50903d88e564cd72fab11e82051",
+		"balance":"0x1","nonce":"0x0","codeHash":"0x00","storageHash":"0x00",
+		"accountProof":["0xaa","0xbb"],
+		"storageProof":[
+			{"key":"0x1","value":"0x2","proof":["0xcc"]},
+			{"key":"0x3","value":"0x0","proof":[]},
+			{"proof":["0xdd","0xee"],"key":"0x4","value":{"nested":[1,2]}}
+		]}}`
+	type seen struct {
+		loc  ProofNodeLocation
+		node byte
+	}
+	var got []seen
+	err := StreamRLPProofNodes(strings.NewReader(response), func(loc ProofNodeLocation, node []byte) error {
+		got = append(got, seen{loc, node[0]})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []seen{
+		{ProofNodeLocation{-1, 0}, 0xaa},
+		{ProofNodeLocation{-1, 1}, 0xbb},
+		{ProofNodeLocation{0, 0}, 0xcc},
+		{ProofNodeLocation{2, 0}, 0xdd},
+		{ProofNodeLocation{2, 1}, 0xee},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Node %d: %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	nodes, err := DecodeRLPProofNodesFrom(strings.NewReader(response))
+	if err != nil || len(nodes) != 2 || nodes[1][0] != 0xbb {
+		t.Errorf("Account proof of a response: %x, %v", nodes, err)
+	}
+
+	// JSON objects are unordered: storageProof may come first.
+	storageFirst := `{"storageProof":[{"key":"0x1","value":"0x2","proof":["0xcc"]}],"accountProof":["0xaa","0xbb"]}`
+	nodes, err = DecodeRLPProofNodesFrom(strings.NewReader(storageFirst))
+	if err != nil || len(nodes) != 2 || nodes[0][0] != 0xaa || nodes[1][0] != 0xbb {
+		t.Errorf("Account proof after the storage proofs: %x, %v", nodes, err)
+	}
+
+	stop := errors.New("stop here")
+	calls := 0
+	err = StreamRLPProofNodes(strings.NewReader(response), func(ProofNodeLocation, []byte) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Callback error: %v after %d calls", err, calls)
+	}
+}
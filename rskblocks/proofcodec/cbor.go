@@ -0,0 +1,275 @@
+package proofcodec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rskblocks"
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+)
+
+// The CBOR encodings are definite-length arrays of unsigned integers and
+// byte strings, mirroring proofs.proto field order:
+//
+//	bundle:    [blockHash, blockNumber, stateRoot, [[key, [node...]]...]]
+//	witness:   [root, blockHash, [key...], [node...]]
+//
+// Only that subset of CBOR is accepted on decoding.
+
+const (
+	cborUint  = 0
+	cborBytes = 2
+	cborArray = 4
+)
+
+// MarshalBundleCBOR encodes b as CBOR.
+func MarshalBundleCBOR(b *rskblocks.ProofBundle) ([]byte, error) {
+	var e cborEncoder
+	e.head(cborArray, 4)
+	e.bytes(b.BlockHash[:])
+	e.head(cborUint, b.BlockNumber)
+	e.bytes(b.StateRoot[:])
+	e.head(cborArray, uint64(len(b.Proofs)))
+	for i, p := range b.Proofs {
+		nodes, err := stripNodes(p.Proof)
+		if err != nil {
+			return nil, fmt.Errorf("proof %d: %w", i, err)
+		}
+		e.head(cborArray, 2)
+		e.bytes(p.Key)
+		e.byteStrings(nodes)
+	}
+	return e.buf, nil
+}
+
+// UnmarshalBundleCBOR decodes a bundle written by MarshalBundleCBOR.
+func UnmarshalBundleCBOR(data []byte) (*rskblocks.ProofBundle, error) {
+	d := &cborDecoder{data: data}
+	if err := d.array(4); err != nil {
+		return nil, err
+	}
+	blockHash, err := d.byteString()
+	if err != nil {
+		return nil, err
+	}
+	number, err := d.head(cborUint)
+	if err != nil {
+		return nil, err
+	}
+	stateRoot, err := d.byteString()
+	if err != nil {
+		return nil, err
+	}
+	n, err := d.arrayLen()
+	if err != nil {
+		return nil, err
+	}
+	b := &rskblocks.ProofBundle{BlockNumber: number, Proofs: make([]rskblocks.KeyProof, n)}
+	if b.BlockHash, err = toHash("block hash", blockHash); err != nil {
+		return nil, err
+	}
+	if b.StateRoot, err = toHash("state root", stateRoot); err != nil {
+		return nil, err
+	}
+	for i := range b.Proofs {
+		if err := d.array(2); err != nil {
+			return nil, err
+		}
+		if b.Proofs[i].Key, err = d.byteString(); err != nil {
+			return nil, err
+		}
+		nodes, err := d.byteStrings()
+		if err != nil {
+			return nil, err
+		}
+		if b.Proofs[i].Proof, err = wrapNodes(nodes); err != nil {
+			return nil, err
+		}
+	}
+	if err := d.end(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalWitnessCBOR encodes w as CBOR.
+func MarshalWitnessCBOR(w *rsktrie.Witness) ([]byte, error) {
+	nodes, err := stripNodes(w.Nodes)
+	if err != nil {
+		return nil, err
+	}
+	var e cborEncoder
+	e.head(cborArray, 4)
+	e.bytes(w.Root[:])
+	e.bytes(w.BlockHash[:])
+	e.byteStrings(w.Keys)
+	e.byteStrings(nodes)
+	return e.buf, nil
+}
+
+// UnmarshalWitnessCBOR decodes a witness written by MarshalWitnessCBOR.
+func UnmarshalWitnessCBOR(data []byte) (*rsktrie.Witness, error) {
+	d := &cborDecoder{data: data}
+	if err := d.array(4); err != nil {
+		return nil, err
+	}
+	root, err := d.byteString()
+	if err != nil {
+		return nil, err
+	}
+	blockHash, err := d.byteString()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := d.byteStrings()
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := d.byteStrings()
+	if err != nil {
+		return nil, err
+	}
+	w := &rsktrie.Witness{Keys: keys}
+	if w.Root, err = toHash("root", root); err != nil {
+		return nil, err
+	}
+	if w.BlockHash, err = toHash("block hash", blockHash); err != nil {
+		return nil, err
+	}
+	if w.Nodes, err = wrapNodes(nodes); err != nil {
+		return nil, err
+	}
+	if err := d.end(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+type cborEncoder struct {
+	buf []byte
+}
+
+// head writes an item head: the major type and its argument, in the
+// shortest form (as RFC 8949's deterministic encoding requires).
+func (e *cborEncoder) head(major byte, n uint64) {
+	m := major << 5
+	switch {
+	case n < 24:
+		e.buf = append(e.buf, m|byte(n))
+	case n <= 0xff:
+		e.buf = append(e.buf, m|24, byte(n))
+	case n <= 0xffff:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, m|25), uint16(n))
+	case n <= 0xffffffff:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, m|26), uint32(n))
+	default:
+		e.buf = binary.BigEndian.AppendUint64(append(e.buf, m|27), n)
+	}
+}
+
+func (e *cborEncoder) bytes(b []byte) {
+	e.head(cborBytes, uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *cborEncoder) byteStrings(list [][]byte) {
+	e.head(cborArray, uint64(len(list)))
+	for _, b := range list {
+		e.bytes(b)
+	}
+}
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+var errCBORTruncated = errors.New("cbor: unexpected end of data")
+
+// head reads an item head of the given major type, returning its argument.
+func (d *cborDecoder) head(major byte) (uint64, error) {
+	if d.pos >= len(d.data) {
+		return 0, errCBORTruncated
+	}
+	b := d.data[d.pos]
+	if b>>5 != major {
+		return 0, fmt.Errorf("cbor: expected major type %d at offset %d, got %d", major, d.pos, b>>5)
+	}
+	d.pos++
+	info := b & 0x1f
+	if info < 24 {
+		return uint64(info), nil
+	}
+	if info > 27 {
+		return 0, fmt.Errorf("cbor: unsupported additional info %d at offset %d", info, d.pos-1)
+	}
+	size := 1 << (info - 24)
+	if len(d.data)-d.pos < size {
+		return 0, errCBORTruncated
+	}
+	var n uint64
+	for _, c := range d.data[d.pos : d.pos+size] {
+		n = n<<8 | uint64(c)
+	}
+	d.pos += size
+	return n, nil
+}
+
+// arrayLen reads an array head. Every element takes at least a byte, which
+// bounds the length by the data left.
+func (d *cborDecoder) arrayLen() (int, error) {
+	n, err := d.head(cborArray)
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(d.data)-d.pos) {
+		return 0, errCBORTruncated
+	}
+	return int(n), nil
+}
+
+func (d *cborDecoder) array(want int) error {
+	n, err := d.arrayLen()
+	if err != nil {
+		return err
+	}
+	if n != want {
+		return fmt.Errorf("cbor: expected %d-element array, got %d", want, n)
+	}
+	return nil
+}
+
+func (d *cborDecoder) byteString() ([]byte, error) {
+	n, err := d.head(cborBytes)
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errCBORTruncated
+	}
+	b := append([]byte{}, d.data[d.pos:d.pos+int(n)]...)
+	d.pos += int(n)
+	return b, nil
+}
+
+func (d *cborDecoder) byteStrings() ([][]byte, error) {
+	n, err := d.arrayLen()
+	if err != nil {
+		return nil, err
+	}
+	list := make([][]byte, n)
+	for i := range list {
+		if list[i], err = d.byteString(); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+func (d *cborDecoder) end() error {
+	if d.pos != len(d.data) {
+		return fmt.Errorf("cbor: %d trailing bytes", len(d.data)-d.pos)
+	}
+	return nil
+}
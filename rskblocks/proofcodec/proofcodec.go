@@ -0,0 +1,49 @@
+// Package proofcodec provides compact binary transport encodings for
+// rskblocks.ProofBundle and rsktrie.Witness: CBOR (RFC 8949) and protobuf,
+// per the schema in proofs.proto. Both carry nodes and keys as raw bytes,
+// roughly halving the size of the JSON+hex form.
+//
+// As in the types' own Marshal encodings, proof nodes travel as serialized
+// node messages, without the per-node RLP string header of the eth_getProof
+// format, and are restored to that format on decoding. Decoding does not
+// verify; call Verify on the result for that.
+package proofcodec
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// stripNodes converts eth_getProof-format nodes to serialized messages.
+func stripNodes(nodes [][]byte) ([][]byte, error) {
+	out := make([][]byte, len(nodes))
+	for i, node := range nodes {
+		if err := rlp.DecodeBytes(node, &out[i]); err != nil {
+			return nil, fmt.Errorf("node %d: %w", i, err)
+		}
+	}
+	return out, nil
+}
+
+// wrapNodes converts serialized messages back to eth_getProof format.
+func wrapNodes(messages [][]byte) ([][]byte, error) {
+	out := make([][]byte, len(messages))
+	for i, msg := range messages {
+		enc, err := rlp.EncodeToBytes(msg)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = enc
+	}
+	return out, nil
+}
+
+// toHash converts a decoded hash field, which must be exactly 32 bytes.
+func toHash(field string, b []byte) (common.Hash, error) {
+	if len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("%s is %d bytes, want %d", field, len(b), common.HashLength)
+	}
+	return common.BytesToHash(b), nil
+}
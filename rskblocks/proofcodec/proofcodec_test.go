@@ -0,0 +1,130 @@
+package proofcodec
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rskblocks"
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func testTrie() (*rsktrie.Trie, [][]byte) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045e71a7a2c50903d88e564cd72fab11e82051")
+	trie := rsktrie.NewTrie(nil).Put(mapper.GetAccountKey(contract), []byte{0x01})
+	var keys [][]byte
+	for i := 0; i < 30; i++ {
+		key := mapper.GetAccountStorageKey(contract, common.BigToHash(big.NewInt(int64(i))))
+		trie = trie.Put(key, bytes.Repeat([]byte{byte(i + 1)}, i+1))
+		keys = append(keys, key)
+	}
+	return trie, keys
+}
+
+func testBundle(t *testing.T) *rskblocks.ProofBundle {
+	trie, keys := testTrie()
+	bundle := rskblocks.NewProofBundle(&rskblocks.BlockHeader{
+		StateRoot: common.BytesToHash(trie.GetHash()),
+		Number:    big.NewInt(6_000_000),
+	})
+	for _, key := range keys[:5] {
+		proof, err := trie.GenerateProof(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bundle.Add(key, proof)
+	}
+	return bundle
+}
+
+func TestBundleCodecs(t *testing.T) {
+	bundle := testBundle(t)
+	binary, err := bundle.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, codec := range []struct {
+		name      string
+		marshal   func(*rskblocks.ProofBundle) ([]byte, error)
+		unmarshal func([]byte) (*rskblocks.ProofBundle, error)
+	}{
+		{"cbor", MarshalBundleCBOR, UnmarshalBundleCBOR},
+		{"proto", MarshalBundleProto, UnmarshalBundleProto},
+	} {
+		data, err := codec.marshal(bundle)
+		if err != nil {
+			t.Fatalf("%s: %v", codec.name, err)
+		}
+		if len(data) > len(binary)+16 {
+			t.Errorf("%s: %d bytes, expected about the RLP form's %d", codec.name, len(data), len(binary))
+		}
+		decoded, err := codec.unmarshal(data)
+		if err != nil {
+			t.Fatalf("%s: %v", codec.name, err)
+		}
+		if !reflect.DeepEqual(decoded, bundle) {
+			t.Errorf("%s: round trip changed the bundle", codec.name)
+		}
+		if _, err := decoded.Verify(); err != nil {
+			t.Errorf("%s: expected the decoded bundle to verify: %v", codec.name, err)
+		}
+		if _, err := codec.unmarshal(data[:len(data)-1]); err == nil {
+			t.Errorf("%s: expected truncated data to be rejected", codec.name)
+		}
+	}
+
+	// Unknown protobuf fields are skipped
+	data, _ := MarshalBundleProto(bundle)
+	data = protowire.AppendTag(data, 99, protowire.Fixed64Type)
+	data = protowire.AppendFixed64(data, 1)
+	if decoded, err := UnmarshalBundleProto(data); err != nil || !reflect.DeepEqual(decoded, bundle) {
+		t.Errorf("Expected an unknown field to be skipped, got %v", err)
+	}
+	if _, err := UnmarshalBundleCBOR(append(mustCBOR(t, bundle), 0x00)); err == nil {
+		t.Error("Expected trailing CBOR data to be rejected")
+	}
+}
+
+func mustCBOR(t *testing.T, b *rskblocks.ProofBundle) []byte {
+	data, err := MarshalBundleCBOR(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestWitnessCodecs(t *testing.T) {
+	trie, keys := testTrie()
+	witness, err := rsktrie.NewWitness(trie, common.Hash{0x0b}, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, codec := range []struct {
+		name      string
+		marshal   func(*rsktrie.Witness) ([]byte, error)
+		unmarshal func([]byte) (*rsktrie.Witness, error)
+	}{
+		{"cbor", MarshalWitnessCBOR, UnmarshalWitnessCBOR},
+		{"proto", MarshalWitnessProto, UnmarshalWitnessProto},
+	} {
+		data, err := codec.marshal(witness)
+		if err != nil {
+			t.Fatalf("%s: %v", codec.name, err)
+		}
+		decoded, err := codec.unmarshal(data)
+		if err != nil {
+			t.Fatalf("%s: %v", codec.name, err)
+		}
+		if !reflect.DeepEqual(decoded, witness) {
+			t.Errorf("%s: round trip changed the witness", codec.name)
+		}
+		if _, err := decoded.Verify(); err != nil {
+			t.Errorf("%s: expected the decoded witness to verify: %v", codec.name, err)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package proofcodec
+
+import (
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rskblocks"
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers from proofs.proto.
+const (
+	keyProofKey   protowire.Number = 1
+	keyProofNodes protowire.Number = 2
+
+	bundleBlockHash   protowire.Number = 1
+	bundleBlockNumber protowire.Number = 2
+	bundleStateRoot   protowire.Number = 3
+	bundleProofs      protowire.Number = 4
+
+	witnessRoot      protowire.Number = 1
+	witnessBlockHash protowire.Number = 2
+	witnessKeys      protowire.Number = 3
+	witnessNodes     protowire.Number = 4
+)
+
+// MarshalBundleProto encodes b as a ProofBundle message.
+func MarshalBundleProto(b *rskblocks.ProofBundle) ([]byte, error) {
+	var buf []byte
+	buf = appendBytesField(buf, bundleBlockHash, b.BlockHash[:])
+	if b.BlockNumber != 0 {
+		buf = protowire.AppendTag(buf, bundleBlockNumber, protowire.VarintType)
+		buf = protowire.AppendVarint(buf, b.BlockNumber)
+	}
+	buf = appendBytesField(buf, bundleStateRoot, b.StateRoot[:])
+	for i, p := range b.Proofs {
+		nodes, err := stripNodes(p.Proof)
+		if err != nil {
+			return nil, fmt.Errorf("proof %d: %w", i, err)
+		}
+		msg := appendBytesField(nil, keyProofKey, p.Key)
+		for _, node := range nodes {
+			msg = appendRepeatedField(msg, keyProofNodes, node)
+		}
+		buf = appendRepeatedField(buf, bundleProofs, msg)
+	}
+	return buf, nil
+}
+
+// UnmarshalBundleProto decodes a ProofBundle message.
+func UnmarshalBundleProto(data []byte) (*rskblocks.ProofBundle, error) {
+	b := &rskblocks.ProofBundle{}
+	var blockHash, stateRoot []byte
+	err := forEachField(data, func(num protowire.Number, typ protowire.Type, v []byte, n uint64) error {
+		switch {
+		case num == bundleBlockHash && typ == protowire.BytesType:
+			blockHash = v
+		case num == bundleBlockNumber && typ == protowire.VarintType:
+			b.BlockNumber = n
+		case num == bundleStateRoot && typ == protowire.BytesType:
+			stateRoot = v
+		case num == bundleProofs && typ == protowire.BytesType:
+			p, err := unmarshalKeyProof(v)
+			if err != nil {
+				return fmt.Errorf("proof %d: %w", len(b.Proofs), err)
+			}
+			b.Proofs = append(b.Proofs, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if b.BlockHash, err = toHash("block hash", blockHash); err != nil {
+		return nil, err
+	}
+	if b.StateRoot, err = toHash("state root", stateRoot); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func unmarshalKeyProof(data []byte) (rskblocks.KeyProof, error) {
+	var p rskblocks.KeyProof
+	var nodes [][]byte
+	err := forEachField(data, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) error {
+		switch {
+		case num == keyProofKey && typ == protowire.BytesType:
+			p.Key = v
+		case num == keyProofNodes && typ == protowire.BytesType:
+			nodes = append(nodes, v)
+		}
+		return nil
+	})
+	if err != nil {
+		return p, err
+	}
+	p.Proof, err = wrapNodes(nodes)
+	return p, err
+}
+
+// MarshalWitnessProto encodes w as a Witness message.
+func MarshalWitnessProto(w *rsktrie.Witness) ([]byte, error) {
+	nodes, err := stripNodes(w.Nodes)
+	if err != nil {
+		return nil, err
+	}
+	var buf []byte
+	buf = appendBytesField(buf, witnessRoot, w.Root[:])
+	buf = appendBytesField(buf, witnessBlockHash, w.BlockHash[:])
+	for _, key := range w.Keys {
+		buf = appendRepeatedField(buf, witnessKeys, key)
+	}
+	for _, node := range nodes {
+		buf = appendRepeatedField(buf, witnessNodes, node)
+	}
+	return buf, nil
+}
+
+// UnmarshalWitnessProto decodes a Witness message.
+func UnmarshalWitnessProto(data []byte) (*rsktrie.Witness, error) {
+	w := &rsktrie.Witness{}
+	var root, blockHash []byte
+	var nodes [][]byte
+	err := forEachField(data, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case witnessRoot:
+			root = v
+		case witnessBlockHash:
+			blockHash = v
+		case witnessKeys:
+			w.Keys = append(w.Keys, v)
+		case witnessNodes:
+			nodes = append(nodes, v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if w.Root, err = toHash("root", root); err != nil {
+		return nil, err
+	}
+	if w.BlockHash, err = toHash("block hash", blockHash); err != nil {
+		return nil, err
+	}
+	if w.Nodes, err = wrapNodes(nodes); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// appendBytesField appends a singular bytes field, omitted when empty as
+// proto3 does.
+func appendBytesField(buf []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return buf
+	}
+	return appendRepeatedField(buf, num, v)
+}
+
+// appendRepeatedField appends one element of a repeated bytes or message
+// field, which is written even when empty.
+func appendRepeatedField(buf []byte, num protowire.Number, v []byte) []byte {
+	buf = protowire.AppendTag(buf, num, protowire.BytesType)
+	return protowire.AppendBytes(buf, v)
+}
+
+// forEachField calls fn with each field of a message: for bytes fields a
+// copy of the value, for varints the number. Fields of other wire types,
+// and unknown fields, are skipped.
+func forEachField(data []byte, fn func(num protowire.Number, typ protowire.Type, v []byte, n uint64) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return fmt.Errorf("protobuf: %w", protowire.ParseError(n))
+		}
+		data = data[n:]
+		var err error
+		switch typ {
+		case protowire.BytesType:
+			v, m := protowire.ConsumeBytes(data)
+			if m < 0 {
+				return fmt.Errorf("protobuf: field %d: %w", num, protowire.ParseError(m))
+			}
+			err, n = fn(num, typ, append([]byte{}, v...), 0), m
+		case protowire.VarintType:
+			v, m := protowire.ConsumeVarint(data)
+			if m < 0 {
+				return fmt.Errorf("protobuf: field %d: %w", num, protowire.ParseError(m))
+			}
+			err, n = fn(num, typ, nil, v), m
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return fmt.Errorf("protobuf: field %d: %w", num, protowire.ParseError(n))
+			}
+		}
+		if err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
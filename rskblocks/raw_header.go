@@ -0,0 +1,106 @@
+package rskblocks
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ErrBlockHashMismatch is returned when a raw header does not hash to the
+// block hash it was claimed to have.
+var ErrBlockHashMismatch = errors.New("raw header does not match block hash")
+
+// stateRootField is the position of stateRoot in an RLP encoded header.
+const stateRootField = 3
+
+// ExtractStateRoot returns the stateRoot of an RLP encoded header without
+// decoding the rest of it, so it works for every header version and
+// encoding. The root is only as trustworthy as the header: check it with
+// VerifyRawHeaderHash first.
+func ExtractStateRoot(rawHeader []byte) (common.Hash, error) {
+	fields, err := decodeHeaderFields(rawHeader)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	root, err := decodeHeaderHash(fields[stateRootField])
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("header stateRoot: %w", err)
+	}
+	return root, nil
+}
+
+// VerifyRawHeaderHash checks that keccak256(rawHeader) is blockHash, i.e.
+// that rawHeader is the encoding the block hash commits to (see
+// BlockHeader.GetEncodedForHash). Full encodings carrying the merged mining
+// proof and coinbase since RSKIP-92 do not hash to the block hash; decode
+// those with ChainConfig.DecodeBlockHeader and compare BlockHeader.Hash.
+func VerifyRawHeaderHash(rawHeader []byte, blockHash common.Hash) error {
+	if got := crypto.Keccak256Hash(rawHeader); got != blockHash {
+		return fmt.Errorf("%w: hashes to %s, want %s", ErrBlockHashMismatch, got, blockHash)
+	}
+	return nil
+}
+
+// decodeHeaderFields splits an RLP encoded header into its fields.
+func decodeHeaderFields(data []byte) ([][]byte, error) {
+	var fields [][]byte
+	if err := rlp.DecodeBytes(data, &fields); err != nil {
+		return nil, fmt.Errorf("decode header: %w", err)
+	}
+	if len(fields) < coreHeaderFields {
+		return nil, fmt.Errorf("header has %d fields, need at least %d", len(fields), coreHeaderFields)
+	}
+	return fields, nil
+}
+
+// RawHeaderProofResult is the outcome of VerifyProofAgainstRawHeader.
+type RawHeaderProofResult struct {
+	StateRoot common.Hash         // The authenticated header's state root
+	Account   *AccountProofResult // The account proof's result
+	Storage   *StorageProofResult // The storage proof's result; nil if no slot was given
+}
+
+// Valid reports whether the account proof and, if checked, the storage
+// proof are valid.
+func (r *RawHeaderProofResult) Valid() bool {
+	return r.Account.Valid && (r.Storage == nil || r.Storage.Valid)
+}
+
+// VerifyProofAgainstRawHeader decodes rawHeader, the full encoding
+// rsk_getRawBlockHeaderBy* returns, under config's rules for its block
+// number and checks that it hashes to blockHash. It then verifies
+// accountProof for address and, if storageKey is not nil, storageProof for
+// that slot against the header's state root. A header that does not match
+// is an error; invalid proofs are reported in the result, as by
+// VerifyAccountProof and VerifyStorageProof.
+func (v *ProofVerifier) VerifyProofAgainstRawHeader(
+	config *ChainConfig,
+	rawHeader []byte,
+	blockHash common.Hash,
+	address common.Address,
+	storageKey *common.Hash,
+	accountProof [][]byte,
+	storageProof [][]byte,
+) (*RawHeaderProofResult, error) {
+	header, err := config.DecodeBlockHeader(rawHeader)
+	if err != nil {
+		return nil, err
+	}
+	if err := header.CheckHash(blockHash); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBlockHashMismatch, err)
+	}
+	stateRoot := header.StateRoot
+	result := &RawHeaderProofResult{StateRoot: stateRoot}
+	if result.Account, err = v.VerifyAccountProof(stateRoot, address, accountProof); err != nil {
+		return nil, err
+	}
+	if storageKey != nil {
+		if result.Storage, err = v.VerifyStorageProof(stateRoot, address, *storageKey, storageProof); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
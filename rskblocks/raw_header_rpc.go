@@ -0,0 +1,70 @@
+package rskblocks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// DecodeRawBlockHeaderByHash decodes the result of
+// rsk_getRawBlockHeaderByHash under config's rules for its block number and
+// checks that it hashes to hash. Unlike eth_getBlockByHash, the raw header
+// carries every field the block hash commits to.
+func DecodeRawBlockHeaderByHash(raw []byte, hash common.Hash, config *ChainConfig) (*BlockHeader, error) {
+	header, err := config.DecodeBlockHeader(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := header.CheckHash(hash); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// DecodeRawBlockHeaderByNumber decodes the result of
+// rsk_getRawBlockHeaderByNumber under config's rules and checks that it is
+// block number. Only its hash, checked against one obtained independently
+// (see BlockHeader.CheckHash), authenticates it.
+func DecodeRawBlockHeaderByNumber(raw []byte, number uint64, config *ChainConfig) (*BlockHeader, error) {
+	header, err := config.DecodeBlockHeader(raw)
+	if err != nil {
+		return nil, err
+	}
+	if got := bigOrZero(header.Number); !got.IsUint64() || got.Uint64() != number {
+		return nil, fmt.Errorf("raw header is block %v, want %d", header.Number, number)
+	}
+	return header, nil
+}
+
+// GetRawBlockHeaderByHash calls rsk_getRawBlockHeaderByHash and decodes the
+// result with DecodeRawBlockHeaderByHash.
+func (c *ProofClient) GetRawBlockHeaderByHash(ctx context.Context, hash common.Hash, config *ChainConfig) (*BlockHeader, error) {
+	raw, err := c.getRawBlockHeader(ctx, "rsk_getRawBlockHeaderByHash", hash)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeRawBlockHeaderByHash(raw, hash, config)
+}
+
+// GetRawBlockHeaderByNumber calls rsk_getRawBlockHeaderByNumber and decodes
+// the result with DecodeRawBlockHeaderByNumber.
+func (c *ProofClient) GetRawBlockHeaderByNumber(ctx context.Context, number uint64, config *ChainConfig) (*BlockHeader, error) {
+	raw, err := c.getRawBlockHeader(ctx, "rsk_getRawBlockHeaderByNumber", hexutil.Uint64(number))
+	if err != nil {
+		return nil, err
+	}
+	return DecodeRawBlockHeaderByNumber(raw, number, config)
+}
+
+func (c *ProofClient) getRawBlockHeader(ctx context.Context, method string, arg any) ([]byte, error) {
+	var raw hexutil.Bytes
+	if err := c.rpc.CallContext(ctx, &raw, method, arg); err != nil {
+		return nil, fmt.Errorf("%s RPC call failed: %w", method, err)
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("%s: block not found", method)
+	}
+	return raw, nil
+}
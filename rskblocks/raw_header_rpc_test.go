@@ -0,0 +1,73 @@
+package rskblocks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestDecodeRawBlockHeader(t *testing.T) {
+	config := RegtestChainConfig()
+	header := InputToBlockHeader(testHeaderInput(), config.BlockHashConfig(7139700))
+	raw := header.GetFullEncoded()
+
+	got, err := DecodeRawBlockHeaderByHash(raw, header.Hash(), config)
+	if err != nil || got.Hash() != header.Hash() {
+		t.Fatalf("By hash: %v", err)
+	}
+	if _, err := DecodeRawBlockHeaderByHash(raw, common.Hash{1}, config); err == nil {
+		t.Error("Wrong hash accepted")
+	}
+	if _, err := DecodeRawBlockHeaderByNumber(raw, 7139700, config); err != nil {
+		t.Errorf("By number: %v", err)
+	}
+	if _, err := DecodeRawBlockHeaderByNumber(raw, 7139701, config); err == nil {
+		t.Error("Wrong number accepted")
+	}
+	if _, err := DecodeRawBlockHeaderByNumber([]byte{0xc0}, 7139700, config); err == nil {
+		t.Error("Malformed header accepted")
+	}
+}
+
+func TestGetRawBlockHeader_MockServer(t *testing.T) {
+	config := RegtestChainConfig()
+	header := InputToBlockHeader(testHeaderInput(), config.BlockHashConfig(7139700))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		result := fmt.Sprintf("%q", hexutil.Bytes(header.GetFullEncoded()))
+		if req.Method != "rsk_getRawBlockHeaderByHash" && req.Method != "rsk_getRawBlockHeaderByNumber" {
+			t.Errorf("Unexpected method %s", req.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, result)
+	}))
+	defer server.Close()
+	rpcClient, err := rpc.Dial(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewProofClientWithRPC(rpcClient)
+	defer client.Close()
+
+	ctx := context.Background()
+	if got, err := client.GetRawBlockHeaderByHash(ctx, header.Hash(), config); err != nil || got.Hash() != header.Hash() {
+		t.Errorf("By hash: %v", err)
+	}
+	if _, err := client.GetRawBlockHeaderByHash(ctx, common.Hash{1}, config); err == nil {
+		t.Error("Header for another hash accepted")
+	}
+	if got, err := client.GetRawBlockHeaderByNumber(ctx, 7139700, config); err != nil || got.Hash() != header.Hash() {
+		t.Errorf("By number: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package rskblocks
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestExtractStateRoot(t *testing.T) {
+	input := testHeaderInput()
+	input.StateRoot = common.HexToHash("0x1234")
+	header := InputToBlockHeader(input, DefaultRegtestConfig())
+	for name, raw := range map[string][]byte{
+		"hash encoding": header.GetEncodedForHash(),
+		"full encoding": header.GetFullEncoded(),
+	} {
+		if root, err := ExtractStateRoot(raw); err != nil || root != input.StateRoot {
+			t.Errorf("%s: got %s, %v", name, root, err)
+		}
+	}
+	if _, err := ExtractStateRoot([]byte{0xc0}); err == nil {
+		t.Error("Empty list accepted")
+	}
+}
+
+func TestVerifyRawHeaderHash(t *testing.T) {
+	header := InputToBlockHeader(testHeaderInput(), DefaultRegtestConfig())
+	raw := header.GetEncodedForHash()
+	if err := VerifyRawHeaderHash(raw, header.Hash()); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyRawHeaderHash(raw, common.Hash{1}); !errors.Is(err, ErrBlockHashMismatch) {
+		t.Errorf("Wrong hash: %v", err)
+	}
+}
+
+func TestVerifyProofAgainstRawHeader(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051")
+	slot := common.HexToHash("0x05")
+	account, err := (&rsktrie.AccountState{Nonce: big.NewInt(1), Balance: big.NewInt(2)}).Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	trie := rsktrie.NewTrie(nil).
+		Put(mapper.GetAccountKey(contract), account).
+		Put(mapper.GetAccountStorageKey(contract, slot), []byte{0x2a})
+
+	// A post-RSKIP-92 header as rsk_getRawBlockHeaderBy* returns it: the
+	// full encoding, whose merkle proof and coinbase the hash leaves out.
+	config := RegtestChainConfig()
+	input := testHeaderInput()
+	input.StateRoot = common.BytesToHash(trie.GetHash())
+	input.BitcoinMergedMiningHeader = bytes.Repeat([]byte{0xaa}, 80)
+	input.BitcoinMergedMiningMerkleProof = bytes.Repeat([]byte{0xbb}, 64)
+	input.BitcoinMergedMiningCoinbaseTransaction = bytes.Repeat([]byte{0xcc}, 100)
+	header := InputToBlockHeader(input, config.BlockHashConfig(input.Number.Uint64()))
+	raw := header.GetFullEncoded()
+	if VerifyRawHeaderHash(raw, header.Hash()) == nil {
+		t.Fatal("Expected the full encoding not to hash to the block hash")
+	}
+	accountProof := buildTestProof(t, trie, mapper.GetAccountKey(contract))
+	storageProof := buildTestProof(t, trie, mapper.GetAccountStorageKey(contract, slot))
+
+	verifier := NewProofVerifier()
+	result, err := verifier.VerifyProofAgainstRawHeader(config, raw, header.Hash(), contract, &slot, accountProof, storageProof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Valid() || result.StateRoot != input.StateRoot || result.Account.Balance.Int64() != 2 ||
+		string(result.Storage.Value) != "\x2a" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	// Without a slot only the account is checked.
+	result, err = verifier.VerifyProofAgainstRawHeader(config, raw, header.Hash(), contract, nil, accountProof, nil)
+	if err != nil || !result.Valid() || result.Storage != nil {
+		t.Errorf("Account only: %+v, %v", result, err)
+	}
+
+	// A proof for another slot does not verify.
+	other := common.HexToHash("0x06")
+	result, err = verifier.VerifyProofAgainstRawHeader(config, raw, header.Hash(), contract, &other, accountProof, storageProof)
+	if err == nil && result.Valid() && result.Storage.Status == rsktrie.ProofPresent {
+		t.Error("Proof for the wrong slot accepted")
+	}
+
+	if _, err := verifier.VerifyProofAgainstRawHeader(config, raw, common.Hash{1}, contract, &slot, accountProof, storageProof); !errors.Is(err, ErrBlockHashMismatch) {
+		t.Errorf("Unauthenticated header: %v", err)
+	}
+}
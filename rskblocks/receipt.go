@@ -2,11 +2,12 @@ package rskblocks
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"io"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
@@ -14,10 +15,10 @@ import (
 // RSK receipt RLP encoding order: [postTxState, cumulativeGas, bloom, logs, gasUsed, status]
 type TransactionReceipt struct {
 	// Consensus fields
-	PostState         []byte      `json:"root"`
-	CumulativeGasUsed uint64      `json:"cumulativeGasUsed"`
-	Bloom             types.Bloom `json:"logsBloom"`
-	Logs              []*Log      `json:"logs"`
+	PostState         []byte `json:"root"`
+	CumulativeGasUsed uint64 `json:"cumulativeGasUsed"`
+	Bloom             Bloom  `json:"logsBloom"`
+	Logs              []*Log `json:"logs"`
 
 	// Implementation fields
 	TxHash          common.Hash    `json:"transactionHash"`
@@ -34,12 +35,22 @@ type TransactionReceipt struct {
 type receiptRLP struct {
 	PostState         []byte
 	CumulativeGasUsed []byte
-	Bloom             types.Bloom
+	Bloom             Bloom
 	Logs              []*Log
 	GasUsed           []byte
 	Status            []byte
 }
 
+// legacyReceiptRLP decodes receiptRLP with the status optional.
+type legacyReceiptRLP struct {
+	PostState         []byte
+	CumulativeGasUsed []byte
+	Bloom             Bloom
+	Logs              []*Log
+	GasUsed           []byte
+	Status            []byte `rlp:"optional"`
+}
+
 // Log represents a contract log event.
 type Log struct {
 	Address common.Address `json:"address" gencodec:"required"`
@@ -62,11 +73,18 @@ func (r *TransactionReceipt) EncodeRLP(w io.Writer) error {
 	})
 }
 
+// DecodeRLP implements rlp.Decoder. Like rskj, it also accepts receipts
+// encoded before the status field existed, which leave Status nil.
 func (r *TransactionReceipt) DecodeRLP(s *rlp.Stream) error {
-	var dec receiptRLP
+	var dec legacyReceiptRLP
 	if err := s.Decode(&dec); err != nil {
 		return err
 	}
+	for name, gas := range map[string][]byte{"cumulative gas": dec.CumulativeGasUsed, "gas used": dec.GasUsed} {
+		if len(gas) > 8 {
+			return fmt.Errorf("receipt %s has %d bytes", name, len(gas))
+		}
+	}
 	r.PostState = dec.PostState
 	r.CumulativeGasUsed = bytesToUint64(dec.CumulativeGasUsed)
 	r.Bloom = dec.Bloom
@@ -76,6 +94,34 @@ func (r *TransactionReceipt) DecodeRLP(s *rlp.Stream) error {
 	return nil
 }
 
+// Succeeded reports whether the transaction succeeded: rskj writes status
+// 0x01 for success and an empty status for failure.
+func (r *TransactionReceipt) Succeeded() bool {
+	return bytes.Equal(r.Status, []byte{0x01})
+}
+
+// CreateBloom returns the bloom filter of logs: each log's address and
+// topics are added, as rskj's LogInfo.getBloom does.
+func CreateBloom(logs []*Log) Bloom {
+	var bloom Bloom
+	for _, log := range logs {
+		bloom.Add(log.Address.Bytes())
+		for _, topic := range log.Topics {
+			bloom.Add(topic.Bytes())
+		}
+	}
+	return bloom
+}
+
+// VerifyBloom reports whether the receipt's bloom is the one its logs
+// produce, so a log filter over blooms cannot miss or invent matches.
+func (r *TransactionReceipt) VerifyBloom() error {
+	if want := CreateBloom(r.Logs); r.Bloom != want {
+		return errors.New("receipt bloom does not match its logs")
+	}
+	return nil
+}
+
 // uint64ToBytes converts a uint64 to a trimmed big-endian byte array
 func uint64ToBytes(val uint64) []byte {
 	if val == 0 {
@@ -0,0 +1,63 @@
+package rskblocks
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// indexKey is the trie key of the i-th transaction or receipt of a block:
+// the RLP encoding of i.
+func indexKey(i int) []byte {
+	key, _ := rlp.EncodeToBytes(uint64(i))
+	return key
+}
+
+// VerifyReceiptsRoot reports whether receipts are the block's receipts, in
+// order, by recomputing the receipts trie root.
+func VerifyReceiptsRoot(header *BlockHeader, receipts []*TransactionReceipt) error {
+	if root := CalculateReceiptsTrieRoot(receipts); !bytes.Equal(root, header.ReceiptTrieRoot[:]) {
+		return fmt.Errorf("receipts hash to trie root %x, header has %s", root, header.ReceiptTrieRoot.Hex())
+	}
+	return nil
+}
+
+// GenerateReceiptProof returns the proof, leaf to root, that receipts[index]
+// is in the receipts trie of a block with these receipts.
+func GenerateReceiptProof(receipts []*TransactionReceipt, index int) ([][]byte, error) {
+	if index < 0 || index >= len(receipts) {
+		return nil, fmt.Errorf("receipt index %d out of range [0, %d)", index, len(receipts))
+	}
+	return CalculateReceiptsTrieFor(receipts).GenerateProof(indexKey(index))
+}
+
+// VerifyReceiptProof verifies that receipt is the index-th receipt of the
+// block whose header commits to receiptsRoot. Encoded receipts are long
+// values the trie commits to by hash, so the receipt itself is checked
+// against the proven hash.
+func VerifyReceiptProof(receiptsRoot common.Hash, index int, receipt *TransactionReceipt, proof [][]byte) error {
+	encoded, err := rlp.EncodeToBytes(receipt)
+	if err != nil {
+		return err
+	}
+	return verifyIndexedProof("receipt", receiptsRoot, index, encoded, proof)
+}
+
+// verifyIndexedProof verifies that value is at index in the trie with root.
+func verifyIndexedProof(kind string, root common.Hash, index int, value []byte, proof [][]byte) error {
+	if index < 0 {
+		return fmt.Errorf("%s index %d is negative", kind, index)
+	}
+	result, err := rsktrie.VerifyKeyProofWithValue(root[:], indexKey(index), proof, value)
+	if err != nil {
+		return fmt.Errorf("%s %d: %w", kind, index, err)
+	}
+	if result.Status != rsktrie.ProofPresent {
+		return fmt.Errorf("%s %d is %s in the trie", kind, index, result.Status)
+	}
+	return nil
+}
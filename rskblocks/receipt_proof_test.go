@@ -0,0 +1,57 @@
+package rskblocks
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func testReceipts(n int) []*TransactionReceipt {
+	var receipts []*TransactionReceipt
+	for i := 0; i < n; i++ {
+		log := &Log{Address: common.BigToAddress(big.NewInt(int64(i + 1))), Topics: []common.Hash{{byte(i)}}}
+		receipts = append(receipts, &TransactionReceipt{
+			CumulativeGasUsed: uint64(21000 * (i + 1)),
+			GasUsed:           21000,
+			Logs:              []*Log{log},
+			Bloom:             CreateBloom([]*Log{log}),
+			Status:            []byte{0x01},
+		})
+	}
+	return receipts
+}
+
+func TestReceiptProof(t *testing.T) {
+	receipts := testReceipts(20)
+	header := &BlockHeader{ReceiptTrieRoot: common.BytesToHash(CalculateReceiptsTrieRoot(receipts))}
+	if err := VerifyReceiptsRoot(header, receipts); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyReceiptsRoot(header, receipts[1:]); err == nil {
+		t.Error("Expected a missing receipt to change the root")
+	}
+
+	for i, receipt := range receipts {
+		proof, err := GenerateReceiptProof(receipts, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := VerifyReceiptProof(header.ReceiptTrieRoot, i, receipt, proof); err != nil {
+			t.Fatalf("Receipt %d: %v", i, err)
+		}
+		if err := VerifyReceiptProof(header.ReceiptTrieRoot, (i+1)%len(receipts), receipt, proof); err == nil {
+			t.Errorf("Receipt %d verified at the wrong index", i)
+		}
+	}
+
+	proof, _ := GenerateReceiptProof(receipts, 3)
+	forged := *receipts[3]
+	forged.Status = nil
+	if err := VerifyReceiptProof(header.ReceiptTrieRoot, 3, &forged, proof); err == nil {
+		t.Error("Expected a modified receipt to fail")
+	}
+	if _, err := GenerateReceiptProof(receipts, 20); err == nil {
+		t.Error("Expected an out of range index to fail")
+	}
+}
@@ -99,7 +99,7 @@ func TestReceiptRLP(t *testing.T) {
 	receipt := &TransactionReceipt{
 		PostState:         []byte{0x01},
 		CumulativeGasUsed: 21000,
-		Bloom:             types.BytesToBloom([]byte{0x01, 0x02}),
+		Bloom:             Bloom(types.BytesToBloom([]byte{0x01, 0x02})),
 		Logs: []*Log{
 			{
 				Address: common.HexToAddress("0x1111111111111111111111111111111111111111"),
@@ -132,3 +132,60 @@ func TestReceiptRLP(t *testing.T) {
 		t.Errorf("Logs len mismatch")
 	}
 }
+
+func TestReceiptStatusAndRoundTrip(t *testing.T) {
+	for _, c := range []struct {
+		raw       string
+		succeeded bool
+	}{{RlpReceiptSuccess, true}, {RlpReceiptFailed, false}} {
+		raw, _ := hex.DecodeString(c.raw)
+		var receipt TransactionReceipt
+		if err := rlp.DecodeBytes(raw, &receipt); err != nil {
+			t.Fatal(err)
+		}
+		if receipt.Succeeded() != c.succeeded {
+			t.Errorf("Succeeded() = %v, want %v", receipt.Succeeded(), c.succeeded)
+		}
+		encoded, _ := rlp.EncodeToBytes(&receipt)
+		if hex.EncodeToString(encoded) != c.raw {
+			t.Errorf("Receipt does not round-trip:\n %x\nwant\n %s", encoded, c.raw)
+		}
+	}
+}
+
+func TestReceiptWithoutStatus(t *testing.T) {
+	legacy, err := rlp.EncodeToBytes([]interface{}{[]byte{0x01}, []byte{0x52, 0x08}, types.Bloom{}, []interface{}{}, []byte{0x52, 0x08}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var receipt TransactionReceipt
+	if err := rlp.DecodeBytes(legacy, &receipt); err != nil {
+		t.Fatalf("Receipt without status failed to decode: %v", err)
+	}
+	if receipt.Status != nil || receipt.Succeeded() || receipt.GasUsed != 21000 {
+		t.Errorf("Unexpected receipt %+v", receipt)
+	}
+
+	oversized, _ := rlp.EncodeToBytes([]interface{}{[]byte{}, make([]byte, 9), types.Bloom{}, []interface{}{}, []byte{}, []byte{}})
+	if err := rlp.DecodeBytes(oversized, &receipt); err == nil {
+		t.Error("Expected error for a 9-byte gas value")
+	}
+}
+
+func TestReceiptBloom(t *testing.T) {
+	log := &Log{
+		Address: common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		Topics:  []common.Hash{common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")},
+	}
+	receipt := &TransactionReceipt{Logs: []*Log{log}, Bloom: CreateBloom([]*Log{log})}
+	if err := receipt.VerifyBloom(); err != nil {
+		t.Fatal(err)
+	}
+	if !receipt.Bloom.Matches(log.Address[:]) || !receipt.Bloom.Matches(log.Topics[0][:]) {
+		t.Error("Bloom does not contain the log's address and topic")
+	}
+	receipt.Bloom = Bloom{}
+	if err := receipt.VerifyBloom(); err == nil {
+		t.Error("Expected an empty bloom to fail")
+	}
+}
@@ -0,0 +1,59 @@
+package rskblocks
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+)
+
+// NewRemascTransaction returns the REMASC transaction of block blockNumber:
+// a call to the REMASC contract with zero gas, gas price and value, no data
+// and no signature, whose nonce is blockNumber-1.
+func NewRemascTransaction(blockNumber uint64) *Transaction {
+	var nonce uint64
+	if blockNumber > 0 {
+		nonce = blockNumber - 1
+	}
+	return NewTransaction(nonce, rsktrie.RemascAddress, nil, 0, nil, nil)
+}
+
+// IsRemasc reports whether tx has the shape of a REMASC transaction, as
+// rskj's isRemascTransaction checks it: sent to the REMASC contract with
+// zero gas, gas price and value, no data, and no signature. Where it may
+// appear is checked by Block.VerifyRemasc.
+func (tx *Transaction) IsRemasc() bool {
+	d := tx.data
+	return d.Recipient != nil && *d.Recipient == rsktrie.RemascAddress &&
+		d.GasLimit == 0 && bigOrZero(d.Price).Sign() == 0 && bigOrZero(d.Amount).Sign() == 0 &&
+		len(d.Payload) == 0 &&
+		bigOrZero(d.V).Sign() == 0 && bigOrZero(d.R).Sign() == 0 && bigOrZero(d.S).Sign() == 0
+}
+
+// VerifyRemasc checks the block's REMASC transaction: every block but
+// genesis ends with exactly one, whose nonce is the parent's number, and no
+// other transaction is REMASC-shaped.
+func (b *Block) VerifyRemasc() error {
+	number := bigOrZero(b.Header.Number)
+	if number.Sign() == 0 {
+		return nil
+	}
+	n := len(b.Transactions)
+	if n == 0 {
+		return errors.New("block has no REMASC transaction")
+	}
+	last := b.Transactions[n-1]
+	if !last.IsRemasc() {
+		return errors.New("last transaction is not a REMASC transaction")
+	}
+	if want := new(big.Int).Sub(number, big.NewInt(1)); new(big.Int).SetUint64(last.Nonce()).Cmp(want) != 0 {
+		return fmt.Errorf("REMASC transaction has nonce %d, want %v", last.Nonce(), want)
+	}
+	for i, tx := range b.Transactions[:n-1] {
+		if tx.IsRemasc() {
+			return fmt.Errorf("transaction %d is a REMASC transaction but not last", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package rskblocks
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestNewRemascTransaction(t *testing.T) {
+	tx := NewRemascTransaction(2)
+	if !tx.IsRemasc() || tx.Nonce() != 1 {
+		t.Fatalf("Unexpected REMASC transaction %+v", tx.data)
+	}
+	// Hash of block 2's REMASC transaction as reported by an RSK node; see
+	// TestRemascTransaction.
+	if want := common.HexToHash("0x2508efeddbab2f46ce53e0fb5ed61df9ac1ce696311941207833d7365194dacd"); tx.Hash() != want {
+		t.Errorf("REMASC hash %s, want %s", tx.Hash(), want)
+	}
+	raw, err := tx.GetEncodedRLP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Transaction
+	if err := rlp.DecodeBytes(raw, &decoded); err != nil {
+		t.Fatalf("REMASC transaction does not decode: %v", err)
+	}
+	if !decoded.IsRemasc() || decoded.Hash() != tx.Hash() {
+		t.Error("Decoded REMASC transaction differs")
+	}
+	if from, err := NewEIP155Signer(RegtestChainID).Sender(&decoded); err != nil || from != (common.Address{}) {
+		t.Errorf("REMASC sender = %s, %v", from, err)
+	}
+
+	remasc := common.HexToAddress("0x0000000000000000000000000000000001000008")
+	withValue := NewTransaction(1, remasc, big.NewInt(1), 0, nil, nil)
+	if withValue.IsRemasc() {
+		t.Error("A transfer to the REMASC contract is not a REMASC transaction")
+	}
+}
+
+func TestBlockVerifyRemasc(t *testing.T) {
+	block := testBlock(t)
+	if err := block.VerifyRemasc(); err == nil {
+		t.Error("Expected a wrong REMASC nonce to fail")
+	}
+
+	block.Transactions[1] = NewRemascTransaction(block.Header.Number.Uint64())
+	if err := block.VerifyRemasc(); err != nil {
+		t.Fatalf("VerifyRemasc failed: %v", err)
+	}
+
+	swapped := &Block{Header: block.Header, Transactions: []*Transaction{block.Transactions[1], block.Transactions[0]}}
+	if err := swapped.VerifyRemasc(); err == nil {
+		t.Error("Expected a REMASC transaction that is not last to fail")
+	}
+	twice := &Block{Header: block.Header, Transactions: []*Transaction{block.Transactions[1], block.Transactions[1]}}
+	if err := twice.VerifyRemasc(); err == nil {
+		t.Error("Expected two REMASC transactions to fail")
+	}
+	if err := (&Block{Header: block.Header}).VerifyRemasc(); err == nil {
+		t.Error("Expected a block without transactions to fail")
+	}
+	if err := (&Block{Header: &BlockHeader{Number: big.NewInt(0)}}).VerifyRemasc(); err != nil {
+		t.Errorf("Genesis needs no REMASC transaction: %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package rskblocks
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ResponseVerification is the consolidated result of verifying a whole
+// eth_getProof response against a block header.
+type ResponseVerification struct {
+	// StateRoot is the header's state root every proof was checked against.
+	StateRoot common.Hash
+
+	Account *AccountProofResult
+
+	// Storage holds one result per storage proof, in response order.
+	Storage []*StorageProofResult
+
+	// Mismatches lists fields the node reported that disagree with the
+	// proven state, such as a balance not matching the account record.
+	Mismatches []error
+
+	// Valid is true when every proof verified and no field mismatched.
+	Valid bool
+}
+
+// Err returns every proof failure and mismatch joined, or nil if the response
+// is valid.
+func (r *ResponseVerification) Err() error {
+	var errs []error
+	if r.Account != nil && r.Account.Error != nil {
+		errs = append(errs, fmt.Errorf("account proof: %w", r.Account.Error))
+	}
+	for _, s := range r.Storage {
+		if s.Error != nil {
+			errs = append(errs, fmt.Errorf("storage proof %s: %w", s.StorageKey, s.Error))
+		}
+	}
+	return errors.Join(append(errs, r.Mismatches...)...)
+}
+
+// VerifyEthGetProofResponse verifies an entire eth_getProof response against
+// header's state root: the account proof, every storage proof, and that the
+// balance, nonce and storage values the node reported are the ones the proofs
+// commit to. An absent account must be reported with zero balance and nonce,
+// and an absent slot with value zero. A verifier for an Orchid-era block (see
+// NewProofVerifierForBlock) verifies storage proofs against the account's
+// storage root instead.
+//
+// Proof failures and mismatches are reported in the result; the error is only
+// non-nil for a response that cannot be decoded.
+func (v *ProofVerifier) VerifyEthGetProofResponse(header *BlockHeader, response *ProofResponse) (*ResponseVerification, error) {
+	result := &ResponseVerification{StateRoot: header.StateRoot}
+
+	accountNodes, err := DecodeRLPProofNodes(response.AccountProof)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode account proof nodes: %w", err)
+	}
+	result.Account, err = v.VerifyAccountProof(header.StateRoot, response.Address, accountNodes)
+	if err != nil {
+		return nil, fmt.Errorf("account proof verification error: %w", err)
+	}
+
+	// An Orchid proof commits to the account record by hash only. The
+	// record is exactly the reported fields, so rebuild it and check it
+	// against the hash; any misreported field then fails the proof.
+	if result.Account.Valid && result.Account.ValueHash != nil {
+		record, err := (&rsktrie.AccountState{
+			Nonce:       new(big.Int).SetUint64(response.GetNonce()),
+			Balance:     response.GetBalance(),
+			StorageRoot: response.StorageHash,
+			CodeHash:    response.CodeHash,
+		}).Encode()
+		if err != nil {
+			return nil, err
+		}
+		result.Account, err = v.VerifyAccountProofWithValue(header.StateRoot, response.Address, record, accountNodes)
+		if err != nil {
+			return nil, fmt.Errorf("account proof verification error: %w", err)
+		}
+	}
+
+	// Orchid storage lives in a per-contract trie under the record's
+	// storage root.
+	storageRoot := header.StateRoot
+	if v.keyMapper.Version() == rsktrie.KeyMapperOrchid {
+		storageRoot = result.Account.StorageRoot
+	}
+
+	if result.Account.Valid {
+		if reported := response.GetBalance(); reported.Cmp(result.Account.Balance) != 0 {
+			result.Mismatches = append(result.Mismatches,
+				fmt.Errorf("reported balance %s, proven %s", reported, result.Account.Balance))
+		}
+		if reported := new(big.Int).SetUint64(response.GetNonce()); reported.Cmp(result.Account.Nonce) != 0 {
+			result.Mismatches = append(result.Mismatches,
+				fmt.Errorf("reported nonce %s, proven %s", reported, result.Account.Nonce))
+		}
+	}
+
+	for _, sp := range response.StorageProof {
+		key := common.HexToHash(sp.Key)
+		nodes, err := DecodeRLPProofNodes(sp.Proofs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode storage proof nodes for key %s: %w", sp.Key, err)
+		}
+		storage, err := v.VerifyStorageProof(storageRoot, response.Address, key, nodes)
+		if err != nil {
+			return nil, fmt.Errorf("storage proof verification error for key %s: %w", sp.Key, err)
+		}
+		result.Storage = append(result.Storage, storage)
+		if !storage.Valid {
+			continue
+		}
+		reported, ok := parseStorageValue(sp.Value)
+		if !ok {
+			result.Mismatches = append(result.Mismatches,
+				fmt.Errorf("storage %s: malformed reported value %q", key, sp.Value))
+		} else if proven := new(big.Int).SetBytes(storage.Value); reported.Cmp(proven) != 0 {
+			result.Mismatches = append(result.Mismatches,
+				fmt.Errorf("storage %s: reported value %#x, proven %#x", key, reported, proven))
+		}
+	}
+
+	result.Valid = result.Err() == nil
+	return result, nil
+}
+
+// parseStorageValue parses a reported storage value. Nodes return "0x" or
+// "0x0" for empty slots and may keep leading zeros.
+func parseStorageValue(s string) (*big.Int, bool) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if digits == "" {
+		return new(big.Int), true
+	}
+	return new(big.Int).SetString(digits, 16)
+}
@@ -0,0 +1,85 @@
+package rskblocks
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func hexProof(nodes [][]byte) []string {
+	out := make([]string, len(nodes))
+	for i, n := range nodes {
+		out[i] = hexutil.Encode(n)
+	}
+	return out
+}
+
+func TestVerifyEthGetProofResponse(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	addr := common.HexToAddress("0x77045e71a7a2c50903d88e564cd72fab11e82051")
+	slot := common.BigToHash(big.NewInt(3))
+	emptySlot := common.BigToHash(big.NewInt(4))
+
+	account, err := (&rsktrie.AccountState{Nonce: big.NewInt(5), Balance: big.NewInt(123456)}).Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	trie := rsktrie.NewTrie(nil).
+		Put(mapper.GetAccountKey(addr), account).
+		Put(mapper.GetAccountStorageKey(addr, slot), []byte{0x2a})
+	header := &BlockHeader{StateRoot: common.BytesToHash(trie.GetHash())}
+
+	response := func() *ProofResponse {
+		return &ProofResponse{
+			Address:      addr,
+			AccountProof: hexProof(buildTestProof(t, trie, mapper.GetAccountKey(addr))),
+			Balance:      (*hexutil.Big)(big.NewInt(123456)),
+			Nonce:        5,
+			StorageProof: []StorageProof{
+				{Key: slot.Hex(), Value: "0x2a", Proofs: hexProof(buildTestProof(t, trie, mapper.GetAccountStorageKey(addr, slot)))},
+				{Key: emptySlot.Hex(), Value: "0x0", Proofs: hexProof(buildTestProof(t, trie, mapper.GetAccountStorageKey(addr, emptySlot)))},
+			},
+		}
+	}
+
+	verifier := NewProofVerifier()
+	result, err := verifier.VerifyEthGetProofResponse(header, response())
+	if err != nil {
+		t.Fatalf("VerifyEthGetProofResponse failed: %v", err)
+	}
+	if !result.Valid {
+		t.Fatalf("Expected valid response, got %v", result.Err())
+	}
+	if result.Account.Balance.Cmp(big.NewInt(123456)) != 0 || result.Account.Nonce.Int64() != 5 {
+		t.Errorf("Unexpected account state %+v", result.Account)
+	}
+	if len(result.Storage) != 2 || result.Storage[1].Status != rsktrie.ProofProvenAbsent {
+		t.Errorf("Unexpected storage results %+v", result.Storage)
+	}
+
+	// A node lying about the balance or a slot value is caught even though
+	// every proof is genuine.
+	lying := response()
+	lying.Balance = (*hexutil.Big)(big.NewInt(999))
+	lying.StorageProof[1].Value = "0x01"
+	result, err = verifier.VerifyEthGetProofResponse(header, lying)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Valid || len(result.Mismatches) != 2 {
+		t.Errorf("Expected 2 mismatches, got %v", result.Mismatches)
+	}
+
+	// A proof against another root fails.
+	result, err = verifier.VerifyEthGetProofResponse(&BlockHeader{StateRoot: common.Hash{0x01}}, response())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Valid || result.Account.Valid || result.Err() == nil {
+		t.Error("Expected invalid result against wrong state root")
+	}
+}
@@ -0,0 +1,110 @@
+package rskblocks
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ResultLRU is a ResultCache bounded in size and entry age, for memoizing hot
+// keys (token balances, oracle slots) across requests. Results are keyed by
+// state root rather than block hash: the root is what the proof commits to,
+// and blocks sharing a root share every result.
+//
+// Entries older than the TTL are dropped on access; beyond the size bound
+// the least recently used entry is evicted. Verified results never go stale,
+// so the TTL only bounds how long entries for old blocks linger.
+type ResultLRU struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	now   func() time.Time
+	ll    *list.List
+	items map[string]*list.Element
+
+	hits   uint64
+	misses uint64
+}
+
+type resultLRUEntry struct {
+	key    string
+	result *rsktrie.KeyProofResult
+	added  time.Time
+}
+
+// NewResultLRU returns a cache holding up to size results, each for at most
+// ttl. A ttl <= 0 keeps results until evicted.
+func NewResultLRU(size int, ttl time.Duration) *ResultLRU {
+	return &ResultLRU{
+		size:  size,
+		ttl:   ttl,
+		now:   time.Now,
+		ll:    list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+// Get implements ResultCache.
+func (c *ResultLRU) Get(root common.Hash, key []byte) (*rsktrie.KeyProofResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[string(root[:])+string(key)]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	entry := el.Value.(*resultLRUEntry)
+	if c.expired(entry) {
+		c.remove(el)
+		c.misses++
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	c.hits++
+	return entry.result, true
+}
+
+// Add implements ResultCache.
+func (c *ResultLRU) Add(root common.Hash, key []byte, result *rsktrie.KeyProofResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	k := string(root[:]) + string(key)
+	if el, ok := c.items[k]; ok {
+		entry := el.Value.(*resultLRUEntry)
+		entry.result, entry.added = result, c.now()
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[k] = c.ll.PushFront(&resultLRUEntry{key: k, result: result, added: c.now()})
+	for c.ll.Len() > c.size {
+		c.remove(c.ll.Back())
+	}
+}
+
+func (c *ResultLRU) expired(entry *resultLRUEntry) bool {
+	return c.ttl > 0 && c.now().Sub(entry.added) >= c.ttl
+}
+
+func (c *ResultLRU) remove(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*resultLRUEntry).key)
+}
+
+// ResultLRUStats reports the effectiveness of a ResultLRU.
+type ResultLRUStats struct {
+	Size   int
+	Hits   uint64
+	Misses uint64
+}
+
+// Stats returns the cache's size and hit counts. Size includes expired
+// entries not yet dropped.
+func (c *ResultLRU) Stats() ResultLRUStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ResultLRUStats{Size: c.ll.Len(), Hits: c.hits, Misses: c.misses}
+}
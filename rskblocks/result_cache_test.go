@@ -0,0 +1,59 @@
+package rskblocks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestResultLRU(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	cache := NewResultLRU(2, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	root := common.Hash{0x01}
+	present := &rsktrie.KeyProofResult{Status: rsktrie.ProofPresent, Value: []byte{0x2a}}
+	cache.Add(root, []byte{0x01}, present)
+	cache.Add(root, []byte{0x02}, present)
+	if r, ok := cache.Get(root, []byte{0x01}); !ok || r != present {
+		t.Fatalf("Expected a hit, got %v, %v", r, ok)
+	}
+	if _, ok := cache.Get(common.Hash{0x02}, []byte{0x01}); ok {
+		t.Error("Expected results to be keyed by root")
+	}
+
+	// Key 0x02 is now least recently used
+	cache.Add(root, []byte{0x03}, present)
+	if _, ok := cache.Get(root, []byte{0x02}); ok {
+		t.Error("Expected the least recently used entry to be evicted")
+	}
+	if _, ok := cache.Get(root, []byte{0x01}); !ok {
+		t.Error("Expected the recently used entry to be kept")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.Get(root, []byte{0x01}); ok {
+		t.Error("Expected the entry to expire")
+	}
+	if stats := cache.Stats(); stats.Size != 1 || stats.Hits != 2 || stats.Misses != 3 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	// The verifier fills the cache and answers from it
+	mapper := rsktrie.NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045e71a7a2c50903d88e564cd72fab11e82051")
+	slot := common.Hash{0x01}
+	trie := rsktrie.NewTrie(nil).Put(mapper.GetAccountStorageKey(contract, slot), []byte{0x2a})
+	stateRoot := common.BytesToHash(trie.GetHash())
+	verifier := NewProofVerifier(WithResultCache(cache))
+	proof := buildTestProof(t, trie, mapper.GetAccountStorageKey(contract, slot))
+	if r, err := verifier.VerifyStorageProof(stateRoot, contract, slot, proof); err != nil || !r.Valid {
+		t.Fatalf("Expected a valid proof, got %+v, %v", r, err)
+	}
+	if r, err := verifier.VerifyStorageProof(stateRoot, contract, slot, nil); err != nil || !r.Valid || r.Value[0] != 0x2a {
+		t.Errorf("Expected a cached result, got %+v, %v", r, err)
+	}
+}
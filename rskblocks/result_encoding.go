@@ -0,0 +1,200 @@
+package rskblocks
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// accountProofResultRLP is the wire form of an AccountProofResult. Nonce and
+// Balance are optBig encoded, to keep nil apart from zero; the audit is its
+// own MarshalBinary encoding. Error keeps only its message, and Exists
+// follows from Status.
+type accountProofResultRLP struct {
+	Valid       bool
+	Status      uint64
+	Address     common.Address
+	Value       []byte
+	Nonce       []byte
+	Balance     []byte
+	Error       string
+	ValueHash   []byte
+	StorageRoot common.Hash
+	Audit       []byte
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, so results can be
+// cached in Redis or on disk (and are encoded this way by encoding/gob). An
+// Error survives as its message only: decoding yields a plain error, which
+// no longer matches errors.As or errors.Is targets.
+func (r *AccountProofResult) MarshalBinary() ([]byte, error) {
+	audit, err := r.Audit.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return marshalResult(&accountProofResultRLP{
+		Valid:       r.Valid,
+		Status:      uint64(r.Status),
+		Address:     r.Address,
+		Value:       r.Value,
+		Nonce:       encodeOptBig(r.Nonce),
+		Balance:     encodeOptBig(r.Balance),
+		Error:       errorMessage(r.Error),
+		ValueHash:   r.ValueHash,
+		StorageRoot: r.StorageRoot,
+		Audit:       audit,
+	})
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (r *AccountProofResult) UnmarshalBinary(data []byte) error {
+	var dec accountProofResultRLP
+	if err := unmarshalResult("account proof result", data, &dec); err != nil {
+		return err
+	}
+	audit, err := decodeAudit(dec.Audit)
+	if err != nil {
+		return err
+	}
+	*r = AccountProofResult{
+		Valid:       dec.Valid,
+		Status:      rsktrie.ProofStatus(dec.Status),
+		Address:     dec.Address,
+		Exists:      rsktrie.ProofStatus(dec.Status) == rsktrie.ProofPresent,
+		Value:       nilIfEmpty(dec.Value),
+		Nonce:       decodeOptBig(dec.Nonce),
+		Balance:     decodeOptBig(dec.Balance),
+		Error:       messageError(dec.Error),
+		ValueHash:   nilIfEmpty(dec.ValueHash),
+		StorageRoot: dec.StorageRoot,
+		Audit:       audit,
+	}
+	return nil
+}
+
+type storageProofResultRLP struct {
+	Valid      bool
+	Status     uint64
+	StorageKey common.Hash
+	Value      []byte
+	ValueHash  []byte
+	Audit      []byte
+	Error      string
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, with the same caveat
+// about Error as AccountProofResult.MarshalBinary.
+func (r *StorageProofResult) MarshalBinary() ([]byte, error) {
+	audit, err := r.Audit.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return marshalResult(&storageProofResultRLP{
+		Valid:      r.Valid,
+		Status:     uint64(r.Status),
+		StorageKey: r.StorageKey,
+		Value:      r.Value,
+		ValueHash:  r.ValueHash,
+		Audit:      audit,
+		Error:      errorMessage(r.Error),
+	})
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (r *StorageProofResult) UnmarshalBinary(data []byte) error {
+	var dec storageProofResultRLP
+	if err := unmarshalResult("storage proof result", data, &dec); err != nil {
+		return err
+	}
+	audit, err := decodeAudit(dec.Audit)
+	if err != nil {
+		return err
+	}
+	*r = StorageProofResult{
+		Valid:      dec.Valid,
+		Status:     rsktrie.ProofStatus(dec.Status),
+		StorageKey: dec.StorageKey,
+		Value:      nilIfEmpty(dec.Value),
+		ValueHash:  nilIfEmpty(dec.ValueHash),
+		Audit:      audit,
+		Error:      messageError(dec.Error),
+	}
+	return nil
+}
+
+// marshalResult encodes v as a version byte followed by its RLP encoding,
+// as the rsktrie result encodings do.
+func marshalResult(v any) ([]byte, error) {
+	body, err := rlp.EncodeToBytes(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{rsktrie.ResultEncodingVersion}, body...), nil
+}
+
+func unmarshalResult(what string, data []byte, v any) error {
+	if len(data) == 0 {
+		return fmt.Errorf("empty %s", what)
+	}
+	if data[0] != rsktrie.ResultEncodingVersion {
+		return fmt.Errorf("unsupported %s version %d", what, data[0])
+	}
+	if err := rlp.DecodeBytes(data[1:], v); err != nil {
+		return fmt.Errorf("decode %s: %w", what, err)
+	}
+	return nil
+}
+
+func decodeAudit(data []byte) (*rsktrie.ProofAudit, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	audit := new(rsktrie.ProofAudit)
+	if err := audit.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return audit, nil
+}
+
+// encodeOptBig encodes a non-negative, possibly nil, integer: nil as no
+// bytes, otherwise a 0x01 marker followed by its big-endian bytes.
+func encodeOptBig(v *big.Int) []byte {
+	if v == nil {
+		return nil
+	}
+	return append([]byte{0x01}, v.Bytes()...)
+}
+
+func decodeOptBig(b []byte) *big.Int {
+	if len(b) == 0 {
+		return nil
+	}
+	return new(big.Int).SetBytes(b[1:])
+}
+
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func messageError(msg string) error {
+	if msg == "" {
+		return nil
+	}
+	return errors.New(msg)
+}
+
+// nilIfEmpty undoes RLP decoding nil byte slices as empty ones.
+func nilIfEmpty(b []byte) []byte {
+	if len(b) == 0 {
+		return nil
+	}
+	return b
+}
@@ -0,0 +1,68 @@
+package rskblocks
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestProofResultBinary(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045e71a7a2c50903d88e564cd72fab11e82051")
+	account, err := (&rsktrie.AccountState{Nonce: big.NewInt(0), Balance: big.NewInt(5)}).Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slot := common.Hash{0x01}
+	trie := rsktrie.NewTrie(nil).
+		Put(mapper.GetAccountKey(contract), account).
+		Put(mapper.GetAccountStorageKey(contract, slot), []byte{0x2a})
+	stateRoot := common.BytesToHash(trie.GetHash())
+	verifier := NewProofVerifier()
+
+	accountResult, err := verifier.VerifyAccountProof(stateRoot, contract, buildTestProof(t, trie, mapper.GetAccountKey(contract)))
+	if err != nil || !accountResult.Valid {
+		t.Fatalf("Expected a valid account proof, got %+v, %v", accountResult, err)
+	}
+	storageResult, err := verifier.VerifyStorageProof(stateRoot, contract, slot, buildTestProof(t, trie, mapper.GetAccountStorageKey(contract, slot)))
+	if err != nil || !storageResult.Valid {
+		t.Fatalf("Expected a valid storage proof, got %+v, %v", storageResult, err)
+	}
+
+	// gob encodes through MarshalBinary; a zero nonce must not come back nil
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(accountResult); err != nil {
+		t.Fatal(err)
+	}
+	var gotAccount AccountProofResult
+	if err := gob.NewDecoder(&buf).Decode(&gotAccount); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&gotAccount, accountResult) {
+		t.Errorf("Expected %+v, got %+v", accountResult, gotAccount)
+	}
+
+	data, err := storageResult.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotStorage StorageProofResult
+	if err := gotStorage.UnmarshalBinary(data); err != nil || !reflect.DeepEqual(&gotStorage, storageResult) {
+		t.Errorf("Expected %+v, got %+v, %v", storageResult, gotStorage, err)
+	}
+
+	// Errors survive as their message
+	invalid, _ := verifier.VerifyStorageProof(common.Hash{0x01}, contract, slot, buildTestProof(t, trie, mapper.GetAccountStorageKey(contract, slot)))
+	if data, err = invalid.MarshalBinary(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gotStorage.UnmarshalBinary(data); err != nil || gotStorage.Valid || gotStorage.Error.Error() != invalid.Error.Error() {
+		t.Errorf("Expected %v, got %+v, %v", invalid.Error, gotStorage, err)
+	}
+}
@@ -0,0 +1,250 @@
+package rskblocks
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// RPCBlock is a block as RSK's eth_getBlockByNumber and eth_getBlockByHash
+// return it, including the RSK-only fields, with hex quantities converted
+// to numbers. Quantities a response omits are nil.
+type RPCBlock struct {
+	Number               uint64
+	Hash                 common.Hash
+	ParentHash           common.Hash
+	UnclesHash           common.Hash // sha3Uncles
+	Miner                common.Address
+	StateRoot            common.Hash
+	TxTrieRoot           common.Hash // transactionsRoot
+	ReceiptTrieRoot      common.Hash // receiptsRoot
+	LogsBloom            Bloom
+	Difficulty           *big.Int
+	TotalDifficulty      *big.Int
+	CumulativeDifficulty *big.Int // Difficulty plus the uncles' difficulty
+	GasLimit             *big.Int
+	GasUsed              *big.Int
+	Timestamp            uint64
+	ExtraData            []byte
+	Size                 uint64
+	MinimumGasPrice      *big.Int
+	PaidFees             *big.Int
+
+	HashForMergedMining                    common.Hash
+	BitcoinMergedMiningHeader              []byte
+	BitcoinMergedMiningCoinbaseTransaction []byte
+	BitcoinMergedMiningMerkleProof         []byte
+
+	Uncles []common.Hash
+	// Transactions holds transaction hashes or, if full transactions were
+	// requested, transaction objects; see TransactionHashes.
+	Transactions json.RawMessage
+}
+
+// rpcBlockJSON is the wire form of RPCBlock. Quantities are kept as text
+// because RSKj encodes zero as "0x" in places, which hexutil.Big rejects.
+type rpcBlockJSON struct {
+	Number               string          `json:"number"`
+	Hash                 common.Hash     `json:"hash"`
+	ParentHash           common.Hash     `json:"parentHash"`
+	UnclesHash           common.Hash     `json:"sha3Uncles"`
+	Miner                common.Address  `json:"miner"`
+	StateRoot            common.Hash     `json:"stateRoot"`
+	TxTrieRoot           common.Hash     `json:"transactionsRoot"`
+	ReceiptTrieRoot      common.Hash     `json:"receiptsRoot"`
+	LogsBloom            Bloom           `json:"logsBloom"`
+	Difficulty           string          `json:"difficulty"`
+	TotalDifficulty      string          `json:"totalDifficulty"`
+	CumulativeDifficulty string          `json:"cumulativeDifficulty"`
+	GasLimit             string          `json:"gasLimit"`
+	GasUsed              string          `json:"gasUsed"`
+	Timestamp            string          `json:"timestamp"`
+	ExtraData            hexutil.Bytes   `json:"extraData"`
+	Size                 string          `json:"size"`
+	MinimumGasPrice      string          `json:"minimumGasPrice"`
+	PaidFees             string          `json:"paidFees"`
+	HashForMergedMining  common.Hash     `json:"hashForMergedMining"`
+	BtcHeader            hexutil.Bytes   `json:"bitcoinMergedMiningHeader"`
+	BtcCoinbase          hexutil.Bytes   `json:"bitcoinMergedMiningCoinbaseTransaction"`
+	BtcMerkleProof       hexutil.Bytes   `json:"bitcoinMergedMiningMerkleProof"`
+	Uncles               []common.Hash   `json:"uncles"`
+	Transactions         json.RawMessage `json:"transactions"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *RPCBlock) UnmarshalJSON(data []byte) error {
+	var dec rpcBlockJSON
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	*b = RPCBlock{
+		Hash:                                   dec.Hash,
+		ParentHash:                             dec.ParentHash,
+		UnclesHash:                             dec.UnclesHash,
+		Miner:                                  dec.Miner,
+		StateRoot:                              dec.StateRoot,
+		TxTrieRoot:                             dec.TxTrieRoot,
+		ReceiptTrieRoot:                        dec.ReceiptTrieRoot,
+		LogsBloom:                              dec.LogsBloom,
+		ExtraData:                              dec.ExtraData,
+		HashForMergedMining:                    dec.HashForMergedMining,
+		BitcoinMergedMiningHeader:              dec.BtcHeader,
+		BitcoinMergedMiningCoinbaseTransaction: dec.BtcCoinbase,
+		BitcoinMergedMiningMerkleProof:         dec.BtcMerkleProof,
+		Uncles:                                 dec.Uncles,
+		Transactions:                           dec.Transactions,
+	}
+	for _, q := range []struct {
+		name string
+		text string
+		dst  **big.Int
+	}{
+		{"difficulty", dec.Difficulty, &b.Difficulty},
+		{"totalDifficulty", dec.TotalDifficulty, &b.TotalDifficulty},
+		{"cumulativeDifficulty", dec.CumulativeDifficulty, &b.CumulativeDifficulty},
+		{"gasLimit", dec.GasLimit, &b.GasLimit},
+		{"gasUsed", dec.GasUsed, &b.GasUsed},
+		{"minimumGasPrice", dec.MinimumGasPrice, &b.MinimumGasPrice},
+		{"paidFees", dec.PaidFees, &b.PaidFees},
+	} {
+		if q.text == "" {
+			continue
+		}
+		v, ok := parseStorageValue(q.text)
+		if !ok {
+			return fmt.Errorf("invalid %s %q", q.name, q.text)
+		}
+		*q.dst = v
+	}
+	for _, q := range []struct {
+		name string
+		text string
+		dst  *uint64
+	}{
+		{"number", dec.Number, &b.Number},
+		{"timestamp", dec.Timestamp, &b.Timestamp},
+		{"size", dec.Size, &b.Size},
+	} {
+		v, ok := parseStorageValue(q.text)
+		if !ok || !v.IsUint64() {
+			return fmt.Errorf("invalid %s %q", q.name, q.text)
+		}
+		*q.dst = v.Uint64()
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (b RPCBlock) MarshalJSON() ([]byte, error) {
+	quantity := func(v *big.Int) string {
+		if v == nil {
+			return ""
+		}
+		return hexutil.EncodeBig(v)
+	}
+	return json.Marshal(&struct {
+		rpcBlockJSON
+		// Omit quantities that were absent rather than encode "".
+		Difficulty           string          `json:"difficulty,omitempty"`
+		TotalDifficulty      string          `json:"totalDifficulty,omitempty"`
+		CumulativeDifficulty string          `json:"cumulativeDifficulty,omitempty"`
+		GasLimit             string          `json:"gasLimit,omitempty"`
+		GasUsed              string          `json:"gasUsed,omitempty"`
+		MinimumGasPrice      string          `json:"minimumGasPrice,omitempty"`
+		PaidFees             string          `json:"paidFees,omitempty"`
+		Transactions         json.RawMessage `json:"transactions,omitempty"`
+	}{
+		rpcBlockJSON: rpcBlockJSON{
+			Number:              hexutil.EncodeUint64(b.Number),
+			Hash:                b.Hash,
+			ParentHash:          b.ParentHash,
+			UnclesHash:          b.UnclesHash,
+			Miner:               b.Miner,
+			StateRoot:           b.StateRoot,
+			TxTrieRoot:          b.TxTrieRoot,
+			ReceiptTrieRoot:     b.ReceiptTrieRoot,
+			LogsBloom:           b.LogsBloom,
+			Timestamp:           hexutil.EncodeUint64(b.Timestamp),
+			ExtraData:           b.ExtraData,
+			Size:                hexutil.EncodeUint64(b.Size),
+			HashForMergedMining: b.HashForMergedMining,
+			BtcHeader:           b.BitcoinMergedMiningHeader,
+			BtcCoinbase:         b.BitcoinMergedMiningCoinbaseTransaction,
+			BtcMerkleProof:      b.BitcoinMergedMiningMerkleProof,
+			Uncles:              b.Uncles,
+		},
+		Difficulty:           quantity(b.Difficulty),
+		TotalDifficulty:      quantity(b.TotalDifficulty),
+		CumulativeDifficulty: quantity(b.CumulativeDifficulty),
+		GasLimit:             quantity(b.GasLimit),
+		GasUsed:              quantity(b.GasUsed),
+		MinimumGasPrice:      quantity(b.MinimumGasPrice),
+		PaidFees:             quantity(b.PaidFees),
+		Transactions:         b.Transactions,
+	})
+}
+
+// ParseRPCBlock parses the JSON result of eth_getBlockByNumber or
+// eth_getBlockByHash.
+func ParseRPCBlock(data []byte) (*RPCBlock, error) {
+	var b RPCBlock
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parse block: %w", err)
+	}
+	return &b, nil
+}
+
+// TransactionHashes returns the hashes of the block's transactions, whether
+// the response lists hashes or full transaction objects.
+func (b *RPCBlock) TransactionHashes() ([]common.Hash, error) {
+	if len(b.Transactions) == 0 || string(b.Transactions) == "null" {
+		return nil, nil
+	}
+	var hashes []common.Hash
+	if err := json.Unmarshal(b.Transactions, &hashes); err == nil {
+		return hashes, nil
+	}
+	var txs []struct {
+		Hash common.Hash `json:"hash"`
+	}
+	if err := json.Unmarshal(b.Transactions, &txs); err != nil {
+		return nil, fmt.Errorf("parse block transactions: %w", err)
+	}
+	hashes = make([]common.Hash, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.Hash
+	}
+	return hashes, nil
+}
+
+// HeaderInput returns the header fields the response carries, for
+// ComputeBlockHash or InputToBlockHeader. eth_getBlock responses omit some
+// of what the hash commits to, such as the ummRoot and parallel execution
+// edges, so check the result against Hash, or use
+// rsk_getRawBlockHeaderByHash (see DecodeRawBlockHeaderByHash).
+func (b *RPCBlock) HeaderInput() *BlockHeaderInput {
+	return &BlockHeaderInput{
+		ParentHash:                             b.ParentHash,
+		UnclesHash:                             b.UnclesHash,
+		Coinbase:                               b.Miner,
+		StateRoot:                              b.StateRoot,
+		TxTrieRoot:                             b.TxTrieRoot,
+		ReceiptTrieRoot:                        b.ReceiptTrieRoot,
+		LogsBloom:                              b.LogsBloom,
+		Difficulty:                             b.Difficulty,
+		Number:                                 new(big.Int).SetUint64(b.Number),
+		GasLimit:                               b.GasLimit,
+		GasUsed:                                b.GasUsed,
+		Timestamp:                              new(big.Int).SetUint64(b.Timestamp),
+		ExtraData:                              b.ExtraData,
+		PaidFees:                               b.PaidFees,
+		MinimumGasPrice:                        b.MinimumGasPrice,
+		UncleCount:                             len(b.Uncles),
+		BitcoinMergedMiningHeader:              b.BitcoinMergedMiningHeader,
+		BitcoinMergedMiningMerkleProof:         b.BitcoinMergedMiningMerkleProof,
+		BitcoinMergedMiningCoinbaseTransaction: b.BitcoinMergedMiningCoinbaseTransaction,
+	}
+}
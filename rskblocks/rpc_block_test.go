@@ -0,0 +1,113 @@
+package rskblocks
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const testRPCBlockJSON = `{
+	"number": "0x6cf174",
+	"hash": "0xb0a3a3e8b7d1a8ce8ec39d7b6f2c83e7b0f6a66b9c2b9f4f1d3f7b1f2c5a9e01",
+	"parentHash": "0x0000000000000000000000000000000000000000000000000000000000000001",
+	"sha3Uncles": "0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347",
+	"miner": "0xec4ddeb4380ad69b3e509baad9f158cdf4e4681d",
+	"stateRoot": "0x0000000000000000000000000000000000000000000000000000000000000002",
+	"transactionsRoot": "0x0000000000000000000000000000000000000000000000000000000000000003",
+	"receiptsRoot": "0x0000000000000000000000000000000000000000000000000000000000000004",
+	"difficulty": "0x1234567",
+	"totalDifficulty": "0x10000000000",
+	"cumulativeDifficulty": "0x2345678",
+	"gasLimit": "0x67c280",
+	"gasUsed": "0x5208",
+	"timestamp": "0x69824213",
+	"extraData": "0x6578747261",
+	"size": "0x3c2",
+	"minimumGasPrice": "0x",
+	"paidFees": "0x3e8",
+	"hashForMergedMining": "0x0000000000000000000000000000000000000000000000000000000000000005",
+	"bitcoinMergedMiningHeader": "0xaabb",
+	"bitcoinMergedMiningCoinbaseTransaction": "0xcc",
+	"bitcoinMergedMiningMerkleProof": "0xdd",
+	"uncles": [
+		"0x00000000000000000000000000000000000000000000000000000000000000a1",
+		"0x00000000000000000000000000000000000000000000000000000000000000a2"
+	],
+	"transactions": ["0x00000000000000000000000000000000000000000000000000000000000000b1"]
+}`
+
+func TestParseRPCBlock(t *testing.T) {
+	b, err := ParseRPCBlock([]byte(testRPCBlockJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Number != 7139700 || b.Timestamp != 0x69824213 || b.Size != 0x3c2 {
+		t.Errorf("Number %d, timestamp %d, size %d", b.Number, b.Timestamp, b.Size)
+	}
+	if b.Difficulty.Int64() != 0x1234567 || b.CumulativeDifficulty.Int64() != 0x2345678 ||
+		b.TotalDifficulty.Int64() != 0x10000000000 || b.PaidFees.Int64() != 1000 {
+		t.Errorf("Quantities %v %v %v %v", b.Difficulty, b.CumulativeDifficulty, b.TotalDifficulty, b.PaidFees)
+	}
+	if b.MinimumGasPrice == nil || b.MinimumGasPrice.Sign() != 0 {
+		t.Errorf("minimumGasPrice \"0x\" parsed as %v", b.MinimumGasPrice)
+	}
+	if string(b.ExtraData) != "extra" || len(b.BitcoinMergedMiningHeader) != 2 || len(b.Uncles) != 2 {
+		t.Error("Byte fields or uncles not parsed")
+	}
+	hashes, err := b.TransactionHashes()
+	if err != nil || len(hashes) != 1 || hashes[0] != common.HexToHash("0xb1") {
+		t.Errorf("Transaction hashes %v, %v", hashes, err)
+	}
+
+	// The parsed fields are those testHeaderInput builds a header from.
+	b.BitcoinMergedMiningHeader, b.BitcoinMergedMiningCoinbaseTransaction, b.BitcoinMergedMiningMerkleProof = nil, nil, nil
+	b.LogsBloom[3] = 0x40
+	if got, want := ComputeBlockHash(b.HeaderInput(), DefaultRegtestConfig()), ComputeBlockHash(testHeaderInput(), DefaultRegtestConfig()); got != want {
+		t.Errorf("Header input hashes to %s, want %s", got, want)
+	}
+
+	// Round trip.
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := ParseRPCBlock(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Hash != b.Hash || again.PaidFees.Cmp(b.PaidFees) != 0 || again.Number != b.Number || again.LogsBloom != b.LogsBloom {
+		t.Errorf("Round trip changed the block: %s", data)
+	}
+}
+
+func TestRPCBlockOptionalFields(t *testing.T) {
+	b, err := ParseRPCBlock([]byte(`{"number":"0x1","timestamp":"0x2","size":"0x3","transactions":[{"hash":"0x00000000000000000000000000000000000000000000000000000000000000c1","nonce":"0x0"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Difficulty != nil || b.PaidFees != nil || b.CumulativeDifficulty != nil {
+		t.Error("Absent quantities are not nil")
+	}
+	hashes, err := b.TransactionHashes()
+	if err != nil || len(hashes) != 1 || hashes[0] != common.HexToHash("0xc1") {
+		t.Errorf("Full transaction hashes %v, %v", hashes, err)
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fields["paidFees"]; ok {
+		t.Errorf("Absent paidFees marshaled: %s", data)
+	}
+
+	for _, bad := range []string{`{"number":"0xzz"}`, `{"number":"0x1","timestamp":"0x10000000000000000"}`, `{"number":"0x1","difficulty":"x"}`} {
+		if _, err := ParseRPCBlock([]byte(bad)); err == nil {
+			t.Errorf("%s accepted", bad)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package rskblocks
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// StorageEntry is one slot of a contract's complete storage. As with
+// StorageProofResult, long values are carried by hash only.
+type StorageEntry struct {
+	Key []byte // the slot's trie key
+	// Slot is the storage slot, valid if SlotKnown. Slots are rebuilt from
+	// their trie key, falling back to the mapper's preimage store.
+	Slot      common.Hash
+	SlotKnown bool
+	Value     []byte
+	ValueHash []byte
+}
+
+// GenerateStorageSubtreeProof returns the nodes proving the complete storage
+// of address in trie: every key under its storage prefix.
+func GenerateStorageSubtreeProof(trie *rsktrie.Trie, mapper *rsktrie.TrieKeyMapper, address common.Address) ([][]byte, error) {
+	return trie.GeneratePrefixProof(mapper.GetAccountStoragePrefixKey(address))
+}
+
+// VerifyStorageSubtree verifies that proofNodes prove the complete storage of
+// address at stateRoot, as from GenerateStorageSubtreeProof, and returns
+// every slot in trie key order. No slot can be left out of a valid proof, so
+// an auditor can attest to the contract's full storage at the block. A
+// contract without storage verifies with no entries.
+//
+// Only unitrie state is supported: Orchid storage lives in a separate trie
+// per contract.
+func (v *ProofVerifier) VerifyStorageSubtree(stateRoot common.Hash, address common.Address, proofNodes [][]byte) ([]StorageEntry, error) {
+	mapper, err := v.unitrieMapper()
+	if err != nil {
+		return nil, err
+	}
+	prefix := mapper.GetAccountStoragePrefixKey(address)
+	entries, err := rsktrie.VerifyPrefixProof(stateRoot[:], prefix, proofNodes)
+	if err != nil {
+		return nil, err
+	}
+
+	var slots []StorageEntry
+	for _, e := range entries {
+		// The prefix key itself only marks that the account has storage
+		if bytes.Equal(e.Key, prefix) {
+			continue
+		}
+		entry := StorageEntry{Key: e.Key, Value: e.Value, ValueHash: e.ValueHash}
+		if _, slot, err := mapper.StorageSlotFromKey(e.Key); err == nil {
+			entry.Slot, entry.SlotKnown = slot, true
+		}
+		slots = append(slots, entry)
+	}
+	return slots, nil
+}
+
+// StorageSubtreeHash returns the hash of address's storage subtree from the
+// nodes of partial, a single commitment to the contract's whole storage, as
+// an Ethereum storage root is. ok is false if the contract has no storage.
+// partial needs only the nodes on the path to the storage prefix, which any
+// verified storage proof of the contract supplies.
+//
+// Only unitrie state is supported; Orchid accounts record their storage root
+// directly (see AccountProofResult.StorageRoot).
+func (v *ProofVerifier) StorageSubtreeHash(partial *rsktrie.PartialTrie, address common.Address) (hash common.Hash, ok bool, err error) {
+	mapper, err := v.unitrieMapper()
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+	return partial.SubtreeHash(mapper.GetAccountStoragePrefixKey(address))
+}
+
+// unitrieMapper returns the verifier's key mapper, for operations on the
+// unitrie storage layout.
+func (v *ProofVerifier) unitrieMapper() (*rsktrie.TrieKeyMapper, error) {
+	mapper, ok := v.keyMapper.(*rsktrie.TrieKeyMapper)
+	if !ok {
+		return nil, fmt.Errorf("storage subtrees need the unitrie key mapper, have %s", v.keyMapper.Version())
+	}
+	return mapper, nil
+}
@@ -0,0 +1,121 @@
+package rskblocks
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestVerifyStorageSubtree(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045e71a7a2c50903d88e564cd72fab11e82051")
+	other := common.HexToAddress("0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826")
+
+	trie := rsktrie.NewTrie(nil).
+		Put(mapper.GetAccountKey(contract), []byte{0x01}).
+		Put(mapper.GetAccountStoragePrefixKey(contract), []byte{0x01}).
+		Put(mapper.GetAccountStorageKey(other, common.Hash{0x01}), []byte{0x09})
+	want := map[common.Hash][]byte{}
+	for i := 1; i <= 12; i++ {
+		slot := common.BigToHash(big.NewInt(int64(i)))
+		want[slot] = []byte{byte(i)}
+		trie = trie.Put(mapper.GetAccountStorageKey(contract, slot), want[slot])
+	}
+	stateRoot := common.BytesToHash(trie.GetHash())
+
+	proof, err := GenerateStorageSubtreeProof(trie, mapper, contract)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := NewProofVerifier()
+	entries, err := verifier.VerifyStorageSubtree(stateRoot, contract, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %d slots, got %d", len(want), len(entries))
+	}
+	for _, e := range entries {
+		if !e.SlotKnown || !bytes.Equal(e.Value, want[e.Slot]) {
+			t.Errorf("Unexpected entry %+v", e)
+		}
+	}
+
+	// Dropping a node leaves part of the storage unproven
+	for i := range proof {
+		partial := append(append([][]byte{}, proof[:i]...), proof[i+1:]...)
+		if _, err := verifier.VerifyStorageSubtree(stateRoot, contract, partial); err == nil {
+			t.Errorf("Expected the proof without node %d to fail", i)
+		}
+	}
+
+	empty := common.HexToAddress("0x01")
+	proof, err = GenerateStorageSubtreeProof(trie, mapper, empty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := verifier.VerifyStorageSubtree(stateRoot, empty, proof); err != nil || len(entries) != 0 {
+		t.Errorf("Expected no storage, got %d entries, %v", len(entries), err)
+	}
+	if _, err := NewProofVerifier(WithKeyMapper(rsktrie.NewOrchidKeyMapper())).VerifyStorageSubtree(stateRoot, contract, proof); err == nil {
+		t.Error("Expected Orchid state to be unsupported")
+	}
+}
+
+func TestStorageSubtreeHash(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045e71a7a2c50903d88e564cd72fab11e82051")
+	other := common.HexToAddress("0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826")
+	slot := common.Hash{0x01}
+	build := func(contractValue, otherValue byte) *rsktrie.Trie {
+		return rsktrie.NewTrie(nil).
+			Put(mapper.GetAccountKey(contract), []byte{0x01}).
+			Put(mapper.GetAccountStoragePrefixKey(contract), []byte{0x01}).
+			Put(mapper.GetAccountStorageKey(contract, slot), []byte{contractValue}).
+			Put(mapper.GetAccountStorageKey(contract, common.Hash{0x02}), []byte{0x07}).
+			Put(mapper.GetAccountKey(other), []byte{0x01}).
+			Put(mapper.GetAccountStoragePrefixKey(other), []byte{0x01}).
+			Put(mapper.GetAccountStorageKey(other, slot), []byte{otherValue})
+	}
+	verifier := NewProofVerifier()
+	subtreeHash := func(trie *rsktrie.Trie, address common.Address) (common.Hash, bool) {
+		partial := rsktrie.NewPartialTrie(common.BytesToHash(trie.GetHash()))
+		if err := partial.AddProof(buildTestProof(t, trie, mapper.GetAccountStorageKey(contract, slot))); err != nil {
+			t.Fatal(err)
+		}
+		hash, ok, err := verifier.StorageSubtreeHash(partial, address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash, ok
+	}
+
+	base, ok := subtreeHash(build(0x2a, 0x09), contract)
+	if !ok {
+		t.Fatal("Expected the contract to have storage")
+	}
+	if h, _ := subtreeHash(build(0x2a, 0x0a), contract); h != base {
+		t.Error("Expected another contract's storage not to change the hash")
+	}
+	if h, _ := subtreeHash(build(0x2b, 0x09), contract); h == base {
+		t.Error("Expected a changed slot to change the hash")
+	}
+	if _, ok := subtreeHash(build(0x2a, 0x09), common.HexToAddress("0x01")); ok {
+		t.Error("Expected an account without storage to have no subtree")
+	}
+
+	// The other contract's storage is off the ingested path
+	trie := build(0x2a, 0x09)
+	partial := rsktrie.NewPartialTrie(common.BytesToHash(trie.GetHash()))
+	if err := partial.AddProof(buildTestProof(t, trie, mapper.GetAccountStorageKey(contract, slot))); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := verifier.StorageSubtreeHash(partial, other); !errors.Is(err, rsktrie.ErrNotCovered) {
+		t.Errorf("Expected ErrNotCovered, got %v", err)
+	}
+}
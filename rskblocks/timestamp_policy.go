@@ -0,0 +1,76 @@
+package rskblocks
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// TimestampOrder is how a header's timestamp must relate to its parent's.
+type TimestampOrder int
+
+const (
+	// TimestampAfterParent requires a later timestamp than the parent's, as
+	// consensus does.
+	TimestampAfterParent TimestampOrder = iota
+	// TimestampNotBeforeParent also accepts the parent's timestamp.
+	TimestampNotBeforeParent
+	// TimestampUnordered does not compare with the parent.
+	TimestampUnordered
+)
+
+// TimestampPolicy decides which header timestamps ChainValidator accepts.
+// Deployments whose clock is skewed, such as embedded devices without time
+// synchronization, can widen the drift or correct the clock by a known
+// offset instead of rejecting valid headers.
+type TimestampPolicy struct {
+	// MaxFutureDrift is how far past the current time a timestamp may be;
+	// zero disables the check.
+	MaxFutureDrift time.Duration
+	// ClockOffset is added to the local clock to get the current time, for
+	// a clock known to run behind (positive) or ahead (negative).
+	ClockOffset time.Duration
+	// Order is the required relation to the parent's timestamp.
+	Order TimestampOrder
+	// Now returns the local time; nil means time.Now.
+	Now func() time.Time
+}
+
+// DefaultTimestampPolicy returns rskj's rules: a timestamp must follow its
+// parent's and be at most 540 seconds in the future.
+func DefaultTimestampPolicy() TimestampPolicy {
+	return TimestampPolicy{MaxFutureDrift: 540 * time.Second}
+}
+
+// Check checks header's timestamp against parent's and the current time.
+func (p *TimestampPolicy) Check(header, parent *BlockHeader) error {
+	ts := bigOrZero(header.Timestamp)
+	switch cmp := ts.Cmp(bigOrZero(parent.Timestamp)); p.Order {
+	case TimestampAfterParent:
+		if cmp <= 0 {
+			return fmt.Errorf("timestamp %v is not after parent's %v", header.Timestamp, parent.Timestamp)
+		}
+	case TimestampNotBeforeParent:
+		if cmp < 0 {
+			return fmt.Errorf("timestamp %v is before parent's %v", header.Timestamp, parent.Timestamp)
+		}
+	case TimestampUnordered:
+	default:
+		return fmt.Errorf("unknown timestamp order %d", p.Order)
+	}
+	if p.MaxFutureDrift > 0 {
+		limit := big.NewInt(p.now().Add(p.MaxFutureDrift).Unix())
+		if ts.Cmp(limit) > 0 {
+			return fmt.Errorf("timestamp %v is more than %s in the future", header.Timestamp, p.MaxFutureDrift)
+		}
+	}
+	return nil
+}
+
+func (p *TimestampPolicy) now() time.Time {
+	now := time.Now
+	if p.Now != nil {
+		now = p.Now
+	}
+	return now().Add(p.ClockOffset)
+}
@@ -0,0 +1,48 @@
+package rskblocks
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestTimestampPolicy(t *testing.T) {
+	parent := testChain(1, nil)[0] // timestamp 1_700_000_000
+	child := func(ts int64) *BlockHeader {
+		return testChild(parent, func(input *BlockHeaderInput) { input.Timestamp = big.NewInt(ts) })
+	}
+	now := func() time.Time { return time.Unix(1_700_000_100, 0) }
+
+	cases := []struct {
+		name   string
+		policy TimestampPolicy
+		ts     int64
+		ok     bool
+	}{
+		{"default later", TimestampPolicy{MaxFutureDrift: 540 * time.Second, Now: now}, 1_700_000_010, true},
+		{"default equal", TimestampPolicy{Now: now}, 1_700_000_000, false},
+		{"not before equal", TimestampPolicy{Order: TimestampNotBeforeParent}, 1_700_000_000, true},
+		{"not before earlier", TimestampPolicy{Order: TimestampNotBeforeParent}, 1_699_999_999, false},
+		{"unordered earlier", TimestampPolicy{Order: TimestampUnordered}, 1_699_999_000, true},
+		{"unknown order", TimestampPolicy{Order: 7}, 1_700_000_010, false},
+		{"within drift", TimestampPolicy{MaxFutureDrift: time.Minute, Now: now}, 1_700_000_160, true},
+		{"past drift", TimestampPolicy{MaxFutureDrift: time.Minute, Now: now}, 1_700_000_161, false},
+		{"slow clock corrected", TimestampPolicy{MaxFutureDrift: time.Minute, ClockOffset: time.Hour, Now: now}, 1_700_003_000, true},
+		{"fast clock corrected", TimestampPolicy{MaxFutureDrift: time.Minute, ClockOffset: -time.Minute, Now: now}, 1_700_000_101, false},
+		{"drift disabled", TimestampPolicy{Now: now}, 1_800_000_000, true},
+	}
+	for _, c := range cases {
+		if err := c.policy.Check(child(c.ts), parent); (err == nil) != c.ok {
+			t.Errorf("%s: %v", c.name, err)
+		}
+	}
+
+	if p := DefaultTimestampPolicy(); p.MaxFutureDrift != 540*time.Second || p.Order != TimestampAfterParent {
+		t.Errorf("Default policy %+v", p)
+	}
+	v := testChainValidator()
+	v.Timestamps.Order = TimestampUnordered
+	if err := v.ValidateChild(child(1_699_999_000), parent); err != nil {
+		t.Errorf("Validator ignored its timestamp policy: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package rskblocks
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie/solstorage"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ERC20BalanceSlot returns the storage slot of holder's balance in a token
+// whose balances mapping(address => uint256) is declared at balanceSlotIndex
+// (0 for OpenZeppelin's ERC20).
+func ERC20BalanceSlot(holder common.Address, balanceSlotIndex uint64) common.Hash {
+	return solstorage.Mapping(solstorage.Slot(balanceSlotIndex), solstorage.AddressKey(holder))
+}
+
+// VerifyERC20Balance verifies a storage proof for holder's balance slot in
+// token and returns the balance. A proof that the slot does not exist yields
+// a zero balance.
+//
+// Parameters:
+//   - stateRoot: The state root from the block header
+//   - token: The ERC20 contract address
+//   - holder: The account whose balance is read
+//   - balanceSlotIndex: The declaration slot of the balances mapping
+//   - proofNodes: RLP-encoded trie nodes from eth_getProof for ERC20BalanceSlot(holder, balanceSlotIndex)
+func (v *ProofVerifier) VerifyERC20Balance(
+	stateRoot common.Hash,
+	token common.Address,
+	holder common.Address,
+	balanceSlotIndex uint64,
+	proofNodes [][]byte,
+) (*big.Int, error) {
+	value, err := v.verifiedStorageWord(stateRoot, token, ERC20BalanceSlot(holder, balanceSlotIndex), proofNodes)
+	if err != nil {
+		return nil, fmt.Errorf("verify balance of %s in %s: %w", holder.Hex(), token.Hex(), err)
+	}
+	return new(big.Int).SetBytes(value), nil
+}
+
+// verifiedStorageWord verifies a storage proof and returns the slot's value,
+// as RSK stores it: at most 32 bytes with leading zeros stripped, nil if the
+// slot does not exist.
+func (v *ProofVerifier) verifiedStorageWord(
+	stateRoot common.Hash,
+	address common.Address,
+	slot common.Hash,
+	proofNodes [][]byte,
+) ([]byte, error) {
+	result, err := v.VerifyStorageProof(stateRoot, address, slot, proofNodes)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Valid {
+		return nil, result.Error
+	}
+	if result.Status == rsktrie.ProofPresent && len(result.Value) == 0 {
+		return nil, fmt.Errorf("storage value of slot %x is not included in the proof", slot)
+	}
+	if len(result.Value) > common.HashLength {
+		return nil, fmt.Errorf("storage value of slot %x is %d bytes, expected at most 32", slot, len(result.Value))
+	}
+	return result.Value, nil
+}
+
+// ERC721OwnerSlot returns the storage slot of tokenID's owner in a token whose
+// owners mapping(uint256 => address) is declared at ownersSlotIndex (2 for
+// OpenZeppelin's ERC721 v4 and v5).
+func ERC721OwnerSlot(tokenID *big.Int, ownersSlotIndex uint64) common.Hash {
+	return solstorage.Mapping(solstorage.Slot(ownersSlotIndex), solstorage.UintKey(tokenID))
+}
+
+// VerifyERC721Owner verifies a storage proof for tokenID's owner slot in token
+// and returns the owner. A proof that the slot does not exist yields the zero
+// address, i.e. the token is not minted.
+//
+// Parameters:
+//   - stateRoot: The state root from the block header
+//   - token: The ERC721 contract address
+//   - tokenID: The token whose owner is read
+//   - ownersSlotIndex: The declaration slot of the owners mapping
+//   - proofNodes: RLP-encoded trie nodes from eth_getProof for ERC721OwnerSlot(tokenID, ownersSlotIndex)
+func (v *ProofVerifier) VerifyERC721Owner(
+	stateRoot common.Hash,
+	token common.Address,
+	tokenID *big.Int,
+	ownersSlotIndex uint64,
+	proofNodes [][]byte,
+) (common.Address, error) {
+	value, err := v.verifiedStorageWord(stateRoot, token, ERC721OwnerSlot(tokenID, ownersSlotIndex), proofNodes)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("verify owner of token %s in %s: %w", tokenID, token.Hex(), err)
+	}
+	if len(value) > common.AddressLength {
+		return common.Address{}, fmt.Errorf("owner slot of token %s holds %d bytes, not an address", tokenID, len(value))
+	}
+	return common.BytesToAddress(value), nil
+}
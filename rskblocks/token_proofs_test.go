@@ -0,0 +1,86 @@
+package rskblocks
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// buildTestProof returns the RLP proof nodes, leaf to root, on the path to key.
+func buildTestProof(t *testing.T, root *rsktrie.Trie, key []byte) [][]byte {
+	t.Helper()
+	proof, err := root.GenerateProof(key)
+	if err != nil {
+		t.Fatalf("GenerateProof failed: %v", err)
+	}
+	return proof
+}
+
+func TestVerifyERC20Balance(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	token := common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051")
+	holder := common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
+	other := common.HexToAddress("0x0000000000000000000000000000000000001234")
+
+	balance := big.NewInt(1_000_000_000)
+	trie := rsktrie.NewTrie(nil).
+		Put(mapper.GetAccountKey(token), []byte{0x01}).
+		Put(mapper.GetAccountStorageKey(token, ERC20BalanceSlot(holder, 0)), balance.Bytes()).
+		Put(mapper.GetAccountStorageKey(token, ERC20BalanceSlot(other, 0)), []byte{0x07})
+	stateRoot := common.BytesToHash(trie.GetHash())
+
+	verifier := NewProofVerifier()
+	proof := buildTestProof(t, trie, mapper.GetAccountStorageKey(token, ERC20BalanceSlot(holder, 0)))
+	got, err := verifier.VerifyERC20Balance(stateRoot, token, holder, 0, proof)
+	if err != nil {
+		t.Fatalf("VerifyERC20Balance failed: %v", err)
+	}
+	if got.Cmp(balance) != 0 {
+		t.Errorf("Expected balance %s, got %s", balance, got)
+	}
+
+	// A proof for the wrong mapping slot does not prove the balance.
+	if got, err := verifier.VerifyERC20Balance(stateRoot, token, holder, 1, proof); err == nil && got.Sign() != 0 {
+		t.Errorf("Expected zero or error for wrong slot index, got %s", got)
+	}
+
+	if _, err := verifier.VerifyERC20Balance(common.Hash{}, token, holder, 0, proof); err == nil {
+		t.Error("Expected error for mismatched state root")
+	}
+}
+
+func TestVerifyERC721Owner(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	token := common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051")
+	owner := common.HexToAddress("0x00a2d9F938E13CD947Ec05AbC7FE734Df8DD8260")
+	tokenID := big.NewInt(42)
+
+	ownerKey := mapper.GetAccountStorageKey(token, ERC721OwnerSlot(tokenID, 2))
+	trie := rsktrie.NewTrie(nil).
+		Put(mapper.GetAccountKey(token), []byte{0x01}).
+		Put(ownerKey, common.TrimLeftZeroes(owner.Bytes())).
+		Put(mapper.GetAccountStorageKey(token, ERC721OwnerSlot(big.NewInt(43), 2)), []byte{0x09})
+	stateRoot := common.BytesToHash(trie.GetHash())
+
+	verifier := NewProofVerifier()
+	got, err := verifier.VerifyERC721Owner(stateRoot, token, tokenID, 2, buildTestProof(t, trie, ownerKey))
+	if err != nil {
+		t.Fatalf("VerifyERC721Owner failed: %v", err)
+	}
+	if got != owner {
+		t.Errorf("Expected owner %s, got %s", owner.Hex(), got.Hex())
+	}
+
+	// Token 44 was never minted: the proof shows its slot is absent.
+	missingKey := mapper.GetAccountStorageKey(token, ERC721OwnerSlot(big.NewInt(44), 2))
+	got, err = verifier.VerifyERC721Owner(stateRoot, token, big.NewInt(44), 2, buildTestProof(t, trie, missingKey))
+	if err != nil {
+		t.Fatalf("VerifyERC721Owner for unminted token failed: %v", err)
+	}
+	if got != (common.Address{}) {
+		t.Errorf("Expected zero owner for unminted token, got %s", got.Hex())
+	}
+}
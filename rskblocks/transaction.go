@@ -2,6 +2,7 @@ package rskblocks
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"math/big"
 	"sync/atomic"
@@ -253,13 +254,44 @@ func (tx *Transaction) GetEncodedRLP() ([]byte, error) {
 }
 
 // DecodeRLP implements rlp.Decoder
+// Fields are read as raw bytes rather than canonical integers, since RSK's
+// encoding of internal transactions writes zero gas price and gas limit as
+// a single 0x00 byte.
 func (tx *Transaction) DecodeRLP(s *rlp.Stream) error {
 	_, size, _ := s.Kind()
-	err := s.Decode(&tx.data)
-	if err == nil {
-		tx.size.Store(common.StorageSize(rlp.ListSize(size)))
+	var fields [][]byte
+	if err := s.Decode(&fields); err != nil {
+		return err
 	}
-	return err
+	if len(fields) != 9 {
+		return fmt.Errorf("transaction has %d fields, want 9", len(fields))
+	}
+	for i, name := range []string{"nonce", "gas limit"} {
+		if field := fields[i*2]; len(field) > 8 {
+			return fmt.Errorf("transaction %s has %d bytes", name, len(field))
+		}
+	}
+	d := txdata{
+		AccountNonce: bytesToUint64(fields[0]),
+		Price:        new(big.Int).SetBytes(fields[1]),
+		GasLimit:     bytesToUint64(fields[2]),
+		Amount:       new(big.Int).SetBytes(fields[4]),
+		Payload:      fields[5],
+		V:            new(big.Int).SetBytes(fields[6]),
+		R:            new(big.Int).SetBytes(fields[7]),
+		S:            new(big.Int).SetBytes(fields[8]),
+	}
+	switch len(fields[3]) {
+	case 0:
+	case common.AddressLength:
+		to := common.BytesToAddress(fields[3])
+		d.Recipient = &to
+	default:
+		return fmt.Errorf("transaction recipient has %d bytes", len(fields[3]))
+	}
+	tx.data = d
+	tx.size.Store(common.StorageSize(rlp.ListSize(size)))
+	return nil
 }
 
 func (tx *Transaction) Hash() common.Hash {
@@ -291,10 +323,19 @@ func (tx *Transaction) To() *common.Address {
 	return &to
 }
 
-func (tx *Transaction) WithSignature(signer Signer, sig []byte) (*Transaction, error) {
-	// Stub for simplicity if needed, but for now we might expose V,R,S setters or assume decoded
-	return nil, nil
+// RawSignatureValues returns the transaction's v, r and s.
+func (tx *Transaction) RawSignatureValues() (v, r, s *big.Int) {
+	return new(big.Int).Set(bigOrZero(tx.data.V)), new(big.Int).Set(bigOrZero(tx.data.R)), new(big.Int).Set(bigOrZero(tx.data.S))
 }
 
-// Signer interface stub
-type Signer interface{}
+// WithSignature returns a copy of tx signed with sig, a 65-byte
+// [R || S || V] signature over signer.Hash(tx).
+func (tx *Transaction) WithSignature(signer Signer, sig []byte) (*Transaction, error) {
+	v, r, s, err := signer.SignatureValues(sig)
+	if err != nil {
+		return nil, err
+	}
+	d := tx.data
+	d.V, d.R, d.S = v, r, s
+	return &Transaction{data: d}, nil
+}
@@ -0,0 +1,34 @@
+package rskblocks
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// VerifyTxRoot reports whether txs are the block's transactions, in order,
+// by recomputing the transactions trie root.
+func VerifyTxRoot(header *BlockHeader, txs []*Transaction) error {
+	if root := GetTxTrieRoot(txs); !bytes.Equal(root, header.TxTrieRoot[:]) {
+		return fmt.Errorf("transactions hash to trie root %x, header has %s", root, header.TxTrieRoot.Hex())
+	}
+	return nil
+}
+
+// GenerateTxProof returns the proof, leaf to root, that txs[index] is in the
+// transactions trie of a block with these transactions.
+func GenerateTxProof(txs []*Transaction, index int) ([][]byte, error) {
+	if index < 0 || index >= len(txs) {
+		return nil, fmt.Errorf("transaction index %d out of range [0, %d)", index, len(txs))
+	}
+	return GetTxTrieFor(txs).GenerateProof(indexKey(index))
+}
+
+// VerifyTxProof verifies that rawTx, a transaction's RLP encoding as sent
+// with eth_sendRawTransaction, is the index-th transaction of the block
+// whose header commits to txRoot. With a validated header this detects a
+// deposit without trusting the node that reported it.
+func VerifyTxProof(txRoot common.Hash, index int, rawTx []byte, proof [][]byte) error {
+	return verifyIndexedProof("transaction", txRoot, index, rawTx, proof)
+}
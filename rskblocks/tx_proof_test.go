@@ -0,0 +1,45 @@
+package rskblocks
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestTxProof(t *testing.T) {
+	block := testBlock(t)
+	header, txs := block.Header, block.Transactions
+	if err := VerifyTxRoot(header, txs); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyTxRoot(header, txs[:1]); err == nil {
+		t.Error("Expected a missing transaction to change the root")
+	}
+
+	for i, tx := range txs {
+		raw, err := tx.GetEncodedRLP()
+		if err != nil {
+			t.Fatal(err)
+		}
+		proof, err := GenerateTxProof(txs, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := VerifyTxProof(header.TxTrieRoot, i, raw, proof); err != nil {
+			t.Fatalf("Transaction %d: %v", i, err)
+		}
+		if err := VerifyTxProof(header.TxTrieRoot, 1-i, raw, proof); err == nil {
+			t.Errorf("Transaction %d verified at the wrong index", i)
+		}
+		if err := VerifyTxProof(common.Hash{1}, i, raw, proof); err == nil {
+			t.Errorf("Transaction %d verified against the wrong root", i)
+		}
+	}
+
+	raw, _ := txs[0].GetEncodedRLP()
+	raw[len(raw)-1] ^= 1
+	proof, _ := GenerateTxProof(txs, 0)
+	if err := VerifyTxProof(header.TxTrieRoot, 0, raw, proof); err == nil {
+		t.Error("Expected a modified transaction to fail")
+	}
+}
@@ -0,0 +1,140 @@
+package rskblocks
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// RSK network chain IDs.
+const (
+	MainnetChainID = 30
+	TestnetChainID = 31
+	RegtestChainID = 33
+)
+
+// ErrInvalidSignature is returned for a transaction whose signature values
+// are malformed or do not match the signer's chain.
+var ErrInvalidSignature = errors.New("invalid transaction signature")
+
+// Signer hashes transactions for signing and recovers their senders.
+type Signer interface {
+	// ChainID returns the chain the signer signs for.
+	ChainID() *big.Int
+	// Hash returns the hash a sender signs.
+	Hash(tx *Transaction) common.Hash
+	// SignatureValues converts a 65-byte [R || S || V] signature, with V 0
+	// or 1, to the transaction's v, r and s.
+	SignatureValues(sig []byte) (v, r, s *big.Int, err error)
+	// Sender recovers the address that signed tx. A REMASC transaction is
+	// unsigned and has the zero address as sender.
+	Sender(tx *Transaction) (common.Address, error)
+}
+
+// EIP155Signer signs with replay protection (EIP-155): the chain ID is part
+// of the signed hash and v is chainID*2+35 or +36. Like rskj, it also
+// accepts unprotected signatures (v of 27 or 28) when recovering senders.
+type EIP155Signer struct {
+	chainID *big.Int
+}
+
+var _ Signer = EIP155Signer{}
+
+// NewEIP155Signer returns a signer for chainID, e.g. MainnetChainID.
+func NewEIP155Signer(chainID uint64) EIP155Signer {
+	return EIP155Signer{chainID: new(big.Int).SetUint64(chainID)}
+}
+
+func (s EIP155Signer) ChainID() *big.Int {
+	return new(big.Int).Set(s.chainID)
+}
+
+func (s EIP155Signer) Hash(tx *Transaction) common.Hash {
+	return s.hash(tx, true)
+}
+
+// hash returns the signing hash, with the EIP-155 chain ID fields if
+// protected.
+func (s EIP155Signer) hash(tx *Transaction, protected bool) common.Hash {
+	fields := tx.ethRLPFields()[:6]
+	if protected {
+		fields = append(fields, s.chainID.Bytes(), []byte{}, []byte{})
+	}
+	encoded, _ := rlp.EncodeToBytes(fields)
+	return crypto.Keccak256Hash(encoded)
+}
+
+func (s EIP155Signer) SignatureValues(sig []byte) (v, r, sv *big.Int, err error) {
+	if len(sig) != crypto.SignatureLength {
+		return nil, nil, nil, fmt.Errorf("signature is %d bytes, want %d", len(sig), crypto.SignatureLength)
+	}
+	if sig[64] > 1 {
+		return nil, nil, nil, fmt.Errorf("signature recovery id %d, want 0 or 1", sig[64])
+	}
+	r = new(big.Int).SetBytes(sig[:32])
+	sv = new(big.Int).SetBytes(sig[32:64])
+	v = new(big.Int).Mul(s.chainID, big.NewInt(2))
+	v.Add(v, big.NewInt(35+int64(sig[64])))
+	return v, r, sv, nil
+}
+
+func (s EIP155Signer) Sender(tx *Transaction) (common.Address, error) {
+	if cached, ok := tx.from.Load().(sigCache); ok && cached.chainID.Cmp(s.chainID) == 0 {
+		return cached.from, nil
+	}
+	if tx.IsRemasc() {
+		return common.Address{}, nil
+	}
+	v, r, sv := tx.RawSignatureValues()
+	var recovery byte
+	var protected bool
+	switch {
+	case v.Cmp(big.NewInt(27)) == 0 || v.Cmp(big.NewInt(28)) == 0:
+		recovery = byte(v.Uint64() - 27)
+	default:
+		id := new(big.Int).Sub(v, big.NewInt(35))
+		if id.Sign() < 0 || new(big.Int).Rsh(id, 1).Cmp(s.chainID) != 0 {
+			return common.Address{}, fmt.Errorf("%w: v %v is not for chain %v", ErrInvalidSignature, v, s.chainID)
+		}
+		recovery = byte(id.Bit(0))
+		protected = true
+	}
+	if !crypto.ValidateSignatureValues(recovery, r, sv, true) {
+		return common.Address{}, ErrInvalidSignature
+	}
+	sig := make([]byte, crypto.SignatureLength)
+	r.FillBytes(sig[:32])
+	sv.FillBytes(sig[32:64])
+	sig[64] = recovery
+	hash := s.hash(tx, protected)
+	pub, err := crypto.SigToPub(hash[:], sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	from := crypto.PubkeyToAddress(*pub)
+	tx.from.Store(sigCache{chainID: s.chainID, from: from})
+	return from, nil
+}
+
+// sigCache is the recovered sender cached on a transaction.
+type sigCache struct {
+	chainID *big.Int
+	from    common.Address
+}
+
+// SignTx signs tx with key for signer's chain, returning a new signed
+// transaction. Its GetEncodedRLP is the raw transaction eth_sendRawTransaction
+// takes.
+func SignTx(tx *Transaction, signer Signer, key *ecdsa.PrivateKey) (*Transaction, error) {
+	hash := signer.Hash(tx)
+	sig, err := crypto.Sign(hash[:], key)
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, sig)
+}
@@ -0,0 +1,82 @@
+package rskblocks
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestSignTx(t *testing.T) {
+	key, _ := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	signer := NewEIP155Signer(MainnetChainID)
+
+	tx := NewTransaction(3, to, big.NewInt(1e15), 21000, big.NewInt(65_164_000), []byte{0xca, 0xfe})
+	signed, err := SignTx(tx, signer, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, r, s := signed.RawSignatureValues()
+	if v.Int64() != 95 && v.Int64() != 96 || r.Sign() == 0 || s.Sign() == 0 {
+		t.Errorf("Signature values v=%v r=%v s=%v", v, r, s)
+	}
+	if got, err := signer.Sender(signed); err != nil || got != from {
+		t.Errorf("Sender = %s, %v; want %s", got, err, from)
+	}
+
+	// The raw transaction is what an Ethereum EIP-155 signer produces for
+	// the same chain ID.
+	raw, err := signed.GetEncodedRLP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ethSigner := types.NewEIP155Signer(big.NewInt(MainnetChainID))
+	ethTx, err := types.SignTx(types.NewTransaction(3, to, big.NewInt(1e15), 21000, big.NewInt(65_164_000), []byte{0xca, 0xfe}), ethSigner, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ethRaw, _ := rlp.EncodeToBytes(ethTx)
+	if !bytes.Equal(raw, ethRaw) {
+		t.Errorf("Raw transaction\n %x\nwant\n %x", raw, ethRaw)
+	}
+	if signed.Hash() != ethTx.Hash() {
+		t.Errorf("Hash %s, want %s", signed.Hash(), ethTx.Hash())
+	}
+
+	var decoded Transaction
+	if err := rlp.DecodeBytes(raw, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := signer.Sender(&decoded); err != nil || got != from {
+		t.Errorf("Decoded sender = %s, %v; want %s", got, err, from)
+	}
+	if _, err := NewEIP155Signer(TestnetChainID).Sender(&decoded); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature for the wrong chain, got %v", err)
+	}
+}
+
+func TestSenderUnprotected(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	tx := NewContractCreation(0, big.NewInt(0), 100000, big.NewInt(1), []byte{0x60, 0x00})
+	signer := NewEIP155Signer(RegtestChainID)
+	hash := signer.hash(tx, false)
+	sig, err := crypto.Sign(hash[:], key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unprotected := NewSignedTransaction(0, nil, big.NewInt(0), 100000, big.NewInt(1), []byte{0x60, 0x00},
+		big.NewInt(27+int64(sig[64])), new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64]))
+	if got, err := signer.Sender(unprotected); err != nil || got != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Errorf("Sender = %s, %v", got, err)
+	}
+	if _, _, _, err := signer.SignatureValues(sig[:64]); err == nil {
+		t.Error("Expected error for a short signature")
+	}
+}
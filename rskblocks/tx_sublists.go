@@ -0,0 +1,48 @@
+package rskblocks
+
+import "fmt"
+
+// TxExecutionSublists is the number of parallel transaction sublists a block
+// may declare (rskj's Constants.getTransactionExecutionThreads). The
+// transactions after the last edge form the sequential sublist, which is
+// not counted.
+const TxExecutionSublists = 2
+
+// ValidateTxExecutionSublistsEdges checks RSKIP-144 edges for a block with
+// txCount transactions as rskj's ValidTxExecutionSublistsEdgesRule does: at
+// most TxExecutionSublists edges, strictly increasing, positive and not
+// past the last transaction. Absent or empty edges are valid.
+func ValidateTxExecutionSublistsEdges(edges []int16, txCount int) error {
+	if len(edges) > TxExecutionSublists {
+		return fmt.Errorf("%d sublist edges, at most %d allowed", len(edges), TxExecutionSublists)
+	}
+	prev := 0
+	for _, edge := range edges {
+		if int(edge) <= prev {
+			return fmt.Errorf("sublist edges %v are not ascending", edges)
+		}
+		if int(edge) > txCount {
+			return fmt.Errorf("sublist edge %d is past the block's %d transactions", edge, txCount)
+		}
+		prev = int(edge)
+	}
+	return nil
+}
+
+// TxExecutionSublists splits the block's transactions at the header's
+// edges: the parallel sublists, in order, followed by the sequential
+// sublist, which may be empty. A block without edges has only the
+// sequential sublist.
+func (b *Block) TxExecutionSublists() ([][]*Transaction, error) {
+	edges := b.Header.TxExecutionSublistsEdges
+	if err := ValidateTxExecutionSublistsEdges(edges, len(b.Transactions)); err != nil {
+		return nil, err
+	}
+	sublists := make([][]*Transaction, 0, len(edges)+1)
+	start := 0
+	for _, edge := range edges {
+		sublists = append(sublists, b.Transactions[start:edge])
+		start = int(edge)
+	}
+	return append(sublists, b.Transactions[start:]), nil
+}
@@ -0,0 +1,86 @@
+package rskblocks
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestValidateTxExecutionSublistsEdges(t *testing.T) {
+	tests := []struct {
+		edges   []int16
+		txCount int
+		valid   bool
+	}{
+		{nil, 0, true},
+		{[]int16{}, 3, true},
+		{[]int16{2}, 3, true},
+		{[]int16{1, 3}, 3, true},
+		{[]int16{1, 2, 3}, 3, false},
+		{[]int16{2, 2}, 3, false},
+		{[]int16{3, 1}, 3, false},
+		{[]int16{0}, 3, false},
+		{[]int16{-1}, 3, false},
+		{[]int16{4}, 3, false},
+	}
+	for _, test := range tests {
+		err := ValidateTxExecutionSublistsEdges(test.edges, test.txCount)
+		if (err == nil) != test.valid {
+			t.Errorf("Edges %v over %d transactions: got %v, want valid %v", test.edges, test.txCount, err, test.valid)
+		}
+	}
+}
+
+func TestBlockTxExecutionSublists(t *testing.T) {
+	var txs []*Transaction
+	for i := 0; i < 5; i++ {
+		txs = append(txs, NewTransaction(uint64(i), common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil))
+	}
+	block := &Block{Header: &BlockHeader{TxExecutionSublistsEdges: []int16{2, 4}}, Transactions: txs}
+	sublists, err := block.TxExecutionSublists()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sizes []int
+	for _, sublist := range sublists {
+		sizes = append(sizes, len(sublist))
+	}
+	if len(sizes) != 3 || sizes[0] != 2 || sizes[1] != 2 || sizes[2] != 1 || sublists[1][0] != txs[2] {
+		t.Errorf("Unexpected sublist sizes %v", sizes)
+	}
+
+	block.Header.TxExecutionSublistsEdges = nil
+	if sublists, _ := block.TxExecutionSublists(); len(sublists) != 1 || len(sublists[0]) != 5 {
+		t.Error("Without edges every transaction is sequential")
+	}
+	block.Header.TxExecutionSublistsEdges = []int16{6}
+	if _, err := block.TxExecutionSublists(); err == nil {
+		t.Error("Expected an out of range edge to fail")
+	}
+}
+
+func TestTxExecutionSublistsEdgesHashing(t *testing.T) {
+	for _, config := range []BlockHashConfig{ConfigForBlockNumber(5_000_000, "mainnet"), ConfigForBlockNumber(7139700, "testnet")} {
+		hashes := make(map[string]bool)
+		for _, edges := range [][]int16{nil, {}, {3}, {3, 7}} {
+			input := testHeaderInput()
+			input.TxExecutionSublistsEdges = edges
+			header := InputToBlockHeader(input, config)
+
+			decoded, err := DecodeBlockHeader(header.GetFullEncoded(), config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decoded.GetFullEncoded(), header.GetFullEncoded()) || decoded.Hash() != header.Hash() {
+				t.Errorf("V%d edges %v did not round trip", config.Version, edges)
+			}
+			hashes[header.Hash().Hex()] = true
+		}
+		// V1 headers always carry edges, so nil and empty hash alike there.
+		if want := map[byte]int{0: 4, 1: 3}[config.Version]; len(hashes) != want {
+			t.Errorf("V%d: %d distinct hashes for four edge values, want %d", config.Version, len(hashes), want)
+		}
+	}
+}
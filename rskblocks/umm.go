@@ -0,0 +1,20 @@
+package rskblocks
+
+import "fmt"
+
+// IsUmm reports whether the header is a UMM block: one whose ummRoot is set
+// and non-empty, so merged mining commits to keccak256(hash[:20] ||
+// ummRoot) rather than the header hash alone (see BaseHashForMergedMining).
+// After papyrus200 every header carries the field, usually empty.
+func (h *BlockHeader) IsUmm() bool {
+	return h.UmmRoot != nil && len(*h.UmmRoot) != 0
+}
+
+// ValidateUmmRoot checks the ummRoot as rskj does before hashing: absent,
+// empty, or exactly 20 bytes.
+func (h *BlockHeader) ValidateUmmRoot() error {
+	if h.IsUmm() && len(*h.UmmRoot) != ummLeafLength {
+		return fmt.Errorf("ummRoot has %d bytes, want 0 or %d", len(*h.UmmRoot), ummLeafLength)
+	}
+	return nil
+}
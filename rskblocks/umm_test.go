@@ -0,0 +1,96 @@
+package rskblocks
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestUmmRootRoundTrip(t *testing.T) {
+	config := ConfigForBlockNumber(2_400_000, "mainnet")
+	if !config.IncludeUmmRoot {
+		t.Fatal("Expected UMM to be active after papyrus200")
+	}
+	for _, umm := range [][]byte{{}, bytes.Repeat([]byte{0x42}, ummLeafLength)} {
+		input := testHeaderInput()
+		input.Number = big.NewInt(2_400_000)
+		input.UmmRoot = &umm
+		header := InputToBlockHeader(input, config)
+		if header.IsUmm() != (len(umm) != 0) {
+			t.Errorf("IsUmm = %v for a %d-byte ummRoot", header.IsUmm(), len(umm))
+		}
+
+		encoded := header.GetFullEncoded()
+		decoded, err := DecodeBlockHeader(encoded, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.UmmRoot == nil || !bytes.Equal(*decoded.UmmRoot, umm) {
+			t.Errorf("Decoded ummRoot %v, want %x", decoded.UmmRoot, umm)
+		}
+		if !bytes.Equal(decoded.GetFullEncoded(), encoded) {
+			t.Error("Header did not round trip")
+		}
+		if decoded.Hash() != header.Hash() {
+			t.Error("Decoded header hashes differently")
+		}
+	}
+
+	// The ummRoot is part of the hashed encoding.
+	a, b := testHeaderInput(), testHeaderInput()
+	ummA, ummB := bytes.Repeat([]byte{1}, ummLeafLength), bytes.Repeat([]byte{2}, ummLeafLength)
+	a.UmmRoot, b.UmmRoot = &ummA, &ummB
+	if InputToBlockHeader(a, config).Hash() == InputToBlockHeader(b, config).Hash() {
+		t.Error("Different ummRoots produced the same hash")
+	}
+}
+
+func TestValidateUmmRoot(t *testing.T) {
+	header := &BlockHeader{}
+	if header.IsUmm() || header.ValidateUmmRoot() != nil {
+		t.Error("A header without ummRoot is valid and not UMM")
+	}
+	bad := []byte{1, 2, 3}
+	header.UmmRoot = &bad
+	if err := header.ValidateUmmRoot(); err == nil {
+		t.Error("Expected a 3-byte ummRoot to fail")
+	}
+
+	input := testHeaderInput()
+	input.UmmRoot = &bad
+	config := DefaultRegtestConfig()
+	if _, err := DecodeBlockHeader(InputToBlockHeader(input, config).GetFullEncoded(), config); err == nil {
+		t.Error("Expected the decoder to reject a malformed ummRoot")
+	}
+}
+
+func TestChainValidatorUmmRoot(t *testing.T) {
+	umm := bytes.Repeat([]byte{0x33}, ummLeafLength)
+	headers := testChain(4, func(i int, input *BlockHeaderInput) {
+		if i == 2 {
+			input.UmmRoot = &umm
+		}
+	})
+
+	v := testChainValidator()
+	var checked []*big.Int
+	v.UmmRoot = func(header *BlockHeader) error {
+		checked = append(checked, header.Number)
+		return nil
+	}
+	if err := v.Validate(headers); err != nil {
+		t.Fatal(err)
+	}
+	if len(checked) != 1 || checked[0].Int64() != 102 {
+		t.Errorf("Hook called for blocks %v, want [102]", checked)
+	}
+
+	errBadCommitment := errors.New("bad commitment")
+	v.UmmRoot = func(*BlockHeader) error { return errBadCommitment }
+	err := v.Validate(headers)
+	var violation *ChainViolation
+	if !errors.As(err, &violation) || violation.Rule != RuleUmmRoot || violation.Index != 2 || !errors.Is(err, errBadCommitment) {
+		t.Errorf("Expected an umm-root violation at index 2, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package rskblocks
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// errNoProofNodes is returned when a session verifies before any proof has
+// been added to it.
+var errNoProofNodes = errors.New("no proof nodes added to session")
+
+// VerificationSession verifies many keys against one state root from proof
+// nodes decoded once: each proof added is decoded and merged into a single
+// hash-indexed node set, which every verification then walks. Verifying the
+// slots of one contract this way decodes their shared upper nodes once
+// rather than once per slot.
+//
+// The verifier's limits and node format apply to each proof added, and its
+// depth limit to each walk. Strict mode and the result cache do not apply,
+// since the node set is shared between keys. A session is safe for
+// concurrent use; since walking the node set caches node hashes,
+// verifications hold the session's lock exclusively.
+type VerificationSession struct {
+	v     *ProofVerifier
+	root  common.Hash
+	mu    sync.Mutex
+	nodes *rsktrie.ProofNodeSet
+}
+
+// NewVerificationSession returns an empty session for stateRoot.
+func (v *ProofVerifier) NewVerificationSession(stateRoot common.Hash) *VerificationSession {
+	return &VerificationSession{v: v, root: stateRoot}
+}
+
+// Root returns the state root the session verifies against.
+func (s *VerificationSession) Root() common.Hash {
+	return s.root
+}
+
+// AddProof decodes proofNodes, as returned by eth_getProof, into the
+// session's node set.
+func (s *VerificationSession) AddProof(proofNodes [][]byte) error {
+	nodes, err := s.v.config.DecodeProofNodes(proofNodes)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.nodes == nil {
+		s.nodes = nodes
+	} else {
+		s.nodes.Merge(nodes)
+	}
+	return nil
+}
+
+// VerifyAccount verifies address's account against the session's nodes, as
+// VerifyAccountProof does.
+func (s *VerificationSession) VerifyAccount(address common.Address) *AccountProofResult {
+	result, err := s.verify(s.v.keyMapper.GetAccountKey(address))
+	if err != nil {
+		return &AccountProofResult{
+			Valid:   false,
+			Status:  rsktrie.ProofInvalid,
+			Address: address,
+			Error:   err,
+		}
+	}
+	return accountProofResult(address, result)
+}
+
+// VerifyStorage verifies a storage slot of address against the session's
+// nodes, as VerifyStorageProof does.
+func (s *VerificationSession) VerifyStorage(address common.Address, storageKey common.Hash) *StorageProofResult {
+	result, err := s.verify(s.v.keyMapper.GetAccountStorageKey(address, storageKey))
+	if err != nil {
+		return &StorageProofResult{
+			Valid:      false,
+			Status:     rsktrie.ProofInvalid,
+			StorageKey: storageKey,
+			Error:      err,
+		}
+	}
+	return storageProofResult(storageKey, result)
+}
+
+func (s *VerificationSession) verify(key []byte) (*rsktrie.KeyProofResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.nodes == nil {
+		return nil, errNoProofNodes
+	}
+	result, err := s.v.config.VerifyNodeSet(s.nodes, s.root[:], key)
+	if err != nil && s.v.logf != nil {
+		s.v.logf("proof for key %s against root %x is invalid: %v", rsktrie.FormatKey(key), s.root, err)
+	}
+	return result, err
+}
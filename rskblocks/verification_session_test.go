@@ -0,0 +1,115 @@
+package rskblocks
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestVerificationSession(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051")
+	account, err := (&rsktrie.AccountState{Nonce: big.NewInt(1), Balance: big.NewInt(2)}).Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trie := rsktrie.NewTrie(nil).Put(mapper.GetAccountKey(contract), account)
+	var slots []common.Hash
+	for i := 0; i < 20; i++ {
+		slot := common.BigToHash(big.NewInt(int64(i)))
+		slots = append(slots, slot)
+		trie = trie.Put(mapper.GetAccountStorageKey(contract, slot), []byte{byte(i + 1)})
+	}
+	stateRoot := common.BytesToHash(trie.GetHash())
+
+	session := NewProofVerifier().NewVerificationSession(stateRoot)
+	if r := session.VerifyStorage(contract, slots[0]); r.Valid || !errors.Is(r.Error, errNoProofNodes) {
+		t.Errorf("Expected an empty session to fail, got %+v", r)
+	}
+	if err := session.AddProof(buildTestProof(t, trie, mapper.GetAccountKey(contract))); err != nil {
+		t.Fatal(err)
+	}
+	for _, slot := range slots {
+		if err := session.AddProof(buildTestProof(t, trie, mapper.GetAccountStorageKey(contract, slot))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if r := session.VerifyAccount(contract); !r.Valid || r.Status != rsktrie.ProofPresent || r.Balance.Int64() != 2 {
+		t.Errorf("Expected the account present, got %+v", r)
+	}
+	for i, slot := range slots {
+		r := session.VerifyStorage(contract, slot)
+		if !r.Valid || r.Status != rsktrie.ProofPresent || !bytes.Equal(r.Value, []byte{byte(i + 1)}) {
+			t.Errorf("Slot %d: unexpected result %+v", i, r)
+		}
+	}
+
+	// Absence is proven once the absent slot's proof is added
+	absent := common.BigToHash(big.NewInt(99))
+	if err := session.AddProof(buildTestProof(t, trie, mapper.GetAccountStorageKey(contract, absent))); err != nil {
+		t.Fatal(err)
+	}
+	if r := session.VerifyStorage(contract, absent); !r.Valid || r.Status != rsktrie.ProofProvenAbsent {
+		t.Errorf("Expected slot 99 proven absent, got %+v", r)
+	}
+
+	other := NewProofVerifier().NewVerificationSession(common.Hash{0x01})
+	if err := other.AddProof(buildTestProof(t, trie, mapper.GetAccountKey(contract))); err != nil {
+		t.Fatal(err)
+	}
+	if r := other.VerifyAccount(contract); r.Valid || r.Status != rsktrie.ProofInvalid {
+		t.Errorf("Expected the wrong root to be invalid, got %+v", r)
+	}
+}
+
+func TestVerificationSessionConcurrent(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051")
+	account, err := (&rsktrie.AccountState{Nonce: big.NewInt(1), Balance: big.NewInt(2)}).Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	trie := rsktrie.NewTrie(nil).Put(mapper.GetAccountKey(contract), account)
+	var slots []common.Hash
+	for i := 0; i < 8; i++ {
+		slot := common.BigToHash(big.NewInt(int64(i)))
+		slots = append(slots, slot)
+		trie = trie.Put(mapper.GetAccountStorageKey(contract, slot), []byte{byte(i + 1)})
+	}
+
+	session := NewProofVerifier().NewVerificationSession(common.BytesToHash(trie.GetHash()))
+	if err := session.AddProof(buildTestProof(t, trie, mapper.GetAccountKey(contract))); err != nil {
+		t.Fatal(err)
+	}
+	for _, slot := range slots {
+		if err := session.AddProof(buildTestProof(t, trie, mapper.GetAccountStorageKey(contract, slot))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Run under -race: walks share decoded nodes whose hashes are cached lazily
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, slot := range slots {
+				if r := session.VerifyAccount(contract); !r.Valid {
+					t.Errorf("Unexpected account result %+v", r)
+				}
+				if r := session.VerifyStorage(contract, slot); !r.Valid || !bytes.Equal(r.Value, []byte{byte(i + 1)}) {
+					t.Errorf("Slot %d: unexpected result %+v", i, r)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
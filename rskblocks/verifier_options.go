@@ -0,0 +1,105 @@
+package rskblocks
+
+import (
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Option configures a ProofVerifier built by NewProofVerifier.
+type Option func(*ProofVerifier)
+
+// ResultCache memoizes successful key verifications. A key's status and value
+// are fixed by the state root, so a result verified once for (root, key)
+// holds for every later proof of it; on a hit the supplied proof is not
+// examined at all. Implementations must be safe for concurrent use.
+type ResultCache interface {
+	Get(root common.Hash, key []byte) (*rsktrie.KeyProofResult, bool)
+	Add(root common.Hash, key []byte, result *rsktrie.KeyProofResult)
+}
+
+// WithKeyMapper derives trie keys with keyMapper and requires proof nodes in
+// the node format of its era.
+func WithKeyMapper(keyMapper rsktrie.KeyMapper) Option {
+	return func(v *ProofVerifier) {
+		v.keyMapper = keyMapper
+		v.config.Format = rsktrie.NodeFormatFor(keyMapper.Version())
+	}
+}
+
+// WithKeyMapperVersion is WithKeyMapper for the mapper of version. It panics
+// if version is unsupported.
+func WithKeyMapperVersion(version rsktrie.KeyMapperVersion) Option {
+	keyMapper, err := rsktrie.NewKeyMapper(version)
+	if err != nil {
+		panic(fmt.Sprintf("rskblocks: %v", err))
+	}
+	return WithKeyMapper(keyMapper)
+}
+
+// WithStrict enables strict mode, in which proofs with duplicate nodes or
+// nodes off the key's path are invalid, to catch padded or malformed proofs
+// from a buggy or malicious provider (see rsktrie.VerifyKeyProofStrict).
+func WithStrict(strict bool) Option {
+	return func(v *ProofVerifier) {
+		v.config.Strict = strict
+	}
+}
+
+// WithLimits sets the caps on proof size, node count and traversal depth
+// applied to each proof, in place of rsktrie.DefaultProofLimits. The zero
+// ProofLimits disables them.
+func WithLimits(limits rsktrie.ProofLimits) Option {
+	return func(v *ProofVerifier) {
+		v.config.Limits = limits
+	}
+}
+
+// WithProofOrder sets the node order proofs are expected in. With a known
+// order (or rsktrie.ProofOrderAuto) proofs are walked by position, without
+// indexing their nodes by hash, and out-of-order proofs are invalid.
+func WithProofOrder(order rsktrie.ProofOrder) Option {
+	return func(v *ProofVerifier) {
+		v.config.Order = order
+	}
+}
+
+// WithNodeFormat requires proof nodes in format, overriding the format
+// implied by the key mapper.
+func WithNodeFormat(format rsktrie.NodeFormat) Option {
+	return func(v *ProofVerifier) {
+		v.config.Format = format
+	}
+}
+
+// WithHashFunction hashes proof nodes with hash instead of keccak256, e.g.
+// for tries built with another hash in tests.
+func WithHashFunction(hash func([]byte) []byte) Option {
+	return func(v *ProofVerifier) {
+		v.config.Hash = hash
+	}
+}
+
+// WithDecodeWorkers caps the goroutines decoding each proof's nodes; 1
+// decodes serially. The default is GOMAXPROCS.
+func WithDecodeWorkers(workers int) Option {
+	return func(v *ProofVerifier) {
+		v.config.DecodeWorkers = workers
+	}
+}
+
+// WithResultCache memoizes successful verifications in cache.
+func WithResultCache(cache ResultCache) Option {
+	return func(v *ProofVerifier) {
+		v.cache = cache
+	}
+}
+
+// WithLogf reports failed verifications through logf, e.g. log.Printf.
+func WithLogf(logf func(format string, args ...any)) Option {
+	return func(v *ProofVerifier) {
+		v.logf = logf
+	}
+}
@@ -0,0 +1,98 @@
+package rskblocks
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type mapCache struct {
+	mu      sync.Mutex
+	results map[string]*rsktrie.KeyProofResult
+	hits    int
+}
+
+func (c *mapCache) Get(root common.Hash, key []byte) (*rsktrie.KeyProofResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.results[string(root[:])+string(key)]
+	if ok {
+		c.hits++
+	}
+	return r, ok
+}
+
+func (c *mapCache) Add(root common.Hash, key []byte, result *rsktrie.KeyProofResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results[string(root[:])+string(key)] = result
+}
+
+func TestProofVerifierOptions(t *testing.T) {
+	mapper := rsktrie.NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045e71a7a2c50903d88e564cd72fab11e82051")
+	slot, other := common.Hash{0x01}, common.Hash{0x02}
+	trie := rsktrie.NewTrie(nil).
+		Put(mapper.GetAccountStorageKey(contract, slot), []byte{0x2a}).
+		Put(mapper.GetAccountStorageKey(contract, other), []byte{0x07})
+	root := common.BytesToHash(trie.GetHash())
+	proof := buildTestProof(t, trie, mapper.GetAccountStorageKey(contract, slot))
+	padded := append(append([][]byte{}, buildTestProof(t, trie, mapper.GetAccountStorageKey(contract, other))...), proof...)
+
+	// Defaults stay lenient.
+	if result, _ := NewProofVerifier().VerifyStorageProof(root, contract, slot, padded); !result.Valid {
+		t.Errorf("Expected default verifier to accept padded proof: %v", result.Error)
+	}
+
+	var logged []string
+	logf := func(format string, args ...any) { logged = append(logged, fmt.Sprintf(format, args...)) }
+	strict := NewProofVerifier(WithStrict(true), WithLogf(logf))
+	if result, _ := strict.VerifyStorageProof(root, contract, slot, padded); result.Valid {
+		t.Error("Expected strict verifier to reject padded proof")
+	}
+	if len(logged) != 1 || !strings.Contains(logged[0], "is invalid") {
+		t.Errorf("Expected one logged failure, got %q", logged)
+	}
+
+	limited := NewProofVerifier(WithLimits(rsktrie.ProofLimits{MaxNodes: 1}))
+	if result, _ := limited.VerifyStorageProof(root, contract, slot, proof); result.Valid {
+		t.Error("Expected node limit to reject proof")
+	}
+
+	ordered := NewProofVerifier(WithProofOrder(rsktrie.ProofOrderRootToLeaf))
+	if result, _ := ordered.VerifyStorageProof(root, contract, slot, proof); result.Valid {
+		t.Error("Expected leaf-to-root proof to fail in root-to-leaf mode")
+	}
+
+	if v := NewProofVerifier(WithKeyMapperVersion(rsktrie.KeyMapperOrchid)); v.keyMapper.Version() != rsktrie.KeyMapperOrchid || v.config.Format != rsktrie.NodeFormatOrchid {
+		t.Error("Expected Orchid key mapper and node format")
+	}
+
+	// A foreign hash cannot find the root.
+	hashed := NewProofVerifier(WithHashFunction(func(b []byte) []byte { return make([]byte, 32) }))
+	if result, _ := hashed.VerifyStorageProof(root, contract, slot, proof); result.Valid {
+		t.Error("Expected custom hash function to be used")
+	}
+
+	cache := &mapCache{results: make(map[string]*rsktrie.KeyProofResult)}
+	cached := NewProofVerifier(WithResultCache(cache))
+	for i := 0; i < 3; i++ {
+		result, _ := cached.VerifyStorageProof(root, contract, slot, proof)
+		if !result.Valid || result.Value[0] != 0x2a {
+			t.Fatalf("Unexpected cached result %+v", result)
+		}
+	}
+	if cache.hits != 2 {
+		t.Errorf("Expected 2 cache hits, got %d", cache.hits)
+	}
+	// Failures are not cached.
+	cached.VerifyStorageProof(common.Hash{}, contract, slot, proof)
+	if len(cache.results) != 1 {
+		t.Errorf("Expected only the success cached, got %d entries", len(cache.results))
+	}
+}
@@ -0,0 +1,157 @@
+package rskrpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rskblocks"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Batch queues requests and sends them to the node as one JSON-RPC batch,
+// in a single round trip. Each request gets its own result and error, set
+// by Send and checked as by the Client method of the same name. A Batch is
+// not safe for concurrent use.
+//
+//	batch := client.NewBatch()
+//	proofs := make([]*rskrpc.ProofCall, len(addresses))
+//	for i, address := range addresses {
+//	    proofs[i] = batch.GetProof(address, nil, ref)
+//	}
+//	if err := batch.Send(ctx); err != nil {
+//	    return err // the batch as a whole failed
+//	}
+//	for _, p := range proofs {
+//	    if p.Err != nil { ... }
+//	}
+type Batch struct {
+	client *Client
+	elems  []rpc.BatchElem
+	done   []func(ctx context.Context, served int, err error)
+}
+
+// ProofCall is an eth_getProof request queued in a Batch.
+type ProofCall struct {
+	Result *rskblocks.AccountResult
+	Err    error
+}
+
+// BlockCall is an eth_getBlockByNumber or eth_getBlockByHash request queued
+// in a Batch. If the client cross-checks blocks, Send checks the result
+// against the other endpoints once the batch returns.
+type BlockCall struct {
+	Result *rskblocks.RPCBlock
+	Err    error
+}
+
+// ReceiptCall is an eth_getTransactionReceipt request queued in a Batch.
+type ReceiptCall struct {
+	Result *Receipt
+	Err    error
+}
+
+// NewBatch returns an empty batch of requests to c's node.
+func (c *Client) NewBatch() *Batch {
+	return &Batch{client: c}
+}
+
+// Len returns the number of requests queued.
+func (b *Batch) Len() int {
+	return len(b.elems)
+}
+
+// GetProof queues Client.GetProof. An invalid request fails at once and is
+// not sent.
+func (b *Batch) GetProof(address common.Address, storageKeys []common.Hash, ref BlockRef) *ProofCall {
+	call := &ProofCall{}
+	if call.Err = checkProofRequest(storageKeys, ref); call.Err != nil {
+		return call
+	}
+	b.add(func(_ context.Context, _ int, err error) {
+		if err == nil {
+			err = checkProofResult(call.Result, address, storageKeys, ref)
+		}
+		call.Err = err
+	}, &call.Result, "eth_getProof", address, nonNilKeys(storageKeys), ref)
+	return call
+}
+
+// BlockByNumber queues Client.BlockByNumber.
+func (b *Batch) BlockByNumber(ref BlockRef, fullTxs bool) *BlockCall {
+	if hash, ok := ref.Hash(); ok {
+		return b.BlockByHash(hash, fullTxs)
+	}
+	return b.block("eth_getBlockByNumber", ref, fullTxs)
+}
+
+// BlockByHash queues Client.BlockByHash.
+func (b *Batch) BlockByHash(hash common.Hash, fullTxs bool) *BlockCall {
+	return b.block("eth_getBlockByHash", hash, fullTxs)
+}
+
+func (b *Batch) block(method string, id any, fullTxs bool) *BlockCall {
+	call := &BlockCall{}
+	b.add(func(ctx context.Context, served int, err error) {
+		if err == nil && call.Result == nil {
+			err = fmt.Errorf("block %v: %w", id, ErrNotFound)
+		}
+		if err == nil {
+			err = b.client.crossCheck(ctx, served, call.Result.Number, call.Result.Hash, method == "eth_getBlockByHash")
+		}
+		if err != nil {
+			call.Result = nil
+		}
+		call.Err = err
+	}, &call.Result, method, id, fullTxs)
+	return call
+}
+
+// TransactionReceipt queues Client.TransactionReceipt.
+func (b *Batch) TransactionReceipt(txHash common.Hash) *ReceiptCall {
+	call := &ReceiptCall{}
+	b.add(func(_ context.Context, _ int, err error) {
+		if err == nil && call.Result == nil {
+			err = fmt.Errorf("receipt of %s: %w", txHash, ErrNotFound)
+		}
+		call.Err = err
+	}, &call.Result, "eth_getTransactionReceipt", txHash)
+	return call
+}
+
+// add queues a request whose outcome done records.
+func (b *Batch) add(done func(ctx context.Context, served int, err error), result any, method string, args ...any) {
+	b.elems = append(b.elems, rpc.BatchElem{Method: method, Args: args, Result: result})
+	b.done = append(b.done, done)
+}
+
+// Send sends the queued requests and sets their results and errors, then
+// empties the batch for reuse. It returns an error only if the batch as a
+// whole failed, which is then also every request's error. A failed batch
+// is retried whole under the client's retry policy.
+func (b *Batch) Send(ctx context.Context) error {
+	if len(b.elems) == 0 {
+		return nil
+	}
+	elems, done := b.elems, b.done
+	b.elems, b.done = nil, nil
+	served, err := b.client.do(ctx, len(elems), func(ctx context.Context, client *rpc.Client) error {
+		return client.BatchCallContext(ctx, elems)
+	})
+	if err != nil {
+		err = fmt.Errorf("batch of %d RPC calls failed: %w", len(elems), err)
+		for _, d := range done {
+			d(ctx, served, err)
+		}
+		return err
+	}
+	for i, elem := range elems {
+		err := elem.Error
+		if err != nil {
+			err = fmt.Errorf("%s RPC call failed: %w", elem.Method, err)
+		}
+		done[i](ctx, served, err)
+	}
+	return nil
+}
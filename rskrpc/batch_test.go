@@ -0,0 +1,98 @@
+package rskrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBatch(t *testing.T) {
+	contract := common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051")
+	var requests int
+	client := newTestClient(t, func(method string, params json.RawMessage) (any, error) {
+		requests++
+		switch method {
+		case "eth_getProof":
+			if strings.Contains(string(params), strings.ToLower(contract.Hex())) {
+				return map[string]any{"address": contract, "balance": "0x1", "nonce": "0x0", "accountProof": []string{"0x01"}, "storageProof": []any{}}, nil
+			}
+			return nil, errors.New("header not found")
+		case "eth_getBlockByNumber":
+			if string(params) == `["0x1",false]` {
+				return map[string]any{"number": "0x1", "timestamp": "0x0", "size": "0x0"}, nil
+			}
+			return nil, nil
+		case "eth_getBlockByHash":
+			return map[string]any{"number": "0x2", "timestamp": "0x0", "size": "0x0"}, nil
+		case "eth_getTransactionReceipt":
+			return nil, nil
+		}
+		return nil, errors.New("method not found")
+	})
+
+	batch := client.NewBatch()
+	good := batch.GetProof(contract, nil, Latest())
+	failing := batch.GetProof(common.Address{1}, nil, Latest())
+	invalid := batch.GetProof(contract, nil, Pending())
+	block := batch.BlockByNumber(BlockNumber(1), false)
+	missing := batch.BlockByNumber(BlockNumber(5), false)
+	byHash := batch.BlockByNumber(BlockHash(common.Hash{7}), false)
+	receipt := batch.TransactionReceipt(common.Hash{8})
+	if batch.Len() != 6 {
+		t.Fatalf("Queued %d requests, want 6 (the invalid one is not sent)", batch.Len())
+	}
+	if err := batch.Send(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 6 || batch.Len() != 0 {
+		t.Errorf("Server saw %d requests, %d left queued", requests, batch.Len())
+	}
+
+	if good.Err != nil || good.Result.Balance.ToInt().Int64() != 1 {
+		t.Errorf("Proof: %+v, %v", good.Result, good.Err)
+	}
+	if failing.Err == nil || !strings.Contains(failing.Err.Error(), "header not found") {
+		t.Errorf("Failing proof: %v", failing.Err)
+	}
+	if !errors.Is(invalid.Err, ErrInvalidArgument) {
+		t.Errorf("Invalid proof request: %v", invalid.Err)
+	}
+	if block.Err != nil || block.Result.Number != 1 {
+		t.Errorf("Block: %v", block.Err)
+	}
+	if !errors.Is(missing.Err, ErrNotFound) || !errors.Is(receipt.Err, ErrNotFound) {
+		t.Errorf("Missing block %v, receipt %v", missing.Err, receipt.Err)
+	}
+	if byHash.Err != nil || byHash.Result.Number != 2 {
+		t.Errorf("Block by hash: %v", byHash.Err)
+	}
+
+	if err := client.NewBatch().Send(context.Background()); err != nil {
+		t.Errorf("Empty batch: %v", err)
+	}
+}
+
+func TestBatchTransportFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "banned", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	client, err := Dial(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	batch := client.NewBatch()
+	call := batch.BlockByNumber(Latest(), false)
+	err = batch.Send(context.Background())
+	if err == nil || call.Err == nil {
+		t.Errorf("Batch error %v, call error %v", err, call.Err)
+	}
+}
@@ -0,0 +1,104 @@
+package rskrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// BlockRef selects the block a request reads: a tag such as "latest", a
+// block number or a block hash. The zero value is "latest".
+type BlockRef struct {
+	tag      string
+	number   uint64
+	isNumber bool
+	hash     *common.Hash
+}
+
+// Latest refers to the best block.
+func Latest() BlockRef { return BlockRef{tag: "latest"} }
+
+// Earliest refers to the genesis block.
+func Earliest() BlockRef { return BlockRef{tag: "earliest"} }
+
+// Pending refers to the block being mined.
+func Pending() BlockRef { return BlockRef{tag: "pending"} }
+
+// BlockNumber refers to block number on the best chain.
+func BlockNumber(number uint64) BlockRef { return BlockRef{number: number, isNumber: true} }
+
+// BlockHash refers to the block with hash, whether or not it is on the best
+// chain. It is sent as an EIP-1898 block parameter.
+func BlockHash(hash common.Hash) BlockRef { return BlockRef{hash: &hash} }
+
+// ParseBlockRef parses a block tag ("latest", "earliest", "pending"), a
+// block number in hex or decimal, or a 32-byte block hash.
+func ParseBlockRef(s string) (BlockRef, error) {
+	switch s {
+	case "", "latest":
+		return Latest(), nil
+	case "earliest":
+		return Earliest(), nil
+	case "pending":
+		return Pending(), nil
+	}
+	if strings.HasPrefix(s, "0x") && len(s) == 2+2*common.HashLength {
+		hash, err := hexutil.Decode(s)
+		if err != nil {
+			return BlockRef{}, fmt.Errorf("%w: block hash %q: %v", ErrInvalidArgument, s, err)
+		}
+		return BlockHash(common.BytesToHash(hash)), nil
+	}
+	var number hexutil.Uint64
+	if strings.HasPrefix(s, "0x") {
+		if err := number.UnmarshalText([]byte(s)); err != nil {
+			return BlockRef{}, fmt.Errorf("%w: block number %q: %v", ErrInvalidArgument, s, err)
+		}
+		return BlockNumber(uint64(number)), nil
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return BlockRef{}, fmt.Errorf("%w: block reference %q", ErrInvalidArgument, s)
+	}
+	return BlockNumber(n), nil
+}
+
+// Hash returns the block hash of a hash reference.
+func (r BlockRef) Hash() (common.Hash, bool) {
+	if r.hash == nil {
+		return common.Hash{}, false
+	}
+	return *r.hash, true
+}
+
+// IsPending reports whether r refers to the pending block, whose state is
+// not final and cannot be proven.
+func (r BlockRef) IsPending() bool {
+	return r.tag == "pending"
+}
+
+// String returns the reference as text: a tag, a hex number or a hash.
+func (r BlockRef) String() string {
+	switch {
+	case r.hash != nil:
+		return r.hash.Hex()
+	case r.isNumber:
+		return hexutil.EncodeUint64(r.number)
+	case r.tag == "":
+		return "latest"
+	}
+	return r.tag
+}
+
+// MarshalJSON implements json.Marshaler. Hash references marshal to an
+// EIP-1898 {"blockHash": ...} object, the others to a string.
+func (r BlockRef) MarshalJSON() ([]byte, error) {
+	if r.hash != nil {
+		return json.Marshal(map[string]common.Hash{"blockHash": *r.hash})
+	}
+	return json.Marshal(r.String())
+}
@@ -0,0 +1,276 @@
+// Package rskrpc is a typed client for the RSK JSON-RPC endpoints whose
+// results gorsk verifies. Responses are decoded into the rskblocks types the
+// verifier takes, so callers need no JSON-RPC plumbing of their own.
+//
+// # Usage
+//
+//	client, err := rskrpc.Dial("http://localhost:4444")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer client.Close()
+//
+//	block, err := client.BlockByNumber(ctx, rskrpc.Latest(), false)
+package rskrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rskblocks"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrNotFound is returned when the node has no block, header or receipt for
+// the request.
+var ErrNotFound = errors.New("not found")
+
+// ErrInvalidArgument is returned for a request that is rejected before it
+// is sent.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// ErrInvalidResponse is returned for a response that does not answer the
+// request.
+var ErrInvalidResponse = errors.New("invalid response")
+
+// Client is a typed RSK JSON-RPC client. It is safe for concurrent use.
+type Client struct {
+	endpoints []endpoint
+	options   Options
+	next      atomic.Uint64 // index of the endpoint calls go to
+}
+
+// Dial connects to the RSK node at rawURL.
+func Dial(rawURL string) (*Client, error) {
+	return DialContext(context.Background(), rawURL)
+}
+
+// DialContext connects to the RSK node at rawURL, bounded by ctx.
+func DialContext(ctx context.Context, rawURL string) (*Client, error) {
+	c, err := rpc.DialContext(ctx, rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RPC: %w", err)
+	}
+	return &Client{endpoints: []endpoint{{url: rawURL, rpc: c}}}, nil
+}
+
+// NewClient returns a Client over an established RPC connection.
+func NewClient(c *rpc.Client) *Client {
+	return &Client{endpoints: []endpoint{{rpc: c}}}
+}
+
+// Close closes the underlying RPC connections.
+func (c *Client) Close() {
+	for _, ep := range c.endpoints {
+		ep.rpc.Close()
+	}
+}
+
+// ChainID calls eth_chainId.
+func (c *Client) ChainID(ctx context.Context) (uint64, error) {
+	var id hexutil.Uint64
+	if err := c.call(ctx, &id, "eth_chainId"); err != nil {
+		return 0, err
+	}
+	return uint64(id), nil
+}
+
+// GetProof calls eth_getProof for address and storageKeys at ref and
+// returns the typed EIP-1186 result, ready for
+// rskblocks.ProofVerifier.VerifyAccountResult. The request is rejected if a
+// storage key repeats or ref is the pending block, whose state cannot be
+// proven; the response, if it is for another account or does not hold
+// exactly the requested storage proofs, in order.
+func (c *Client) GetProof(ctx context.Context, address common.Address, storageKeys []common.Hash, ref BlockRef) (*rskblocks.AccountResult, error) {
+	if err := checkProofRequest(storageKeys, ref); err != nil {
+		return nil, err
+	}
+	var result *rskblocks.AccountResult
+	if err := c.call(ctx, &result, "eth_getProof", address, nonNilKeys(storageKeys), ref); err != nil {
+		return nil, err
+	}
+	if err := checkProofResult(result, address, storageKeys, ref); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func checkProofRequest(storageKeys []common.Hash, ref BlockRef) error {
+	if ref.IsPending() {
+		return fmt.Errorf("%w: the pending block's state cannot be proven", ErrInvalidArgument)
+	}
+	seen := make(map[common.Hash]bool, len(storageKeys))
+	for _, key := range storageKeys {
+		if seen[key] {
+			return fmt.Errorf("%w: storage key %s repeats", ErrInvalidArgument, key)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// nonNilKeys makes no storage keys marshal as [] rather than null.
+func nonNilKeys(storageKeys []common.Hash) []common.Hash {
+	if storageKeys == nil {
+		return []common.Hash{}
+	}
+	return storageKeys
+}
+
+func checkProofResult(result *rskblocks.AccountResult, address common.Address, storageKeys []common.Hash, ref BlockRef) error {
+	if result == nil {
+		return fmt.Errorf("eth_getProof at %s: %w", ref, ErrNotFound)
+	}
+	if result.Address != address {
+		return fmt.Errorf("%w: proof for %s, requested %s", ErrInvalidResponse, result.Address, address)
+	}
+	if len(result.StorageProof) != len(storageKeys) {
+		return fmt.Errorf("%w: %d storage proofs for %d keys", ErrInvalidResponse, len(result.StorageProof), len(storageKeys))
+	}
+	for i, key := range storageKeys {
+		if got := result.StorageProof[i].Key; got != key {
+			return fmt.Errorf("%w: storage proof %d is for key %s, requested %s", ErrInvalidResponse, i, got, key)
+		}
+	}
+	return nil
+}
+
+// BlockByNumber calls eth_getBlockByNumber. With fullTxs the block's
+// Transactions hold transaction objects rather than hashes.
+// A hash reference calls eth_getBlockByHash.
+func (c *Client) BlockByNumber(ctx context.Context, ref BlockRef, fullTxs bool) (*rskblocks.RPCBlock, error) {
+	if hash, ok := ref.Hash(); ok {
+		return c.BlockByHash(ctx, hash, fullTxs)
+	}
+	return c.block(ctx, "eth_getBlockByNumber", ref, fullTxs)
+}
+
+// BlockByHash calls eth_getBlockByHash.
+func (c *Client) BlockByHash(ctx context.Context, hash common.Hash, fullTxs bool) (*rskblocks.RPCBlock, error) {
+	return c.block(ctx, "eth_getBlockByHash", hash, fullTxs)
+}
+
+func (c *Client) block(ctx context.Context, method string, id any, fullTxs bool) (*rskblocks.RPCBlock, error) {
+	var block *rskblocks.RPCBlock
+	served, err := c.callOn(ctx, &block, method, id, fullTxs)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %v: %w", id, ErrNotFound)
+	}
+	if err := c.crossCheck(ctx, served, block.Number, block.Hash, method == "eth_getBlockByHash"); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// RawBlockHeaderByNumber calls rsk_getRawBlockHeaderByNumber and returns the
+// RLP encoded header, unchecked; see HeaderByNumber. A hash reference calls
+// rsk_getRawBlockHeaderByHash.
+func (c *Client) RawBlockHeaderByNumber(ctx context.Context, ref BlockRef) ([]byte, error) {
+	if hash, ok := ref.Hash(); ok {
+		return c.RawBlockHeaderByHash(ctx, hash)
+	}
+	raw, _, err := c.rawHeader(ctx, "rsk_getRawBlockHeaderByNumber", ref)
+	return raw, err
+}
+
+// RawBlockHeaderByHash calls rsk_getRawBlockHeaderByHash and returns the
+// RLP encoded header, unchecked; see HeaderByHash.
+func (c *Client) RawBlockHeaderByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	raw, _, err := c.rawHeader(ctx, "rsk_getRawBlockHeaderByHash", hash)
+	return raw, err
+}
+
+// rawHeader also returns the index of the endpoint that served the header.
+func (c *Client) rawHeader(ctx context.Context, method string, id any) ([]byte, int, error) {
+	var raw hexutil.Bytes
+	served, err := c.callOn(ctx, &raw, method, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(raw) == 0 {
+		return nil, 0, fmt.Errorf("block header %v: %w", id, ErrNotFound)
+	}
+	return raw, served, nil
+}
+
+// HeaderByNumber fetches the raw header of block number and decodes it
+// under config's rules with rskblocks.DecodeRawBlockHeaderByNumber.
+func (c *Client) HeaderByNumber(ctx context.Context, number uint64, config *rskblocks.ChainConfig) (*rskblocks.BlockHeader, error) {
+	raw, served, err := c.rawHeader(ctx, "rsk_getRawBlockHeaderByNumber", BlockNumber(number))
+	if err != nil {
+		return nil, err
+	}
+	header, err := rskblocks.DecodeRawBlockHeaderByNumber(raw, number, config)
+	if err != nil {
+		return nil, err
+	}
+	return c.crossCheckHeader(ctx, served, header, false)
+}
+
+// HeaderByHash fetches the raw header of block hash, decodes it under
+// config's rules and checks that it hashes to hash, with
+// rskblocks.DecodeRawBlockHeaderByHash.
+func (c *Client) HeaderByHash(ctx context.Context, hash common.Hash, config *rskblocks.ChainConfig) (*rskblocks.BlockHeader, error) {
+	raw, served, err := c.rawHeader(ctx, "rsk_getRawBlockHeaderByHash", hash)
+	if err != nil {
+		return nil, err
+	}
+	header, err := rskblocks.DecodeRawBlockHeaderByHash(raw, hash, config)
+	if err != nil {
+		return nil, err
+	}
+	return c.crossCheckHeader(ctx, served, header, true)
+}
+
+func (c *Client) crossCheckHeader(ctx context.Context, served int, header *rskblocks.BlockHeader, byHash bool) (*rskblocks.BlockHeader, error) {
+	if err := c.crossCheck(ctx, served, header.Number.Uint64(), header.Hash(), byHash); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// TransactionReceipt calls eth_getTransactionReceipt.
+func (c *Client) TransactionReceipt(ctx context.Context, txHash common.Hash) (*Receipt, error) {
+	var receipt *Receipt
+	if err := c.call(ctx, &receipt, "eth_getTransactionReceipt", txHash); err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		return nil, fmt.Errorf("receipt of %s: %w", txHash, ErrNotFound)
+	}
+	return receipt, nil
+}
+
+// Call calls eth_call, executing msg against the state at ref, and returns
+// the return data.
+func (c *Client) Call(ctx context.Context, msg CallMsg, ref BlockRef) ([]byte, error) {
+	var result hexutil.Bytes
+	if err := c.call(ctx, &result, "eth_call", msg, ref); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *Client) call(ctx context.Context, result any, method string, args ...any) error {
+	_, err := c.callOn(ctx, result, method, args...)
+	return err
+}
+
+// callOn is call that also returns the index of the endpoint that answered.
+func (c *Client) callOn(ctx context.Context, result any, method string, args ...any) (int, error) {
+	served, err := c.do(ctx, 1, func(ctx context.Context, client *rpc.Client) error {
+		return client.CallContext(ctx, result, method, args...)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s RPC call failed: %w", method, err)
+	}
+	return served, nil
+}
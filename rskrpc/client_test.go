@@ -0,0 +1,204 @@
+package rskrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rskblocks"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+type rpcRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// testHandler answers one request with its result or an error.
+type testHandler func(method string, params json.RawMessage) (any, error)
+
+// newTestClient returns a client of a JSON-RPC server answering every
+// request, single or batched, with handle.
+func newTestClient(t *testing.T, handle testHandler) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		answer := func(req rpcRequest) rpcResponse {
+			resp := rpcResponse{JSONRPC: "2.0", ID: req.ID}
+			result, err := handle(req.Method, req.Params)
+			if err != nil {
+				resp.Error = &rpcError{Code: -32000, Message: err.Error()}
+			} else if result == nil {
+				resp.Result = json.RawMessage("null")
+			} else {
+				resp.Result = result
+			}
+			return resp
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+			var reqs []rpcRequest
+			json.Unmarshal(body, &reqs)
+			resps := make([]rpcResponse, len(reqs))
+			for i, req := range reqs {
+				resps[i] = answer(req)
+			}
+			json.NewEncoder(w).Encode(resps)
+			return
+		}
+		var req rpcRequest
+		json.Unmarshal(body, &req)
+		json.NewEncoder(w).Encode(answer(req))
+	}))
+	t.Cleanup(server.Close)
+	client, err := Dial(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestBlockRef(t *testing.T) {
+	for _, c := range []struct {
+		ref  BlockRef
+		want string
+	}{
+		{BlockRef{}, "latest"},
+		{Latest(), "latest"},
+		{Earliest(), "earliest"},
+		{Pending(), "pending"},
+		{BlockNumber(0), "0x0"},
+		{BlockNumber(7139700), "0x6cf174"},
+	} {
+		data, err := json.Marshal(c.ref)
+		if err != nil || string(data) != `"`+c.want+`"` {
+			t.Errorf("%v marshals to %s, %v; want %q", c.ref, data, err, c.want)
+		}
+	}
+}
+
+func TestClientEndpoints(t *testing.T) {
+	config := rskblocks.RegtestChainConfig()
+	input := &rskblocks.BlockHeaderInput{
+		Difficulty: big.NewInt(1),
+		Number:     big.NewInt(12),
+		GasLimit:   big.NewInt(6_800_000),
+		GasUsed:    big.NewInt(0),
+		Timestamp:  big.NewInt(1_700_000_000),
+		PaidFees:   big.NewInt(0),
+	}
+	header := rskblocks.InputToBlockHeader(input, config.BlockHashConfig(12))
+	txHash := common.HexToHash("0xaa")
+	contract := common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051")
+
+	var calls []string
+	client := newTestClient(t, func(method string, params json.RawMessage) (any, error) {
+		calls = append(calls, method+" "+string(params))
+		switch method {
+		case "eth_chainId":
+			return "0x21", nil
+		case "eth_getBlockByNumber":
+			if string(params) == `["0x63",false]` {
+				return nil, nil
+			}
+			return map[string]any{"number": "0xc", "hash": header.Hash(), "timestamp": "0x0", "size": "0x1", "paidFees": "0x0"}, nil
+		case "rsk_getRawBlockHeaderByNumber", "rsk_getRawBlockHeaderByHash":
+			return hexutil.Bytes(header.GetFullEncoded()), nil
+		case "eth_getTransactionReceipt":
+			if !bytes.Contains(params, []byte(txHash.Hex())) {
+				return nil, nil
+			}
+			return map[string]any{
+				"transactionHash":   txHash,
+				"transactionIndex":  "0x0",
+				"blockNumber":       "0xc",
+				"cumulativeGasUsed": "0x5208",
+				"gasUsed":           "0x5208",
+				"status":            "0x1",
+				"root":              "0x01",
+				"logs": []any{map[string]any{
+					"address": contract, "topics": []common.Hash{{1}}, "data": "0x02",
+					"blockNumber": "0xc", "transactionIndex": "0x0", "logIndex": "0x0",
+				}},
+			}, nil
+		case "eth_call":
+			return "0x2a", nil
+		case "eth_getProof":
+			return map[string]any{"address": contract, "balance": "0x5", "nonce": "0x1", "accountProof": []string{"0x01"}, "storageProof": []any{}}, nil
+		}
+		return nil, errors.New("method not found")
+	})
+	ctx := context.Background()
+
+	if id, err := client.ChainID(ctx); err != nil || id != 33 {
+		t.Errorf("ChainID: %d, %v", id, err)
+	}
+	block, err := client.BlockByNumber(ctx, BlockNumber(12), false)
+	if err != nil || block.Number != 12 || block.Hash != header.Hash() {
+		t.Errorf("BlockByNumber: %+v, %v", block, err)
+	}
+	if _, err := client.BlockByNumber(ctx, BlockNumber(99), false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Missing block: %v", err)
+	}
+	if got, err := client.HeaderByNumber(ctx, 12, config); err != nil || got.Hash() != header.Hash() {
+		t.Errorf("HeaderByNumber: %v", err)
+	}
+	if got, err := client.HeaderByHash(ctx, header.Hash(), config); err != nil || got.Hash() != header.Hash() {
+		t.Errorf("HeaderByHash: %v", err)
+	}
+	if _, err := client.HeaderByHash(ctx, common.Hash{1}, config); err == nil {
+		t.Error("Header for another hash accepted")
+	}
+
+	receipt, err := client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	consensus := receipt.TransactionReceipt()
+	if consensus.GasUsed != 21000 || !bytes.Equal(consensus.Status, []byte{1}) || len(consensus.Logs) != 1 || consensus.Logs[0].Address != contract {
+		t.Errorf("Receipt %+v", consensus)
+	}
+
+	to := contract
+	out, err := client.Call(ctx, CallMsg{To: &to, Data: []byte{0x70, 0xa0}, Value: rskblocks.CoinFromWei(1)}, Latest())
+	if err != nil || !bytes.Equal(out, []byte{0x2a}) {
+		t.Errorf("Call: %x, %v", out, err)
+	}
+	if last := calls[len(calls)-1]; last != `eth_call [{"data":"0x70a0","to":"0x77045e71a7a2c50903d88e564cd72fab11e82051","value":"0x1"},"latest"]` {
+		t.Errorf("eth_call sent %s", last)
+	}
+
+	proof, err := client.GetProof(ctx, contract, nil, Latest())
+	if err != nil || proof.Balance.ToInt().Int64() != 5 || len(proof.AccountProof) != 1 {
+		t.Errorf("GetProof: %+v, %v", proof, err)
+	}
+
+	if _, err := client.TransactionReceipt(ctx, common.Hash{}); !errors.Is(err, ErrNotFound) {
+		t.Error("Missing receipt accepted")
+	}
+}
@@ -0,0 +1,238 @@
+package rskrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrEndpointMismatch is returned when endpoints disagree on a block hash,
+// so at least one of them is lying or on another chain.
+var ErrEndpointMismatch = errors.New("endpoints disagree")
+
+// ErrNotConfirmed is returned when no endpoint could confirm a block hash,
+// because they failed or do not have the block yet.
+var ErrNotConfirmed = errors.New("no endpoint confirms the block")
+
+// RetryPolicy says how a Client retries a failed call. Every call the
+// Client makes is a read, so all of them are retried. Each retry goes to the
+// next endpoint, after a backoff doubling from InitialBackoff up to
+// MaxBackoff.
+type RetryPolicy struct {
+	// Attempts is the number of tries of a call, counting the first. Less
+	// than one means one.
+	Attempts int
+	// InitialBackoff is the wait before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between retries. Zero means no cap.
+	MaxBackoff time.Duration
+	// AttemptTimeout bounds each try, so a hanging endpoint is failed over
+	// like an erroring one. Zero means only the caller's context bounds it.
+	AttemptTimeout time.Duration
+}
+
+// DefaultRetryPolicy returns a policy suited to public RPC providers.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Attempts:       4,
+		InitialBackoff: 250 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		AttemptTimeout: 30 * time.Second,
+	}
+}
+
+// backoff returns the wait before retry number retry, counting from 1.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	wait := p.InitialBackoff
+	for i := 1; i < retry && wait > 0; i++ {
+		wait *= 2
+		if p.MaxBackoff > 0 && wait >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	return wait
+}
+
+// Options configure a Client over several endpoints.
+type Options struct {
+	Retry RetryPolicy
+	// CrossCheckBlocks makes the Client check every block and header it
+	// returns against the other endpoints: by hash for blocks fetched by
+	// hash, otherwise as CheckBlockHash does. It needs at least two
+	// endpoints.
+	CrossCheckBlocks bool
+	// RateLimit applies to each endpoint separately.
+	RateLimit RateLimit
+}
+
+// endpoint is one RSK node a Client talks to.
+type endpoint struct {
+	url     string
+	rpc     *rpc.Client
+	limiter *limiter
+}
+
+// DialEndpoints connects to the RSK nodes at urls, in order of preference,
+// and returns a Client that fails over between them under opts. Calls go to
+// one endpoint at a time, moving on to the next once it fails.
+func DialEndpoints(ctx context.Context, urls []string, opts Options) (*Client, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("%w: no endpoints", ErrInvalidArgument)
+	}
+	if opts.CrossCheckBlocks && len(urls) < 2 {
+		return nil, fmt.Errorf("%w: cross-checking blocks needs at least two endpoints", ErrInvalidArgument)
+	}
+	c := &Client{options: opts}
+	for _, url := range urls {
+		client, err := rpc.DialContext(ctx, url)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("failed to connect to RPC %s: %w", url, err)
+		}
+		c.endpoints = append(c.endpoints, endpoint{url: url, rpc: client, limiter: newLimiter(opts.RateLimit)})
+	}
+	return c, nil
+}
+
+// do runs fn, a request of calls calls, against the current endpoint,
+// retrying and failing over to the next endpoints under the retry policy.
+// It returns the index of the endpoint that answered.
+func (c *Client) do(ctx context.Context, calls int, fn func(ctx context.Context, client *rpc.Client) error) (int, error) {
+	policy := c.options.Retry
+	var err error
+	for attempt := 0; attempt < max(policy.Attempts, 1); attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, policy.backoff(attempt)); err != nil {
+				return 0, err
+			}
+		}
+		i := c.next.Load()
+		served := int(i % uint64(len(c.endpoints)))
+		err = c.try(ctx, c.endpoints[served], calls, policy.AttemptTimeout, fn)
+		if err == nil {
+			return served, nil
+		}
+		if !retryable(ctx, err) {
+			return 0, err
+		}
+		c.next.CompareAndSwap(i, i+1)
+	}
+	return 0, err
+}
+
+func (c *Client) try(ctx context.Context, ep endpoint, calls int, timeout time.Duration, fn func(ctx context.Context, client *rpc.Client) error) error {
+	release, err := ep.limiter.acquire(ctx, calls)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	err = fn(ctx, ep.rpc)
+	if err != nil && len(c.endpoints) > 1 {
+		err = fmt.Errorf("%s: %w", ep.url, err)
+	}
+	return err
+}
+
+// retryable reports whether a call that failed with err may succeed if
+// tried again. Requests any node would refuse are not retried, nor are calls
+// whose context is done.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		switch rpcErr.ErrorCode() {
+		case -32600, -32602: // invalid request, invalid params
+			return false
+		}
+	}
+	return true
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CheckBlockHash asks every endpoint for the hash of block number. It
+// returns ErrEndpointMismatch if one answers other than hash, and
+// ErrNotConfirmed if none answers hash. Endpoints that fail or do not have
+// the block yet are skipped.
+func (c *Client) CheckBlockHash(ctx context.Context, number uint64, hash common.Hash) error {
+	return c.checkBlock(ctx, number, hash, false, -1)
+}
+
+// crossCheck checks a block that endpoint served against the other
+// endpoints if the client cross-checks blocks. A block fetched by hash may
+// be an uncle or on a side chain, so the others are asked for that hash;
+// one fetched by number or tag is compared with their canonical block at
+// its number.
+func (c *Client) crossCheck(ctx context.Context, served int, number uint64, hash common.Hash, byHash bool) error {
+	if !c.options.CrossCheckBlocks {
+		return nil
+	}
+	return c.checkBlock(ctx, number, hash, byHash, served)
+}
+
+// checkBlock asks every endpoint but skip, the one whose answer is being
+// checked, for block hash with number: by hash if byHash, otherwise for
+// their canonical block at number.
+func (c *Client) checkBlock(ctx context.Context, number uint64, hash common.Hash, byHash bool, skip int) error {
+	method, id := "eth_getBlockByNumber", any(BlockNumber(number))
+	if byHash {
+		method, id = "eth_getBlockByHash", hash
+	}
+	confirmed := false
+	for i, ep := range c.endpoints {
+		if i == skip {
+			continue
+		}
+		var block *struct {
+			Number hexutil.Uint64 `json:"number"`
+			Hash   common.Hash    `json:"hash"`
+		}
+		err := c.try(ctx, ep, 1, c.options.Retry.AttemptTimeout, func(ctx context.Context, client *rpc.Client) error {
+			return client.CallContext(ctx, &block, method, id, false)
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			continue
+		}
+		if block == nil {
+			continue
+		}
+		if block.Hash != hash || uint64(block.Number) != number {
+			return fmt.Errorf("%w: %s has block %d %s, want %d %s", ErrEndpointMismatch, ep.url, block.Number, block.Hash, number, hash)
+		}
+		confirmed = true
+	}
+	if !confirmed {
+		return fmt.Errorf("block %d %s: %w", number, hash, ErrNotConfirmed)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package rskrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testEndpoint serves handle like newTestClient and counts its requests.
+type testEndpoint struct {
+	url      string
+	requests atomic.Int32
+}
+
+func newTestEndpoint(t *testing.T, handle testHandler) *testEndpoint {
+	t.Helper()
+	ep := &testEndpoint{}
+	inner := newTestClient(t, func(method string, params json.RawMessage) (any, error) {
+		ep.requests.Add(1)
+		return handle(method, params)
+	})
+	ep.url = inner.endpoints[0].url
+	return ep
+}
+
+func testBlockJSON(number string, hash common.Hash) map[string]any {
+	return map[string]any{"number": number, "hash": hash, "timestamp": "0x0", "size": "0x0"}
+}
+
+func dialTestEndpoints(t *testing.T, opts Options, eps ...*testEndpoint) *Client {
+	t.Helper()
+	urls := make([]string, len(eps))
+	for i, ep := range eps {
+		urls[i] = ep.url
+	}
+	client, err := DialEndpoints(context.Background(), urls, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestFailover(t *testing.T) {
+	down := newTestEndpoint(t, func(string, json.RawMessage) (any, error) {
+		return nil, errors.New("overloaded")
+	})
+	up := newTestEndpoint(t, func(string, json.RawMessage) (any, error) {
+		return "0x1f", nil
+	})
+	client := dialTestEndpoints(t, Options{Retry: RetryPolicy{Attempts: 3, InitialBackoff: time.Millisecond}}, down, up)
+
+	for i := 0; i < 2; i++ {
+		id, err := client.ChainID(context.Background())
+		if err != nil || id != 31 {
+			t.Fatalf("ChainID: %d, %v", id, err)
+		}
+	}
+	if down.requests.Load() != 1 || up.requests.Load() != 2 {
+		t.Errorf("Requests: down %d, up %d; want the client to stay on the working endpoint", down.requests.Load(), up.requests.Load())
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	down := newTestEndpoint(t, func(string, json.RawMessage) (any, error) {
+		return nil, errors.New("overloaded")
+	})
+	client := dialTestEndpoints(t, Options{Retry: RetryPolicy{Attempts: 3, InitialBackoff: time.Millisecond}}, down)
+	if _, err := client.ChainID(context.Background()); err == nil {
+		t.Fatal("ChainID succeeded against a failing endpoint")
+	}
+	if down.requests.Load() != 3 {
+		t.Errorf("Tried %d times, want 3", down.requests.Load())
+	}
+}
+
+func TestRetryTimeout(t *testing.T) {
+	release := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer hanging.Close()
+	defer close(release)
+	up := newTestEndpoint(t, func(string, json.RawMessage) (any, error) {
+		return "0x1f", nil
+	})
+	client, err := DialEndpoints(context.Background(), []string{hanging.URL, up.url}, Options{
+		Retry: RetryPolicy{Attempts: 2, AttemptTimeout: 50 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if id, err := client.ChainID(context.Background()); err != nil || id != 31 {
+		t.Errorf("ChainID: %d, %v", id, err)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	ctx := context.Background()
+	if retryable(ctx, &testRPCError{code: -32602}) {
+		t.Error("Invalid params retried")
+	}
+	if !retryable(ctx, &testRPCError{code: -32000}) || !retryable(ctx, errors.New("EOF")) {
+		t.Error("Server error not retried")
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if retryable(cancelled, context.Canceled) {
+		t.Error("Cancelled call retried")
+	}
+}
+
+type testRPCError struct{ code int }
+
+func (e *testRPCError) Error() string  { return "rpc error" }
+func (e *testRPCError) ErrorCode() int { return e.code }
+
+var _ rpc.Error = (*testRPCError)(nil)
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	for retry, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 300 * time.Millisecond, 10: 300 * time.Millisecond} {
+		if got := p.backoff(retry); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", retry, got, want)
+		}
+	}
+}
+
+func TestCrossCheckBlocks(t *testing.T) {
+	honest := common.Hash{1}
+	serve := func(hash common.Hash) testHandler {
+		return func(string, json.RawMessage) (any, error) {
+			return testBlockJSON("0x5", hash), nil
+		}
+	}
+	opts := Options{CrossCheckBlocks: true}
+
+	client := dialTestEndpoints(t, opts, newTestEndpoint(t, serve(honest)), newTestEndpoint(t, serve(honest)))
+	if _, err := client.BlockByNumber(context.Background(), BlockNumber(5), false); err != nil {
+		t.Errorf("Agreeing endpoints: %v", err)
+	}
+
+	liar := newTestEndpoint(t, serve(common.Hash{2}))
+	client = dialTestEndpoints(t, opts, newTestEndpoint(t, serve(honest)), liar)
+	if _, err := client.BlockByNumber(context.Background(), BlockNumber(5), false); !errors.Is(err, ErrEndpointMismatch) {
+		t.Errorf("Lying endpoint: %v", err)
+	}
+
+	lagging := newTestEndpoint(t, func(string, json.RawMessage) (any, error) { return nil, nil })
+	client = dialTestEndpoints(t, opts, newTestEndpoint(t, serve(honest)), lagging)
+	if err := client.CheckBlockHash(context.Background(), 5, honest); err != nil {
+		t.Errorf("Lagging endpoint: %v", err)
+	}
+	// The serving endpoint does not confirm its own block.
+	if _, err := client.BlockByNumber(context.Background(), BlockNumber(5), false); !errors.Is(err, ErrNotConfirmed) {
+		t.Errorf("Block only one endpoint has: %v", err)
+	}
+
+	down := newTestEndpoint(t, func(string, json.RawMessage) (any, error) { return nil, errors.New("overloaded") })
+	client = dialTestEndpoints(t, opts, newTestEndpoint(t, serve(honest)), down)
+	if _, err := client.BlockByNumber(context.Background(), BlockNumber(5), false); !errors.Is(err, ErrNotConfirmed) {
+		t.Errorf("Other endpoint down: %v", err)
+	}
+}
+
+func TestCrossCheckBlocksByHash(t *testing.T) {
+	canonical, side := common.Hash{1}, common.Hash{9}
+	// serve answers by number with the canonical block 5 and by hash with
+	// the blocks in known, which map requested to served hashes.
+	serve := func(known map[common.Hash]common.Hash) testHandler {
+		return func(method string, params json.RawMessage) (any, error) {
+			if method == "eth_getBlockByNumber" {
+				return testBlockJSON("0x5", canonical), nil
+			}
+			var args []json.RawMessage
+			var hash common.Hash
+			if err := json.Unmarshal(params, &args); err != nil || json.Unmarshal(args[0], &hash) != nil {
+				return nil, errors.New("bad params")
+			}
+			if served, ok := known[hash]; ok {
+				return testBlockJSON("0x5", served), nil
+			}
+			return nil, nil
+		}
+	}
+	opts := Options{CrossCheckBlocks: true}
+	both := map[common.Hash]common.Hash{canonical: canonical, side: side}
+
+	// A side-chain block is confirmed by hash, not against the canonical one.
+	client := dialTestEndpoints(t, opts, newTestEndpoint(t, serve(both)), newTestEndpoint(t, serve(both)))
+	if _, err := client.BlockByHash(context.Background(), side, false); err != nil {
+		t.Errorf("Side-chain block by hash: %v", err)
+	}
+	if _, err := client.BlockByNumber(context.Background(), BlockHash(side), false); err != nil {
+		t.Errorf("Side-chain block by hash reference: %v", err)
+	}
+	batch := client.NewBatch()
+	call := batch.BlockByHash(side, false)
+	if err := batch.Send(context.Background()); err != nil || call.Err != nil {
+		t.Errorf("Batched side-chain block: %v, %v", err, call.Err)
+	}
+
+	// An endpoint answering the hash with another block disagrees.
+	liar := newTestEndpoint(t, serve(map[common.Hash]common.Hash{side: {2}}))
+	client = dialTestEndpoints(t, opts, newTestEndpoint(t, serve(both)), liar)
+	if _, err := client.BlockByHash(context.Background(), side, false); !errors.Is(err, ErrEndpointMismatch) {
+		t.Errorf("Lying endpoint by hash: %v", err)
+	}
+
+	// An endpoint without the side-chain block does not confirm it.
+	canonicalOnly := newTestEndpoint(t, serve(map[common.Hash]common.Hash{canonical: canonical}))
+	client = dialTestEndpoints(t, opts, newTestEndpoint(t, serve(both)), canonicalOnly)
+	if _, err := client.BlockByHash(context.Background(), side, false); !errors.Is(err, ErrNotConfirmed) {
+		t.Errorf("Unknown side-chain block: %v", err)
+	}
+	// By number the canonical block is still what is compared.
+	if _, err := client.BlockByNumber(context.Background(), BlockNumber(5), false); err != nil {
+		t.Errorf("Canonical block by number: %v", err)
+	}
+}
+
+func TestCrossCheckBatchBlocks(t *testing.T) {
+	serve := func(hash common.Hash) testHandler {
+		return func(method string, _ json.RawMessage) (any, error) {
+			if method == "eth_getTransactionReceipt" {
+				return nil, nil
+			}
+			return testBlockJSON("0x5", hash), nil
+		}
+	}
+	client := dialTestEndpoints(t, Options{CrossCheckBlocks: true}, newTestEndpoint(t, serve(common.Hash{1})), newTestEndpoint(t, serve(common.Hash{2})))
+
+	batch := client.NewBatch()
+	block := batch.BlockByNumber(BlockNumber(5), false)
+	receipt := batch.TransactionReceipt(common.Hash{3})
+	if err := batch.Send(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(block.Err, ErrEndpointMismatch) || block.Result != nil {
+		t.Errorf("Batched block from a lying endpoint: %v", block.Err)
+	}
+	if !errors.Is(receipt.Err, ErrNotFound) {
+		t.Errorf("Receipt: %v", receipt.Err)
+	}
+}
+
+func TestDialEndpointsInvalid(t *testing.T) {
+	if _, err := DialEndpoints(context.Background(), nil, Options{}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("No endpoints: %v", err)
+	}
+	if _, err := DialEndpoints(context.Background(), []string{"http://localhost:4444"}, Options{CrossCheckBlocks: true}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Cross-checking with one endpoint: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package rskrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestParseBlockRef(t *testing.T) {
+	hash := common.HexToHash("0xb0a3a3e8b7d1a8ce8ec39d7b6f2c83e7b0f6a66b9c2b9f4f1d3f7b1f2c5a9e01")
+	for _, c := range []struct {
+		in   string
+		json string
+	}{
+		{"", `"latest"`},
+		{"latest", `"latest"`},
+		{"earliest", `"earliest"`},
+		{"pending", `"pending"`},
+		{"0x6cf174", `"0x6cf174"`},
+		{"7139700", `"0x6cf174"`},
+		{hash.Hex(), `{"blockHash":"` + hash.Hex() + `"}`},
+	} {
+		ref, err := ParseBlockRef(c.in)
+		if err != nil {
+			t.Errorf("%q: %v", c.in, err)
+			continue
+		}
+		if data, _ := json.Marshal(ref); string(data) != c.json {
+			t.Errorf("%q marshals to %s, want %s", c.in, data, c.json)
+		}
+	}
+	if got, ok := BlockHash(hash).Hash(); !ok || got != hash {
+		t.Error("Hash reference lost its hash")
+	}
+	for _, bad := range []string{"safe", "0x", "0xzz", "-1", "+5", "0x" + common.Bytes2Hex(make([]byte, 31)) + "zz"} {
+		if _, err := ParseBlockRef(bad); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%q: %v", bad, err)
+		}
+	}
+}
+
+func TestGetProofValidation(t *testing.T) {
+	contract := common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051")
+	keys := []common.Hash{{1}, {2}}
+	var lastParams string
+	response := map[string]any{
+		"address":      contract,
+		"accountProof": []string{"0x01"},
+		"balance":      "0x0",
+		"nonce":        "0x0",
+		"storageProof": []any{
+			map[string]any{"key": "0x01" + common.Bytes2Hex(make([]byte, 31)), "value": "0x", "proof": []string{}},
+			map[string]any{"key": "0x02" + common.Bytes2Hex(make([]byte, 31)), "value": "0x7", "proof": []string{"0x02"}},
+		},
+	}
+	client := newTestClient(t, func(method string, params json.RawMessage) (any, error) {
+		lastParams = string(params)
+		return response, nil
+	})
+	ctx := context.Background()
+
+	result, err := client.GetProof(ctx, contract, keys, BlockHash(common.Hash{9}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.StorageProof[1].Value.Int64() != 7 {
+		t.Errorf("Storage value %v", result.StorageProof[1].Value)
+	}
+	if want := `{"blockHash":"` + (common.Hash{9}).Hex() + `"}]`; lastParams[len(lastParams)-len(want):] != want {
+		t.Errorf("Block hash sent as %s", lastParams)
+	}
+
+	for name, call := range map[string]func() error{
+		"pending": func() error {
+			_, err := client.GetProof(ctx, contract, keys, Pending())
+			return err
+		},
+		"repeated key": func() error {
+			_, err := client.GetProof(ctx, contract, []common.Hash{{1}, {1}}, Latest())
+			return err
+		},
+	} {
+		if err := call(); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	for name, call := range map[string]func() error{
+		"other account": func() error {
+			_, err := client.GetProof(ctx, common.Address{1}, keys, Latest())
+			return err
+		},
+		"missing proof": func() error {
+			_, err := client.GetProof(ctx, contract, []common.Hash{{1}, {2}, {3}}, Latest())
+			return err
+		},
+		"reordered": func() error {
+			_, err := client.GetProof(ctx, contract, []common.Hash{{2}, {1}}, Latest())
+			return err
+		},
+	} {
+		if err := call(); !errors.Is(err, ErrInvalidResponse) {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
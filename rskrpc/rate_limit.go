@@ -0,0 +1,113 @@
+package rskrpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimit bounds the load a Client puts on each endpoint, so bulk jobs
+// stay within what public RPC providers tolerate. The zero value sets no
+// limit.
+type RateLimit struct {
+	// QPS is the sustained rate of calls per second. A batch of n calls
+	// counts as n. Zero means no rate limit.
+	QPS float64
+	// Burst is how many calls may go at once after the endpoint has been
+	// idle. Less than one means one.
+	Burst int
+	// MaxInFlight is the number of requests that may wait on the endpoint
+	// at once. Zero means no bound.
+	MaxInFlight int
+}
+
+// limiter enforces a RateLimit on one endpoint. The rate is a token bucket
+// kept as the time the next call may start.
+type limiter struct {
+	interval time.Duration
+	burst    int
+	inFlight chan struct{}
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// newLimiter returns a limiter enforcing limit, or nil if it sets none.
+func newLimiter(limit RateLimit) *limiter {
+	if limit.QPS <= 0 && limit.MaxInFlight <= 0 {
+		return nil
+	}
+	l := &limiter{burst: max(limit.Burst, 1)}
+	if limit.QPS > 0 {
+		l.interval = time.Duration(float64(time.Second) / limit.QPS)
+	}
+	if limit.MaxInFlight > 0 {
+		l.inFlight = make(chan struct{}, limit.MaxInFlight)
+	}
+	return l
+}
+
+// acquire waits until a request of calls calls may be sent, and returns the
+// function that marks it done. A nil limiter never waits.
+func (l *limiter) acquire(ctx context.Context, calls int) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	release = func() {}
+	if l.inFlight != nil {
+		select {
+		case l.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		release = func() { <-l.inFlight }
+	}
+	deadline, _ := ctx.Deadline()
+	start, end, ok := l.reserve(calls, deadline)
+	if !ok {
+		release()
+		return nil, fmt.Errorf("rate limit wait would pass the deadline: %w", context.DeadlineExceeded)
+	}
+	if err := sleep(ctx, time.Until(start)); err != nil {
+		l.cancel(start, end)
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// reserve takes calls tokens and returns when they may be used, up to end.
+// It takes none if they could not be used by deadline, unless deadline is
+// zero.
+func (l *limiter) reserve(calls int, deadline time.Time) (start, end time.Time, ok bool) {
+	now := time.Now()
+	if l.interval == 0 {
+		return now, now, true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	start = now.Add(-time.Duration(l.burst-1) * l.interval)
+	if l.next.After(start) {
+		start = l.next
+	}
+	if !deadline.IsZero() && start.After(deadline) {
+		return time.Time{}, time.Time{}, false
+	}
+	end = start.Add(time.Duration(calls) * l.interval)
+	l.next = end
+	return start, end, true
+}
+
+// cancel gives back a reservation that was not used. Tokens reserved since
+// are not moved, so only the latest reservation can be given back.
+func (l *limiter) cancel(start, end time.Time) {
+	if l.interval == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.next.Equal(end) {
+		l.next = start
+	}
+}
@@ -0,0 +1,121 @@
+package rskrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// reserveWait reserves calls tokens from l and returns the wait.
+func reserveWait(t *testing.T, l *limiter, calls int) time.Duration {
+	t.Helper()
+	start, _, ok := l.reserve(calls, time.Time{})
+	if !ok {
+		t.Fatal("Reservation without a deadline refused")
+	}
+	return time.Until(start)
+}
+
+func TestLimiterReserve(t *testing.T) {
+	l := newLimiter(RateLimit{QPS: 10, Burst: 2})
+	if reserveWait(t, l, 1) > 0 || reserveWait(t, l, 1) > 0 {
+		t.Error("Burst of 2 waited")
+	}
+	if wait := reserveWait(t, l, 1); wait < 90*time.Millisecond || wait > 100*time.Millisecond {
+		t.Errorf("Third call waits %s, want 100ms", wait)
+	}
+	if wait := reserveWait(t, l, 5); wait < 190*time.Millisecond {
+		t.Errorf("Batch waits %s, want 200ms", wait)
+	}
+	if wait := reserveWait(t, l, 1); wait < 690*time.Millisecond {
+		t.Errorf("Call after a batch of 5 waits %s, want 700ms", wait)
+	}
+}
+
+func TestLimiterGivesUpWithoutTokens(t *testing.T) {
+	l := newLimiter(RateLimit{QPS: 1})
+	reserveWait(t, l, 1)
+
+	// The deadline is before the next slot: nothing is reserved.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Acquire past the deadline: %v", err)
+	}
+
+	// Cancelled while waiting: the slot is given back.
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := l.acquire(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("Cancelled acquire: %v", err)
+	}
+
+	if wait := reserveWait(t, l, 1); wait > time.Second {
+		t.Errorf("Next call waits %s after two abandoned ones, want at most 1s", wait)
+	}
+}
+
+func TestNewLimiterNone(t *testing.T) {
+	if l := newLimiter(RateLimit{}); l != nil {
+		t.Errorf("Zero RateLimit made a limiter")
+	}
+	release, err := (*limiter)(nil).acquire(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+}
+
+func TestMaxInFlight(t *testing.T) {
+	var current, peak atomic.Int32
+	ep := newTestEndpoint(t, func(string, json.RawMessage) (any, error) {
+		n := current.Add(1)
+		defer current.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return "0x1f", nil
+	})
+	client := dialTestEndpoints(t, Options{RateLimit: RateLimit{MaxInFlight: 2}}, ep)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.ChainID(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if peak.Load() > 2 {
+		t.Errorf("%d requests in flight, want at most 2", peak.Load())
+	}
+}
+
+func TestRateLimitContext(t *testing.T) {
+	ep := newTestEndpoint(t, func(string, json.RawMessage) (any, error) {
+		return "0x1f", nil
+	})
+	client := dialTestEndpoints(t, Options{RateLimit: RateLimit{QPS: 1}}, ep)
+	if _, err := client.ChainID(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.ChainID(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Call over the rate limit: %v", err)
+	}
+	if ep.requests.Load() != 1 {
+		t.Errorf("Endpoint saw %d requests, want 1", ep.requests.Load())
+	}
+}
@@ -0,0 +1,87 @@
+package rskrpc
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rskblocks"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Receipt is a transaction receipt as eth_getTransactionReceipt returns it.
+type Receipt struct {
+	TransactionHash   common.Hash         `json:"transactionHash"`
+	TransactionIndex  hexutil.Uint        `json:"transactionIndex"`
+	BlockHash         common.Hash         `json:"blockHash"`
+	BlockNumber       hexutil.Uint64      `json:"blockNumber"`
+	From              common.Address      `json:"from"`
+	To                *common.Address     `json:"to"`
+	CumulativeGasUsed hexutil.Uint64      `json:"cumulativeGasUsed"`
+	GasUsed           hexutil.Uint64      `json:"gasUsed"`
+	ContractAddress   *common.Address     `json:"contractAddress"`
+	Logs              []*rskblocks.RPCLog `json:"logs"`
+	LogsBloom         rskblocks.Bloom     `json:"logsBloom"`
+	Root              hexutil.Bytes       `json:"root"`
+	// Status is 1 for success and 0 for failure.
+	Status hexutil.Uint64 `json:"status"`
+}
+
+// TransactionReceipt converts r to the consensus receipt whose encoding the
+// block's receipts root commits to, for rskblocks' receipt proofs.
+func (r *Receipt) TransactionReceipt() *rskblocks.TransactionReceipt {
+	receipt := &rskblocks.TransactionReceipt{
+		PostState:         r.Root,
+		CumulativeGasUsed: uint64(r.CumulativeGasUsed),
+		Bloom:             r.LogsBloom,
+		TxHash:            r.TransactionHash,
+		GasUsed:           uint64(r.GasUsed),
+		Status:            []byte{},
+	}
+	if r.ContractAddress != nil {
+		receipt.ContractAddress = *r.ContractAddress
+	}
+	if r.Status == 1 {
+		receipt.Status = []byte{1}
+	}
+	receipt.Logs = make([]*rskblocks.Log, len(r.Logs))
+	for i, l := range r.Logs {
+		receipt.Logs[i] = &rskblocks.Log{Address: l.Address, Topics: l.Topics, Data: l.Data}
+	}
+	return receipt
+}
+
+// CallMsg is the transaction eth_call executes. Nil and zero fields are
+// left for the node to fill in.
+type CallMsg struct {
+	From     *common.Address
+	To       *common.Address
+	Gas      uint64
+	GasPrice rskblocks.Coin
+	Value    rskblocks.Coin
+	Data     []byte
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m CallMsg) MarshalJSON() ([]byte, error) {
+	arg := map[string]any{}
+	if m.From != nil {
+		arg["from"] = m.From
+	}
+	if m.To != nil {
+		arg["to"] = m.To
+	}
+	if m.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(m.Gas)
+	}
+	if !m.GasPrice.IsZero() {
+		arg["gasPrice"] = m.GasPrice
+	}
+	if !m.Value.IsZero() {
+		arg["value"] = m.Value
+	}
+	if len(m.Data) > 0 {
+		arg["data"] = hexutil.Bytes(m.Data)
+	}
+	return json.Marshal(arg)
+}
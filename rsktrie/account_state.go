@@ -0,0 +1,92 @@
+package rsktrie
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// AccountState is the account record stored in the unitrie under an account
+// key. RSK encodes it as the RLP list [nonce, balance, stateFlags], with
+// big-endian integers (zero as the empty string) and stateFlags omitted when
+// zero. Unlike Ethereum, the storage root and code hash are not part of the
+// record: storage and code live under their own keys.
+//
+// Orchid (pre-unitrie) records did carry them, as
+// [nonce, balance, storageRoot, codeHash, stateFlags]; StorageRoot and
+// CodeHash are zero for unitrie records.
+type AccountState struct {
+	Nonce       *big.Int
+	Balance     *big.Int
+	StateFlags  uint64
+	StorageRoot common.Hash
+	CodeHash    common.Hash
+}
+
+// DecodeAccountState decodes an account record, such as the Value of an
+// account proof.
+func DecodeAccountState(data []byte) (*AccountState, error) {
+	var fields [][]byte
+	if err := rlp.DecodeBytes(data, &fields); err != nil {
+		return nil, fmt.Errorf("decode account state: %w", err)
+	}
+	if len(fields) < 2 || len(fields) > 5 {
+		return nil, fmt.Errorf("decode account state: expected 2 to 5 fields, got %d", len(fields))
+	}
+	state := &AccountState{
+		Nonce:   new(big.Int).SetBytes(fields[0]),
+		Balance: new(big.Int).SetBytes(fields[1]),
+	}
+	flags := fields[2:]
+	if len(fields) >= 4 {
+		if len(fields[2]) != common.HashLength || len(fields[3]) != common.HashLength {
+			return nil, fmt.Errorf("decode account state: malformed orchid storage root or code hash")
+		}
+		state.StorageRoot = common.BytesToHash(fields[2])
+		state.CodeHash = common.BytesToHash(fields[3])
+		flags = fields[4:]
+	}
+	if len(flags) == 1 {
+		state.StateFlags = new(big.Int).SetBytes(flags[0]).Uint64()
+	}
+	return state, nil
+}
+
+// AccountState decodes the account record proven by r, the result for an
+// account key. A proven-absent account has zero nonce and balance.
+func (r *KeyProofResult) AccountState() (*AccountState, error) {
+	switch r.Status {
+	case ProofPresent:
+		if r.HasLongValue() {
+			return nil, fmt.Errorf("account record is committed by hash only; supply it with ResolveValue")
+		}
+		return DecodeAccountState(r.Value)
+	case ProofProvenAbsent:
+		return &AccountState{Nonce: new(big.Int), Balance: new(big.Int)}, nil
+	}
+	return nil, fmt.Errorf("account state of an invalid proof")
+}
+
+// Encode returns the account record in the format DecodeAccountState reads:
+// the Orchid layout if CodeHash is set (an Orchid account without code has
+// the hash of empty code), the unitrie layout otherwise.
+func (a *AccountState) Encode() ([]byte, error) {
+	fields := [][]byte{bigBytes(a.Nonce), bigBytes(a.Balance)}
+	if a.CodeHash != (common.Hash{}) {
+		fields = append(fields, a.StorageRoot.Bytes(), a.CodeHash.Bytes())
+	}
+	if a.StateFlags != 0 {
+		fields = append(fields, new(big.Int).SetUint64(a.StateFlags).Bytes())
+	}
+	return rlp.EncodeToBytes(fields)
+}
+
+// bigBytes returns the trimmed big-endian bytes of n; nil and zero are empty.
+func bigBytes(n *big.Int) []byte {
+	if n == nil {
+		return nil
+	}
+	return n.Bytes()
+}
@@ -0,0 +1,64 @@
+package rsktrie
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestAccountStateRoundTrip(t *testing.T) {
+	for _, state := range []*AccountState{
+		{Nonce: big.NewInt(0), Balance: big.NewInt(0)},
+		{Nonce: big.NewInt(7), Balance: big.NewInt(1_000_000), StateFlags: 1},
+		{Nonce: big.NewInt(2), Balance: big.NewInt(3), StorageRoot: common.Hash{0x01}, CodeHash: common.Hash{0x02}},
+	} {
+		enc, err := state.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec, err := DecodeAccountState(enc)
+		if err != nil {
+			t.Fatalf("DecodeAccountState failed: %v", err)
+		}
+		if dec.Nonce.Cmp(state.Nonce) != 0 || dec.Balance.Cmp(state.Balance) != 0 || dec.StateFlags != state.StateFlags ||
+			dec.StorageRoot != state.StorageRoot || dec.CodeHash != state.CodeHash {
+			t.Errorf("Round trip mismatch: %+v != %+v", dec, state)
+		}
+	}
+	if _, err := DecodeAccountState([]byte{0x01}); err == nil {
+		t.Error("Expected error for malformed account state")
+	}
+}
+
+func TestVerifyAccountProofDecodesState(t *testing.T) {
+	m := NewTrieKeyMapper()
+	addr := common.HexToAddress("0x77045e71a7a2c50903d88e564cd72fab11e82051")
+	absent := common.HexToAddress("0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826")
+	broken := common.HexToAddress("0x0000000000000000000000000000000000001234")
+
+	account, err := (&AccountState{Nonce: big.NewInt(3), Balance: big.NewInt(5000)}).Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	trie := NewTrie(nil).
+		Put(m.GetAccountKey(addr), account).
+		Put(m.GetAccountKey(broken), []byte{0x01})
+	root := common.BytesToHash(trie.GetHash())
+	v := NewProofVerifier()
+
+	result, _ := v.VerifyAccountProof(root, addr, testProof(t, trie, m.GetAccountKey(addr)))
+	if !result.Valid || !result.Exists || result.Nonce.Int64() != 3 || result.Balance.Int64() != 5000 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	result, _ = v.VerifyAccountProof(root, absent, testProof(t, trie, m.GetAccountKey(absent)))
+	if !result.Valid || result.Exists || result.Status != ProofProvenAbsent || result.Nonce.Sign() != 0 || result.Balance.Sign() != 0 {
+		t.Errorf("Expected zero state for absent account, got %+v", result)
+	}
+
+	result, _ = v.VerifyAccountProof(root, broken, testProof(t, trie, m.GetAccountKey(broken)))
+	if result.Valid || result.Exists || result.Error == nil {
+		t.Errorf("Expected invalid result for undecodable record, got %+v", result)
+	}
+}
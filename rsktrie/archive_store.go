@@ -0,0 +1,157 @@
+package rsktrie
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ArchiveStore persists verified values indexed by state root and trie key,
+// so historical queries ("what was slot X at block N") can be answered later
+// without fetching or verifying proofs again. Absence is recorded too: a key
+// proven missing under a root is stored as such and distinguished from a key
+// that was never archived.
+//
+// The archive trusts its writers; only store values that were verified
+// against root.
+type ArchiveStore struct {
+	kv        KeyValueStore
+	keyMapper *TrieKeyMapper
+}
+
+var (
+	archiveValuePrefix = []byte("archive-value-")
+	archiveBlockPrefix = []byte("archive-block-")
+)
+
+const (
+	archiveAbsent  byte = 0
+	archivePresent byte = 1
+)
+
+func NewArchiveStore(kv KeyValueStore) *ArchiveStore {
+	return &ArchiveStore{kv: kv, keyMapper: NewTrieKeyMapper()}
+}
+
+// ArchiveEntry is a single verified (key, value) pair. A nil Value records
+// that the key does not exist under the root.
+type ArchiveEntry struct {
+	Key   []byte
+	Value []byte
+}
+
+// Put records the value of key under root. A nil value records absence.
+func (s *ArchiveStore) Put(root []byte, key []byte, value []byte) error {
+	return s.PutAll(root, []ArchiveEntry{{Key: key, Value: value}})
+}
+
+// PutAll records several entries under root in one batch.
+func (s *ArchiveStore) PutAll(root []byte, entries []ArchiveEntry) error {
+	batch := s.kv.NewBatch()
+	for _, e := range entries {
+		batch.Put(archiveValueKey(root, e.Key), encodeArchiveValue(e.Value))
+	}
+	if err := batch.Write(); err != nil {
+		return fmt.Errorf("archive entries under root %x: %w", root, err)
+	}
+	return nil
+}
+
+// Get returns the archived value of key under root. found is false if the key
+// was never archived for root; a found key with a nil value is a recorded
+// absence.
+func (s *ArchiveStore) Get(root []byte, key []byte) (value []byte, found bool, err error) {
+	rec, err := s.kv.Get(archiveValueKey(root, key))
+	if err != nil {
+		return nil, false, fmt.Errorf("read archive entry: %w", err)
+	}
+	if rec == nil {
+		return nil, false, nil
+	}
+	if len(rec) > 0 {
+		switch rec[0] {
+		case archiveAbsent:
+			return nil, true, nil
+		case archivePresent:
+			return rec[1:], true, nil
+		}
+	}
+	return nil, false, fmt.Errorf("corrupt archive entry for key %x under root %x", key, root)
+}
+
+// PutAccount records an account's encoded state under root.
+func (s *ArchiveStore) PutAccount(root []byte, addr common.Address, value []byte) error {
+	return s.Put(root, s.keyMapper.GetAccountKey(addr), value)
+}
+
+// GetAccount returns an account's archived encoded state under root.
+func (s *ArchiveStore) GetAccount(root []byte, addr common.Address) ([]byte, bool, error) {
+	return s.Get(root, s.keyMapper.GetAccountKey(addr))
+}
+
+// PutStorage records a storage slot's value under root.
+func (s *ArchiveStore) PutStorage(root []byte, addr common.Address, slot common.Hash, value []byte) error {
+	return s.Put(root, s.keyMapper.GetAccountStorageKey(addr, slot), value)
+}
+
+// GetStorage returns a storage slot's archived value under root.
+func (s *ArchiveStore) GetStorage(root []byte, addr common.Address, slot common.Hash) ([]byte, bool, error) {
+	return s.Get(root, s.keyMapper.GetAccountStorageKey(addr, slot))
+}
+
+// PutBlockRoot records the state root of the block at height.
+func (s *ArchiveStore) PutBlockRoot(height uint64, root []byte) error {
+	return s.kv.Put(archiveBlockKey(height), root)
+}
+
+// BlockRoot returns the state root recorded for height, or nil.
+func (s *ArchiveStore) BlockRoot(height uint64) ([]byte, error) {
+	root, err := s.kv.Get(archiveBlockKey(height))
+	if err != nil {
+		return nil, fmt.Errorf("read root of block %d: %w", height, err)
+	}
+	return root, nil
+}
+
+// GetStorageAt returns a storage slot's archived value at block height.
+func (s *ArchiveStore) GetStorageAt(height uint64, addr common.Address, slot common.Hash) ([]byte, bool, error) {
+	root, err := s.BlockRoot(height)
+	if err != nil || root == nil {
+		return nil, false, err
+	}
+	return s.GetStorage(root, addr, slot)
+}
+
+// GetAccountAt returns an account's archived encoded state at block height.
+func (s *ArchiveStore) GetAccountAt(height uint64, addr common.Address) ([]byte, bool, error) {
+	root, err := s.BlockRoot(height)
+	if err != nil || root == nil {
+		return nil, false, err
+	}
+	return s.GetAccount(root, addr)
+}
+
+func archiveValueKey(root []byte, key []byte) []byte {
+	k := make([]byte, 0, len(archiveValuePrefix)+len(root)+len(key))
+	k = append(k, archiveValuePrefix...)
+	k = append(k, root...)
+	return append(k, key...)
+}
+
+func archiveBlockKey(height uint64) []byte {
+	k := make([]byte, len(archiveBlockPrefix)+8)
+	copy(k, archiveBlockPrefix)
+	binary.BigEndian.PutUint64(k[len(archiveBlockPrefix):], height)
+	return k
+}
+
+func encodeArchiveValue(value []byte) []byte {
+	if value == nil {
+		return []byte{archiveAbsent}
+	}
+	rec := make([]byte, 1+len(value))
+	rec[0] = archivePresent
+	copy(rec[1:], value)
+	return rec
+}
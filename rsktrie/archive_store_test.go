@@ -0,0 +1,61 @@
+package rsktrie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestArchiveStoreHistoricalStorage(t *testing.T) {
+	archive := NewArchiveStore(NewMemKeyValueStore())
+	addr := common.HexToAddress("0x0000000000000000000000000000000001000006")
+	slot := common.HexToHash("0x01")
+	root1 := bytes.Repeat([]byte{1}, 32)
+	root2 := bytes.Repeat([]byte{2}, 32)
+
+	if err := archive.PutBlockRoot(100, root1); err != nil {
+		t.Fatalf("PutBlockRoot failed: %v", err)
+	}
+	if err := archive.PutBlockRoot(101, root2); err != nil {
+		t.Fatalf("PutBlockRoot failed: %v", err)
+	}
+	if err := archive.PutStorage(root1, addr, slot, []byte{0x2a}); err != nil {
+		t.Fatalf("PutStorage failed: %v", err)
+	}
+	if err := archive.PutStorage(root2, addr, slot, nil); err != nil {
+		t.Fatalf("PutStorage failed: %v", err)
+	}
+
+	val, found, err := archive.GetStorageAt(100, addr, slot)
+	if err != nil || !found || !bytes.Equal(val, []byte{0x2a}) {
+		t.Errorf("Block 100: expected 2a, got %x found=%v err=%v", val, found, err)
+	}
+	val, found, err = archive.GetStorageAt(101, addr, slot)
+	if err != nil || !found || val != nil {
+		t.Errorf("Block 101: expected recorded absence, got %x found=%v err=%v", val, found, err)
+	}
+	_, found, err = archive.GetStorageAt(102, addr, slot)
+	if err != nil || found {
+		t.Errorf("Block 102: expected not archived, got found=%v err=%v", found, err)
+	}
+	_, found, _ = archive.GetStorage(root1, addr, common.HexToHash("0x02"))
+	if found {
+		t.Error("Expected unarchived slot to be not found")
+	}
+}
+
+func TestArchiveStoreCorruptEntry(t *testing.T) {
+	kv := NewMemKeyValueStore()
+	archive := NewArchiveStore(kv)
+	root := bytes.Repeat([]byte{1}, 32)
+	key := []byte{0x01}
+	for _, rec := range [][]byte{{}, {0xff}} {
+		if err := kv.Put(archiveValueKey(root, key), rec); err != nil {
+			t.Fatal(err)
+		}
+		if _, found, err := archive.Get(root, key); err == nil || found {
+			t.Errorf("Record %x: expected a corrupt entry error, got found=%v err=%v", rec, found, err)
+		}
+	}
+}
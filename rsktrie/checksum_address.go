@@ -0,0 +1,105 @@
+package rsktrie
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/crypto/sha3"
+)
+
+// Chain IDs of the public RSK networks, as used by RSKIP-60 checksums.
+const (
+	MainnetChainID uint64 = 30
+	TestnetChainID uint64 = 31
+	RegtestChainID uint64 = 33
+)
+
+// ChainIDForNetwork returns the chain ID of a named RSK network ("mainnet",
+// "testnet" or "regtest").
+func ChainIDForNetwork(network string) (uint64, error) {
+	switch network {
+	case "mainnet":
+		return MainnetChainID, nil
+	case "testnet":
+		return TestnetChainID, nil
+	case "regtest":
+		return RegtestChainID, nil
+	default:
+		return 0, fmt.Errorf("unknown network %q", network)
+	}
+}
+
+// ChecksumAddress returns addr in RSKIP-60 mixed-case checksum encoding for
+// chainID. The checksum hashes chainID, "0x" and the lowercase hex address,
+// so the same address renders differently on mainnet and testnet. A chainID
+// of 0 yields the plain EIP-55 encoding.
+func ChecksumAddress(addr common.Address, chainID uint64) string {
+	lower := hex.EncodeToString(addr.Bytes())
+
+	h := sha3.NewLegacyKeccak256()
+	if chainID != 0 {
+		h.Write([]byte(strconv.FormatUint(chainID, 10) + "0x"))
+	}
+	h.Write([]byte(lower))
+	digest := h.Sum(nil)
+
+	out := []byte(lower)
+	for i, c := range out {
+		nibble := digest[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if c >= 'a' && nibble&0x0f >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
+
+// ParseAddress parses a 0x-prefixed hex address. All-lowercase and
+// all-uppercase input carries no checksum and is accepted as is; mixed-case
+// input must match the RSKIP-60 checksum for chainID.
+func ParseAddress(s string, chainID uint64) (common.Address, error) {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return common.Address{}, fmt.Errorf("address %q: missing 0x prefix", s)
+	}
+	body := s[2:]
+	if len(body) != 2*common.AddressLength {
+		return common.Address{}, fmt.Errorf("address %q: expected %d hex digits, got %d", s, 2*common.AddressLength, len(body))
+	}
+	raw, err := hex.DecodeString(body)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("address %q: %w", s, err)
+	}
+	addr := common.BytesToAddress(raw)
+
+	if body == strings.ToLower(body) || body == strings.ToUpper(body) {
+		return addr, nil
+	}
+	if want := ChecksumAddress(addr, chainID); want[2:] != body {
+		return common.Address{}, fmt.Errorf("address %q: invalid checksum for chain %d, expected %s", s, chainID, want)
+	}
+	return addr, nil
+}
+
+// IsValidChecksumAddress reports whether s is a mixed-case address whose
+// checksum is valid for chainID. Unlike ParseAddress, it rejects input that
+// carries no checksum.
+func IsValidChecksumAddress(s string, chainID uint64) bool {
+	addr, err := ParseAddress(s, chainID)
+	return err == nil && ChecksumAddress(addr, chainID) == s
+}
+
+// GetAccountKeyFromHex parses s as with ParseAddress and returns its account
+// key, so that a checksum meant for another network is rejected rather than
+// silently mapped to a key.
+func (m *TrieKeyMapper) GetAccountKeyFromHex(s string, chainID uint64) ([]byte, error) {
+	addr, err := ParseAddress(s, chainID)
+	if err != nil {
+		return nil, err
+	}
+	return m.GetAccountKey(addr), nil
+}
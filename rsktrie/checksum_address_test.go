@@ -0,0 +1,80 @@
+package rsktrie
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestChecksumAddress(t *testing.T) {
+	// RSKIP-60 test vectors.
+	mainnet := []string{
+		"0x5aaEB6053f3e94c9b9a09f33669435E7ef1bEAeD",
+		"0xFb6916095cA1Df60bb79ce92cE3EA74c37c5d359",
+		"0xD1220A0Cf47c7B9BE7a2e6ba89F429762E7B9adB",
+	}
+	testnet := []string{
+		"0x5aAeb6053F3e94c9b9A09F33669435E7EF1BEaEd",
+		"0xFb6916095CA1dF60bb79CE92ce3Ea74C37c5D359",
+		"0xdbF03B407C01E7cd3cbEa99509D93f8dDDc8C6fB",
+		"0xd1220a0CF47c7B9Be7A2E6Ba89f429762E7b9adB",
+	}
+	for _, s := range mainnet {
+		addr := common.HexToAddress(s)
+		if got := ChecksumAddress(addr, MainnetChainID); got != s {
+			t.Errorf("Expected mainnet %s, got %s", s, got)
+		}
+	}
+	for _, s := range testnet {
+		addr := common.HexToAddress(s)
+		if got := ChecksumAddress(addr, TestnetChainID); got != s {
+			t.Errorf("Expected testnet %s, got %s", s, got)
+		}
+	}
+
+	// Without a chain ID the encoding is EIP-55.
+	addr := common.HexToAddress(mainnet[0])
+	if got := ChecksumAddress(addr, 0); got != addr.Hex() {
+		t.Errorf("Expected EIP-55 %s, got %s", addr.Hex(), got)
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	s := "0x5aaEB6053f3e94c9b9a09f33669435E7ef1bEAeD"
+	want := common.HexToAddress(s)
+
+	for _, in := range []string{s, strings.ToLower(s), "0x" + strings.ToUpper(s[2:])} {
+		got, err := ParseAddress(in, MainnetChainID)
+		if err != nil || got != want {
+			t.Errorf("ParseAddress(%s): expected %s, got %s, %v", in, want.Hex(), got.Hex(), err)
+		}
+	}
+
+	if _, err := ParseAddress(s, TestnetChainID); err == nil {
+		t.Error("Expected mainnet checksum to be rejected on testnet")
+	}
+	if _, err := ParseAddress(s[:len(s)-1], MainnetChainID); err == nil {
+		t.Error("Expected error for short address")
+	}
+	if _, err := ParseAddress(s[2:], MainnetChainID); err == nil {
+		t.Error("Expected error for missing 0x prefix")
+	}
+
+	if !IsValidChecksumAddress(s, MainnetChainID) {
+		t.Error("Expected mainnet checksum to be valid")
+	}
+	if IsValidChecksumAddress(strings.ToLower(s), MainnetChainID) {
+		t.Error("Expected lowercase address to carry no checksum")
+	}
+
+	m := NewTrieKeyMapper()
+	key, err := m.GetAccountKeyFromHex(s, MainnetChainID)
+	if err != nil || !bytes.Equal(key, m.GetAccountKey(want)) {
+		t.Errorf("Unexpected account key %x, %v", key, err)
+	}
+	if _, err := m.GetAccountKeyFromHex(s, TestnetChainID); err == nil {
+		t.Error("Expected key mapper to reject a mainnet checksum on testnet")
+	}
+}
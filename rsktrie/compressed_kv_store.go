@@ -0,0 +1,164 @@
+package rsktrie
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression selects the codec used by CompressedKeyValueStore.
+type Compression byte
+
+const (
+	CompressionNone   Compression = 0
+	CompressionSnappy Compression = 1
+	CompressionZstd   Compression = 2
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionSnappy:
+		return "snappy"
+	case CompressionZstd:
+		return "zstd"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(c))
+	}
+}
+
+// CompressedKeyValueStore wraps a KeyValueStore and compresses every value it
+// writes. Each record is framed with a one-byte codec tag, so records written
+// with different codecs (or left uncompressed because compression did not
+// help) can be read back transparently.
+//
+// The framing changes the on-disk format: wrap a backend from the start, not
+// one that already holds unframed records.
+type CompressedKeyValueStore struct {
+	kv    KeyValueStore
+	codec Compression
+
+	zstdEnc *zstd.Encoder
+	zstdDec *zstd.Decoder
+}
+
+// NewCompressedKeyValueStore returns a wrapper around kv that compresses new
+// records with codec.
+func NewCompressedKeyValueStore(kv KeyValueStore, codec Compression) (*CompressedKeyValueStore, error) {
+	if codec > CompressionZstd {
+		return nil, fmt.Errorf("unsupported compression %s", codec)
+	}
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, fmt.Errorf("create zstd encoder: %w", err)
+	}
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		enc.Close()
+		return nil, fmt.Errorf("create zstd decoder: %w", err)
+	}
+	return &CompressedKeyValueStore{kv: kv, codec: codec, zstdEnc: enc, zstdDec: dec}, nil
+}
+
+func (s *CompressedKeyValueStore) Get(key []byte) ([]byte, error) {
+	record, err := s.kv.Get(key)
+	if err != nil || record == nil {
+		return nil, err
+	}
+	return s.decode(record)
+}
+
+func (s *CompressedKeyValueStore) Put(key []byte, value []byte) error {
+	return s.kv.Put(key, s.encode(value))
+}
+
+func (s *CompressedKeyValueStore) Delete(key []byte) error {
+	return s.kv.Delete(key)
+}
+
+func (s *CompressedKeyValueStore) NewBatch() KeyValueBatch {
+	return &compressedBatch{store: s, batch: s.kv.NewBatch()}
+}
+
+func (s *CompressedKeyValueStore) Close() error {
+	s.zstdEnc.Close()
+	s.zstdDec.Close()
+	return s.kv.Close()
+}
+
+// encode compresses value and prepends the codec tag. Values that do not
+// shrink are stored raw.
+func (s *CompressedKeyValueStore) encode(value []byte) []byte {
+	var compressed []byte
+	switch s.codec {
+	case CompressionSnappy:
+		compressed = snappy.Encode(nil, value)
+	case CompressionZstd:
+		compressed = s.zstdEnc.EncodeAll(value, nil)
+	}
+
+	if compressed != nil && len(compressed) < len(value) {
+		record := make([]byte, 1+len(compressed))
+		record[0] = byte(s.codec)
+		copy(record[1:], compressed)
+		return record
+	}
+
+	record := make([]byte, 1+len(value))
+	record[0] = byte(CompressionNone)
+	copy(record[1:], value)
+	return record
+}
+
+func (s *CompressedKeyValueStore) decode(record []byte) ([]byte, error) {
+	if len(record) == 0 {
+		return nil, errors.New("empty compressed record")
+	}
+	payload := record[1:]
+	switch Compression(record[0]) {
+	case CompressionNone:
+		return payload, nil
+	case CompressionSnappy:
+		value, err := snappy.Decode(nil, payload)
+		if err != nil {
+			return nil, fmt.Errorf("snappy decode: %w", err)
+		}
+		return value, nil
+	case CompressionZstd:
+		value, err := s.zstdDec.DecodeAll(payload, nil)
+		if err != nil {
+			return nil, fmt.Errorf("zstd decode: %w", err)
+		}
+		return value, nil
+	default:
+		return nil, fmt.Errorf("unknown compression tag %d", record[0])
+	}
+}
+
+type compressedBatch struct {
+	store *CompressedKeyValueStore
+	batch KeyValueBatch
+}
+
+func (b *compressedBatch) Put(key []byte, value []byte) {
+	b.batch.Put(key, b.store.encode(value))
+}
+
+func (b *compressedBatch) Delete(key []byte) {
+	b.batch.Delete(key)
+}
+
+func (b *compressedBatch) Len() int {
+	return b.batch.Len()
+}
+
+func (b *compressedBatch) Write() error {
+	return b.batch.Write()
+}
+
+func (b *compressedBatch) Reset() {
+	b.batch.Reset()
+}
@@ -0,0 +1,224 @@
+package rsktrie
+
+import (
+	"context"
+	"fmt"
+)
+
+// ContextTrieStore is a TrieStore whose operations honor cancellation and
+// deadlines. Remote and disk-backed stores should implement it so a slow
+// backend cannot stall a lookup indefinitely.
+//
+// Unlike the TrieStore methods, which log and return nil, the context
+// variants surface backend errors. A missing entry is still (nil, nil).
+type ContextTrieStore interface {
+	TrieStore
+	SaveContext(ctx context.Context, t *Trie) error
+	RetrieveContext(ctx context.Context, hash []byte) (*Trie, error)
+	RetrieveValueContext(ctx context.Context, hash []byte) ([]byte, error)
+}
+
+var (
+	_ ContextTrieStore = (*MemTrieStore)(nil)
+	_ ContextTrieStore = (*KVTrieStore)(nil)
+)
+
+// ContextKeyValueStore is implemented by KeyValueStores that can abort a read
+// when ctx is done.
+type ContextKeyValueStore interface {
+	KeyValueStore
+	GetContext(ctx context.Context, key []byte) ([]byte, error)
+}
+
+// RetrieveContext loads a node from store, using its context-aware variant
+// when available. For plain stores ctx is only checked before the call.
+func RetrieveContext(ctx context.Context, store TrieStore, hash []byte) (*Trie, error) {
+	if cs, ok := store.(ContextTrieStore); ok {
+		return cs.RetrieveContext(ctx, hash)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return store.Retrieve(hash), nil
+}
+
+// RetrieveValueContext loads a long value from store, using its
+// context-aware variant when available.
+func RetrieveValueContext(ctx context.Context, store TrieStore, hash []byte) ([]byte, error) {
+	if cs, ok := store.(ContextTrieStore); ok {
+		return cs.RetrieveValueContext(ctx, hash)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return store.RetrieveValue(hash), nil
+}
+
+// getContext reads key from kv, returning early with ctx.Err() if ctx is done
+// first. Backends without GetContext are read on a separate goroutine, which
+// finishes in the background after an abandoned read.
+func getContext(ctx context.Context, kv KeyValueStore, key []byte) ([]byte, error) {
+	if ckv, ok := kv.(ContextKeyValueStore); ok {
+		return ckv.GetContext(ctx, key)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if ctx.Done() == nil {
+		// Never cancelled; skip the goroutine.
+		return kv.Get(key)
+	}
+
+	type result struct {
+		val []byte
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		val, err := kv.Get(key)
+		ch <- result{val, err}
+	}()
+	select {
+	case r := <-ch:
+		return r.val, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *MemTrieStore) SaveContext(ctx context.Context, t *Trie) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.Save(t)
+	return nil
+}
+
+func (s *MemTrieStore) RetrieveContext(ctx context.Context, hash []byte) (*Trie, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Retrieve(hash), nil
+}
+
+func (s *MemTrieStore) RetrieveValueContext(ctx context.Context, hash []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.RetrieveValue(hash), nil
+}
+
+func (s *KVTrieStore) SaveContext(ctx context.Context, t *Trie) error {
+	if t == nil {
+		return nil
+	}
+	batch := s.NewBatch()
+	batch.Put(t)
+	// Serializing may be slow for a fresh subtree; check again before writing.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+func (s *KVTrieStore) RetrieveContext(ctx context.Context, hash []byte) (*Trie, error) {
+	if hash == nil {
+		return nil, nil
+	}
+	msg, err := getContext(ctx, s.kv, hash)
+	if err != nil {
+		return nil, fmt.Errorf("read node %x: %w", hash, err)
+	}
+	if msg == nil {
+		return nil, nil
+	}
+	t, err := FromMessage(msg, s)
+	if err != nil {
+		return nil, fmt.Errorf("parse stored node %x: %w", hash, err)
+	}
+	t.saved = true
+	return t, nil
+}
+
+func (s *KVTrieStore) RetrieveValueContext(ctx context.Context, hash []byte) ([]byte, error) {
+	if hash == nil {
+		return nil, nil
+	}
+	val, err := getContext(ctx, s.kv, hash)
+	if err != nil {
+		return nil, fmt.Errorf("read value %x: %w", hash, err)
+	}
+	return val, nil
+}
+
+// GetContext is Get with cancellation: every node and long value loaded from
+// the store along the way is read with ctx, and a node referenced but missing
+// from the store is reported as an error rather than as an absent key.
+func (t *Trie) GetContext(ctx context.Context, key []byte) ([]byte, error) {
+	node, err := t.FindContext(ctx, TrieKeySliceFromKey(key))
+	if err != nil || node == nil {
+		return nil, err
+	}
+	return node.GetValueContext(ctx)
+}
+
+// FindContext is Find with cancellation; see GetContext.
+func (t *Trie) FindContext(ctx context.Context, key *TrieKeySlice) (*Trie, error) {
+	node := t
+	for {
+		if node.sharedPath.Length() > key.Length() {
+			return nil, nil
+		}
+		common := key.CommonPath(node.sharedPath)
+		if common.Length() < node.sharedPath.Length() {
+			return nil, nil
+		}
+		if common.Length() == key.Length() {
+			return node, nil
+		}
+
+		ref := node.right
+		if key.Get(common.Length()) == 0 {
+			ref = node.left
+		}
+		next, err := ref.getNodeContext(ctx)
+		if err != nil || next == nil {
+			return nil, err
+		}
+		node = next
+		key = key.Slice(common.Length()+1, key.Length())
+	}
+}
+
+// GetValueContext is GetValue with cancellation for long values.
+func (t *Trie) GetValueContext(ctx context.Context) ([]byte, error) {
+	if t.value == nil && t.hasStoredValue() {
+		val, err := RetrieveValueContext(ctx, t.store, t.valueHash)
+		if err != nil {
+			return nil, err
+		}
+		return t.checkLongValue(val)
+	}
+	return t.GetValue(), nil
+}
+
+func (n *NodeReference) getNodeContext(ctx context.Context) (*Trie, error) {
+	if n.lazyNode != nil {
+		return n.lazyNode, nil
+	}
+	if n.lazyHash == nil {
+		return nil, nil
+	}
+	if n.store == nil {
+		return nil, fmt.Errorf("missing node %x: no store", n.lazyHash)
+	}
+	node, err := RetrieveContext(ctx, n.store, n.lazyHash)
+	if err != nil {
+		return nil, err
+	}
+	if node == nil {
+		return nil, fmt.Errorf("missing node %x", n.lazyHash)
+	}
+	n.lazyNode = node
+	return node, nil
+}
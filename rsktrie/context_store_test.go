@@ -0,0 +1,80 @@
+package rsktrie
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingKeyValueStore delays every Get until release is closed.
+type blockingKeyValueStore struct {
+	*MemKeyValueStore
+	release chan struct{}
+}
+
+func (s *blockingKeyValueStore) Get(key []byte) ([]byte, error) {
+	<-s.release
+	return s.MemKeyValueStore.Get(key)
+}
+
+func TestTrieGetContextReadsThroughStore(t *testing.T) {
+	store := NewKVTrieStore(NewMemKeyValueStore())
+	trie := buildStoreTestTrie(store)
+	if err := trie.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := store.RetrieveContext(context.Background(), trie.GetHash())
+	if err != nil || loaded == nil {
+		t.Fatalf("RetrieveContext failed: %v", err)
+	}
+	val, err := loaded.GetContext(context.Background(), []byte("key-21"))
+	if err != nil {
+		t.Fatalf("GetContext failed: %v", err)
+	}
+	if !bytes.Equal(val, bytes.Repeat([]byte{21}, 10+21*3)) {
+		t.Errorf("Unexpected value %x", val)
+	}
+	val, err = loaded.GetContext(context.Background(), []byte("absent"))
+	if err != nil || val != nil {
+		t.Errorf("Expected (nil, nil) for absent key, got (%x, %v)", val, err)
+	}
+}
+
+func TestKVTrieStoreRetrieveContextHonorsDeadline(t *testing.T) {
+	mem := NewMemKeyValueStore()
+	trie := buildStoreTestTrie(NewKVTrieStore(mem))
+	if err := trie.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	slow := &blockingKeyValueStore{MemKeyValueStore: mem, release: make(chan struct{})}
+	defer close(slow.release)
+	store := NewKVTrieStore(slow)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := store.RetrieveContext(ctx, trie.GetHash())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestTrieGetContextReportsMissingNode(t *testing.T) {
+	store := NewKVTrieStore(NewMemKeyValueStore())
+	trie := buildStoreTestTrie(store)
+	if err := trie.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Reload the root into an empty store so its children cannot be found.
+	detached, err := FromMessage(trie.ToMessage(), NewKVTrieStore(NewMemKeyValueStore()))
+	if err != nil {
+		t.Fatalf("FromMessage failed: %v", err)
+	}
+	if _, err := detached.GetContext(context.Background(), []byte("key-5")); err == nil {
+		t.Error("Expected error for missing child node")
+	}
+}
@@ -0,0 +1,135 @@
+package rsktrie
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+const encryptedRecordVersion byte = 1
+
+// EncryptedKeyValueStore wraps a KeyValueStore and encrypts every value with
+// AES-GCM under a caller-supplied key. The record key is bound as additional
+// data, so a record copied under another key fails to decrypt.
+//
+// Only values are encrypted. Keys are stored as-is: node and value hashes
+// reveal nothing, but archive and preimage keys embed trie keys and, with
+// them, account addresses.
+//
+// Ciphertext does not compress; to combine with compression, wrap the
+// EncryptedKeyValueStore in a CompressedKeyValueStore, not the other way
+// round.
+type EncryptedKeyValueStore struct {
+	kv   KeyValueStore
+	aead cipher.AEAD
+}
+
+// NewEncryptedKeyValueStore returns a wrapper around kv that encrypts with key,
+// which must be 16, 24 or 32 bytes long (AES-128, AES-192 or AES-256).
+func NewEncryptedKeyValueStore(kv KeyValueStore, key []byte) (*EncryptedKeyValueStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm: %w", err)
+	}
+	return &EncryptedKeyValueStore{kv: kv, aead: aead}, nil
+}
+
+func (s *EncryptedKeyValueStore) Get(key []byte) ([]byte, error) {
+	record, err := s.kv.Get(key)
+	if err != nil || record == nil {
+		return nil, err
+	}
+	return s.open(key, record)
+}
+
+func (s *EncryptedKeyValueStore) Put(key []byte, value []byte) error {
+	record, err := s.seal(key, value)
+	if err != nil {
+		return err
+	}
+	return s.kv.Put(key, record)
+}
+
+func (s *EncryptedKeyValueStore) Delete(key []byte) error {
+	return s.kv.Delete(key)
+}
+
+func (s *EncryptedKeyValueStore) NewBatch() KeyValueBatch {
+	return &encryptedBatch{store: s, batch: s.kv.NewBatch()}
+}
+
+func (s *EncryptedKeyValueStore) Close() error {
+	return s.kv.Close()
+}
+
+// seal encrypts value into [version][nonce][ciphertext+tag].
+func (s *EncryptedKeyValueStore) seal(key []byte, value []byte) ([]byte, error) {
+	nonceSize := s.aead.NonceSize()
+	record := make([]byte, 1+nonceSize, 1+nonceSize+len(value)+s.aead.Overhead())
+	record[0] = encryptedRecordVersion
+	if _, err := rand.Read(record[1:]); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	return s.aead.Seal(record, record[1:], value, key), nil
+}
+
+func (s *EncryptedKeyValueStore) open(key []byte, record []byte) ([]byte, error) {
+	nonceSize := s.aead.NonceSize()
+	if len(record) < 1+nonceSize+s.aead.Overhead() {
+		return nil, errors.New("encrypted record too short")
+	}
+	if record[0] != encryptedRecordVersion {
+		return nil, fmt.Errorf("unknown encrypted record version %d", record[0])
+	}
+	value, err := s.aead.Open(nil, record[1:1+nonceSize], record[1+nonceSize:], key)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt record %x: %w", key, err)
+	}
+	return value, nil
+}
+
+type encryptedBatch struct {
+	store *EncryptedKeyValueStore
+	batch KeyValueBatch
+	err   error
+}
+
+func (b *encryptedBatch) Put(key []byte, value []byte) {
+	record, err := b.store.seal(key, value)
+	if err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return
+	}
+	b.batch.Put(key, record)
+}
+
+func (b *encryptedBatch) Delete(key []byte) {
+	b.batch.Delete(key)
+}
+
+func (b *encryptedBatch) Len() int {
+	return b.batch.Len()
+}
+
+// Write fails without writing anything if any Put could not be encrypted.
+func (b *encryptedBatch) Write() error {
+	if b.err != nil {
+		err := b.err
+		b.Reset()
+		return err
+	}
+	return b.batch.Write()
+}
+
+func (b *encryptedBatch) Reset() {
+	b.batch.Reset()
+	b.err = nil
+}
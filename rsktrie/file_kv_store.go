@@ -0,0 +1,266 @@
+package rsktrie
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+)
+
+const (
+	fileFrameHeaderSize = 8 // payload length (4) + CRC32 (4)
+
+	fileOpPut    byte = 0
+	fileOpDelete byte = 1
+)
+
+// FileKeyValueStore is an append-only, single-file KeyValueStore.
+//
+// Every write, including single Put and Delete calls, is appended as one
+// checksummed frame and fsynced. On open the frames are replayed to rebuild an
+// in-memory index of value offsets; a torn or corrupt frame at the tail (a
+// crash mid-write) is truncated away, so a batch is either fully applied or
+// not applied at all. A corrupt frame before the tail fails the open.
+//
+// The file is never compacted: deleted and overwritten values keep their
+// space. This fits trie stores, where keys are content hashes and values are
+// immutable.
+type FileKeyValueStore struct {
+	mu    sync.RWMutex
+	f     *os.File
+	size  int64
+	index map[string]fileValueLoc
+}
+
+type fileValueLoc struct {
+	offset int64
+	length int
+}
+
+// OpenFileKeyValueStore opens (or creates) the store at path.
+func OpenFileKeyValueStore(path string) (*FileKeyValueStore, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open store file: %w", err)
+	}
+	s := &FileKeyValueStore{f: f, index: make(map[string]fileValueLoc)}
+	if err := s.replay(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// replay rebuilds the index from the frames on disk and truncates a torn
+// final frame: one that runs past the end of the file, or ends exactly at
+// it with a bad checksum. A bad frame with data after it is corruption, not
+// a crash mid-write, and fails the open rather than losing the frames that
+// follow.
+func (s *FileKeyValueStore) replay() error {
+	info, err := s.f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat store file: %w", err)
+	}
+	fileSize := info.Size()
+	r := bufio.NewReader(s.f)
+	var offset int64
+	header := make([]byte, fileFrameHeaderSize)
+	for offset < fileSize {
+		if fileSize-offset < fileFrameHeaderSize {
+			break
+		}
+		if _, err := io.ReadFull(r, header); err != nil {
+			return fmt.Errorf("read frame header at %d: %w", offset, err)
+		}
+		payloadLen := int64(binary.BigEndian.Uint32(header[0:4]))
+		checksum := binary.BigEndian.Uint32(header[4:8])
+		frameEnd := offset + fileFrameHeaderSize + payloadLen
+		if frameEnd > fileSize {
+			break
+		}
+		payload := make([]byte, payloadLen)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return fmt.Errorf("read frame payload at %d: %w", offset, err)
+		}
+		if crc32.ChecksumIEEE(payload) != checksum {
+			if frameEnd == fileSize {
+				break
+			}
+			return fmt.Errorf("corrupt store file: bad checksum in frame at %d", offset)
+		}
+		locs, err := decodeFileFrame(payload, offset+fileFrameHeaderSize)
+		if err != nil {
+			return fmt.Errorf("corrupt store file: frame at %d: %w", offset, err)
+		}
+		s.applyLocs(locs)
+		offset = frameEnd
+	}
+
+	if offset < fileSize {
+		if err := s.f.Truncate(offset); err != nil {
+			return fmt.Errorf("truncate torn tail: %w", err)
+		}
+	}
+	if _, err := s.f.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("seek to end: %w", err)
+	}
+	s.size = offset
+	return nil
+}
+
+// frameLoc is a decoded operation with the absolute file offset of its value.
+type frameLoc struct {
+	key    string
+	loc    fileValueLoc
+	delete bool
+}
+
+func encodeFileFrame(ops []kvOp) (frame []byte, locs []frameLoc) {
+	payload := make([]byte, 0, 64*len(ops))
+	locs = make([]frameLoc, len(ops))
+	var tmp [binary.MaxVarintLen64]byte
+	for i, op := range ops {
+		if op.delete {
+			payload = append(payload, fileOpDelete)
+		} else {
+			payload = append(payload, fileOpPut)
+		}
+		n := binary.PutUvarint(tmp[:], uint64(len(op.key)))
+		payload = append(payload, tmp[:n]...)
+		payload = append(payload, op.key...)
+		locs[i] = frameLoc{key: string(op.key), delete: op.delete}
+		if !op.delete {
+			n = binary.PutUvarint(tmp[:], uint64(len(op.value)))
+			payload = append(payload, tmp[:n]...)
+			// Offset is relative to the payload until the frame is placed.
+			locs[i].loc = fileValueLoc{offset: int64(len(payload)), length: len(op.value)}
+			payload = append(payload, op.value...)
+		}
+	}
+
+	frame = make([]byte, fileFrameHeaderSize, fileFrameHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(payload))
+	frame = append(frame, payload...)
+	return frame, locs
+}
+
+func decodeFileFrame(payload []byte, base int64) ([]frameLoc, error) {
+	var locs []frameLoc
+	pos := 0
+	for pos < len(payload) {
+		op := payload[pos]
+		pos++
+		keyLen, n := binary.Uvarint(payload[pos:])
+		if n <= 0 || pos+n+int(keyLen) > len(payload) {
+			return nil, errors.New("corrupt key length")
+		}
+		pos += n
+		key := string(payload[pos : pos+int(keyLen)])
+		pos += int(keyLen)
+
+		switch op {
+		case fileOpDelete:
+			locs = append(locs, frameLoc{key: key, delete: true})
+		case fileOpPut:
+			valLen, n := binary.Uvarint(payload[pos:])
+			if n <= 0 || pos+n+int(valLen) > len(payload) {
+				return nil, errors.New("corrupt value length")
+			}
+			pos += n
+			locs = append(locs, frameLoc{key: key, loc: fileValueLoc{offset: base + int64(pos), length: int(valLen)}})
+			pos += int(valLen)
+		default:
+			return nil, fmt.Errorf("unknown operation %d", op)
+		}
+	}
+	return locs, nil
+}
+
+func (s *FileKeyValueStore) applyLocs(locs []frameLoc) {
+	for _, l := range locs {
+		if l.delete {
+			delete(s.index, l.key)
+		} else {
+			s.index[l.key] = l.loc
+		}
+	}
+}
+
+func (s *FileKeyValueStore) Get(key []byte) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.f == nil {
+		return nil, errors.New("store is closed")
+	}
+	loc, ok := s.index[string(key)]
+	if !ok {
+		return nil, nil
+	}
+	val := make([]byte, loc.length)
+	if _, err := s.f.ReadAt(val, loc.offset); err != nil {
+		return nil, fmt.Errorf("read value: %w", err)
+	}
+	return val, nil
+}
+
+func (s *FileKeyValueStore) Put(key []byte, value []byte) error {
+	return s.commit([]kvOp{{key: key, value: value}})
+}
+
+func (s *FileKeyValueStore) Delete(key []byte) error {
+	return s.commit([]kvOp{{key: key, delete: true}})
+}
+
+func (s *FileKeyValueStore) NewBatch() KeyValueBatch {
+	return &kvBatch{commit: s.commit}
+}
+
+func (s *FileKeyValueStore) commit(ops []kvOp) error {
+	frame, locs := encodeFileFrame(ops)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return errors.New("store is closed")
+	}
+
+	if _, err := s.f.WriteAt(frame, s.size); err != nil {
+		s.f.Truncate(s.size)
+		return fmt.Errorf("append frame: %w", err)
+	}
+	if err := s.f.Sync(); err != nil {
+		s.f.Truncate(s.size)
+		return fmt.Errorf("sync frame: %w", err)
+	}
+
+	base := s.size + fileFrameHeaderSize
+	for i := range locs {
+		locs[i].loc.offset += base
+	}
+	s.applyLocs(locs)
+	s.size += int64(len(frame))
+	return nil
+}
+
+// Len returns the number of live keys.
+func (s *FileKeyValueStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.index)
+}
+
+func (s *FileKeyValueStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
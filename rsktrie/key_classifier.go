@@ -0,0 +1,83 @@
+package rsktrie
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// KeyKind identifies what a unitrie key addresses.
+type KeyKind int
+
+const (
+	KeyKindUnknown KeyKind = iota
+	KeyKindAccount
+	// KeyKindStorageRoot is the account's storage prefix node
+	// (AccountKey + StoragePrefix), which rskj marks with a one-byte value.
+	KeyKindStorageRoot
+	KeyKindStorage
+	KeyKindCode
+)
+
+func (k KeyKind) String() string {
+	switch k {
+	case KeyKindAccount:
+		return "account"
+	case KeyKindStorageRoot:
+		return "storage-root"
+	case KeyKindStorage:
+		return "storage"
+	case KeyKindCode:
+		return "code"
+	default:
+		return "unknown"
+	}
+}
+
+// KeyInfo is the interpretation of a raw trie key.
+type KeyInfo struct {
+	Kind    KeyKind
+	Address common.Address
+	// Slot is the storage slot of a storage key, valid if SlotKnown.
+	Slot      common.Hash
+	SlotKnown bool
+}
+
+// ClassifyKey interprets a complete trie key as an account, code, storage
+// root or storage key and extracts the embedded address. For storage keys the
+// slot is rebuilt from the key, falling back to the preimage store; if that
+// fails the key is still classified, with SlotKnown unset.
+//
+// Keys that do not follow the unitrie layout, or whose embedded address does
+// not match its secure prefix, are reported as KeyKindUnknown with an error.
+func (m *TrieKeyMapper) ClassifyKey(key []byte) (KeyInfo, error) {
+	accountKeyLen := len(DomainPrefix) + SecureAccountKey
+	if len(key) < accountKeyLen {
+		return KeyInfo{}, fmt.Errorf("key %x shorter than an account key", key)
+	}
+	addr, err := m.AddressFromKey(key[:accountKeyLen])
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	info := KeyInfo{Address: addr}
+	rest := key[accountKeyLen:]
+
+	switch {
+	case len(rest) == 0:
+		info.Kind = KeyKindAccount
+	case bytes.Equal(rest, CodePrefix):
+		info.Kind = KeyKindCode
+	case bytes.Equal(rest, StoragePrefix):
+		info.Kind = KeyKindStorageRoot
+	case bytes.HasPrefix(rest, StoragePrefix) && len(rest) >= len(StoragePrefix)+SecureKeySize:
+		info.Kind = KeyKindStorage
+		if _, slot, err := m.StorageSlotFromKey(key); err == nil {
+			info.Slot = slot
+			info.SlotKnown = true
+		}
+	default:
+		return KeyInfo{}, fmt.Errorf("key %x: unrecognized suffix %x after account key", key, rest)
+	}
+	return info, nil
+}
@@ -0,0 +1,73 @@
+package rsktrie
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestClassifyKey(t *testing.T) {
+	mapper := NewTrieKeyMapper()
+	addr := common.HexToAddress("0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826")
+	slot := common.HexToHash("0x05")
+
+	cases := []struct {
+		key  []byte
+		kind KeyKind
+	}{
+		{mapper.GetAccountKey(addr), KeyKindAccount},
+		{mapper.GetCodeKey(addr), KeyKindCode},
+		{mapper.GetAccountStoragePrefixKey(addr), KeyKindStorageRoot},
+		{mapper.GetAccountStorageKey(addr, slot), KeyKindStorage},
+		{mapper.GetAccountStorageKey(addr, common.Hash{}), KeyKindStorage},
+	}
+	for _, c := range cases {
+		info, err := mapper.ClassifyKey(c.key)
+		if err != nil {
+			t.Fatalf("ClassifyKey(%x) failed: %v", c.key, err)
+		}
+		if info.Kind != c.kind {
+			t.Errorf("Expected %s for %x, got %s", c.kind, c.key, info.Kind)
+		}
+		if info.Address != addr {
+			t.Errorf("Expected address %x, got %x", addr, info.Address)
+		}
+	}
+
+	info, _ := mapper.ClassifyKey(mapper.GetAccountStorageKey(addr, slot))
+	if !info.SlotKnown || info.Slot != slot {
+		t.Errorf("Expected slot %x, got %x (known=%v)", slot, info.Slot, info.SlotKnown)
+	}
+	info, _ = mapper.ClassifyKey(mapper.GetAccountStorageKey(addr, common.Hash{}))
+	if !info.SlotKnown || info.Slot != (common.Hash{}) {
+		t.Errorf("Expected zero slot, got %x (known=%v)", info.Slot, info.SlotKnown)
+	}
+
+	// Corrupted address bytes no longer match the secure prefix.
+	bad := mapper.GetAccountKey(addr)
+	bad[len(bad)-1] ^= 0xff
+	if info, err := mapper.ClassifyKey(bad); err == nil || info.Kind != KeyKindUnknown {
+		t.Errorf("Expected unknown key with error, got %s, %v", info.Kind, err)
+	}
+}
+
+func TestSpecialContractKeys(t *testing.T) {
+	mapper := NewTrieKeyMapper()
+	c, ok := LookupSpecialContract(common.HexToAddress("0x0000000000000000000000000000000001000006"))
+	if !ok || c.Name != "Bridge" {
+		t.Fatalf("Expected Bridge, got %+v (found=%v)", c, ok)
+	}
+	if IsSpecialContract(common.HexToAddress("0x1000006")) == IsSpecialContract(common.HexToAddress("0x1234")) {
+		t.Error("Expected only the Bridge address to be special")
+	}
+
+	keys := mapper.ContractKeys(RemascAddress)
+	info, err := mapper.ClassifyKey(keys.Code)
+	if err != nil || info.Kind != KeyKindCode || info.Address != RemascAddress {
+		t.Errorf("Unexpected REMASC code key classification %+v, %v", info, err)
+	}
+	info, err = mapper.ClassifyKey(mapper.BridgeStorageKey(common.HexToHash("0x01")))
+	if err != nil || info.Kind != KeyKindStorage || info.Address != BridgeAddress {
+		t.Errorf("Unexpected Bridge storage key classification %+v, %v", info, err)
+	}
+}
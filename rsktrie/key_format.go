@@ -0,0 +1,170 @@
+package rsktrie
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Segment names used by SplitKey and FormatKey.
+const (
+	SegmentDomain        = "domain"
+	SegmentAccountPrefix = "account-prefix"
+	SegmentAddress       = "address"
+	SegmentStorage       = "storage"
+	SegmentSlotPrefix    = "slot-prefix"
+	SegmentSlot          = "slot"
+	SegmentCode          = "code"
+	// SegmentRest holds bytes that do not follow the unitrie layout.
+	SegmentRest = "rest"
+)
+
+// KeySegment is a named run of bytes within a trie key.
+type KeySegment struct {
+	Name  string
+	Bytes []byte
+}
+
+// segmentSizes gives the fixed size of each named segment; slot and rest are
+// variable.
+var segmentSizes = map[string]int{
+	SegmentDomain:        len(DomainPrefix),
+	SegmentAccountPrefix: SecureKeySize,
+	SegmentAddress:       AddressKeySize,
+	SegmentStorage:       len(StoragePrefix),
+	SegmentSlotPrefix:    SecureKeySize,
+	SegmentCode:          len(CodePrefix),
+}
+
+// SplitKey splits key into its unitrie segments: domain prefix, account
+// secure prefix and address, then either the code marker or the storage
+// marker, slot secure prefix and slot payload. The split is purely
+// positional, so it also works on truncated keys and keys whose secure
+// prefixes do not match their payload; a truncated key yields a short last
+// segment, and anything past the layout is returned as SegmentRest.
+func SplitKey(key []byte) []KeySegment {
+	var segments []KeySegment
+	take := func(name string, n int) bool {
+		if len(key) == 0 {
+			return false
+		}
+		if n > len(key) || n < 0 {
+			n = len(key)
+		}
+		segments = append(segments, KeySegment{Name: name, Bytes: key[:n]})
+		key = key[n:]
+		return true
+	}
+
+	if !take(SegmentDomain, len(DomainPrefix)) ||
+		!take(SegmentAccountPrefix, SecureKeySize) ||
+		!take(SegmentAddress, AddressKeySize) {
+		return segments
+	}
+	switch {
+	case len(key) == len(CodePrefix) && key[0] == CodePrefix[0]:
+		take(SegmentCode, len(CodePrefix))
+	case len(key) > 0 && key[0] == StoragePrefix[0]:
+		if take(SegmentStorage, len(StoragePrefix)) && take(SegmentSlotPrefix, SecureKeySize) {
+			take(SegmentSlot, -1)
+		}
+	default:
+		take(SegmentRest, -1)
+	}
+	return segments
+}
+
+// FormatKey renders key as "/"-separated name=hex segments, e.g.
+//
+//	domain=00/account-prefix=1a2b…/address=cd2a…/storage=00/slot-prefix=…/slot=01
+//
+// ParseKey reverses it.
+func FormatKey(key []byte) string {
+	segments := SplitKey(key)
+	parts := make([]string, len(segments))
+	for i, s := range segments {
+		parts[i] = s.Name + "=" + hex.EncodeToString(s.Bytes)
+	}
+	return strings.Join(parts, "/")
+}
+
+// FormatKeyBits renders key as the bit string the trie branches on, with "/"
+// between segments.
+func FormatKeyBits(key []byte) string {
+	segments := SplitKey(key)
+	parts := make([]string, len(segments))
+	for i, s := range segments {
+		parts[i] = FormatBits(TrieKeySliceFromKey(s.Bytes))
+	}
+	return strings.Join(parts, "/")
+}
+
+// FormatBits renders a key slice, such as a node's shared path, as a string
+// of '0' and '1'.
+func FormatBits(slice *TrieKeySlice) string {
+	var b strings.Builder
+	b.Grow(slice.Length())
+	for i := 0; i < slice.Length(); i++ {
+		b.WriteByte('0' + slice.Get(i))
+	}
+	return b.String()
+}
+
+// ParseKey parses the output of FormatKey back into a key. Segment names are
+// optional, so plain "/"-separated hex (with or without 0x) is accepted too;
+// named segments of a fixed size must have that size.
+func ParseKey(s string) ([]byte, error) {
+	var key []byte
+	for i, part := range strings.Split(s, "/") {
+		name, value, named := strings.Cut(part, "=")
+		if !named {
+			name, value = "", part
+		}
+		value = strings.TrimPrefix(value, "0x")
+		b, err := hex.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("segment %d: %w", i, err)
+		}
+		if named {
+			size, fixed := segmentSizes[name]
+			if !fixed && name != SegmentSlot && name != SegmentRest {
+				return nil, fmt.Errorf("segment %d: unknown name %q", i, name)
+			}
+			if fixed && len(b) != size {
+				return nil, fmt.Errorf("segment %d: %s is %d bytes, expected %d", i, name, len(b), size)
+			}
+		}
+		key = append(key, b...)
+	}
+	return key, nil
+}
+
+// ParseBits parses a string of '0' and '1', optionally with "/" separators as
+// written by FormatKeyBits, into a key slice. The length need not be a whole
+// number of bytes.
+func ParseBits(s string) (*TrieKeySlice, error) {
+	bits := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '0', '1':
+			bits = append(bits, s[i]-'0')
+		case '/':
+		default:
+			return nil, fmt.Errorf("invalid bit %q at offset %d", s[i], i)
+		}
+	}
+	return NewTrieKeySlice(bits, 0, len(bits)), nil
+}
+
+// DescribeKeyBit names the segment that bit position bit of key falls in,
+// e.g. "address bit 37", for pointing at where a proof path diverges.
+func DescribeKeyBit(key []byte, bit int) string {
+	pos := bit
+	for _, s := range SplitKey(key) {
+		if pos < len(s.Bytes)*8 {
+			return fmt.Sprintf("%s bit %d", s.Name, pos)
+		}
+		pos -= len(s.Bytes) * 8
+	}
+	return fmt.Sprintf("bit %d past end of key", bit)
+}
@@ -0,0 +1,84 @@
+package rsktrie
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestFormatKeyRoundTrip(t *testing.T) {
+	m := NewTrieKeyMapper()
+	addr := common.HexToAddress("0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826")
+
+	keys := map[string][]byte{
+		"account": m.GetAccountKey(addr),
+		"code":    m.GetCodeKey(addr),
+		"storage": m.GetAccountStorageKey(addr, common.HexToHash("0x0102")),
+		"slot 0":  m.GetAccountStorageKey(addr, common.Hash{}),
+	}
+	for name, key := range keys {
+		s := FormatKey(key)
+		got, err := ParseKey(s)
+		if err != nil {
+			t.Fatalf("%s: ParseKey(%s) failed: %v", name, s, err)
+		}
+		if !bytes.Equal(got, key) {
+			t.Errorf("%s: expected %x, got %x", name, key, got)
+		}
+	}
+
+	s := FormatKey(keys["storage"])
+	if !strings.Contains(s, "/address=cd2a3d9f938e13cd947ec05abc7fe734df8dd826/storage=00/") ||
+		!strings.HasSuffix(s, "/slot=0102") {
+		t.Errorf("Unexpected storage key rendering %s", s)
+	}
+	if s := FormatKey(keys["code"]); !strings.HasSuffix(s, "/code=80") {
+		t.Errorf("Unexpected code key rendering %s", s)
+	}
+}
+
+func TestParseKeyErrors(t *testing.T) {
+	if key, err := ParseKey("0x00/abcd"); err != nil || !bytes.Equal(key, []byte{0, 0xab, 0xcd}) {
+		t.Errorf("Expected unnamed hex segments to parse, got %x, %v", key, err)
+	}
+	if _, err := ParseKey("domain=00/address=cd2a"); err == nil {
+		t.Error("Expected error for short address segment")
+	}
+	if _, err := ParseKey("bogus=00"); err == nil {
+		t.Error("Expected error for unknown segment name")
+	}
+	if _, err := ParseKey("domain=zz"); err == nil {
+		t.Error("Expected error for invalid hex")
+	}
+}
+
+func TestFormatKeyBits(t *testing.T) {
+	m := NewTrieKeyMapper()
+	key := m.GetCodeKey(common.HexToAddress("0x01"))
+
+	s := FormatKeyBits(key)
+	if !strings.HasPrefix(s, "00000000/") || !strings.HasSuffix(s, "/10000000") {
+		t.Errorf("Unexpected bit rendering %s", s)
+	}
+	slice, err := ParseBits(s)
+	if err != nil {
+		t.Fatalf("ParseBits failed: %v", err)
+	}
+	if !bytes.Equal(slice.Encode(), key) {
+		t.Errorf("Expected %x, got %x", key, slice.Encode())
+	}
+
+	slice, err = ParseBits("101")
+	if err != nil || slice.Length() != 3 || FormatBits(slice) != "101" {
+		t.Errorf("Unexpected partial slice %v, %v", slice, err)
+	}
+	if _, err := ParseBits("012"); err == nil {
+		t.Error("Expected error for invalid bit")
+	}
+
+	if got := DescribeKeyBit(key, 8+SecureKeySize*8+5); got != "address bit 5" {
+		t.Errorf("Expected address bit 5, got %s", got)
+	}
+}
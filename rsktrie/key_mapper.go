@@ -1,6 +1,10 @@
 package rsktrie
 
 import (
+	"bytes"
+	"fmt"
+	"log"
+
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -17,10 +21,85 @@ var (
 )
 
 // TrieKeyMapper generates trie keys for accounts and storage in RSK's unified trie
-type TrieKeyMapper struct{}
+type TrieKeyMapper struct {
+	preimages PreimageStore
+	cache     *securePrefixCache
+	zeroSlot  ZeroSlotEncoding
+}
+
+// ZeroSlotEncoding selects the storage key payload of the all-zero slot,
+// whose leading-zero-stripped form is otherwise empty.
+type ZeroSlotEncoding int
+
+const (
+	// ZeroSlotSingleByte encodes slot 0 as a single 0x00 byte. This is what
+	// rskj does: DataWord.getByteArrayForStorage strips leading zeroes with
+	// ByteUtil.stripLeadingZeroes, which returns {0} for all-zero input.
+	ZeroSlotSingleByte ZeroSlotEncoding = iota
+	// ZeroSlotEmpty encodes slot 0 with an empty payload, as earlier
+	// versions of this package did. Keys derived this way do not match rskj.
+	ZeroSlotEmpty
+)
+
+func (e ZeroSlotEncoding) String() string {
+	switch e {
+	case ZeroSlotSingleByte:
+		return "single-byte"
+	case ZeroSlotEmpty:
+		return "empty"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(e))
+	}
+}
 
+// NewTrieKeyMapper returns a mapper that memoizes up to
+// DefaultSecurePrefixCacheSize secure prefixes.
 func NewTrieKeyMapper() *TrieKeyMapper {
-	return &TrieKeyMapper{}
+	return NewTrieKeyMapperWithCacheSize(DefaultSecurePrefixCacheSize)
+}
+
+// NewTrieKeyMapperWithCacheSize returns a mapper that memoizes up to size
+// secure prefixes. A size of zero or less disables memoization.
+func NewTrieKeyMapperWithCacheSize(size int) *TrieKeyMapper {
+	m := &TrieKeyMapper{}
+	if size > 0 {
+		m.cache = newSecurePrefixCache(size)
+	}
+	return m
+}
+
+// NewTrieKeyMapperWithPreimages returns a mapper that records the address and
+// slot preimage of every secure prefix it computes into preimages.
+func NewTrieKeyMapperWithPreimages(preimages PreimageStore) *TrieKeyMapper {
+	m := NewTrieKeyMapper()
+	m.preimages = preimages
+	return m
+}
+
+// WithZeroSlotEncoding returns a copy of the mapper that encodes slot 0 with
+// enc. The copy shares the original's cache and preimage store.
+func (m *TrieKeyMapper) WithZeroSlotEncoding(enc ZeroSlotEncoding) *TrieKeyMapper {
+	c := *m
+	c.zeroSlot = enc
+	return &c
+}
+
+// ZeroSlotEncoding returns how the mapper encodes slot 0.
+func (m *TrieKeyMapper) ZeroSlotEncoding() ZeroSlotEncoding {
+	return m.zeroSlot
+}
+
+// CacheStats returns the mapper's secure prefix cache statistics.
+func (m *TrieKeyMapper) CacheStats() SecurePrefixCacheStats {
+	if m.cache == nil {
+		return SecurePrefixCacheStats{}
+	}
+	return m.cache.stats()
+}
+
+// Preimages returns the mapper's preimage store, or nil.
+func (m *TrieKeyMapper) Preimages() PreimageStore {
+	return m.preimages
 }
 
 // GetAccountKey generates the trie key for an account address
@@ -56,10 +135,11 @@ func (m *TrieKeyMapper) GetAccountStoragePrefixKey(addr common.Address) []byte {
 
 // GetAccountStorageKey generates the full trie key for a storage slot
 // Format: StoragePrefixKey + SecureKeyPrefix(storageKey) + stripLeadingZeros(storageKey)
+// Slot 0 is encoded according to the mapper's ZeroSlotEncoding.
 func (m *TrieKeyMapper) GetAccountStorageKey(addr common.Address, storageKey common.Hash) []byte {
 	prefixKey := m.GetAccountStoragePrefixKey(addr)
 	securePrefix := m.SecureKeyPrefix(storageKey.Bytes())
-	strippedKey := stripLeadingZeros(storageKey.Bytes())
+	strippedKey := m.slotPayload(storageKey)
 
 	result := make([]byte, 0, len(prefixKey)+len(securePrefix)+len(strippedKey))
 	result = append(result, prefixKey...)
@@ -70,17 +150,119 @@ func (m *TrieKeyMapper) GetAccountStorageKey(addr common.Address, storageKey com
 
 // SecureKeyPrefix returns the first 10 bytes of keccak256(key)
 func (m *TrieKeyMapper) SecureKeyPrefix(key []byte) []byte {
+	if m.cache != nil {
+		if prefix, ok := m.cache.get(key); ok {
+			// Copy so callers cannot corrupt the cached entry.
+			return copyBytes(prefix)
+		}
+	}
+
 	hash := Keccak256(key)
-	return hash[:SecureKeySize]
+	prefix := hash[:SecureKeySize]
+	// Hits skip this, which is fine: the preimage was recorded on the miss.
+	if m.preimages != nil {
+		if err := m.preimages.PutPreimage(prefix, key); err != nil {
+			log.Printf("Failed to record preimage for %x: %v", prefix, err)
+		}
+	}
+	if m.cache != nil {
+		m.cache.add(key, copyBytes(prefix))
+	}
+	return prefix
+}
+
+// AddressFromKey recovers the account address from an account, code or
+// storage key. Keys truncated inside the address are resolved through the
+// preimage store.
+func (m *TrieKeyMapper) AddressFromKey(key []byte) (common.Address, error) {
+	domain := len(DomainPrefix)
+	if len(key) < domain+SecureKeySize || !bytes.Equal(key[:domain], DomainPrefix) {
+		return common.Address{}, fmt.Errorf("key %x is not an account key", key)
+	}
+	securePrefix := key[domain : domain+SecureKeySize]
+
+	var addr []byte
+	if len(key) >= domain+SecureAccountKey {
+		addr = key[domain+SecureKeySize : domain+SecureAccountKey]
+		if !bytes.Equal(Keccak256(addr)[:SecureKeySize], securePrefix) {
+			return common.Address{}, fmt.Errorf("key %x: address does not match secure prefix", key)
+		}
+	} else {
+		preimage, err := m.lookup(securePrefix)
+		if err != nil {
+			return common.Address{}, err
+		}
+		if len(preimage) != AddressKeySize || !bytes.HasPrefix(preimage, key[domain+SecureKeySize:]) {
+			return common.Address{}, fmt.Errorf("key %x: preimage %x is not a matching address", key, preimage)
+		}
+		addr = preimage
+	}
+	return common.BytesToAddress(addr), nil
+}
+
+// StorageSlotFromKey recovers the account address and storage slot from a
+// storage key. The slot is rebuilt from the stripped slot bytes in the key;
+// keys truncated before them are resolved through the preimage store.
+func (m *TrieKeyMapper) StorageSlotFromKey(key []byte) (common.Address, common.Hash, error) {
+	addr, err := m.AddressFromKey(key)
+	if err != nil {
+		return common.Address{}, common.Hash{}, err
+	}
+	slotStart := len(DomainPrefix) + SecureAccountKey + len(StoragePrefix)
+	if len(key) < slotStart+SecureKeySize || !bytes.Equal(key[slotStart-len(StoragePrefix):slotStart], StoragePrefix) {
+		return common.Address{}, common.Hash{}, fmt.Errorf("key %x is not a storage key", key)
+	}
+	securePrefix := key[slotStart : slotStart+SecureKeySize]
+	stripped := key[slotStart+SecureKeySize:]
+	if len(stripped) > common.HashLength {
+		return common.Address{}, common.Hash{}, fmt.Errorf("key %x: slot longer than %d bytes", key, common.HashLength)
+	}
+
+	slot := common.BytesToHash(stripped)
+	if bytes.Equal(Keccak256(slot.Bytes())[:SecureKeySize], securePrefix) {
+		return addr, slot, nil
+	}
+	// The key may stop partway through the stripped slot.
+	preimage, err := m.lookup(securePrefix)
+	if err != nil {
+		return common.Address{}, common.Hash{}, err
+	}
+	if len(preimage) != common.HashLength || !bytes.HasPrefix(stripLeadingZeros(preimage), stripped) {
+		return common.Address{}, common.Hash{}, fmt.Errorf("key %x: no matching slot preimage", key)
+	}
+	return addr, common.BytesToHash(preimage), nil
+}
+
+func (m *TrieKeyMapper) lookup(securePrefix []byte) ([]byte, error) {
+	if m.preimages == nil {
+		return nil, fmt.Errorf("no preimage store to resolve prefix %x", securePrefix)
+	}
+	preimage, err := lookupPreimage(m.preimages, securePrefix)
+	if err != nil {
+		return nil, err
+	}
+	if preimage == nil {
+		return nil, fmt.Errorf("unknown preimage for prefix %x", securePrefix)
+	}
+	return preimage, nil
+}
+
+// slotPayload returns the slot bytes that end a storage key.
+func (m *TrieKeyMapper) slotPayload(slot common.Hash) []byte {
+	stripped := stripLeadingZeros(slot.Bytes())
+	if len(stripped) == 0 && m.zeroSlot == ZeroSlotSingleByte {
+		return []byte{0}
+	}
+	return stripped
 }
 
-// stripLeadingZeros removes leading zero bytes from a byte slice
+// stripLeadingZeros removes leading zero bytes from a byte slice, returning
+// an empty slice for all-zero input; see slotPayload for the slot 0 case.
 func stripLeadingZeros(data []byte) []byte {
 	for i := 0; i < len(data); i++ {
 		if data[i] != 0 {
 			return data[i:]
 		}
 	}
-	// If all zeros, return empty slice (or single zero byte depending on RSK behavior)
 	return []byte{}
 }
@@ -0,0 +1,73 @@
+package rsktrie
+
+import (
+	"hash"
+	"log"
+
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/crypto/sha3"
+)
+
+// GetAccountKeys returns the account keys of addrs, in order. All keys share
+// one backing allocation and one keccak state, and bypass the prefix cache,
+// which would only churn for large batches of distinct addresses.
+func (m *TrieKeyMapper) GetAccountKeys(addrs []common.Address) [][]byte {
+	keyLen := len(DomainPrefix) + SecureAccountKey
+	buf := make([]byte, len(addrs)*keyLen)
+	keys := make([][]byte, len(addrs))
+	h := sha3.NewLegacyKeccak256()
+	var digest []byte
+
+	for i, addr := range addrs {
+		key := buf[i*keyLen : (i+1)*keyLen : (i+1)*keyLen]
+		n := copy(key, DomainPrefix)
+		digest = m.bulkSecurePrefix(h, addr.Bytes(), digest)
+		n += copy(key[n:], digest[:SecureKeySize])
+		copy(key[n:], addr.Bytes())
+		keys[i] = key
+	}
+	return keys
+}
+
+// GetStorageKeys returns the storage keys of slots under addr, in order. The
+// account prefix is derived once; see GetAccountKeys for allocation details.
+func (m *TrieKeyMapper) GetStorageKeys(addr common.Address, slots []common.Hash) [][]byte {
+	prefix := m.GetAccountStoragePrefixKey(addr)
+
+	total := 0
+	for _, slot := range slots {
+		total += len(prefix) + SecureKeySize + len(m.slotPayload(slot))
+	}
+	buf := make([]byte, total)
+	keys := make([][]byte, len(slots))
+	h := sha3.NewLegacyKeccak256()
+	var digest []byte
+
+	off := 0
+	for i, slot := range slots {
+		stripped := m.slotPayload(slot)
+		end := off + len(prefix) + SecureKeySize + len(stripped)
+		key := buf[off:end:end]
+		n := copy(key, prefix)
+		digest = m.bulkSecurePrefix(h, slot.Bytes(), digest)
+		n += copy(key[n:], digest[:SecureKeySize])
+		copy(key[n:], stripped)
+		keys[i] = key
+		off = end
+	}
+	return keys
+}
+
+// bulkSecurePrefix hashes key with h, reusing digest's storage, and records
+// the preimage if the mapper has a preimage store.
+func (m *TrieKeyMapper) bulkSecurePrefix(h hash.Hash, key []byte, digest []byte) []byte {
+	h.Reset()
+	h.Write(key)
+	digest = h.Sum(digest[:0])
+	if m.preimages != nil {
+		if err := m.preimages.PutPreimage(digest[:SecureKeySize], key); err != nil {
+			log.Printf("Failed to record preimage for %x: %v", digest[:SecureKeySize], err)
+		}
+	}
+	return digest
+}
@@ -0,0 +1,36 @@
+package rsktrie
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBulkKeysMatchSingleKeys(t *testing.T) {
+	mapper := NewTrieKeyMapper()
+	addrs := []common.Address{
+		common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051"),
+		common.HexToAddress("0x0000000000000000000000000000000001000006"),
+		{},
+	}
+	for i, key := range mapper.GetAccountKeys(addrs) {
+		if !bytes.Equal(key, mapper.GetAccountKey(addrs[i])) {
+			t.Errorf("Account key %d mismatch", i)
+		}
+	}
+
+	slots := []common.Hash{{}, common.HexToHash("0x01"), common.HexToHash("0xff00"), common.HexToHash("0x" + strings.Repeat("ab", 32))}
+	keys := mapper.GetStorageKeys(addrs[0], slots)
+	for i, key := range keys {
+		if !bytes.Equal(key, mapper.GetAccountStorageKey(addrs[0], slots[i])) {
+			t.Errorf("Storage key %d mismatch", i)
+		}
+	}
+	// Keys share a buffer but must not overlap.
+	keys[0] = append(keys[0], 0xee)
+	if !bytes.Equal(keys[1], mapper.GetAccountStorageKey(addrs[0], slots[1])) {
+		t.Error("Appending to one key modified the next")
+	}
+}
@@ -0,0 +1,98 @@
+package rsktrie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestZeroSlotEncoding(t *testing.T) {
+	addr := common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051")
+	mapper := NewTrieKeyMapper()
+	prefixLen := len(mapper.GetAccountStoragePrefixKey(addr)) + SecureKeySize
+
+	// rskj keeps one zero byte for slot 0.
+	key := mapper.GetAccountStorageKey(addr, common.Hash{})
+	if len(key) != prefixLen+1 || key[len(key)-1] != 0 {
+		t.Errorf("Expected single zero byte payload, got %x", key[prefixLen:])
+	}
+	empty := mapper.WithZeroSlotEncoding(ZeroSlotEmpty)
+	if key := empty.GetAccountStorageKey(addr, common.Hash{}); len(key) != prefixLen {
+		t.Errorf("Expected empty payload, got %x", key[prefixLen:])
+	}
+	if mapper.ZeroSlotEncoding() != ZeroSlotSingleByte {
+		t.Error("WithZeroSlotEncoding modified the original mapper")
+	}
+
+	// Non-zero slots are unaffected, and both encodings of slot 0 decode.
+	one := common.HexToHash("0x01")
+	if string(mapper.GetAccountStorageKey(addr, one)) != string(empty.GetAccountStorageKey(addr, one)) {
+		t.Error("Expected identical keys for slot 1")
+	}
+	for _, m := range []*TrieKeyMapper{mapper, empty} {
+		_, slot, err := m.StorageSlotFromKey(m.GetAccountStorageKey(addr, common.Hash{}))
+		if err != nil || slot != (common.Hash{}) {
+			t.Errorf("%s: expected slot 0, got %x, %v", m.ZeroSlotEncoding(), slot, err)
+		}
+	}
+	if keys := empty.GetStorageKeys(addr, []common.Hash{{}}); len(keys[0]) != prefixLen {
+		t.Errorf("Bulk keys ignore zero slot encoding: %x", keys[0])
+	}
+}
+
+// rskjSlotZeroLeaf is the storage leaf for slot 0 of contract
+// 0x77045E71a7A2c50903d88e564cD72fab11e82051, as served by rskj in the
+// eth_getProof response recorded in misc/account-proof-examples.md. Its
+// shared path is the tail of the slot's trie key.
+const rskjSlotZeroLeaf = "50ff56a437b365522d8aa3580c002a"
+
+func TestStorageKeyMatchesRskjLeaf(t *testing.T) {
+	leaf, err := FromMessage(common.FromHex(rskjSlotZeroLeaf), NewMemTrieStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := leaf.GetSharedPath()
+	tail := func(key []byte) []byte {
+		slice := TrieKeySliceFromKey(key)
+		return slice.Slice(slice.Length()-path.Length(), slice.Length()).Expand()
+	}
+
+	addr := common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051")
+	if key := NewTrieKeyMapper().GetAccountStorageKey(addr, common.Hash{}); !bytes.Equal(tail(key), path.Expand()) {
+		t.Errorf("Slot 0 key %x does not end in rskj's leaf path", key)
+	}
+	empty := NewTrieKeyMapper().WithZeroSlotEncoding(ZeroSlotEmpty)
+	if key := empty.GetAccountStorageKey(addr, common.Hash{}); bytes.Equal(tail(key), path.Expand()) {
+		t.Error("ZeroSlotEmpty key matches rskj's leaf path")
+	}
+}
+
+// TestStorageKeyPayloads checks the slot bytes closing a storage key
+// against rskj's DataWord.getByteArrayForStorage, which strips leading zero
+// bytes and keeps a single zero byte for slot 0. The payloads are written
+// out rather than computed with stripLeadingZeros.
+func TestStorageKeyPayloads(t *testing.T) {
+	addr := common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051")
+	mapper := NewTrieKeyMapper()
+	prefix := mapper.GetAccountStoragePrefixKey(addr)
+	for _, tc := range []struct {
+		slot    string
+		payload string
+	}{
+		{"0x00", "00"},
+		{"0x01", "01"},
+		{"0x0100", "0100"},
+		{"0x00000000000000000000000000000000000000000000000000000000000000ff", "ff"},
+		{"0x0000000000000000000000000000000000000000000000000000000100000000", "0100000000"},
+		{"0x00ff000000000000000000000000000000000000000000000000000000000000", "ff000000000000000000000000000000000000000000000000000000000000"},
+		{"0x290decd9548b62a8d60345a988386fc84ba6bc95484008f6362f93160ef3e563", "290decd9548b62a8d60345a988386fc84ba6bc95484008f6362f93160ef3e563"},
+	} {
+		slot := common.HexToHash(tc.slot)
+		want := append(append(append([]byte(nil), prefix...), crypto.Keccak256(slot[:])[:SecureKeySize]...), common.FromHex(tc.payload)...)
+		if got := mapper.GetAccountStorageKey(addr, slot); !bytes.Equal(got, want) {
+			t.Errorf("Slot %s: key %x, want %x", tc.slot, got, want)
+		}
+	}
+}
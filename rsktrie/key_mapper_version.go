@@ -0,0 +1,97 @@
+package rsktrie
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// KeyMapperVersion identifies a state key derivation scheme.
+type KeyMapperVersion int
+
+const (
+	// KeyMapperOrchid is the pre-unitrie layout: accounts live in a secure
+	// state trie keyed by keccak256(address), each contract has its own
+	// storage trie keyed by keccak256(slot), and code is stored by hash
+	// outside the trie.
+	KeyMapperOrchid KeyMapperVersion = iota
+	// KeyMapperUnitrie is the unified trie layout (see TrieKeyMapper).
+	KeyMapperUnitrie
+)
+
+func (v KeyMapperVersion) String() string {
+	switch v {
+	case KeyMapperOrchid:
+		return "orchid"
+	case KeyMapperUnitrie:
+		return "unitrie"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(v))
+	}
+}
+
+// KeyMapper derives the trie keys of accounts, code and storage slots for one
+// key derivation scheme.
+type KeyMapper interface {
+	Version() KeyMapperVersion
+	GetAccountKey(addr common.Address) []byte
+	// GetCodeKey returns nil if code is not stored in the state trie.
+	GetCodeKey(addr common.Address) []byte
+	// GetAccountStorageKey returns the slot's key in the trie that holds the
+	// account's storage: the state trie for unitrie, the contract's own
+	// storage trie for Orchid.
+	GetAccountStorageKey(addr common.Address, storageKey common.Hash) []byte
+}
+
+var (
+	_ KeyMapper = (*TrieKeyMapper)(nil)
+	_ KeyMapper = (*OrchidKeyMapper)(nil)
+)
+
+func (m *TrieKeyMapper) Version() KeyMapperVersion {
+	return KeyMapperUnitrie
+}
+
+// OrchidKeyMapper derives keys for pre-unitrie state.
+type OrchidKeyMapper struct{}
+
+func NewOrchidKeyMapper() *OrchidKeyMapper {
+	return &OrchidKeyMapper{}
+}
+
+func (m *OrchidKeyMapper) Version() KeyMapperVersion {
+	return KeyMapperOrchid
+}
+
+func (m *OrchidKeyMapper) GetAccountKey(addr common.Address) []byte {
+	return Keccak256(addr.Bytes())
+}
+
+func (m *OrchidKeyMapper) GetCodeKey(addr common.Address) []byte {
+	return nil
+}
+
+func (m *OrchidKeyMapper) GetAccountStorageKey(addr common.Address, storageKey common.Hash) []byte {
+	return Keccak256(storageKey.Bytes())
+}
+
+// NewKeyMapper returns the mapper for version.
+func NewKeyMapper(version KeyMapperVersion) (KeyMapper, error) {
+	switch version {
+	case KeyMapperOrchid:
+		return NewOrchidKeyMapper(), nil
+	case KeyMapperUnitrie:
+		return NewTrieKeyMapper(), nil
+	default:
+		return nil, fmt.Errorf("unsupported key mapper version %s", version)
+	}
+}
+
+// KeyMapperVersionAt returns the scheme in use at blockNum, given the height
+// at which the network activated the unitrie.
+func KeyMapperVersionAt(blockNum uint64, unitrieActivation uint64) KeyMapperVersion {
+	if blockNum >= unitrieActivation {
+		return KeyMapperUnitrie
+	}
+	return KeyMapperOrchid
+}
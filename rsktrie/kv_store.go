@@ -0,0 +1,246 @@
+package rsktrie
+
+import (
+	"fmt"
+	"log"
+	"sync"
+)
+
+// KeyValueStore is the byte-level backend behind persistent trie stores.
+// Get returns (nil, nil) for a missing key.
+type KeyValueStore interface {
+	Get(key []byte) ([]byte, error)
+	Put(key []byte, value []byte) error
+	Delete(key []byte) error
+	NewBatch() KeyValueBatch
+	Close() error
+}
+
+// KeyValueBatch queues writes against a KeyValueStore and applies them
+// atomically on Write.
+type KeyValueBatch interface {
+	Put(key []byte, value []byte)
+	Delete(key []byte)
+	Len() int
+	Write() error
+	Reset()
+}
+
+// MemKeyValueStore is an in-memory KeyValueStore, mostly useful for tests and
+// for stacking wrappers on top of.
+type MemKeyValueStore struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+func NewMemKeyValueStore() *MemKeyValueStore {
+	return &MemKeyValueStore{data: make(map[string][]byte)}
+}
+
+func (s *MemKeyValueStore) Get(key []byte) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, ok := s.data[string(key)]
+	if !ok {
+		return nil, nil
+	}
+	dst := make([]byte, len(val))
+	copy(dst, val)
+	return dst, nil
+}
+
+func (s *MemKeyValueStore) Put(key []byte, value []byte) error {
+	v := make([]byte, len(value))
+	copy(v, value)
+	s.mu.Lock()
+	s.data[string(key)] = v
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemKeyValueStore) Delete(key []byte) error {
+	s.mu.Lock()
+	delete(s.data, string(key))
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemKeyValueStore) NewBatch() KeyValueBatch {
+	return &kvBatch{commit: s.apply}
+}
+
+func (s *MemKeyValueStore) Close() error {
+	return nil
+}
+
+// Len returns the number of stored keys.
+func (s *MemKeyValueStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.data)
+}
+
+func (s *MemKeyValueStore) apply(ops []kvOp) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, op := range ops {
+		if op.delete {
+			delete(s.data, string(op.key))
+		} else {
+			s.data[string(op.key)] = op.value
+		}
+	}
+	return nil
+}
+
+// kvOp is a single queued batch operation.
+type kvOp struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+// kvBatch is a generic KeyValueBatch that hands its queued operations to a
+// commit function. Stores provide the commit function to get atomicity.
+type kvBatch struct {
+	ops    []kvOp
+	commit func(ops []kvOp) error
+}
+
+func (b *kvBatch) Put(key []byte, value []byte) {
+	b.ops = append(b.ops, kvOp{key: copyBytes(key), value: copyBytes(value)})
+}
+
+func (b *kvBatch) Delete(key []byte) {
+	b.ops = append(b.ops, kvOp{key: copyBytes(key), delete: true})
+}
+
+func (b *kvBatch) Len() int {
+	return len(b.ops)
+}
+
+func (b *kvBatch) Write() error {
+	if len(b.ops) == 0 {
+		return nil
+	}
+	if err := b.commit(b.ops); err != nil {
+		return err
+	}
+	b.Reset()
+	return nil
+}
+
+func (b *kvBatch) Reset() {
+	b.ops = nil
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
+
+// KVTrieStore is a TrieStore that persists serialized nodes and long values
+// into a KeyValueStore, keyed by their Keccak256 hash (as rskj's
+// TrieStoreImpl does).
+type KVTrieStore struct {
+	kv KeyValueStore
+}
+
+func NewKVTrieStore(kv KeyValueStore) *KVTrieStore {
+	return &KVTrieStore{kv: kv}
+}
+
+// KeyValueStore returns the backend this store writes to.
+func (s *KVTrieStore) KeyValueStore() KeyValueStore {
+	return s.kv
+}
+
+func (s *KVTrieStore) Save(t *Trie) {
+	if t == nil {
+		return
+	}
+	batch := s.NewBatch()
+	batch.Put(t)
+	if err := batch.Write(); err != nil {
+		log.Printf("Failed to save node %x: %v", t.GetHash(), err)
+	}
+}
+
+func (s *KVTrieStore) Retrieve(hash []byte) *Trie {
+	if hash == nil {
+		return nil
+	}
+	msg, err := s.kv.Get(hash)
+	if err != nil {
+		log.Printf("Failed to read node %x: %v", hash, err)
+		return nil
+	}
+	if msg == nil {
+		return nil
+	}
+	t, err := FromMessage(msg, s)
+	if err != nil {
+		log.Printf("Failed to parse stored node %x: %v", hash, err)
+		return nil
+	}
+	t.saved = true
+	return t
+}
+
+func (s *KVTrieStore) RetrieveValue(hash []byte) []byte {
+	if hash == nil {
+		return nil
+	}
+	val, err := s.kv.Get(hash)
+	if err != nil {
+		log.Printf("Failed to read value %x: %v", hash, err)
+		return nil
+	}
+	return val
+}
+
+func (s *KVTrieStore) NewBatch() WriteBatch {
+	return &kvTrieWriteBatch{batch: s.kv.NewBatch()}
+}
+
+// kvTrieWriteBatch adapts a KeyValueBatch to the WriteBatch interface.
+type kvTrieWriteBatch struct {
+	batch KeyValueBatch
+	nodes []*Trie
+}
+
+func (b *kvTrieWriteBatch) Put(t *Trie) {
+	if t == nil {
+		return
+	}
+	b.batch.Put(t.GetHash(), t.ToMessage())
+	b.nodes = append(b.nodes, t)
+}
+
+func (b *kvTrieWriteBatch) PutValue(hash []byte, value []byte) {
+	b.batch.Put(hash, value)
+}
+
+func (b *kvTrieWriteBatch) Len() int {
+	return b.batch.Len()
+}
+
+func (b *kvTrieWriteBatch) Write() error {
+	if err := b.batch.Write(); err != nil {
+		return fmt.Errorf("write trie batch: %w", err)
+	}
+	for _, t := range b.nodes {
+		t.saved = true
+	}
+	b.nodes = nil
+	return nil
+}
+
+func (b *kvTrieWriteBatch) Reset() {
+	b.batch.Reset()
+	b.nodes = nil
+}
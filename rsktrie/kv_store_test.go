@@ -0,0 +1,297 @@
+package rsktrie
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func buildStoreTestTrie(store TrieStore) *Trie {
+	trie := NewTrie(store)
+	for k := 0; k < 40; k++ {
+		key := []byte(fmt.Sprintf("key-%d", k))
+		trie = trie.Put(key, bytes.Repeat([]byte{byte(k)}, 10+k*3))
+	}
+	return trie
+}
+
+func checkStoreTestTrie(t *testing.T, trie *Trie) {
+	t.Helper()
+	for k := 0; k < 40; k++ {
+		key := []byte(fmt.Sprintf("key-%d", k))
+		want := bytes.Repeat([]byte{byte(k)}, 10+k*3)
+		if got := trie.Get(key); !bytes.Equal(got, want) {
+			t.Errorf("Value mismatch for %s: got %x", key, got)
+		}
+	}
+}
+
+func TestKVTrieStoreRoundTrip(t *testing.T) {
+	store := NewKVTrieStore(NewMemKeyValueStore())
+	trie := buildStoreTestTrie(store)
+	if err := trie.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded := store.Retrieve(trie.GetHash())
+	if loaded == nil {
+		t.Fatal("Root not found")
+	}
+	if !bytes.Equal(loaded.GetHash(), trie.GetHash()) {
+		t.Fatalf("Loaded root hash mismatch")
+	}
+	checkStoreTestTrie(t, loaded)
+}
+
+func TestFileKeyValueStoreReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trie.db")
+
+	kv, err := OpenFileKeyValueStore(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	trie := buildStoreTestTrie(NewKVTrieStore(kv))
+	if err := trie.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	root := trie.GetHash()
+	if err := kv.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	kv, err = OpenFileKeyValueStore(path)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer kv.Close()
+	loaded := NewKVTrieStore(kv).Retrieve(root)
+	if loaded == nil {
+		t.Fatal("Root not found after reopen")
+	}
+	checkStoreTestTrie(t, loaded)
+}
+
+func TestFileKeyValueStoreDropsTornFrame(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kv.db")
+
+	kv, err := OpenFileKeyValueStore(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := kv.Put([]byte("a"), []byte("1")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	batch := kv.NewBatch()
+	batch.Put([]byte("b"), []byte("2"))
+	batch.Put([]byte("c"), []byte("3"))
+	if err := batch.Write(); err != nil {
+		t.Fatalf("Batch write failed: %v", err)
+	}
+	kv.Close()
+
+	// Simulate a crash in the middle of the second frame.
+	info, _ := os.Stat(path)
+	if err := os.Truncate(path, info.Size()-2); err != nil {
+		t.Fatal(err)
+	}
+
+	kv, err = OpenFileKeyValueStore(path)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer kv.Close()
+	if v, _ := kv.Get([]byte("a")); !bytes.Equal(v, []byte("1")) {
+		t.Errorf("Expected a=1 to survive, got %q", v)
+	}
+	for _, k := range []string{"b", "c"} {
+		if v, _ := kv.Get([]byte(k)); v != nil {
+			t.Errorf("Expected %s from torn batch to be dropped, got %q", k, v)
+		}
+	}
+
+	// The store must remain writable after recovery.
+	if err := kv.Put([]byte("d"), []byte("4")); err != nil {
+		t.Fatalf("Put after recovery failed: %v", err)
+	}
+	if v, _ := kv.Get([]byte("d")); !bytes.Equal(v, []byte("4")) {
+		t.Errorf("Expected d=4, got %q", v)
+	}
+}
+
+func TestKVTrieStoreChecksLongValues(t *testing.T) {
+	kv := NewMemKeyValueStore()
+	store := NewKVTrieStore(kv)
+	long := bytes.Repeat([]byte{0x42}, 100)
+	trie := NewTrie(store).Put([]byte("key"), long)
+	if err := trie.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	root := trie.GetHash()
+	if got := store.Retrieve(root).Get([]byte("key")); !bytes.Equal(got, long) {
+		t.Fatalf("Got %x, want %x", got, long)
+	}
+
+	for name, stored := range map[string][]byte{
+		"same length": bytes.Repeat([]byte{0x43}, 100),
+		"short":       long[:99],
+	} {
+		if err := kv.Put(Keccak256(long), stored); err != nil {
+			t.Fatal(err)
+		}
+		loaded := store.Retrieve(root)
+		if got := loaded.Get([]byte("key")); got != nil {
+			t.Errorf("%s: tampered long value returned: %x", name, got)
+		}
+		node := loaded.Find(TrieKeySliceFromKey([]byte("key")))
+		if _, err := node.GetValueContext(context.Background()); err == nil {
+			t.Errorf("%s: GetValueContext accepted a tampered long value", name)
+		}
+	}
+}
+
+// writeTwoFrames creates a file store at path holding a=1 and b=2 in two
+// frames, and returns the size of the first frame.
+func writeTwoFrames(t *testing.T, path string) int64 {
+	t.Helper()
+	kv, err := OpenFileKeyValueStore(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := kv.Put([]byte("a"), []byte("1")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	info, _ := os.Stat(path)
+	if err := kv.Put([]byte("b"), []byte("2")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	kv.Close()
+	return info.Size()
+}
+
+func TestFileKeyValueStoreOversizedTornFrame(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kv.db")
+	first := writeTwoFrames(t, path)
+
+	// A torn final frame whose header claims a 4 GiB payload.
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte{0xff, 0xff, 0xff, 0xff}, first); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	kv, err := OpenFileKeyValueStore(path)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer kv.Close()
+	if v, _ := kv.Get([]byte("a")); !bytes.Equal(v, []byte("1")) {
+		t.Errorf("Expected a=1 to survive, got %q", v)
+	}
+	if info, _ := os.Stat(path); info.Size() != first {
+		t.Errorf("File is %d bytes after recovery, want %d", info.Size(), first)
+	}
+}
+
+func TestFileKeyValueStoreRejectsCorruptFrame(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kv.db")
+	writeTwoFrames(t, path)
+	before, _ := os.Stat(path)
+
+	// Flip a payload byte of the first frame; the second stays intact.
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte{0xee}, fileFrameHeaderSize+1); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if kv, err := OpenFileKeyValueStore(path); err == nil {
+		kv.Close()
+		t.Fatal("Expected corruption before the tail to fail the open")
+	}
+	if after, _ := os.Stat(path); after.Size() != before.Size() {
+		t.Errorf("File truncated from %d to %d bytes", before.Size(), after.Size())
+	}
+}
+
+func TestCompressedKeyValueStore(t *testing.T) {
+	for _, codec := range []Compression{CompressionNone, CompressionSnappy, CompressionZstd} {
+		t.Run(codec.String(), func(t *testing.T) {
+			inner := NewMemKeyValueStore()
+			kv, err := NewCompressedKeyValueStore(inner, codec)
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+			defer kv.Close()
+
+			compressible := bytes.Repeat([]byte("rsk"), 200)
+			if err := kv.Put([]byte("k"), compressible); err != nil {
+				t.Fatalf("Put failed: %v", err)
+			}
+			got, err := kv.Get([]byte("k"))
+			if err != nil || !bytes.Equal(got, compressible) {
+				t.Fatalf("Get mismatch: %v", err)
+			}
+
+			raw, _ := inner.Get([]byte("k"))
+			if Compression(raw[0]) != codec {
+				t.Errorf("Expected tag %s, got %s", codec, Compression(raw[0]))
+			}
+			if codec != CompressionNone && len(raw) >= len(compressible) {
+				t.Errorf("Expected compressed record, got %d bytes", len(raw))
+			}
+
+			// Reads work regardless of the codec the record was written with.
+			trie := buildStoreTestTrie(NewKVTrieStore(kv))
+			if err := trie.Save(); err != nil {
+				t.Fatalf("Save failed: %v", err)
+			}
+			checkStoreTestTrie(t, NewKVTrieStore(kv).Retrieve(trie.GetHash()))
+		})
+	}
+}
+
+func TestEncryptedKeyValueStore(t *testing.T) {
+	inner := NewMemKeyValueStore()
+	key := bytes.Repeat([]byte{7}, 32)
+	kv, err := NewEncryptedKeyValueStore(inner, key)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	trie := buildStoreTestTrie(NewKVTrieStore(kv))
+	if err := trie.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	checkStoreTestTrie(t, NewKVTrieStore(kv).Retrieve(trie.GetHash()))
+
+	// The raw backend holds only ciphertext.
+	if raw, _ := inner.Get(trie.GetHash()); bytes.Contains(raw, trie.ToMessage()) {
+		t.Error("Expected root node to be encrypted at rest")
+	}
+
+	// A different key cannot decrypt.
+	other, _ := NewEncryptedKeyValueStore(inner, bytes.Repeat([]byte{8}, 32))
+	if _, err := other.Get(trie.GetHash()); err == nil {
+		t.Error("Expected decryption with the wrong key to fail")
+	}
+
+	// Records are bound to their key.
+	raw, _ := inner.Get(trie.GetHash())
+	inner.Put([]byte("moved"), raw)
+	if _, err := kv.Get([]byte("moved")); err == nil {
+		t.Error("Expected a record moved to another key to fail")
+	}
+
+	if _, err := NewEncryptedKeyValueStore(inner, []byte("short")); err == nil {
+		t.Error("Expected error for invalid key size")
+	}
+}
@@ -0,0 +1,56 @@
+package rsktrie
+
+import (
+	"errors"
+)
+
+// MergeProofNodes combines per-key proofs into one node set, dropping nodes
+// that appear more than once. Keys sharing the upper trie levels share those
+// nodes, so the merged set is usually much smaller than the sum of its parts.
+// First-seen order is kept.
+func MergeProofNodes(proofs ...[][]byte) [][]byte {
+	seen := make(map[string]struct{})
+	var merged [][]byte
+	for _, proof := range proofs {
+		for _, node := range proof {
+			if _, ok := seen[string(node)]; ok {
+				continue
+			}
+			seen[string(node)] = struct{}{}
+			merged = append(merged, node)
+		}
+	}
+	return merged
+}
+
+// VerifyMultiproof verifies every key against expectedHash using one shared
+// node set, such as one built with MergeProofNodes, decoding each node once.
+//
+// Results are in key order. If the node set cannot be decoded, the results
+// are nil. Otherwise keys the set does not prove get Status ProofInvalid, and
+// the error joins their *ProofError values; the other results still hold.
+// DefaultProofLimits apply; see ProofConfig.VerifyMultiproof for others.
+func VerifyMultiproof(expectedHash []byte, keys [][]byte, proofNodes [][]byte) ([]*KeyProofResult, error) {
+	return ProofConfig{Limits: DefaultProofLimits}.VerifyMultiproof(expectedHash, keys, proofNodes)
+}
+
+// VerifyMultiproof verifies keys against one shared node set as the
+// package-level VerifyMultiproof does, applying c's limits, node format and
+// hash function. Order and Strict do not apply to a shared set.
+func (c ProofConfig) VerifyMultiproof(expectedHash []byte, keys [][]byte, proofNodes [][]byte) ([]*KeyProofResult, error) {
+	nodes, err := c.DecodeProofNodes(proofNodes)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*KeyProofResult, len(keys))
+	var errs []error
+	for i, key := range keys {
+		result, err := c.VerifyNodeSet(nodes, expectedHash, key)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		results[i] = result
+	}
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,187 @@
+package rsktrie
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrNotCovered is matched (via errors.Is) by every NotCoveredError.
+var ErrNotCovered = errors.New("key not covered by witness")
+
+// NotCoveredError reports a read the ingested proofs cannot answer: the path
+// to Key needs a node, or a long value, that no proof supplied.
+type NotCoveredError struct {
+	Key []byte
+	// MissingHash is the hash of the missing node or value.
+	MissingHash []byte
+	// KeyPosition is the number of key bits walked before the gap.
+	KeyPosition int
+}
+
+func (e *NotCoveredError) Error() string {
+	return fmt.Sprintf("key %s not covered by witness: missing %x at %s",
+		FormatKey(e.Key), e.MissingHash, DescribeKeyBit(e.Key, e.KeyPosition))
+}
+
+func (e *NotCoveredError) Is(target error) bool {
+	return target == ErrNotCovered
+}
+
+// PartialTrie answers reads against a state root from the proof nodes it has
+// been given, without the full trie. Any key whose path the nodes cover can
+// be read, present or absent; other keys fail with a *NotCoveredError.
+// Since nodes are addressed by hash from the root, ingesting a node that does
+// not belong to the trie cannot change any answer.
+//
+// A PartialTrie is safe for concurrent use.
+type PartialTrie struct {
+	mu     sync.Mutex
+	root   common.Hash
+	config ProofConfig
+	nodes  *ProofNodeSet
+	values map[string][]byte
+}
+
+// NewPartialTrie returns an empty partial trie for root, which applies
+// DefaultProofLimits.
+func NewPartialTrie(root common.Hash) *PartialTrie {
+	return NewPartialTrieWithConfig(root, ProofConfig{Limits: DefaultProofLimits})
+}
+
+// NewPartialTrieWithConfig returns an empty partial trie for root. Each
+// AddProof call is decoded with config's size limits, node format and hash
+// function, and each read is walked within its depth limit.
+func NewPartialTrieWithConfig(root common.Hash, config ProofConfig) *PartialTrie {
+	return &PartialTrie{
+		root:   root,
+		config: config,
+		nodes:  &ProofNodeSet{byHash: make(map[string]proofNode), lastIndex: -1},
+		values: make(map[string][]byte),
+	}
+}
+
+// NewPartialTrieFromWitness returns a partial trie holding w's nodes.
+func NewPartialTrieFromWitness(w *Witness) (*PartialTrie, error) {
+	p := NewPartialTrie(w.Root)
+	if err := p.AddProof(w.Nodes); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Root returns the state root the trie answers for.
+func (p *PartialTrie) Root() common.Hash {
+	return p.root
+}
+
+// AddProof ingests proof nodes in eth_getProof format, rejecting the whole
+// proof if it exceeds the trie's limits or is in the wrong node format.
+func (p *PartialTrie) AddProof(proofNodes [][]byte) error {
+	set, err := p.config.DecodeProofNodes(proofNodes)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nodes.Merge(set)
+	return nil
+}
+
+// AddValue ingests a long value (over 32 bytes, such as contract code), which
+// proofs only commit to by hash. It is stored under its keccak256 hash, so
+// values that no node references are simply never read.
+func (p *PartialTrie) AddValue(value []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.values[string(Keccak256(value))] = append([]byte(nil), value...)
+}
+
+// Get returns key's value, or nil if the proofs show key is absent.
+func (p *PartialTrie) Get(key []byte) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	result, err := p.lookup(key)
+	if err != nil || result.Status != ProofPresent {
+		return nil, err
+	}
+	if result.Value == nil && result.ValueHash != nil {
+		value, ok := p.values[string(result.ValueHash)]
+		if !ok {
+			return nil, &NotCoveredError{Key: key, MissingHash: result.ValueHash, KeyPosition: len(key) * 8}
+		}
+		return append([]byte(nil), value...), nil
+	}
+	return result.Value, nil
+}
+
+// Has reports whether key exists. Unlike Get it does not need long values.
+func (p *PartialTrie) Has(key []byte) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	result, err := p.lookup(key)
+	if err != nil {
+		return false, err
+	}
+	return result.Status == ProofPresent, nil
+}
+
+// lookup walks key through the ingested nodes. The caller holds p.mu, since
+// walking fills nodes' lazily computed hashes.
+func (p *PartialTrie) lookup(key []byte) (*KeyProofResult, error) {
+	result, err := p.config.VerifyNodeSet(p.nodes, p.root[:], key)
+	if err != nil {
+		var perr *ProofError
+		if errors.As(err, &perr) && perr.ExpectedHash != nil {
+			return nil, &NotCoveredError{Key: key, MissingHash: perr.ExpectedHash, KeyPosition: perr.KeyPosition}
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// SubtreeHash returns the hash of the topmost node holding the keys that
+// begin with prefix, which commits to all of them at once: for a contract's
+// storage prefix (mapper.GetAccountStoragePrefixKey), the unitrie equivalent
+// of an Ethereum storage root. ok is false if no key begins with prefix.
+// Only the nodes on the path to prefix are needed.
+func (p *PartialTrie) SubtreeHash(prefix []byte) (hash common.Hash, ok bool, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	bits := TrieKeySliceFromKey(prefix)
+	entry, found := p.nodes.rootNode(p.root[:])
+	if !found {
+		return common.Hash{}, false, &NotCoveredError{Key: prefix, MissingHash: p.root[:]}
+	}
+	node, nodeHash, pos := entry.node, p.root[:], 0
+	for {
+		shared := node.sharedPath
+		for i := 0; i < shared.Length() && pos+i < bits.Length(); i++ {
+			if shared.Get(i) != bits.Get(pos+i) {
+				return common.Hash{}, false, nil
+			}
+		}
+		// The prefix ends at this node or inside its shared path
+		if bits.Length()-pos <= shared.Length() {
+			return common.BytesToHash(nodeHash), true, nil
+		}
+		pos += shared.Length()
+
+		ref := node.left
+		if bits.Get(pos) == 1 {
+			ref = node.right
+		}
+		pos++
+		if ref.IsEmpty() {
+			return common.Hash{}, false, nil
+		}
+		child, found := p.nodes.pathChild(ref, 0)
+		if !found {
+			return common.Hash{}, false, &NotCoveredError{Key: prefix, MissingHash: ref.GetHash(), KeyPosition: pos}
+		}
+		node, nodeHash = child.node, ref.GetHash()
+	}
+}
@@ -0,0 +1,82 @@
+package rsktrie
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestPartialTrie(t *testing.T) {
+	m := NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045e71a7a2c50903d88e564cd72fab11e82051")
+	code := bytes.Repeat([]byte{0x60}, 100)
+
+	trie := NewTrie(nil).
+		Put(m.GetAccountKey(contract), []byte{0x01}).
+		Put(m.GetCodeKey(contract), code)
+	var slots []common.Hash
+	for i := 0; i < 30; i++ {
+		slot := common.BytesToHash([]byte{byte(i)})
+		slots = append(slots, slot)
+		trie = trie.Put(m.GetAccountStorageKey(contract, slot), []byte{byte(i + 1)})
+	}
+	root := common.BytesToHash(trie.GetHash())
+
+	p := NewPartialTrie(root)
+	if _, err := p.Get(m.GetAccountKey(contract)); !errors.Is(err, ErrNotCovered) {
+		t.Fatalf("Expected ErrNotCovered from empty partial trie, got %v", err)
+	}
+
+	covered := [][]byte{
+		m.GetAccountKey(contract),
+		m.GetCodeKey(contract),
+		m.GetAccountStorageKey(contract, slots[3]),
+		m.GetAccountStorageKey(contract, common.HexToHash("0xdead")),
+	}
+	w, err := NewWitness(trie, common.Hash{}, covered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err = NewPartialTrieFromWitness(w)
+	if err != nil {
+		t.Fatalf("NewPartialTrieFromWitness failed: %v", err)
+	}
+
+	if v, err := p.Get(covered[2]); err != nil || !bytes.Equal(v, []byte{4}) {
+		t.Errorf("Expected slot 3 value 04, got %x, %v", v, err)
+	}
+	if v, err := p.Get(covered[3]); err != nil || v != nil {
+		t.Errorf("Expected proven absent slot, got %x, %v", v, err)
+	}
+	if ok, err := p.Has(covered[1]); err != nil || !ok {
+		t.Errorf("Expected code to exist, got %v, %v", ok, err)
+	}
+
+	// Code is committed by hash until its bytes are supplied.
+	var nerr *NotCoveredError
+	if _, err := p.Get(covered[1]); !errors.As(err, &nerr) || !bytes.Equal(nerr.MissingHash, Keccak256(code)) {
+		t.Errorf("Expected code not covered, got %v", err)
+	}
+	p.AddValue(code)
+	if v, err := p.Get(covered[1]); err != nil || !bytes.Equal(v, code) {
+		t.Errorf("Expected code after AddValue, got %d bytes, %v", len(v), err)
+	}
+
+	// A slot off the witnessed paths is not covered until its proof arrives.
+	key := m.GetAccountStorageKey(contract, slots[17])
+	if _, err := p.Get(key); !errors.Is(err, ErrNotCovered) {
+		t.Fatalf("Expected ErrNotCovered for slot 17, got %v", err)
+	}
+	proof, err := trie.GenerateProof(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddProof(proof); err != nil {
+		t.Fatalf("AddProof failed: %v", err)
+	}
+	if v, err := p.Get(key); err != nil || !bytes.Equal(v, []byte{18}) {
+		t.Errorf("Expected slot 17 value 12, got %x, %v", v, err)
+	}
+}
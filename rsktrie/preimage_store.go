@@ -0,0 +1,89 @@
+package rsktrie
+
+import (
+	"fmt"
+	"sync"
+)
+
+// PreimageStore records the inputs behind secure key prefixes (the first
+// SecureKeySize bytes of keccak256(input)), so a trie path that ends inside
+// or right after a hashed prefix can be mapped back to the address or slot
+// that produced it.
+type PreimageStore interface {
+	PutPreimage(securePrefix []byte, preimage []byte) error
+	// Preimage returns (nil, nil) when the prefix is unknown.
+	Preimage(securePrefix []byte) ([]byte, error)
+}
+
+// MemPreimageStore keeps preimages in memory.
+type MemPreimageStore struct {
+	mu        sync.RWMutex
+	preimages map[string][]byte
+}
+
+func NewMemPreimageStore() *MemPreimageStore {
+	return &MemPreimageStore{preimages: make(map[string][]byte)}
+}
+
+func (s *MemPreimageStore) PutPreimage(securePrefix []byte, preimage []byte) error {
+	s.mu.Lock()
+	s.preimages[string(securePrefix)] = copyBytes(preimage)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemPreimageStore) Preimage(securePrefix []byte) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return copyBytes(s.preimages[string(securePrefix)]), nil
+}
+
+// Len returns the number of recorded preimages.
+func (s *MemPreimageStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.preimages)
+}
+
+// preimageKeyPrefix namespaces preimage records so they can share a
+// KeyValueStore with trie nodes, which are keyed by 32-byte hashes.
+var preimageKeyPrefix = []byte("secure-key-preimage-")
+
+// KVPreimageStore persists preimages in a KeyValueStore.
+type KVPreimageStore struct {
+	kv KeyValueStore
+}
+
+func NewKVPreimageStore(kv KeyValueStore) *KVPreimageStore {
+	return &KVPreimageStore{kv: kv}
+}
+
+func (s *KVPreimageStore) PutPreimage(securePrefix []byte, preimage []byte) error {
+	return s.kv.Put(preimageKey(securePrefix), preimage)
+}
+
+func (s *KVPreimageStore) Preimage(securePrefix []byte) ([]byte, error) {
+	return s.kv.Get(preimageKey(securePrefix))
+}
+
+func preimageKey(securePrefix []byte) []byte {
+	key := make([]byte, 0, len(preimageKeyPrefix)+len(securePrefix))
+	key = append(key, preimageKeyPrefix...)
+	return append(key, securePrefix...)
+}
+
+// lookupPreimage returns the recorded preimage for securePrefix after checking
+// that it really hashes to that prefix.
+func lookupPreimage(store PreimageStore, securePrefix []byte) ([]byte, error) {
+	preimage, err := store.Preimage(securePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("read preimage %x: %w", securePrefix, err)
+	}
+	if preimage == nil {
+		return nil, nil
+	}
+	if string(Keccak256(preimage)[:SecureKeySize]) != string(securePrefix) {
+		return nil, fmt.Errorf("stored preimage %x does not match prefix %x", preimage, securePrefix)
+	}
+	return preimage, nil
+}
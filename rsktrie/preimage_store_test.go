@@ -0,0 +1,62 @@
+package rsktrie
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestKeyMapperRecordsPreimages(t *testing.T) {
+	preimages := NewMemPreimageStore()
+	mapper := NewTrieKeyMapperWithPreimages(preimages)
+
+	addr := common.HexToAddress("0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826")
+	slot := common.HexToHash("0x0102")
+	key := mapper.GetAccountStorageKey(addr, slot)
+
+	if preimages.Len() != 2 {
+		t.Fatalf("Expected 2 preimages, got %d", preimages.Len())
+	}
+
+	gotAddr, gotSlot, err := mapper.StorageSlotFromKey(key)
+	if err != nil {
+		t.Fatalf("StorageSlotFromKey failed: %v", err)
+	}
+	if gotAddr != addr || gotSlot != slot {
+		t.Errorf("Expected %x/%x, got %x/%x", addr, slot, gotAddr, gotSlot)
+	}
+}
+
+func TestKeyMapperResolvesTruncatedKeys(t *testing.T) {
+	kv := NewMemKeyValueStore()
+	recorder := NewTrieKeyMapperWithPreimages(NewKVPreimageStore(kv))
+
+	addr := common.HexToAddress("0x0000000000000000000000000000000001000008")
+	slot := common.HexToHash("0xabcdef")
+	key := recorder.GetAccountStorageKey(addr, slot)
+
+	// A fresh mapper reading the same backend, as after a restart.
+	mapper := NewTrieKeyMapperWithPreimages(NewKVPreimageStore(kv))
+
+	// Account key cut right after the secure prefix.
+	gotAddr, err := mapper.AddressFromKey(key[:1+SecureKeySize])
+	if err != nil {
+		t.Fatalf("AddressFromKey failed: %v", err)
+	}
+	if gotAddr != addr {
+		t.Errorf("Expected %x, got %x", addr, gotAddr)
+	}
+
+	// Storage key cut partway through the stripped slot.
+	_, gotSlot, err := mapper.StorageSlotFromKey(key[:len(key)-1])
+	if err != nil {
+		t.Fatalf("StorageSlotFromKey failed: %v", err)
+	}
+	if gotSlot != slot {
+		t.Errorf("Expected slot %x, got %x", slot, gotSlot)
+	}
+
+	if _, err := NewTrieKeyMapper().AddressFromKey(key[:1+SecureKeySize]); err == nil {
+		t.Error("Expected error resolving a truncated key without preimages")
+	}
+}
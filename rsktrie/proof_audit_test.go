@@ -0,0 +1,47 @@
+package rsktrie
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestProofAudit(t *testing.T) {
+	trie := NewTrie(nil)
+	for i := 0; i < 16; i++ {
+		trie = trie.Put([]byte{byte(i * 16), 0x01}, bytes.Repeat([]byte{byte(i)}, 40))
+	}
+	root := trie.GetHash()
+	key, absentKey := []byte{0x20, 0x01}, []byte{0x21, 0x01}
+
+	result, err := VerifyKeyProof(root, key, testProof(t, trie, key))
+	if err != nil || result.Status != ProofPresent {
+		t.Fatalf("Expected present, got %+v, %v", result, err)
+	}
+	audit := result.Audit
+	if audit == nil || len(audit.NodeHashes) == 0 {
+		t.Fatalf("Expected an audit trail, got %+v", audit)
+	}
+	if !bytes.Equal(audit.NodeHashes[0], root) {
+		t.Errorf("Expected the trail to start at the root, got %x", audit.NodeHashes[0])
+	}
+	if audit.ResidualBits != "" {
+		t.Errorf("Expected no residual bits, got %q", audit.ResidualBits)
+	}
+	if want := Keccak256(trie.Get(key)); !bytes.Equal(audit.TerminalValueHash, want) {
+		t.Errorf("Expected terminal value hash %x, got %x", want, audit.TerminalValueHash)
+	}
+
+	result, err = VerifyKeyProof(root, absentKey, testProof(t, trie, absentKey))
+	if err != nil || result.Status != ProofProvenAbsent {
+		t.Fatalf("Expected absent, got %+v, %v", result, err)
+	}
+	audit = result.Audit
+	full := FormatBits(TrieKeySliceFromKey(absentKey))
+	if audit.ResidualBits == "" || !strings.HasSuffix(full, audit.ResidualBits) {
+		t.Errorf("Expected a tail of %s, got %q", full, audit.ResidualBits)
+	}
+	if !bytes.Equal(audit.NodeHashes[0], root) {
+		t.Errorf("Expected the trail to start at the root, got %x", audit.NodeHashes[0])
+	}
+}
@@ -0,0 +1,226 @@
+package rsktrie
+
+import (
+	"context"
+	"fmt"
+)
+
+// ProofLimits caps the resources verifying a single proof may use, so a
+// hostile RPC response cannot make the verifier allocate or walk without
+// bound. Limits are checked before any node is decoded. A zero field means
+// no limit.
+type ProofLimits struct {
+	// MaxProofBytes caps the total size of the RLP-encoded proof nodes.
+	MaxProofBytes int
+	// MaxNodes caps the number of proof nodes.
+	MaxNodes int
+	// MaxDepth caps the number of nodes traversed from the root.
+	MaxDepth int
+}
+
+// DefaultProofLimits are generous for any real unitrie proof: the longest
+// keys (storage keys) are under 600 bits, and nodes are a few hundred bytes
+// at most since long values are only referenced by hash.
+var DefaultProofLimits = ProofLimits{
+	MaxProofBytes: 1 << 20,
+	MaxNodes:      1024,
+	MaxDepth:      1024,
+}
+
+// check rejects proofs exceeding the size limits.
+func (l ProofLimits) check(key []byte, proofNodes [][]byte) error {
+	if l.MaxNodes > 0 && len(proofNodes) > l.MaxNodes {
+		return &ProofError{
+			Reason:    fmt.Sprintf("proof has %d nodes, limit is %d", len(proofNodes), l.MaxNodes),
+			Key:       key,
+			NodeIndex: l.MaxNodes,
+		}
+	}
+	if l.MaxProofBytes > 0 {
+		total := 0
+		for i, node := range proofNodes {
+			total += len(node)
+			if total > l.MaxProofBytes {
+				return &ProofError{
+					Reason:    fmt.Sprintf("proof exceeds %d bytes", l.MaxProofBytes),
+					Key:       key,
+					NodeIndex: i,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// NodeFormat is the serialization format proof nodes must be in.
+type NodeFormat int
+
+const (
+	// NodeFormatAny accepts either format, node by node.
+	NodeFormatAny NodeFormat = iota
+	// NodeFormatRSKIP107 is the unitrie node format, in use since orchid.
+	NodeFormatRSKIP107
+	// NodeFormatOrchid is the pre-unitrie node format. Orchid nodes have no
+	// embedded children and commit to values over 32 bytes (which includes
+	// every account record) by hash only, without their length.
+	NodeFormatOrchid
+)
+
+func (f NodeFormat) String() string {
+	switch f {
+	case NodeFormatRSKIP107:
+		return "rskip107"
+	case NodeFormatOrchid:
+		return "orchid"
+	default:
+		return "any"
+	}
+}
+
+// NodeFormatFor returns the node format of state using key scheme version.
+func NodeFormatFor(version KeyMapperVersion) NodeFormat {
+	if version == KeyMapperOrchid {
+		return NodeFormatOrchid
+	}
+	return NodeFormatRSKIP107
+}
+
+// check rejects a node in the wrong format.
+func (f NodeFormat) check(key []byte, entry proofNode) error {
+	if f == NodeFormatAny || entry.orchid == (f == NodeFormatOrchid) {
+		return nil
+	}
+	return &ProofError{
+		Reason:       fmt.Sprintf("proof node is not in %s format", f),
+		Key:          key,
+		NodeIndex:    entry.index,
+		ComputedHash: entry.hash,
+	}
+}
+
+// ProofConfig configures key proof verification. The zero value verifies as
+// VerifyKeyProof does.
+type ProofConfig struct {
+	// Strict rejects duplicate nodes and nodes off the key's path.
+	Strict bool
+	Limits ProofLimits
+	// Order is the expected node order. A known order verifies without
+	// indexing the nodes by hash, and rejects proofs not in that order.
+	Order ProofOrder
+	// Format is the node format every proof node must be in, to pin
+	// verification to one era of the trie.
+	Format NodeFormat
+	// Hash, if set, hashes serialized proof nodes in place of Keccak256.
+	// Embedded nodes are still resolved from their parent.
+	Hash func([]byte) []byte
+	// DecodeWorkers caps the goroutines decoding a proof's nodes: 0 means
+	// GOMAXPROCS, 1 decodes serially. Short proofs are always decoded
+	// serially.
+	DecodeWorkers int
+}
+
+func (c ProofConfig) hash() func([]byte) []byte {
+	if c.Hash != nil {
+		return c.Hash
+	}
+	return Keccak256
+}
+
+// VerifyKeyProof verifies a key proof as the package-level VerifyKeyProof
+// does, applying c's limits and strictness.
+func (c ProofConfig) VerifyKeyProof(expectedHash []byte, key []byte, proofNodes [][]byte) (*KeyProofResult, error) {
+	return c.VerifyKeyProofContext(context.Background(), expectedHash, key, proofNodes)
+}
+
+// VerifyKeyProofContext is VerifyKeyProof, abandoned with ctx.Err() once ctx
+// is done. ctx is checked between decoding, walking and the strict checks;
+// each of those is bounded by c's limits.
+func (c ProofConfig) VerifyKeyProofContext(ctx context.Context, expectedHash []byte, key []byte, proofNodes [][]byte) (*KeyProofResult, error) {
+	invalid := &KeyProofResult{Status: ProofInvalid}
+	if err := ctx.Err(); err != nil {
+		return invalid, err
+	}
+	if err := c.Limits.check(key, proofNodes); err != nil {
+		return invalid, err
+	}
+	order := c.Order
+	if order == ProofOrderAuto {
+		order, _ = detectProofOrder(expectedHash, proofNodes, c.hash())
+	}
+	var nodes *ProofNodeSet
+	var err error
+	if order == ProofOrderAny {
+		nodes, err = decodeProofNodes(key, proofNodes, c.hash(), c.DecodeWorkers)
+	} else {
+		nodes, err = decodeOrderedProofNodes(key, proofNodes, order, c.hash(), c.DecodeWorkers)
+	}
+	if err != nil {
+		return invalid, err
+	}
+	for _, entry := range nodes.entries() {
+		if err := c.Format.check(key, entry); err != nil {
+			return invalid, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return invalid, err
+	}
+	result, traversal, err := nodes.walk(expectedHash, key, c.Limits.MaxDepth)
+	if err != nil || !c.Strict {
+		return result, err
+	}
+	if err := ctx.Err(); err != nil {
+		return invalid, err
+	}
+
+	if len(nodes.duplicates) > 0 {
+		return invalid, &ProofError{
+			Reason:    "duplicate proof node",
+			Key:       key,
+			NodeIndex: nodes.duplicates[0],
+			Traversal: traversal,
+		}
+	}
+	used := make(map[int]bool, len(traversal))
+	for _, step := range traversal {
+		used[step.NodeIndex] = true
+	}
+	for _, entry := range nodes.entries() {
+		if !used[entry.index] {
+			return invalid, &ProofError{
+				Reason:       "proof node not on the key's path",
+				Key:          key,
+				NodeIndex:    entry.index,
+				ComputedHash: entry.hash,
+				Traversal:    traversal,
+			}
+		}
+	}
+	return result, nil
+}
+
+// DecodeProofNodes decodes proofNodes into a set that can verify many keys
+// (see VerifyNodeSet), applying c's size limits and node format. Order and
+// Strict do not apply to a set shared between keys.
+func (c ProofConfig) DecodeProofNodes(proofNodes [][]byte) (*ProofNodeSet, error) {
+	if err := c.Limits.check(nil, proofNodes); err != nil {
+		return nil, err
+	}
+	set, err := decodeProofNodes(nil, proofNodes, c.hash(), c.DecodeWorkers)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range set.entries() {
+		if err := c.Format.check(nil, entry); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// VerifyNodeSet verifies key against set as ProofNodeSet.Verify does,
+// applying c's depth limit.
+func (c ProofConfig) VerifyNodeSet(set *ProofNodeSet, expectedHash []byte, key []byte) (*KeyProofResult, error) {
+	result, _, err := set.walk(expectedHash, key, c.Limits.MaxDepth)
+	return result, err
+}
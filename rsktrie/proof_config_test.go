@@ -0,0 +1,122 @@
+package rsktrie
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestProofLimits(t *testing.T) {
+	trie := NewTrie(nil)
+	for i := 0; i < 64; i++ {
+		trie = trie.Put([]byte{byte(i * 4), 0x01}, []byte{byte(i + 1)})
+	}
+	root := trie.GetHash()
+	key := []byte{0x00, 0x01}
+	proof := testProof(t, trie, key)
+
+	size := 0
+	for _, node := range proof {
+		size += len(node)
+	}
+
+	tests := []struct {
+		name   string
+		limits ProofLimits
+		ok     bool
+	}{
+		{"no limits", ProofLimits{}, true},
+		{"defaults", DefaultProofLimits, true},
+		{"exact", ProofLimits{MaxProofBytes: size, MaxNodes: len(proof), MaxDepth: len(proof)}, true},
+		{"too many bytes", ProofLimits{MaxProofBytes: size - 1}, false},
+		{"too many nodes", ProofLimits{MaxNodes: len(proof) - 1}, false},
+		{"too deep", ProofLimits{MaxDepth: len(proof) - 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ProofConfig{Limits: tt.limits}.VerifyKeyProof(root, key, proof)
+			if tt.ok {
+				if err != nil || result.Status != ProofPresent {
+					t.Errorf("Expected present, got %s, %v", result.Status, err)
+				}
+				return
+			}
+			var perr *ProofError
+			if !errors.As(err, &perr) || result.Status != ProofInvalid {
+				t.Errorf("Expected *ProofError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestProofLimitsSharedSets(t *testing.T) {
+	trie := NewTrie(nil)
+	for i := 0; i < 64; i++ {
+		trie = trie.Put([]byte{byte(i * 4), 0x01}, []byte{byte(i + 1)})
+	}
+	root := trie.GetHash()
+	keys := [][]byte{{0x00, 0x01}, {0x80, 0x01}}
+	proof := MergeProofNodes(testProof(t, trie, keys[0]), testProof(t, trie, keys[1]))
+	rangeProof, _, err := trie.GenerateRangeProof(nil, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, limits := range []ProofLimits{
+		{MaxNodes: 2},
+		{MaxProofBytes: 64},
+		{MaxDepth: 2},
+	} {
+		config := ProofConfig{Limits: limits}
+		var perr *ProofError
+		if _, err := config.VerifyMultiproof(root, keys, proof); !errors.As(err, &perr) {
+			t.Errorf("VerifyMultiproof with %+v: expected *ProofError, got %v", limits, err)
+		}
+		if _, err := config.VerifyRangeProof(root, nil, nil, rangeProof); !errors.As(err, &perr) {
+			t.Errorf("VerifyRangeProof with %+v: expected *ProofError, got %v", limits, err)
+		}
+		p := NewPartialTrieWithConfig(common.BytesToHash(root), config)
+		if err := p.AddProof(proof); err == nil {
+			_, err = p.Get(keys[0])
+			if !errors.As(err, &perr) {
+				t.Errorf("PartialTrie with %+v: expected *ProofError, got %v", limits, err)
+			}
+		}
+	}
+}
+
+func TestProofConfigDecodeWorkers(t *testing.T) {
+	trie := NewTrie(nil)
+	for i := 0; i < 256; i++ {
+		trie = trie.Put([]byte{byte(i), 0x01, 0x02}, bytes.Repeat([]byte{byte(i)}, 20))
+	}
+	root := trie.GetHash()
+	key := []byte{0x5a, 0x01, 0x02}
+	proof := testProof(t, trie, key)
+	if len(proof) < parallelDecodeMin {
+		t.Fatalf("Expected at least %d nodes, got %d", parallelDecodeMin, len(proof))
+	}
+	want := trie.Get(key)
+
+	for _, workers := range []int{0, 1, 3} {
+		c := ProofConfig{DecodeWorkers: workers}
+		result, err := c.VerifyKeyProof(root, key, proof)
+		if err != nil || !bytes.Equal(result.Value, want) {
+			t.Errorf("workers %d: expected %x, got %+v, %v", workers, want, result, err)
+		}
+		result, err = ProofConfig{DecodeWorkers: workers, Order: ProofOrderLeafToRoot}.VerifyKeyProof(root, key, proof)
+		if err != nil || !bytes.Equal(result.Value, want) {
+			t.Errorf("workers %d, ordered: expected %x, got %+v, %v", workers, want, result, err)
+		}
+
+		// The lowest failing node is reported, whichever worker decodes first
+		bad := append([][]byte{}, proof...)
+		bad[2], bad[5] = []byte{0xff}, []byte{0xff}
+		var perr *ProofError
+		if _, err := c.VerifyKeyProof(root, key, bad); !errors.As(err, &perr) || perr.NodeIndex != 2 {
+			t.Errorf("workers %d: expected a failure at node 2, got %v", workers, err)
+		}
+	}
+}
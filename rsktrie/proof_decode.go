@@ -0,0 +1,60 @@
+package rsktrie
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parallelDecodeMin is the smallest proof decoded concurrently; below it the
+// goroutine handoff costs more than the parsing it spreads out.
+const parallelDecodeMin = 8
+
+// decodeProofNodeList decodes and hashes proofNodes on up to workers
+// goroutines (GOMAXPROCS if workers <= 0), returning them in proof order.
+// On failure it returns the error of the lowest failing index, as a serial
+// decode would.
+func decodeProofNodeList(key []byte, proofNodes [][]byte, hash func([]byte) []byte, workers int) ([]proofNode, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(proofNodes) {
+		workers = len(proofNodes)
+	}
+
+	entries := make([]proofNode, len(proofNodes))
+	if workers <= 1 || len(proofNodes) < parallelDecodeMin {
+		for i, rlpNode := range proofNodes {
+			entry, err := decodeProofNode(key, i, rlpNode, hash)
+			if err != nil {
+				return nil, err
+			}
+			entries[i] = entry
+		}
+		return entries, nil
+	}
+
+	errs := make([]error, len(proofNodes))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				entries[i], errs[i] = decodeProofNode(key, i, proofNodes[i], hash)
+			}
+		}()
+	}
+	for i := range proofNodes {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
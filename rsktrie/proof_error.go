@@ -0,0 +1,66 @@
+package rsktrie
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProofStep is one node visited while walking a proof.
+type ProofStep struct {
+	// NodeIndex is the node's position in the proof, or -1 for a node
+	// embedded in its parent.
+	NodeIndex int
+	Hash      []byte
+	// KeyPosition is the number of key bits consumed on reaching the node.
+	KeyPosition int
+	SharedPath  string
+}
+
+// ProofError describes why a proof failed to verify. VerifyKeyProof and the
+// verifiers built on it return it for every invalid proof; use errors.As to
+// inspect it.
+type ProofError struct {
+	Reason string
+	Key    []byte
+	// NodeIndex is the position in the proof of the node at fault, or -1 if
+	// the failure is about a node that is missing.
+	NodeIndex int
+	// ExpectedHash is the hash the walk needed: the root, or a child hash
+	// referenced by the last visited node.
+	ExpectedHash []byte
+	// ComputedHash is the hash of the node at NodeIndex, when there is one.
+	// For a root mismatch it is the hash of the proof's last node, which
+	// eth_getProof orders as the root.
+	ComputedHash []byte
+	// KeyPosition is the number of key bits consumed when the walk stopped.
+	KeyPosition int
+	Traversal   []ProofStep
+	// Err is the underlying decode error, if any.
+	Err error
+}
+
+func (e *ProofError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Reason)
+	if e.Err != nil {
+		fmt.Fprintf(&b, ": %v", e.Err)
+	}
+	if e.NodeIndex >= 0 {
+		fmt.Fprintf(&b, " (node %d", e.NodeIndex)
+		if e.ComputedHash != nil {
+			fmt.Fprintf(&b, " hash %x", e.ComputedHash)
+		}
+		b.WriteString(")")
+	}
+	if e.ExpectedHash != nil {
+		fmt.Fprintf(&b, ", expected hash %x", e.ExpectedHash)
+	}
+	if len(e.Traversal) > 0 {
+		fmt.Fprintf(&b, ", after %d nodes at %s", len(e.Traversal), DescribeKeyBit(e.Key, e.KeyPosition))
+	}
+	return b.String()
+}
+
+func (e *ProofError) Unwrap() error {
+	return e.Err
+}
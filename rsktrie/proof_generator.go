@@ -0,0 +1,104 @@
+package rsktrie
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// GenerateProof returns the proof for key in the format of rskj's
+// eth_getProof: every node on the path to key, leaf to root, each as the RLP
+// string of its serialized message. If key is absent the path stops where it
+// leaves the trie, which VerifyKeyProof accepts as proof of absence.
+func (t *Trie) GenerateProof(key []byte) ([][]byte, error) {
+	path, err := t.proofPath(key)
+	if err != nil {
+		return nil, err
+	}
+	proof := make([][]byte, len(path))
+	for i, node := range path {
+		enc, err := encodeProofNode(node)
+		if err != nil {
+			return nil, err
+		}
+		proof[len(path)-1-i] = enc
+	}
+	return proof, nil
+}
+
+// GenerateMultiproof returns one deduplicated node set proving every key, for
+// VerifyMultiproof. Nodes shared by several paths are included once, and the
+// root is last, as in GenerateProof.
+func (t *Trie) GenerateMultiproof(keys [][]byte) ([][]byte, error) {
+	seen := make(map[string]struct{})
+	var proof [][]byte
+	for _, key := range keys {
+		path, err := t.proofPath(key)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range path {
+			hash := string(node.GetHash())
+			if _, ok := seen[hash]; ok {
+				continue
+			}
+			seen[hash] = struct{}{}
+			enc, err := encodeProofNode(node)
+			if err != nil {
+				return nil, err
+			}
+			proof = append(proof, enc)
+		}
+	}
+
+	// The root was the first node added; move it last.
+	if len(proof) > 1 {
+		proof = append(proof[1:], proof[0])
+	}
+	return proof, nil
+}
+
+// proofPath returns the nodes from t down to key, or to where key leaves the
+// trie.
+func (t *Trie) proofPath(key []byte) ([]*Trie, error) {
+	keySlice := TrieKeySliceFromKey(key)
+	var path []*Trie
+	pos := 0
+	for node := t; node != nil; {
+		path = append(path, node)
+
+		// Stop where the key ends or diverges from the shared path.
+		shared := node.sharedPath
+		if keySlice.Length()-pos < shared.Length() {
+			break
+		}
+		if keySlice.Slice(pos, pos+shared.Length()).CommonPath(shared).Length() < shared.Length() {
+			break
+		}
+		pos += shared.Length()
+		if pos >= keySlice.Length() {
+			break
+		}
+
+		ref := node.left
+		if keySlice.Get(pos) == 1 {
+			ref = node.right
+		}
+		pos++
+		if ref.IsEmpty() {
+			break
+		}
+		if node = ref.GetNode(); node == nil {
+			return nil, fmt.Errorf("missing node %x", ref.GetHash())
+		}
+	}
+	return path, nil
+}
+
+func encodeProofNode(node *Trie) ([]byte, error) {
+	enc, err := rlp.EncodeToBytes(node.ToMessage())
+	if err != nil {
+		return nil, fmt.Errorf("encode node %x: %w", node.GetHash(), err)
+	}
+	return enc, nil
+}
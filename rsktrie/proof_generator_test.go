@@ -0,0 +1,107 @@
+package rsktrie
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestGenerateProof(t *testing.T) {
+	m := NewTrieKeyMapper()
+	store := NewMemTrieStore()
+	trie := NewTrie(store)
+	var addrs []common.Address
+	for i := 0; i < 100; i++ {
+		addr := common.BigToAddress(new(big.Int).Lsh(big.NewInt(1), uint(i)))
+		addrs = append(addrs, addr)
+		trie = trie.Put(m.GetAccountKey(addr), bytes.Repeat([]byte{byte(i)}, i%40+1))
+	}
+	if err := trie.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Generate from a trie loaded back from the store, as a server would.
+	loaded := store.Retrieve(trie.GetHash())
+	if loaded == nil {
+		t.Fatal("Failed to retrieve saved root")
+	}
+	for i, addr := range addrs {
+		key := m.GetAccountKey(addr)
+		proof, err := loaded.GenerateProof(key)
+		if err != nil {
+			t.Fatalf("GenerateProof failed: %v", err)
+		}
+		result, err := VerifyKeyProof(trie.GetHash(), key, proof)
+		if err != nil || result.Status != ProofPresent {
+			t.Fatalf("Account %d: expected present, got %v", i, err)
+		}
+		if !result.Matches(bytes.Repeat([]byte{byte(i)}, i%40+1)) {
+			t.Errorf("Account %d: value mismatch", i)
+		}
+	}
+
+	missing := m.GetAccountKey(common.HexToAddress("0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826"))
+	proof, err := loaded.GenerateProof(missing)
+	if err != nil {
+		t.Fatalf("GenerateProof failed: %v", err)
+	}
+	result, err := VerifyKeyProof(trie.GetHash(), missing, proof)
+	if err != nil || result.Status != ProofProvenAbsent {
+		t.Errorf("Expected proven absent, got %v", err)
+	}
+
+	// The root is last, as in eth_getProof.
+	var serialized []byte
+	if err := rlp.DecodeBytes(proof[len(proof)-1], &serialized); err != nil || !bytes.Equal(Keccak256(serialized), trie.GetHash()) {
+		t.Errorf("Expected last proof node to be the root, %v", err)
+	}
+}
+
+func TestGenerateMultiproof(t *testing.T) {
+	trie := NewTrie(nil)
+	var keys [][]byte
+	for i := 0; i < 64; i++ {
+		key := []byte{0x42, byte(i * 4), 0x01}
+		keys = append(keys, key)
+		trie = trie.Put(key, bytes.Repeat([]byte{byte(i + 1)}, 40))
+	}
+	keys = append(keys, []byte{0x42, 0x02, 0x01})
+	root := trie.GetHash()
+
+	proof, err := trie.GenerateMultiproof(keys)
+	if err != nil {
+		t.Fatalf("GenerateMultiproof failed: %v", err)
+	}
+	var separate [][][]byte
+	for _, key := range keys {
+		p, err := trie.GenerateProof(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		separate = append(separate, p)
+	}
+	if merged := MergeProofNodes(separate...); len(proof) != len(merged) {
+		t.Errorf("Expected %d deduplicated nodes, got %d", len(merged), len(proof))
+	}
+
+	results, err := VerifyMultiproof(root, keys, proof)
+	if err != nil {
+		t.Fatalf("VerifyMultiproof failed: %v", err)
+	}
+	for i, r := range results[:64] {
+		if r.Status != ProofPresent || !r.Matches(bytes.Repeat([]byte{byte(i + 1)}, 40)) {
+			t.Errorf("Key %d: unexpected result %s", i, r.Status)
+		}
+	}
+	if results[64].Status != ProofProvenAbsent {
+		t.Errorf("Expected missing key proven absent, got %s", results[64].Status)
+	}
+
+	var serialized []byte
+	if err := rlp.DecodeBytes(proof[len(proof)-1], &serialized); err != nil || !bytes.Equal(Keccak256(serialized), root) {
+		t.Errorf("Expected last node to be the root, %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package rsktrie
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ProofOrder is the order of the nodes in a proof.
+type ProofOrder int
+
+const (
+	// ProofOrderAny accepts nodes in any order, looking them up by hash.
+	ProofOrderAny ProofOrder = iota
+	// ProofOrderLeafToRoot is the path from the key's node up to the root,
+	// as RSKj's eth_getProof returns it.
+	ProofOrderLeafToRoot
+	// ProofOrderRootToLeaf is the path from the root down to the key's node.
+	ProofOrderRootToLeaf
+	// ProofOrderAuto detects the order with DetectProofOrder, falling back
+	// to ProofOrderAny if it cannot.
+	ProofOrderAuto
+)
+
+func (o ProofOrder) String() string {
+	switch o {
+	case ProofOrderLeafToRoot:
+		return "leaf-to-root"
+	case ProofOrderRootToLeaf:
+		return "root-to-leaf"
+	case ProofOrderAuto:
+		return "auto"
+	default:
+		return "any"
+	}
+}
+
+// DetectProofOrder reports whether proofNodes run leaf to root or root to
+// leaf, by finding the node hashing to expectedHash at either end. A
+// single-node proof is reported as leaf to root. It returns an error if the
+// root is at neither end, which a path proof never does.
+//
+// Detection only hashes the two end nodes; the ordered verification that
+// follows checks that the nodes in between form the path.
+func DetectProofOrder(expectedHash []byte, proofNodes [][]byte) (ProofOrder, error) {
+	return detectProofOrder(expectedHash, proofNodes, Keccak256)
+}
+
+func detectProofOrder(expectedHash []byte, proofNodes [][]byte, hash func([]byte) []byte) (ProofOrder, error) {
+	if len(proofNodes) == 0 {
+		return ProofOrderAny, fmt.Errorf("empty proof")
+	}
+	endHash := func(rlpNode []byte) []byte {
+		var serialized []byte
+		if err := rlp.DecodeBytes(rlpNode, &serialized); err != nil {
+			return nil
+		}
+		return hash(serialized)
+	}
+	if bytes.Equal(endHash(proofNodes[len(proofNodes)-1]), expectedHash) {
+		return ProofOrderLeafToRoot, nil
+	}
+	if bytes.Equal(endHash(proofNodes[0]), expectedHash) {
+		return ProofOrderRootToLeaf, nil
+	}
+	return ProofOrderAny, fmt.Errorf("root %x is at neither end of the proof", expectedHash)
+}
+
+// decodeOrderedProofNodes decodes a path proof in a known order into a set
+// that is walked by position rather than by hash, avoiding the hash index.
+// Each child on the path must be the next node (embedded children aside).
+func decodeOrderedProofNodes(key []byte, proofNodes [][]byte, order ProofOrder, hash func([]byte) []byte, workers int) (*ProofNodeSet, error) {
+	if len(proofNodes) == 0 {
+		return nil, &ProofError{Reason: "empty proof", Key: key, NodeIndex: -1}
+	}
+	decoded, err := decodeProofNodeList(key, proofNodes, hash, workers)
+	if err != nil {
+		return nil, err
+	}
+	set := &ProofNodeSet{ordered: make([]proofNode, len(proofNodes))}
+	for i, entry := range decoded {
+		pos := i
+		if order == ProofOrderLeafToRoot {
+			pos = len(proofNodes) - 1 - i
+		}
+		set.ordered[pos] = entry
+	}
+	set.lastHash, set.lastIndex = set.ordered[0].hash, set.ordered[0].index
+	return set, nil
+}
@@ -0,0 +1,77 @@
+package rsktrie
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func reversed(nodes [][]byte) [][]byte {
+	out := make([][]byte, len(nodes))
+	for i, n := range nodes {
+		out[len(nodes)-1-i] = n
+	}
+	return out
+}
+
+func TestProofOrder(t *testing.T) {
+	trie := NewTrie(nil)
+	for i := 0; i < 64; i++ {
+		trie = trie.Put([]byte{byte(i * 4), 0x01}, bytes.Repeat([]byte{byte(i)}, 20))
+	}
+	root := trie.GetHash()
+	key, absentKey := []byte{0x10, 0x01}, []byte{0x11, 0x01}
+	leafToRoot := testProof(t, trie, key)
+	rootToLeaf := reversed(leafToRoot)
+	if len(leafToRoot) < 3 {
+		t.Fatalf("Expected a deeper proof, got %d nodes", len(leafToRoot))
+	}
+
+	if order, err := DetectProofOrder(root, leafToRoot); err != nil || order != ProofOrderLeafToRoot {
+		t.Errorf("Expected leaf-to-root, got %s, %v", order, err)
+	}
+	if order, err := DetectProofOrder(root, rootToLeaf); err != nil || order != ProofOrderRootToLeaf {
+		t.Errorf("Expected root-to-leaf, got %s, %v", order, err)
+	}
+	shuffled := append([][]byte{leafToRoot[1], leafToRoot[len(leafToRoot)-1]}, leafToRoot[2:len(leafToRoot)-1]...)
+	shuffled = append(shuffled, leafToRoot[0])
+	if _, err := DetectProofOrder(root, shuffled); err == nil {
+		t.Error("Expected detection to fail with the root in the middle")
+	}
+
+	want := trie.Get(key)
+	for _, tt := range []struct {
+		order ProofOrder
+		proof [][]byte
+	}{
+		{ProofOrderLeafToRoot, leafToRoot},
+		{ProofOrderRootToLeaf, rootToLeaf},
+		{ProofOrderAuto, leafToRoot},
+		{ProofOrderAuto, rootToLeaf},
+		{ProofOrderAuto, shuffled},
+		{ProofOrderAny, shuffled},
+	} {
+		c := ProofConfig{Order: tt.order, Strict: true}
+		result, err := c.VerifyKeyProof(root, key, tt.proof)
+		if err != nil || !bytes.Equal(result.Value, want) {
+			t.Errorf("%s: expected %x, got %+v, %v", tt.order, want, result, err)
+		}
+	}
+
+	absent, err := ProofConfig{Order: ProofOrderLeafToRoot}.VerifyKeyProof(root, absentKey, testProof(t, trie, absentKey))
+	if err != nil || absent.Status != ProofProvenAbsent {
+		t.Errorf("Expected proven absent, got %+v, %v", absent, err)
+	}
+
+	// A proof in the wrong declared order, or with its middle shuffled,
+	// fails instead of silently falling back to a hash lookup.
+	var perr *ProofError
+	if _, err := (ProofConfig{Order: ProofOrderRootToLeaf}).VerifyKeyProof(root, key, leafToRoot); !errors.As(err, &perr) {
+		t.Errorf("Expected *ProofError for wrong order, got %v", err)
+	}
+	swapped := append([][]byte{}, leafToRoot...)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+	if _, err := (ProofConfig{Order: ProofOrderLeafToRoot}).VerifyKeyProof(root, key, swapped); !errors.As(err, &perr) {
+		t.Errorf("Expected *ProofError for swapped nodes, got %v", err)
+	}
+}
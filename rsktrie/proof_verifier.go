@@ -3,6 +3,7 @@ package rsktrie
 import (
 	"bytes"
 	"fmt"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -11,26 +12,61 @@ import (
 // ProofVerifier verifies Merkle proofs from eth_getProof for RSK's binary trie
 type ProofVerifier struct {
 	keyMapper *TrieKeyMapper
+	config    ProofConfig
 }
 
-// NewProofVerifier creates a new proof verifier
+// NewProofVerifier creates a new proof verifier with DefaultProofLimits
 func NewProofVerifier() *ProofVerifier {
 	return &ProofVerifier{
 		keyMapper: NewTrieKeyMapper(),
+		config:    ProofConfig{Limits: DefaultProofLimits},
 	}
 }
 
+// SetStrict enables strict mode, in which proofs with duplicate nodes or
+// nodes off the key's path are invalid (see VerifyKeyProofStrict).
+func (v *ProofVerifier) SetStrict(strict bool) {
+	v.config.Strict = strict
+}
+
+// SetLimits sets the resource limits applied to each proof.
+func (v *ProofVerifier) SetLimits(limits ProofLimits) {
+	v.config.Limits = limits
+}
+
+// SetProofOrder sets the node order proofs are expected in. With a known
+// order (or ProofOrderAuto) proofs are walked by position, without
+// indexing their nodes by hash, and out-of-order proofs are invalid.
+func (v *ProofVerifier) SetProofOrder(order ProofOrder) {
+	v.config.Order = order
+}
+
+// verifyKey verifies a key proof with the verifier's configuration.
+func (v *ProofVerifier) verifyKey(stateRoot common.Hash, key []byte, proofNodes [][]byte) (*KeyProofResult, error) {
+	return v.config.VerifyKeyProof(stateRoot[:], key, proofNodes)
+}
+
 // AccountProofResult contains the result of account proof verification
 type AccountProofResult struct {
-	Valid   bool
+	Valid   bool // Status != ProofInvalid
+	Status  ProofStatus
 	Address common.Address
-	Value   []byte // RLP-encoded account state
+	// Exists is true when the account is proven present; an empty account
+	// still exists. It is false for a proven-absent account and for an
+	// invalid proof, which Valid tells apart.
+	Exists bool
+	Value  []byte // RLP-encoded account state
+	// Nonce and Balance are decoded from Value; both are zero for an absent
+	// account and nil for an invalid proof.
+	Nonce   *big.Int
+	Balance *big.Int
 	Error   error
 }
 
 // StorageProofResult contains the result of storage proof verification
 type StorageProofResult struct {
-	Valid      bool
+	Valid      bool // Status != ProofInvalid
+	Status     ProofStatus
 	StorageKey common.Hash
 	Value      []byte
 	Error      error
@@ -48,20 +84,35 @@ func (v *ProofVerifier) VerifyAccountProof(
 	trieKey := v.keyMapper.GetAccountKey(address)
 
 	// Verify the proof path
-	value, err := v.verifyProof(stateRoot[:], trieKey, proofNodes)
+	result, err := v.verifyKey(stateRoot, trieKey, proofNodes)
 	if err != nil {
 		return &AccountProofResult{
 			Valid:   false,
+			Status:  ProofInvalid,
 			Address: address,
 			Error:   err,
 		}, nil
 	}
 
+	return accountProofResult(address, result), nil
+}
+
+// accountProofResult builds the result for a verified account key, decoding
+// the account record. A record that does not decode makes the proof invalid.
+func accountProofResult(address common.Address, result *KeyProofResult) *AccountProofResult {
+	state, err := result.AccountState()
+	if err != nil {
+		return &AccountProofResult{Status: ProofInvalid, Address: address, Value: result.Value, Error: err}
+	}
 	return &AccountProofResult{
 		Valid:   true,
+		Status:  result.Status,
 		Address: address,
-		Value:   value,
-	}, nil
+		Exists:  result.Status == ProofPresent,
+		Value:   result.Value,
+		Nonce:   state.Nonce,
+		Balance: state.Balance,
+	}
 }
 
 // VerifyStorageProof verifies a storage proof for a contract
@@ -78,10 +129,11 @@ func (v *ProofVerifier) VerifyStorageProof(
 	trieKey := v.keyMapper.GetAccountStorageKey(address, storageKey)
 
 	// Verify the proof path
-	value, err := v.verifyProof(stateRoot[:], trieKey, proofNodes)
+	result, err := v.verifyKey(stateRoot, trieKey, proofNodes)
 	if err != nil {
 		return &StorageProofResult{
 			Valid:      false,
+			Status:     ProofInvalid,
 			StorageKey: storageKey,
 			Error:      err,
 		}, nil
@@ -89,120 +141,411 @@ func (v *ProofVerifier) VerifyStorageProof(
 
 	return &StorageProofResult{
 		Valid:      true,
+		Status:     result.Status,
 		StorageKey: storageKey,
-		Value:      value,
+		Value:      result.Value,
 	}, nil
 }
 
-// verifyProof walks through the proof nodes and verifies the path
-func (v *ProofVerifier) verifyProof(expectedHash []byte, key []byte, proofNodes [][]byte) ([]byte, error) {
-	if len(proofNodes) == 0 {
-		return nil, fmt.Errorf("empty proof")
+// ProofStatus is what a proof establishes about a key.
+type ProofStatus int
+
+const (
+	// ProofInvalid means the proof does not verify against the root, or is
+	// incomplete, so nothing is known about the key.
+	ProofInvalid ProofStatus = iota
+	// ProofPresent means the key exists in the trie.
+	ProofPresent
+	// ProofProvenAbsent means the proof shows the key does not exist: its
+	// path leaves the trie at an empty child or a diverging shared path, or
+	// ends at a node that holds no value.
+	ProofProvenAbsent
+)
+
+func (s ProofStatus) String() string {
+	switch s {
+	case ProofPresent:
+		return "present"
+	case ProofProvenAbsent:
+		return "proven-absent"
+	default:
+		return "invalid"
 	}
+}
 
-	// RSK proof nodes are RLP-encoded. The hash is Keccak256 of the serialized (not RLP) content.
-	// Proof order is leaf-to-root (last node is root).
-	// Build map using hash of the RLP-decoded (serialized) content
+// KeyProofResult is the outcome of verifying a proof for one key.
+type KeyProofResult struct {
+	Status ProofStatus
+	// Value is the key's value if present. Long values (over 32 bytes, e.g.
+	// code) are committed to by hash only, so Value is nil for them unless a
+	// proof node embeds the bytes.
+	Value     []byte
+	ValueHash []byte
+	// ValueLength is the value's length, or -1 for an Orchid long value,
+	// whose node does not record it.
+	ValueLength int
+	// Audit records how the result was reached; nil for invalid proofs.
+	Audit *ProofAudit
+}
+
+// ProofAudit is a reproducible record of a verified read, for logging: with
+// the root and key it pins down exactly which nodes the answer rests on.
+type ProofAudit struct {
+	// NodeHashes are the hashes of the nodes walked, root first, including
+	// nodes embedded in their parent.
+	NodeHashes [][]byte
+	// ResidualBits are the key bits, as '0'/'1', left unmatched where the
+	// walk stopped: empty for a present key, the diverging tail for an
+	// absent one.
+	ResidualBits string
+	// TerminalValueHash is the keccak256 hash of the value held by the last
+	// node walked, or nil if it holds none.
+	TerminalValueHash []byte
+}
 
-	type nodeEntry struct {
-		node           *Trie
-		serializedHash []byte
+// Matches reports whether the proven value equals expected. A proven absence
+// matches a nil or empty expected value; a long value is compared by hash.
+func (r *KeyProofResult) Matches(expected []byte) bool {
+	switch r.Status {
+	case ProofProvenAbsent:
+		return len(expected) == 0
+	case ProofPresent:
+		if r.Value == nil && r.ValueHash != nil {
+			return (r.ValueLength < 0 || r.ValueLength == len(expected)) && bytes.Equal(r.ValueHash, Keccak256(expected))
+		}
+		return bytes.Equal(r.Value, expected)
+	default:
+		return false
 	}
-	nodeMap := make(map[string]nodeEntry)
+}
+
+// HasLongValue reports whether the key is present with a long value the
+// proof only commits to by hash, so Value is nil until ResolveValue supplies
+// the bytes.
+func (r *KeyProofResult) HasLongValue() bool {
+	return r.Status == ProofPresent && r.Value == nil && r.ValueHash != nil
+}
 
-	for i, rlpNode := range proofNodes {
-		// RLP decode to get serialized node
-		var serializedNode []byte
-		if err := rlp.DecodeBytes(rlpNode, &serializedNode); err != nil {
-			return nil, fmt.Errorf("failed to RLP decode proof node %d: %w", i, err)
+// ResolveValue checks an out-of-band value blob against the proven value and
+// sets Value to it. For a long value, keccak256(value) must equal ValueHash
+// and len(value) the declared ValueLength; otherwise value must equal Value.
+func (r *KeyProofResult) ResolveValue(value []byte) error {
+	if r.Status != ProofPresent {
+		return fmt.Errorf("cannot resolve value of a %s key", r.Status)
+	}
+	if !r.HasLongValue() {
+		if !bytes.Equal(r.Value, value) {
+			return fmt.Errorf("value %x does not match proven value %x", value, r.Value)
 		}
+		return nil
+	}
+	if r.ValueLength >= 0 && len(value) != r.ValueLength {
+		return fmt.Errorf("value length %d does not match proven length %d", len(value), r.ValueLength)
+	}
+	if hash := Keccak256(value); !bytes.Equal(hash, r.ValueHash) {
+		return fmt.Errorf("value hash %x does not match proven hash %x", hash, r.ValueHash)
+	}
+	r.Value = append([]byte(nil), value...)
+	r.ValueLength = len(value)
+	return nil
+}
 
-		// Hash of serialized content
-		nodeHash := Keccak256(serializedNode)
+// VerifyKeyProofWithValue verifies a proof as VerifyKeyProof does and then
+// resolves the key's value from value, which the caller fetched separately
+// (e.g. with eth_getCode). A value that does not match makes the result
+// ProofInvalid.
+func VerifyKeyProofWithValue(expectedHash, key []byte, proofNodes [][]byte, value []byte) (*KeyProofResult, error) {
+	result, err := VerifyKeyProof(expectedHash, key, proofNodes)
+	if err != nil {
+		return result, err
+	}
+	if err := result.ResolveValue(value); err != nil {
+		return &KeyProofResult{Status: ProofInvalid}, err
+	}
+	return result, nil
+}
 
-		// Parse the node
-		node, err := FromMessage(serializedNode, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse proof node %d: %w", i, err)
+// VerifyKeyProof walks proofNodes (RLP-encoded serialized nodes, in any order;
+// eth_getProof returns them leaf to root) from the node hashing to
+// expectedHash along key. An invalid proof yields a *ProofError, and the
+// returned result then has Status ProofInvalid.
+func VerifyKeyProof(expectedHash []byte, key []byte, proofNodes [][]byte) (*KeyProofResult, error) {
+	nodes, err := decodeProofNodes(key, proofNodes, Keccak256, 0)
+	if err != nil {
+		return &KeyProofResult{Status: ProofInvalid}, err
+	}
+	return nodes.Verify(expectedHash, key)
+}
+
+// VerifyKeyProofStrict verifies a proof as VerifyKeyProof does, but also
+// rejects proofs carrying anything the traversal does not need: a node that
+// repeats an earlier one, or a node off the path to key. Honest eth_getProof
+// responses hold exactly the path, so either points at a buggy or padded
+// proof. Such proofs fail with a *ProofError and Status ProofInvalid.
+func VerifyKeyProofStrict(expectedHash []byte, key []byte, proofNodes [][]byte) (*KeyProofResult, error) {
+	return ProofConfig{Strict: true}.VerifyKeyProof(expectedHash, key, proofNodes)
+}
+
+// ProofNodeSet is a decoded set of proof nodes, indexed by node hash, that
+// can verify any number of keys without decoding the nodes again.
+type ProofNodeSet struct {
+	byHash map[string]proofNode
+	// The last node, which eth_getProof orders as the root.
+	lastHash  []byte
+	lastIndex int
+	// Indexes of nodes that repeat an earlier node.
+	duplicates []int
+	// For a set decoded in known order, the nodes from the root down, in
+	// place of byHash.
+	ordered []proofNode
+}
+
+type proofNode struct {
+	node   *Trie
+	index  int
+	hash   []byte
+	orchid bool // serialized in the pre-RSKIP-107 format
+}
+
+// NewProofNodeSet decodes RLP-encoded serialized nodes, as returned by
+// eth_getProof or MergeProofNodes.
+func NewProofNodeSet(proofNodes [][]byte) (*ProofNodeSet, error) {
+	return decodeProofNodes(nil, proofNodes, Keccak256, 0)
+}
+
+// Merge adds the nodes of other not already in set, so one set can verify
+// keys covered by several proofs. Node indexes refer to no single proof once
+// merged, so errors from a merged set report them as -1.
+func (set *ProofNodeSet) Merge(other *ProofNodeSet) {
+	if set.byHash == nil || set.lastIndex != -1 {
+		// First merge: drop the indexes of set's own proof
+		entries := set.entries()
+		set.byHash = make(map[string]proofNode, len(entries))
+		for _, entry := range entries {
+			entry.index = -1
+			set.byHash[string(entry.hash)] = entry
 		}
+		set.ordered, set.duplicates = nil, nil
+		set.lastHash, set.lastIndex = nil, -1
+	}
+	for _, entry := range other.entries() {
+		if _, ok := set.byHash[string(entry.hash)]; !ok {
+			entry.index = -1
+			set.byHash[string(entry.hash)] = entry
+		}
+	}
+}
 
-		nodeMap[string(nodeHash)] = nodeEntry{node: node, serializedHash: nodeHash}
+// decodeProofNodes decodes RLP-encoded serialized nodes, indexing them by
+// hash. key is only used to annotate errors.
+func decodeProofNodes(key []byte, proofNodes [][]byte, hash func([]byte) []byte, workers int) (*ProofNodeSet, error) {
+	if len(proofNodes) == 0 {
+		return nil, &ProofError{Reason: "empty proof", Key: key, NodeIndex: -1}
 	}
 
+	decoded, err := decodeProofNodeList(key, proofNodes, hash, workers)
+	if err != nil {
+		return nil, err
+	}
+	set := &ProofNodeSet{byHash: make(map[string]proofNode, len(proofNodes))}
+	for i, entry := range decoded {
+		if _, ok := set.byHash[string(entry.hash)]; ok {
+			set.duplicates = append(set.duplicates, i)
+		} else {
+			set.byHash[string(entry.hash)] = entry
+		}
+		set.lastHash, set.lastIndex = entry.hash, i
+	}
+	return set, nil
+}
+
+// decodeProofNode decodes the RLP-encoded serialized node at index i.
+func decodeProofNode(key []byte, i int, rlpNode []byte, hash func([]byte) []byte) (proofNode, error) {
+	// RSK proof nodes are RLP-encoded. The hash is Keccak256 of the serialized (not RLP) content.
+	var serializedNode []byte
+	if err := rlp.DecodeBytes(rlpNode, &serializedNode); err != nil {
+		return proofNode{}, &ProofError{Reason: "failed to RLP decode proof node", Key: key, NodeIndex: i, Err: err}
+	}
+	nodeHash := hash(serializedNode)
+
+	// Parse the node
+	node, err := FromMessage(serializedNode, nil)
+	if err != nil {
+		return proofNode{}, &ProofError{Reason: "failed to parse proof node", Key: key, NodeIndex: i, ComputedHash: nodeHash, Err: err}
+	}
+	return proofNode{node: node, index: i, hash: nodeHash, orchid: isOrchidMessage(serializedNode)}, nil
+}
+
+// Verify follows key from the node hashing to expectedHash, with the same
+// results as VerifyKeyProof.
+func (set *ProofNodeSet) Verify(expectedHash []byte, key []byte) (*KeyProofResult, error) {
+	result, _, err := set.walk(expectedHash, key, 0)
+	return result, err
+}
+
+// walk implements Verify, also returning the nodes it traversed. A positive
+// maxDepth caps the number of nodes traversed.
+func (set *ProofNodeSet) walk(expectedHash []byte, key []byte, maxDepth int) (*KeyProofResult, []ProofStep, error) {
+	invalid := &KeyProofResult{Status: ProofInvalid}
+	fail := &ProofError{Key: key, NodeIndex: -1}
+
 	// Convert key to bit representation for traversal
 	keySlice := TrieKeySliceFromKey(key)
 
+	// finish attaches the audit trail to a result reached at node, with the
+	// key matched up to bit pos.
+	finish := func(result *KeyProofResult, node *Trie, pos int) (*KeyProofResult, []ProofStep, error) {
+		audit := &ProofAudit{ResidualBits: FormatBits(keySlice.Slice(pos, keySlice.Length()))}
+		for _, step := range fail.Traversal {
+			audit.NodeHashes = append(audit.NodeHashes, step.Hash)
+		}
+		if node.valueLength > 0 || node.valueHash != nil {
+			audit.TerminalValueHash = node.GetValueHash()
+		}
+		result.Audit = audit
+		return result, fail.Traversal, nil
+	}
+	absent := func(node *Trie, pos int) (*KeyProofResult, []ProofStep, error) {
+		return finish(&KeyProofResult{Status: ProofProvenAbsent}, node, pos)
+	}
+
 	// Find the root node (should match expectedHash)
-	rootEntry, ok := nodeMap[string(expectedHash)]
+	root, ok := set.rootNode(expectedHash)
 	if !ok {
-		return nil, fmt.Errorf("root hash %x not found in proof nodes", expectedHash)
+		fail.Reason = "root hash not found in proof nodes"
+		fail.NodeIndex = set.lastIndex
+		fail.ExpectedHash = expectedHash
+		fail.ComputedHash = set.lastHash
+		return invalid, fail.Traversal, fail
 	}
-	currentNode := rootEntry.node
+	currentNode := root.node
+	currentIndex, currentHash := root.index, expectedHash
 
 	// Walk the path
-	keyPos := 0
+	keyPos, consumed := 0, 1
 	for {
-		// Check shared path
 		sharedPath := currentNode.sharedPath
-		if sharedPath.Length() > 0 {
-			// Verify shared path matches
-			remaining := keySlice.Length() - keyPos
-			if remaining < sharedPath.Length() {
-				return nil, fmt.Errorf("key too short for shared path at position %d", keyPos)
-			}
+		fail.Traversal = append(fail.Traversal, ProofStep{
+			NodeIndex:   currentIndex,
+			Hash:        currentHash,
+			KeyPosition: keyPos,
+			SharedPath:  FormatBits(sharedPath),
+		})
+		if maxDepth > 0 && len(fail.Traversal) > maxDepth {
+			fail.Reason = fmt.Sprintf("traversal exceeds depth limit %d", maxDepth)
+			fail.NodeIndex, fail.KeyPosition = currentIndex, keyPos
+			return invalid, fail.Traversal, fail
+		}
 
-			for i := 0; i < sharedPath.Length(); i++ {
-				keyBit := keySlice.Get(keyPos + i)
-				pathBit := sharedPath.Get(i)
-				if keyBit != pathBit {
-					// Key diverges from path - value doesn't exist
-					return nil, nil
-				}
+		// A key that ends inside the shared path, or diverges from it, has
+		// no node of its own.
+		if keySlice.Length()-keyPos < sharedPath.Length() {
+			return absent(currentNode, keyPos)
+		}
+		for i := 0; i < sharedPath.Length(); i++ {
+			if keySlice.Get(keyPos+i) != sharedPath.Get(i) {
+				return absent(currentNode, keyPos+i)
 			}
-			keyPos += sharedPath.Length()
 		}
+		keyPos += sharedPath.Length()
 
 		// Check if we've consumed the entire key
 		if keyPos >= keySlice.Length() {
-			// Found the node - return its value
-			return currentNode.GetValue(), nil
+			// Orchid nodes commit to long values by hash without their
+			// length, which then reads as zero.
+			orchidLong := currentNode.valueLength == 0 && currentNode.valueHash != nil
+			if currentNode.valueLength == 0 && !orchidLong {
+				return absent(currentNode, keyPos)
+			}
+			result := &KeyProofResult{
+				Status:      ProofPresent,
+				Value:       currentNode.GetValue(),
+				ValueLength: int(currentNode.valueLength),
+			}
+			if currentNode.HasLongValue() || orchidLong {
+				result.ValueHash = currentNode.GetValueHash()
+			}
+			if orchidLong {
+				result.ValueLength = -1
+			}
+			return finish(result, currentNode, keyPos)
 		}
 
 		// Get next bit and follow child
 		nextBit := keySlice.Get(keyPos)
 		keyPos++
 
-		var childRef *NodeReference
-		if nextBit == 0 {
-			childRef = currentNode.left
-		} else {
+		childRef := currentNode.left
+		if nextBit == 1 {
 			childRef = currentNode.right
 		}
 
 		if childRef.IsEmpty() {
-			// No child - value doesn't exist
-			return nil, nil
+			return absent(currentNode, keyPos-1)
 		}
 
-		// Get child hash
+		// Look up child in proof nodes
 		childHash := childRef.GetHash()
-		if childHash == nil {
-			// Embedded node - get directly
-			childNode := childRef.GetNode()
-			if childNode == nil {
-				return nil, fmt.Errorf("missing embedded child node")
+		child, ok := set.pathChild(childRef, consumed)
+		if !ok {
+			fail.Reason, fail.ExpectedHash, fail.KeyPosition = "missing proof node", childHash, keyPos
+			if set.ordered != nil {
+				fail.Reason = "missing or out-of-order proof node"
 			}
-			currentNode = childNode
-			continue
+			return invalid, fail.Traversal, fail
 		}
+		if child.index >= 0 {
+			consumed++
+		}
+		currentNode, currentIndex, currentHash = child.node, child.index, childHash
+	}
+}
 
-		// Look up child in proof nodes
-		childEntry, ok := nodeMap[string(childHash)]
-		if !ok {
-			return nil, fmt.Errorf("missing proof node for hash %x", childHash)
+// entries returns the set's distinct nodes.
+func (set *ProofNodeSet) entries() []proofNode {
+	entries := append([]proofNode(nil), set.ordered...)
+	for _, entry := range set.byHash {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// rootNode returns the node hashing to hash: in an ordered set, the first.
+func (set *ProofNodeSet) rootNode(hash []byte) (proofNode, bool) {
+	if set.ordered != nil {
+		return set.ordered[0], bytes.Equal(set.ordered[0].hash, hash)
+	}
+	entry, ok := set.byHash[string(hash)]
+	return entry, ok
+}
+
+// pathChild resolves the child reference of a node on the path being walked,
+// after consumed proof nodes. In an ordered set the child must be the next
+// node. Embedded children have index -1.
+func (set *ProofNodeSet) pathChild(ref *NodeReference, consumed int) (proofNode, bool) {
+	hash := ref.GetHash()
+	if set.ordered != nil {
+		if consumed < len(set.ordered) && bytes.Equal(set.ordered[consumed].hash, hash) {
+			return set.ordered[consumed], true
 		}
-		currentNode = childEntry.node
+	} else if entry, ok := set.byHash[string(hash)]; ok {
+		return entry, true
+	}
+	if ref.lazyNode != nil {
+		return proofNode{node: ref.lazyNode, index: -1, hash: hash}, true
+	}
+	return proofNode{}, false
+}
+
+// child resolves a child reference of a proof node: from the set by hash, or,
+// for a child embedded in its parent's message, from the parent itself.
+func (set *ProofNodeSet) child(ref *NodeReference) *Trie {
+	if entry, ok := set.byHash[string(ref.GetHash())]; ok {
+		return entry.node
 	}
+	// Proof nodes have no store, so a node held by the reference can only
+	// have been decoded from the parent.
+	return ref.lazyNode
 }
 
 // VerifyProofValue is a convenience function that verifies a proof and checks the expected value
@@ -213,10 +556,10 @@ func (v *ProofVerifier) VerifyProofValue(
 	proofNodes [][]byte,
 ) (bool, error) {
 
-	value, err := v.verifyProof(stateRoot[:], key, proofNodes)
+	result, err := v.verifyKey(stateRoot, key, proofNodes)
 	if err != nil {
 		return false, err
 	}
 
-	return bytes.Equal(value, expectedValue), nil
+	return result.Matches(expectedValue), nil
 }
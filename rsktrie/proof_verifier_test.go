@@ -0,0 +1,234 @@
+package rsktrie
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// testProof returns the RLP proof nodes, leaf to root, on the path to key.
+func testProof(t *testing.T, root *Trie, key []byte) [][]byte {
+	t.Helper()
+	proof, err := root.GenerateProof(key)
+	if err != nil {
+		t.Fatalf("GenerateProof failed: %v", err)
+	}
+	return proof
+}
+
+func TestVerifyKeyProofStatus(t *testing.T) {
+	long := bytes.Repeat([]byte{0xab}, 100)
+	trie := NewTrie(nil).
+		Put([]byte{0x10, 0x00}, []byte{0x01}).
+		Put([]byte{0x10, 0x01}, long).
+		Put([]byte{0x10, 0x80}, []byte{0x03})
+	root := trie.GetHash()
+
+	result, err := VerifyKeyProof(root, []byte{0x10, 0x00}, testProof(t, trie, []byte{0x10, 0x00}))
+	if err != nil || result.Status != ProofPresent || !bytes.Equal(result.Value, []byte{0x01}) {
+		t.Fatalf("Expected present 01, got %+v, %v", result, err)
+	}
+
+	// The long value is committed by hash only.
+	result, err = VerifyKeyProof(root, []byte{0x10, 0x01}, testProof(t, trie, []byte{0x10, 0x01}))
+	if err != nil || result.Status != ProofPresent {
+		t.Fatalf("Expected present long value, got %+v, %v", result, err)
+	}
+	if result.Value != nil || result.ValueLength != len(long) {
+		t.Errorf("Expected value by hash only, got %d bytes, length %d", len(result.Value), result.ValueLength)
+	}
+	if !result.Matches(long) || result.Matches(long[1:]) {
+		t.Error("Expected long value to match by hash")
+	}
+
+	// The branch node at 0x10 0x0 (12 bits) has no value of its own, and
+	// 0x10 0x40 leaves the trie at an empty child.
+	for _, key := range [][]byte{{0x10}, {0x10, 0x40}, {0x20, 0x00}} {
+		result, err = VerifyKeyProof(root, key, testProof(t, trie, key))
+		if err != nil || result.Status != ProofProvenAbsent {
+			t.Errorf("Key %x: expected proven absent, got %s, %v", key, result.Status, err)
+		}
+		if !result.Matches(nil) {
+			t.Errorf("Key %x: expected absence to match empty value", key)
+		}
+	}
+
+	// Dropping the root node leaves nothing proven.
+	proof := testProof(t, trie, []byte{0x10, 0x00})
+	result, err = VerifyKeyProof(root, []byte{0x10, 0x00}, proof[:len(proof)-1])
+	if err == nil || result.Status != ProofInvalid {
+		t.Errorf("Expected invalid proof, got %s, %v", result.Status, err)
+	}
+}
+
+func TestVerifyKeyProofDiagnostics(t *testing.T) {
+	trie := NewTrie(nil)
+	for i := 0; i < 64; i++ {
+		trie = trie.Put([]byte{byte(i * 4), 0x01}, bytes.Repeat([]byte{byte(i)}, 40))
+	}
+	key := []byte{0x80, 0x01}
+	proof := testProof(t, trie, key)
+	if len(proof) < 3 {
+		t.Fatalf("Expected a proof of at least 3 nodes, got %d", len(proof))
+	}
+
+	// Wrong root: the error names the proof's last node and both hashes.
+	wrongRoot := bytes.Repeat([]byte{0x11}, 32)
+	_, err := VerifyKeyProof(wrongRoot, key, proof)
+	var perr *ProofError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Expected *ProofError, got %v", err)
+	}
+	if perr.NodeIndex != len(proof)-1 || !bytes.Equal(perr.ExpectedHash, wrongRoot) ||
+		!bytes.Equal(perr.ComputedHash, trie.GetHash()) {
+		t.Errorf("Unexpected root mismatch diagnostics %+v", perr)
+	}
+
+	// Missing inner node: the walk reports the child hash it needed and how
+	// far it got.
+	missing := proof[1]
+	var serialized []byte
+	if err := rlp.DecodeBytes(missing, &serialized); err != nil {
+		t.Fatal(err)
+	}
+	truncated := append([][]byte{proof[0]}, proof[2:]...)
+	_, err = VerifyKeyProof(trie.GetHash(), key, truncated)
+	if !errors.As(err, &perr) {
+		t.Fatalf("Expected *ProofError, got %v", err)
+	}
+	if !bytes.Equal(perr.ExpectedHash, Keccak256(serialized)) || perr.NodeIndex != -1 {
+		t.Errorf("Unexpected missing node diagnostics %+v", perr)
+	}
+	if len(perr.Traversal) != len(proof)-2 || perr.Traversal[0].NodeIndex != len(truncated)-1 {
+		t.Errorf("Unexpected traversal %+v", perr.Traversal)
+	}
+	if perr.KeyPosition == 0 || !strings.Contains(err.Error(), "missing proof node") {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	// Undecodable node.
+	_, err = VerifyKeyProof(trie.GetHash(), key, [][]byte{{0xc0}})
+	if !errors.As(err, &perr) || perr.NodeIndex != 0 || perr.Err == nil {
+		t.Errorf("Expected decode error at node 0, got %v", err)
+	}
+}
+
+func TestVerifyMultiproof(t *testing.T) {
+	trie := NewTrie(nil)
+	var keys [][]byte
+	for i := 0; i < 32; i++ {
+		key := []byte{0x42, byte(i * 8), 0x01}
+		keys = append(keys, key)
+		trie = trie.Put(key, bytes.Repeat([]byte{byte(i + 1)}, 33))
+	}
+	missing := []byte{0x42, 0x03, 0x01}
+	keys = append(keys, missing)
+
+	var proofs [][][]byte
+	total := 0
+	for _, key := range keys {
+		proof := testProof(t, trie, key)
+		proofs = append(proofs, proof)
+		total += len(proof)
+	}
+	merged := MergeProofNodes(proofs...)
+	if len(merged) >= total {
+		t.Errorf("Expected merged set smaller than %d nodes, got %d", total, len(merged))
+	}
+
+	results, err := VerifyMultiproof(trie.GetHash(), keys, merged)
+	if err != nil {
+		t.Fatalf("VerifyMultiproof failed: %v", err)
+	}
+	for i, r := range results[:32] {
+		if r.Status != ProofPresent || !r.Matches(bytes.Repeat([]byte{byte(i + 1)}, 33)) {
+			t.Errorf("Key %d: unexpected result %+v", i, r)
+		}
+	}
+	if results[32].Status != ProofProvenAbsent {
+		t.Errorf("Expected missing key proven absent, got %s", results[32].Status)
+	}
+
+	// A key whose path the set does not cover fails alone.
+	results, err = VerifyMultiproof(trie.GetHash(), append(keys[:1:1], []byte{0x42, 0xf8, 0x01}), MergeProofNodes(proofs[0]))
+	var perr *ProofError
+	if !errors.As(err, &perr) || results[0].Status != ProofPresent || results[1].Status != ProofInvalid {
+		t.Errorf("Expected only the uncovered key to fail, got %v", err)
+	}
+}
+
+func TestVerifyKeyProofWithValue(t *testing.T) {
+	long := bytes.Repeat([]byte{0xab}, 100)
+	longKey, shortKey, absentKey := []byte{0x01}, []byte{0x02}, []byte{0x03}
+	trie := NewTrie(nil).Put(longKey, long).Put(shortKey, []byte{0x07})
+	root := trie.GetHash()
+
+	result, err := VerifyKeyProof(root, longKey, testProof(t, trie, longKey))
+	if err != nil || !result.HasLongValue() || result.Value != nil {
+		t.Fatalf("Expected unresolved long value, got %+v, %v", result, err)
+	}
+
+	result, err = VerifyKeyProofWithValue(root, longKey, testProof(t, trie, longKey), long)
+	if err != nil || !bytes.Equal(result.Value, long) {
+		t.Fatalf("Expected resolved long value, got %+v, %v", result, err)
+	}
+
+	tampered := append([]byte(nil), long...)
+	tampered[0] = 0
+	for name, value := range map[string][]byte{"tampered": tampered, "truncated": long[:99], "extended": append(long, 0xab)} {
+		result, err := VerifyKeyProofWithValue(root, longKey, testProof(t, trie, longKey), value)
+		if err == nil || result.Status != ProofInvalid {
+			t.Errorf("%s: expected invalid, got %+v", name, result)
+		}
+	}
+
+	if _, err := VerifyKeyProofWithValue(root, shortKey, testProof(t, trie, shortKey), []byte{0x07}); err != nil {
+		t.Errorf("Short value: %v", err)
+	}
+	if _, err := VerifyKeyProofWithValue(root, shortKey, testProof(t, trie, shortKey), []byte{0x08}); err == nil {
+		t.Error("Expected error for wrong short value")
+	}
+	if _, err := VerifyKeyProofWithValue(root, absentKey, testProof(t, trie, absentKey), long); err == nil {
+		t.Error("Expected error resolving an absent key")
+	}
+}
+
+func TestVerifyKeyProofStrict(t *testing.T) {
+	trie := NewTrie(nil)
+	for i := 0; i < 50; i++ {
+		trie = trie.Put([]byte{byte(i * 5), 0x01}, bytes.Repeat([]byte{byte(i)}, 40))
+	}
+	root := trie.GetHash()
+	key, other := []byte{0x00, 0x01}, []byte{0xf5, 0x01}
+	proof := testProof(t, trie, key)
+
+	if _, err := VerifyKeyProofStrict(root, key, proof); err != nil {
+		t.Fatalf("Expected exact proof to pass: %v", err)
+	}
+
+	padded := MergeProofNodes(testProof(t, trie, other), proof)
+	if _, err := VerifyKeyProof(root, key, padded); err != nil {
+		t.Fatalf("Expected padded proof to pass in lenient mode: %v", err)
+	}
+	var perr *ProofError
+	if result, err := VerifyKeyProofStrict(root, key, padded); !errors.As(err, &perr) || result.Status != ProofInvalid {
+		t.Errorf("Expected *ProofError for padded proof, got %v", err)
+	} else if !strings.Contains(perr.Reason, "not on the key's path") {
+		t.Errorf("Unexpected reason %q", perr.Reason)
+	}
+
+	duplicated := append([][]byte{proof[0]}, proof...)
+	if _, err := VerifyKeyProofStrict(root, key, duplicated); !errors.As(err, &perr) || perr.Reason != "duplicate proof node" || perr.NodeIndex != 1 {
+		t.Errorf("Expected duplicate node error, got %v", err)
+	}
+
+	v := NewProofVerifier()
+	v.SetStrict(true)
+	if ok, err := v.VerifyProofValue(common.BytesToHash(root), key, trie.Get(key), padded); err == nil || ok {
+		t.Error("Expected strict verifier to reject padded proof")
+	}
+}
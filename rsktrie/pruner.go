@@ -0,0 +1,190 @@
+package rsktrie
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PruningPolicy configures how much persisted state a Pruner keeps.
+type PruningPolicy struct {
+	// RetainBlocks is the number of most recent block heights whose state is
+	// kept. Zero disables pruning.
+	RetainBlocks uint64
+}
+
+// PruneProgress is reported after every root released during a prune pass.
+type PruneProgress struct {
+	Horizon        uint64 // heights strictly below this are being pruned
+	RootsTotal     int    // roots scheduled for release in this pass
+	RootsReleased  int
+	EntriesDeleted int
+	Done           bool
+}
+
+// Pruner keeps the state of the last RetainBlocks heights in a
+// RefCountedTrieStore and releases older roots. Several heights may share a
+// state root (blocks without state changes); a root is only released once no
+// retained height uses it.
+type Pruner struct {
+	store  *RefCountedTrieStore
+	policy PruningPolicy
+
+	mu       sync.Mutex
+	heights  map[uint64][][]byte
+	rootUses map[string]int
+	best     uint64
+}
+
+func NewPruner(store *RefCountedTrieStore, policy PruningPolicy) *Pruner {
+	return &Pruner{
+		store:    store,
+		policy:   policy,
+		heights:  make(map[uint64][][]byte),
+		rootUses: make(map[string]int),
+	}
+}
+
+// AddBlock records that the state at height is rooted at root. The root must
+// already be saved in the store; it is registered as pinned.
+func (p *Pruner) AddBlock(height uint64, root []byte) error {
+	// Registering under p.mu keeps a prune pass from releasing the root
+	// between its registration and its first use being counted.
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.store.AddRoot(root, true); err != nil {
+		return err
+	}
+	for _, r := range p.heights[height] {
+		if string(r) == string(root) {
+			return nil
+		}
+	}
+	p.heights[height] = append(p.heights[height], copyBytes(root))
+	p.rootUses[string(root)]++
+	if height > p.best {
+		p.best = height
+	}
+	return nil
+}
+
+// Horizon returns the lowest height whose state is retained.
+func (p *Pruner) Horizon() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.horizonLocked()
+}
+
+func (p *Pruner) horizonLocked() uint64 {
+	if p.policy.RetainBlocks == 0 || p.best+1 <= p.policy.RetainBlocks {
+		return 0
+	}
+	return p.best + 1 - p.policy.RetainBlocks
+}
+
+// Prune releases the roots of every height below the horizon, reporting
+// progress after each released root. It stops early if ctx is cancelled;
+// heights not yet processed are kept for the next pass.
+func (p *Pruner) Prune(ctx context.Context, progress func(PruneProgress)) (PruneProgress, error) {
+	p.mu.Lock()
+	horizon := p.horizonLocked()
+	var expired []uint64
+	for h := range p.heights {
+		if h < horizon {
+			expired = append(expired, h)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i] < expired[j] })
+
+	// Work out which roots lose their last retained height.
+	var release [][]byte
+	for _, h := range expired {
+		for _, r := range p.heights[h] {
+			p.rootUses[string(r)]--
+			if p.rootUses[string(r)] == 0 {
+				delete(p.rootUses, string(r))
+				release = append(release, r)
+			}
+		}
+		delete(p.heights, h)
+	}
+	p.mu.Unlock()
+
+	report := PruneProgress{Horizon: horizon, RootsTotal: len(release)}
+	for i, root := range release {
+		if err := ctx.Err(); err != nil {
+			p.requeue(horizon-1, release[i:])
+			return report, err
+		}
+		n, released, err := p.release(root)
+		if err != nil {
+			p.requeue(horizon-1, release[i:])
+			return report, fmt.Errorf("release root %x: %w", root, err)
+		}
+		if !released {
+			report.RootsTotal--
+			continue
+		}
+		report.RootsReleased++
+		report.EntriesDeleted += n
+		if progress != nil {
+			progress(report)
+		}
+	}
+
+	report.Done = true
+	if progress != nil {
+		progress(report)
+	}
+	return report, nil
+}
+
+// release releases root from the store unless a block added since the pass
+// began uses it again. It holds p.mu so AddBlock cannot register the root
+// while its entries are deleted.
+func (p *Pruner) release(root []byte) (int, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.rootUses[string(root)] > 0 {
+		return 0, false, nil
+	}
+	n, err := p.store.Release(root)
+	if err != nil {
+		return 0, false, err
+	}
+	return n, true, nil
+}
+
+// requeue puts roots that were not released back under height so the next
+// pass picks them up again.
+func (p *Pruner) requeue(height uint64, roots [][]byte) {
+	if len(roots) == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, r := range roots {
+		p.heights[height] = append(p.heights[height], r)
+		p.rootUses[string(r)]++
+	}
+}
+
+// Run prunes every interval until ctx is cancelled, forwarding progress
+// reports. Errors from individual passes are reported through onError, if
+// set, and do not stop the loop.
+func (p *Pruner) Run(ctx context.Context, interval time.Duration, progress func(PruneProgress), onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := p.Prune(ctx, progress); err != nil && ctx.Err() == nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
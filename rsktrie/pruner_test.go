@@ -0,0 +1,152 @@
+package rsktrie
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPrunerRetainsLastBlocks(t *testing.T) {
+	store := NewRefCountedTrieStore(NewMemKeyValueStore())
+	pruner := NewPruner(store, PruningPolicy{RetainBlocks: 2})
+
+	trie := NewTrie(store)
+	var roots [][]byte
+	for h := uint64(0); h < 5; h++ {
+		trie = trie.Put([]byte{byte(h)}, makeValue(50+int(h)))
+		if err := trie.Save(); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		if err := pruner.AddBlock(h, trie.GetHash()); err != nil {
+			t.Fatalf("AddBlock failed: %v", err)
+		}
+		roots = append(roots, trie.GetHash())
+	}
+	// Height 5 has no state change and shares the root of height 4.
+	if err := pruner.AddBlock(5, roots[4]); err != nil {
+		t.Fatalf("AddBlock failed: %v", err)
+	}
+
+	if h := pruner.Horizon(); h != 4 {
+		t.Fatalf("Expected horizon 4, got %d", h)
+	}
+
+	var reports []PruneProgress
+	final, err := pruner.Prune(context.Background(), func(p PruneProgress) {
+		reports = append(reports, p)
+	})
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if !final.Done || final.RootsReleased != 4 || final.RootsTotal != 4 {
+		t.Errorf("Unexpected final progress %+v", final)
+	}
+	if len(reports) != 5 {
+		t.Errorf("Expected 5 progress reports, got %d", len(reports))
+	}
+
+	for h, root := range roots[:4] {
+		if store.Retrieve(root) != nil {
+			t.Errorf("Root of height %d still stored", h)
+		}
+	}
+	loaded := store.Retrieve(roots[4])
+	if loaded == nil {
+		t.Fatal("Retained root missing")
+	}
+	for h := 0; h < 5; h++ {
+		if loaded.Get([]byte{byte(h)}) == nil {
+			t.Errorf("Value for key %d missing from retained state", h)
+		}
+	}
+}
+
+func TestPrunerStopsOnCancel(t *testing.T) {
+	store := NewRefCountedTrieStore(NewMemKeyValueStore())
+	pruner := NewPruner(store, PruningPolicy{RetainBlocks: 1})
+
+	trie := NewTrie(store)
+	for h := uint64(0); h < 3; h++ {
+		trie = trie.Put([]byte{byte(h)}, []byte{byte(h + 1)})
+		trie.Save()
+		pruner.AddBlock(h, trie.GetHash())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := pruner.Prune(ctx, nil); err == nil {
+		t.Fatal("Expected cancellation error")
+	}
+
+	// Nothing was released, so a second pass does all the work.
+	final, err := pruner.Prune(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if final.RootsReleased != 2 {
+		t.Errorf("Expected 2 roots released, got %d", final.RootsReleased)
+	}
+}
+
+func TestPrunerKeepsRootReaddedDuringPass(t *testing.T) {
+	store := NewRefCountedTrieStore(NewMemKeyValueStore())
+	pruner := NewPruner(store, PruningPolicy{RetainBlocks: 1})
+
+	trie := NewTrie(store)
+	var roots [][]byte
+	for h := uint64(0); h < 3; h++ {
+		trie = trie.Put([]byte{byte(h)}, makeValue(40+int(h)))
+		trie.Save()
+		pruner.AddBlock(h, trie.GetHash())
+		roots = append(roots, trie.GetHash())
+	}
+
+	// A reorg adds height 3 on the state of height 1 while the pass is
+	// releasing the expired roots, after it released height 0's.
+	final, err := pruner.Prune(context.Background(), func(p PruneProgress) {
+		if p.RootsReleased == 1 && !p.Done {
+			if err := pruner.AddBlock(3, roots[1]); err != nil {
+				t.Errorf("AddBlock failed: %v", err)
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if store.Retrieve(roots[1]) == nil {
+		t.Fatal("Root used again by height 3 released")
+	}
+	if store.Retrieve(roots[0]) != nil {
+		t.Error("Expired root of height 0 still stored")
+	}
+	if !final.Done || final.RootsReleased != 1 || final.RootsTotal != 1 {
+		t.Errorf("Unexpected final progress %+v", final)
+	}
+}
+
+func TestPrunerRequeuesRootOnReleaseError(t *testing.T) {
+	kv := &failingDeleteKV{MemKeyValueStore: NewMemKeyValueStore()}
+	store := NewRefCountedTrieStore(kv)
+	pruner := NewPruner(store, PruningPolicy{RetainBlocks: 1})
+
+	trie := NewTrie(store)
+	var roots [][]byte
+	for h := uint64(0); h < 2; h++ {
+		trie = trie.Put([]byte{byte(h)}, makeValue(40+int(h)))
+		trie.Save()
+		pruner.AddBlock(h, trie.GetHash())
+		roots = append(roots, trie.GetHash())
+	}
+
+	kv.failDeletes = true
+	if _, err := pruner.Prune(context.Background(), nil); err == nil {
+		t.Fatal("Expected Prune to fail")
+	}
+	kv.failDeletes = false
+	final, err := pruner.Prune(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if final.RootsReleased != 1 || store.Retrieve(roots[0]) != nil {
+		t.Errorf("Failed root not released on the next pass: %+v", final)
+	}
+}
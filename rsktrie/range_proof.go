@@ -0,0 +1,269 @@
+package rsktrie
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// RangeEntry is a key proven to exist by a range proof. As with
+// KeyProofResult, long values are carried by hash only.
+type RangeEntry struct {
+	Key         []byte
+	Value       []byte
+	ValueHash   []byte
+	ValueLength int
+}
+
+// PrefixRange returns the key range [start, end) holding exactly the keys
+// that begin with prefix, e.g. a contract's storage with
+// PrefixRange(mapper.GetAccountStoragePrefixKey(addr)). end is nil if no key
+// sorts after the prefix's subtree.
+func PrefixRange(prefix []byte) (start, end []byte) {
+	start = append([]byte(nil), prefix...)
+	end = append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] != 0xff {
+			end[i]++
+			return start, end[:i+1]
+		}
+	}
+	return start, nil
+}
+
+// VerifyRangeProof verifies that proofNodes, rooted at expectedHash, prove
+// the complete set of keys k with start <= k < end (byte-wise order; a nil
+// end is unbounded) and returns them in order. Every subtree overlapping the
+// range must be present in the proof, so no key in the range can be left
+// out, and subtrees outside it are only referenced by hash. A missing node
+// yields a *ProofError. DefaultProofLimits apply; see
+// ProofConfig.VerifyRangeProof for others.
+func VerifyRangeProof(expectedHash, start, end []byte, proofNodes [][]byte) ([]RangeEntry, error) {
+	return ProofConfig{Limits: DefaultProofLimits}.VerifyRangeProof(expectedHash, start, end, proofNodes)
+}
+
+// VerifyRangeProof verifies a range proof as the package-level
+// VerifyRangeProof does, applying c's limits, node format and hash function.
+// MaxDepth caps the nodes on any one path from the root.
+func (c ProofConfig) VerifyRangeProof(expectedHash, start, end []byte, proofNodes [][]byte) ([]RangeEntry, error) {
+	nodes, err := c.DecodeProofNodes(proofNodes)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := nodes.byHash[string(expectedHash)]
+	if !ok {
+		return nil, &ProofError{
+			Reason:       "root hash not found in proof nodes",
+			NodeIndex:    nodes.lastIndex,
+			ExpectedHash: expectedHash,
+			ComputedHash: nodes.lastHash,
+		}
+	}
+
+	var entries []RangeEntry
+	w := newRangeWalker(start, end)
+	w.maxDepth = c.Limits.MaxDepth
+	w.resolve = func(ref *NodeReference, path []byte) (*Trie, error) {
+		if child := nodes.child(ref); child != nil {
+			return child, nil
+		}
+		return nil, &ProofError{
+			Reason:       "missing proof node",
+			Key:          PathEncoderEncode(path),
+			NodeIndex:    -1,
+			ExpectedHash: ref.GetHash(),
+			KeyPosition:  len(path),
+		}
+	}
+	w.emit = func(key []byte, node *Trie) bool {
+		entry := RangeEntry{Key: key, Value: node.GetValue(), ValueLength: int(node.valueLength)}
+		if node.HasLongValue() {
+			entry.ValueHash = node.GetValueHash()
+		}
+		entries = append(entries, entry)
+		return true
+	}
+	if _, err := w.walk(root.node, nil, false); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GenerateRangeProof returns the nodes proving the keys k with
+// start <= k < end, in the RLP format of eth_getProof. If limit > 0 and the
+// range holds more than limit keys, the proof covers only the first limit and
+// next is the key after them: verify [start, next) and continue from next.
+// next is nil when the proof covers the whole range.
+func (t *Trie) GenerateRangeProof(start, end []byte, limit int) (proof [][]byte, next []byte, err error) {
+	resolve := func(ref *NodeReference, path []byte) (*Trie, error) {
+		if node := ref.GetNode(); node != nil {
+			return node, nil
+		}
+		return nil, fmt.Errorf("missing node %x at path %s", ref.GetHash(), FormatBits(NewTrieKeySlice(path, 0, len(path))))
+	}
+
+	if limit > 0 {
+		count := 0
+		w := newRangeWalker(start, end)
+		w.resolve = resolve
+		w.emit = func(key []byte, _ *Trie) bool {
+			if count == limit {
+				next = key
+				return false
+			}
+			count++
+			return true
+		}
+		if _, err := w.walk(t, nil, false); err != nil {
+			return nil, nil, err
+		}
+		if next != nil {
+			end = next
+		}
+	}
+
+	w := newRangeWalker(start, end)
+	w.resolve = resolve
+	w.visit = func(node *Trie) error {
+		enc, err := rlp.EncodeToBytes(node.ToMessage())
+		if err != nil {
+			return err
+		}
+		proof = append(proof, enc)
+		return nil
+	}
+	if _, err := w.walk(t, nil, false); err != nil {
+		return nil, nil, err
+	}
+	return proof, next, nil
+}
+
+// rangeWalker visits, in key order, every node whose subtree overlaps a key
+// range, and emits the in-range keys that hold values.
+type rangeWalker struct {
+	start, end       []byte // key bounds, as bits
+	startKey, endKey []byte
+	resolve          func(ref *NodeReference, path []byte) (*Trie, error)
+	visit            func(node *Trie) error
+	emit             func(key []byte, node *Trie) bool
+	// maxDepth, if positive, caps the nodes walked on one path.
+	maxDepth, depth int
+}
+
+func newRangeWalker(start, end []byte) *rangeWalker {
+	w := &rangeWalker{startKey: start, endKey: end}
+	w.start = TrieKeySliceFromKey(start).expandedKey
+	if end != nil {
+		w.end = TrieKeySliceFromKey(end).expandedKey
+	}
+	return w
+}
+
+// walk visits node, reached at bit path, and its overlapping descendants. It
+// returns false once emit asks to stop. Nodes embedded in their parent's
+// message are not passed to visit, since the parent already carries them.
+func (w *rangeWalker) walk(node *Trie, path []byte, embedded bool) (bool, error) {
+	w.depth++
+	defer func() { w.depth-- }()
+	if w.maxDepth > 0 && w.depth > w.maxDepth {
+		return false, &ProofError{
+			Reason:      fmt.Sprintf("traversal exceeds depth limit %d", w.maxDepth),
+			Key:         PathEncoderEncode(path),
+			NodeIndex:   -1,
+			KeyPosition: len(path),
+		}
+	}
+	shared := node.sharedPath
+	full := make([]byte, len(path), len(path)+shared.Length()+1)
+	copy(full, path)
+	for i := 0; i < shared.Length(); i++ {
+		full = append(full, shared.Get(i))
+	}
+	// A node whose shared path leaves the range is still visited: its
+	// shared path is what proves the range holds nothing below it.
+	if w.visit != nil && !embedded {
+		if err := w.visit(node); err != nil {
+			return false, err
+		}
+	}
+	if w.disjoint(full) {
+		return true, nil
+	}
+
+	if node.valueLength > 0 && len(full)%8 == 0 {
+		key := PathEncoderEncode(full)
+		if bytes.Compare(key, w.startKey) >= 0 && (w.endKey == nil || bytes.Compare(key, w.endKey) < 0) {
+			if w.emit != nil && !w.emit(key, node) {
+				return false, nil
+			}
+		}
+	}
+
+	for bit, ref := range []*NodeReference{node.left, node.right} {
+		if ref.IsEmpty() {
+			continue
+		}
+		childPath := append(full, byte(bit))
+		if w.disjoint(childPath) {
+			continue
+		}
+		child, err := w.resolve(ref, childPath)
+		if err != nil {
+			return false, err
+		}
+		if more, err := w.walk(child, childPath, ref.IsEmbeddable()); !more || err != nil {
+			return more, err
+		}
+	}
+	return true, nil
+}
+
+// disjoint reports whether no key beginning with the bit prefix p lies in the
+// range.
+func (w *rangeWalker) disjoint(p []byte) bool {
+	// Entirely below start: p sorts before start without being its prefix.
+	if i := firstDifference(p, w.start); i >= 0 && p[i] < w.start[i] {
+		return true
+	}
+	// Entirely at or above end: end is a prefix of p, or p sorts after it.
+	if w.end != nil {
+		i := firstDifference(p, w.end)
+		if (i < 0 && len(p) >= len(w.end)) || (i >= 0 && p[i] > w.end[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstDifference returns the first index where a and b differ, or -1 if one
+// is a prefix of the other.
+func firstDifference(a, b []byte) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return -1
+}
+
+// GeneratePrefixProof returns the nodes proving every key that begins with
+// prefix, such as a contract's whole storage under
+// mapper.GetAccountStoragePrefixKey(addr). It is GenerateRangeProof over
+// PrefixRange(prefix), without a limit.
+func (t *Trie) GeneratePrefixProof(prefix []byte) ([][]byte, error) {
+	start, end := PrefixRange(prefix)
+	proof, _, err := t.GenerateRangeProof(start, end, 0)
+	return proof, err
+}
+
+// VerifyPrefixProof verifies that proofNodes prove the complete set of keys
+// beginning with prefix, and returns them in order. See VerifyRangeProof.
+func VerifyPrefixProof(expectedHash, prefix []byte, proofNodes [][]byte) ([]RangeEntry, error) {
+	start, end := PrefixRange(prefix)
+	return VerifyRangeProof(expectedHash, start, end, proofNodes)
+}
@@ -0,0 +1,159 @@
+package rsktrie
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestRangeProof(t *testing.T) {
+	trie := NewTrie(nil)
+	var keys [][]byte
+	for i := 0; i < 200; i++ {
+		key := []byte{byte(i * 37), byte(i), 0x01}
+		keys = append(keys, key)
+		trie = trie.Put(key, []byte{byte(i + 1)})
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	root := trie.GetHash()
+
+	start, end := []byte{0x40}, []byte{0x90, 0x10}
+	var want [][]byte
+	for _, k := range keys {
+		if bytes.Compare(k, start) >= 0 && bytes.Compare(k, end) < 0 {
+			want = append(want, k)
+		}
+	}
+
+	proof, next, err := trie.GenerateRangeProof(start, end, 0)
+	if err != nil || next != nil {
+		t.Fatalf("GenerateRangeProof failed: %v, next %x", err, next)
+	}
+	entries, err := VerifyRangeProof(root, start, end, proof)
+	if err != nil {
+		t.Fatalf("VerifyRangeProof failed: %v", err)
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %d entries, got %d", len(want), len(entries))
+	}
+	for i, e := range entries {
+		if !bytes.Equal(e.Key, want[i]) || !bytes.Equal(e.Value, trie.Get(want[i])) {
+			t.Errorf("Entry %d: expected %x, got %x=%x", i, want[i], e.Key, e.Value)
+		}
+	}
+
+	// Every node matters: dropping any one leaves part of the range unproven
+	// (or, for the root, the whole proof).
+	for i := range proof {
+		partial := append(append([][]byte{}, proof[:i]...), proof[i+1:]...)
+		if len(partial) == 0 {
+			continue
+		}
+		if _, err := VerifyRangeProof(root, start, end, partial); err == nil {
+			t.Errorf("Expected error without node %d", i)
+		}
+	}
+
+	// A proof for a narrower range does not prove the wider one.
+	narrow, _, err := trie.GenerateRangeProof(start, []byte{0x50}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var perr *ProofError
+	if _, err := VerifyRangeProof(root, start, end, narrow); !errors.As(err, &perr) {
+		t.Errorf("Expected *ProofError for narrower proof, got %v", err)
+	}
+}
+
+func TestRangeProofPaging(t *testing.T) {
+	m := NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045e71a7a2c50903d88e564cd72fab11e82051")
+	other := common.HexToAddress("0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826")
+
+	trie := NewTrie(nil).
+		Put(m.GetAccountKey(contract), []byte{0x01}).
+		Put(m.GetAccountKey(other), []byte{0x02}).
+		Put(m.GetAccountStorageKey(other, common.Hash{}), []byte{0x03})
+	for i := 0; i < 25; i++ {
+		trie = trie.Put(m.GetAccountStorageKey(contract, common.BigToHash(big.NewInt(int64(i)))), []byte{byte(i + 1)})
+	}
+	root := trie.GetHash()
+
+	start, end := PrefixRange(m.GetAccountStoragePrefixKey(contract))
+	var all []RangeEntry
+	for page := 0; ; page++ {
+		proof, next, err := trie.GenerateRangeProof(start, end, 10)
+		if err != nil {
+			t.Fatalf("GenerateRangeProof failed: %v", err)
+		}
+		pageEnd := end
+		if next != nil {
+			pageEnd = next
+		}
+		entries, err := VerifyRangeProof(root, start, pageEnd, proof)
+		if err != nil {
+			t.Fatalf("Page %d: VerifyRangeProof failed: %v", page, err)
+		}
+		all = append(all, entries...)
+		if next == nil {
+			break
+		}
+		if len(entries) != 10 {
+			t.Errorf("Page %d: expected 10 entries, got %d", page, len(entries))
+		}
+		start = next
+	}
+
+	if len(all) != 25 {
+		t.Fatalf("Expected 25 storage entries, got %d", len(all))
+	}
+	for _, e := range all {
+		info, err := m.ClassifyKey(e.Key)
+		if err != nil || info.Kind != KeyKindStorage || info.Address != contract {
+			t.Errorf("Unexpected key in storage range %s", FormatKey(e.Key))
+		}
+	}
+}
+
+func TestPrefixRange(t *testing.T) {
+	start, end := PrefixRange([]byte{0x01, 0xff})
+	if !bytes.Equal(start, []byte{0x01, 0xff}) || !bytes.Equal(end, []byte{0x02}) {
+		t.Errorf("Unexpected range %x..%x", start, end)
+	}
+	if _, end := PrefixRange([]byte{0xff, 0xff}); end != nil {
+		t.Errorf("Expected unbounded end, got %x", end)
+	}
+}
+
+func TestPrefixProof(t *testing.T) {
+	trie := NewTrie(nil)
+	for i := 0; i < 16; i++ {
+		trie = trie.Put([]byte{0x10, byte(i)}, []byte{byte(i + 1)})
+		trie = trie.Put([]byte{0x20, byte(i)}, []byte{byte(i + 1)})
+	}
+	root := trie.GetHash()
+
+	proof, err := trie.GeneratePrefixProof([]byte{0x10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := VerifyPrefixProof(root, []byte{0x10}, proof)
+	if err != nil || len(entries) != 16 {
+		t.Fatalf("Expected 16 entries, got %d, %v", len(entries), err)
+	}
+
+	// An empty prefix is proven by the nodes whose shared paths leave it
+	for _, prefix := range [][]byte{{0x30}, {0x10, 0x20}, {0x00}} {
+		proof, err := trie.GeneratePrefixProof(prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entries, err := VerifyPrefixProof(root, prefix, proof); err != nil || len(entries) != 0 {
+			t.Errorf("%x: expected no entries, got %d, %v", prefix, len(entries), err)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package rsktrie
+
+import (
+	"fmt"
+	"sync"
+)
+
+// RefCountedTrieStore is a KVTrieStore that tracks, for a set of registered
+// roots, how many of them reach each stored node and long value. Roots can be
+// pinned; Collect drops every unpinned root and deletes the entries that no
+// remaining root references. This keeps a rolling window of recent state
+// without unbounded growth: pin the roots to keep, unpin the rest, collect.
+//
+// Reference counts live in memory. After reopening a persistent backend,
+// re-register the roots to keep before calling Collect.
+type RefCountedTrieStore struct {
+	*KVTrieStore
+
+	mu    sync.Mutex
+	refs  map[string]int
+	roots map[string]*trackedRoot
+}
+
+type trackedRoot struct {
+	pinned bool
+	keys   []string
+}
+
+// GCStats reports what a Collect call removed.
+type GCStats struct {
+	RootsCollected int
+	EntriesDeleted int
+}
+
+func NewRefCountedTrieStore(kv KeyValueStore) *RefCountedTrieStore {
+	return &RefCountedTrieStore{
+		KVTrieStore: NewKVTrieStore(kv),
+		refs:        make(map[string]int),
+		roots:       make(map[string]*trackedRoot),
+	}
+}
+
+// SaveRoot saves t (which must use this store) and registers its hash as a
+// root.
+func (s *RefCountedTrieStore) SaveRoot(t *Trie, pinned bool) error {
+	if err := t.Save(); err != nil {
+		return err
+	}
+	return s.AddRoot(t.GetHash(), pinned)
+}
+
+// AddRoot registers an already stored root and counts a reference on every
+// node and long value reachable from it. Registering a tracked root again only
+// updates its pinned flag.
+func (s *RefCountedTrieStore) AddRoot(root []byte, pinned bool) error {
+	s.mu.Lock()
+	if r, ok := s.roots[string(root)]; ok {
+		r.pinned = pinned
+		s.mu.Unlock()
+		return nil
+	}
+	s.mu.Unlock()
+
+	keys, err := s.reachableKeys(root)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.roots[string(root)]; ok {
+		// Registered concurrently while we were walking.
+		r.pinned = pinned
+		return nil
+	}
+	for _, k := range keys {
+		s.refs[k]++
+	}
+	s.roots[string(root)] = &trackedRoot{pinned: pinned, keys: keys}
+	return nil
+}
+
+// Pin protects a tracked root from collection.
+func (s *RefCountedTrieStore) Pin(root []byte) error {
+	return s.setPinned(root, true)
+}
+
+// Unpin makes a tracked root eligible for the next Collect.
+func (s *RefCountedTrieStore) Unpin(root []byte) error {
+	return s.setPinned(root, false)
+}
+
+func (s *RefCountedTrieStore) setPinned(root []byte, pinned bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.roots[string(root)]
+	if !ok {
+		return fmt.Errorf("root %x is not tracked", root)
+	}
+	r.pinned = pinned
+	return nil
+}
+
+// IsPinned reports whether root is tracked and pinned.
+func (s *RefCountedTrieStore) IsPinned(root []byte) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.roots[string(root)]
+	return ok && r.pinned
+}
+
+// Roots returns the tracked root hashes.
+func (s *RefCountedTrieStore) Roots() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	roots := make([][]byte, 0, len(s.roots))
+	for r := range s.roots {
+		roots = append(roots, []byte(r))
+	}
+	return roots
+}
+
+// RefCount returns how many tracked roots reach the entry stored under hash.
+func (s *RefCountedTrieStore) RefCount(hash []byte) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.refs[string(hash)]
+}
+
+// Collect untracks every unpinned root and deletes, in one batch, the nodes
+// and values that are no longer reachable from any tracked root. If the
+// batch fails, every root stays tracked.
+func (s *RefCountedTrieStore) Collect() (GCStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var collect []string
+	for rootKey, r := range s.roots {
+		if !r.pinned {
+			collect = append(collect, rootKey)
+		}
+	}
+	n, err := s.deleteEntries(s.deadKeysLocked(collect))
+	if err != nil {
+		return GCStats{}, err
+	}
+	for _, rootKey := range collect {
+		s.untrackLocked(rootKey)
+	}
+	return GCStats{RootsCollected: len(collect), EntriesDeleted: n}, nil
+}
+
+// Release untracks a single root, regardless of its pinned flag, and deletes
+// the entries only it referenced. It returns the number of deleted entries.
+// If the deletion fails, the root stays tracked.
+func (s *RefCountedTrieStore) Release(root []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.roots[string(root)]; !ok {
+		return 0, fmt.Errorf("root %x is not tracked", root)
+	}
+	n, err := s.deleteEntries(s.deadKeysLocked([]string{string(root)}))
+	if err != nil {
+		return 0, err
+	}
+	s.untrackLocked(string(root))
+	return n, nil
+}
+
+// deadKeysLocked returns the keys no tracked root would reference once the
+// given roots are untracked. Callers must hold s.mu.
+func (s *RefCountedTrieStore) deadKeysLocked(rootKeys []string) []string {
+	drops := make(map[string]int)
+	var dead []string
+	for _, rootKey := range rootKeys {
+		for _, k := range s.roots[rootKey].keys {
+			drops[k]++
+			if drops[k] == s.refs[k] {
+				dead = append(dead, k)
+			}
+		}
+	}
+	return dead
+}
+
+// untrackLocked drops a root and its references. Callers must hold s.mu.
+func (s *RefCountedTrieStore) untrackLocked(rootKey string) {
+	for _, k := range s.roots[rootKey].keys {
+		s.refs[k]--
+		if s.refs[k] == 0 {
+			delete(s.refs, k)
+		}
+	}
+	delete(s.roots, rootKey)
+}
+
+func (s *RefCountedTrieStore) deleteEntries(keys []string) (int, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	batch := s.kv.NewBatch()
+	for _, k := range keys {
+		batch.Delete([]byte(k))
+	}
+	if err := batch.Write(); err != nil {
+		return 0, fmt.Errorf("delete collected entries: %w", err)
+	}
+	return len(keys), nil
+}
+
+// reachableKeys returns the store keys (node hashes and long-value hashes)
+// reachable from root, each listed once.
+func (s *RefCountedTrieStore) reachableKeys(root []byte) ([]string, error) {
+	seen := make(map[string]bool)
+	var keys []string
+	add := func(k []byte) bool {
+		if seen[string(k)] {
+			return false
+		}
+		seen[string(k)] = true
+		keys = append(keys, string(k))
+		return true
+	}
+
+	var visitStored func(hash []byte) error
+	var visitNode func(node *Trie) error
+
+	visitNode = func(node *Trie) error {
+		if node.HasLongValue() && node.valueHash != nil {
+			add(node.valueHash)
+		}
+		for _, ref := range []*NodeReference{node.left, node.right} {
+			if ref.IsEmpty() {
+				continue
+			}
+			if ref.lazyNode != nil && ref.lazyNode.IsEmbeddable() {
+				// Embedded children are part of the parent's record, but may
+				// still point at a long value.
+				if err := visitNode(ref.lazyNode); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := visitStored(ref.GetHash()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	visitStored = func(hash []byte) error {
+		if !add(hash) {
+			return nil
+		}
+		node := s.Retrieve(hash)
+		if node == nil {
+			return fmt.Errorf("missing node %x", hash)
+		}
+		return visitNode(node)
+	}
+
+	if err := visitStored(root); err != nil {
+		return nil, fmt.Errorf("walk root %x: %w", root, err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,138 @@
+package rsktrie
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// failingDeleteKV is a key-value store whose batches fail while failDeletes
+// is set and they hold a delete.
+type failingDeleteKV struct {
+	*MemKeyValueStore
+	failDeletes bool
+}
+
+func (kv *failingDeleteKV) NewBatch() KeyValueBatch {
+	return &failingDeleteBatch{KeyValueBatch: kv.MemKeyValueStore.NewBatch(), kv: kv}
+}
+
+type failingDeleteBatch struct {
+	KeyValueBatch
+	kv      *failingDeleteKV
+	deletes bool
+}
+
+func (b *failingDeleteBatch) Delete(key []byte) {
+	b.deletes = true
+	b.KeyValueBatch.Delete(key)
+}
+
+func (b *failingDeleteBatch) Write() error {
+	if b.deletes && b.kv.failDeletes {
+		return errors.New("disk full")
+	}
+	return b.KeyValueBatch.Write()
+}
+
+func TestRefCountedStoreCollectsUnpinnedRoots(t *testing.T) {
+	kv := NewMemKeyValueStore()
+	store := NewRefCountedTrieStore(kv)
+
+	v1 := buildStoreTestTrie(store)
+	if err := store.SaveRoot(v1, true); err != nil {
+		t.Fatalf("SaveRoot v1 failed: %v", err)
+	}
+	v2 := v1.Put([]byte("key-3"), makeValue(77)).Put([]byte("extra"), []byte("x"))
+	if err := store.SaveRoot(v2, true); err != nil {
+		t.Fatalf("SaveRoot v2 failed: %v", err)
+	}
+
+	before := kv.Len()
+	if err := store.Unpin(v1.GetHash()); err != nil {
+		t.Fatalf("Unpin failed: %v", err)
+	}
+
+	stats, err := store.Collect()
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if stats.RootsCollected != 1 {
+		t.Errorf("Expected 1 root collected, got %d", stats.RootsCollected)
+	}
+	if stats.EntriesDeleted == 0 || kv.Len() != before-stats.EntriesDeleted {
+		t.Errorf("Unexpected deletion stats %+v (before=%d after=%d)", stats, before, kv.Len())
+	}
+
+	// v1's root is gone, v2 remains fully readable.
+	if store.Retrieve(v1.GetHash()) != nil {
+		t.Error("Collected root still in store")
+	}
+	loaded := store.Retrieve(v2.GetHash())
+	if loaded == nil {
+		t.Fatal("Pinned root missing after Collect")
+	}
+	if !bytes.Equal(loaded.Get([]byte("key-3")), makeValue(77)) {
+		t.Error("Updated value missing from pinned root")
+	}
+	if !bytes.Equal(loaded.Get([]byte("key-39")), bytes.Repeat([]byte{39}, 10+39*3)) {
+		t.Error("Shared value missing from pinned root")
+	}
+}
+
+func TestRefCountedStoreKeepsPinnedRoots(t *testing.T) {
+	store := NewRefCountedTrieStore(NewMemKeyValueStore())
+	trie := buildStoreTestTrie(store)
+	if err := store.SaveRoot(trie, true); err != nil {
+		t.Fatalf("SaveRoot failed: %v", err)
+	}
+
+	stats, err := store.Collect()
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if stats.RootsCollected != 0 || stats.EntriesDeleted != 0 {
+		t.Errorf("Expected nothing collected, got %+v", stats)
+	}
+	if store.RefCount(trie.GetHash()) != 1 {
+		t.Errorf("Expected root refcount 1, got %d", store.RefCount(trie.GetHash()))
+	}
+	if err := store.Pin([]byte("unknown")); err == nil {
+		t.Error("Expected error pinning an untracked root")
+	}
+}
+
+func TestRefCountedStoreKeepsRefsWhenDeleteFails(t *testing.T) {
+	kv := &failingDeleteKV{MemKeyValueStore: NewMemKeyValueStore()}
+	store := NewRefCountedTrieStore(kv)
+
+	v1 := buildStoreTestTrie(store)
+	if err := store.SaveRoot(v1, false); err != nil {
+		t.Fatalf("SaveRoot v1 failed: %v", err)
+	}
+	v2 := v1.Put([]byte("key-3"), makeValue(77))
+	if err := store.SaveRoot(v2, false); err != nil {
+		t.Fatalf("SaveRoot v2 failed: %v", err)
+	}
+	before := kv.Len()
+
+	kv.failDeletes = true
+	if _, err := store.Release(v1.GetHash()); err == nil {
+		t.Fatal("Expected Release to fail")
+	}
+	if _, err := store.Collect(); err == nil {
+		t.Fatal("Expected Collect to fail")
+	}
+	if len(store.Roots()) != 2 || store.RefCount(v1.GetHash()) != 1 {
+		t.Fatalf("Failed deletes changed tracking: %d roots, v1 refcount %d", len(store.Roots()), store.RefCount(v1.GetHash()))
+	}
+
+	kv.failDeletes = false
+	stats, err := store.Collect()
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if stats.RootsCollected != 2 || kv.Len() != 0 || stats.EntriesDeleted != before {
+		t.Errorf("Collect after recovery: %+v, %d of %d entries left", stats, kv.Len(), before)
+	}
+}
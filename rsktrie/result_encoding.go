@@ -0,0 +1,125 @@
+package rsktrie
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ResultEncodingVersion is the encoding version written by the
+// MarshalBinary methods of verification results.
+const ResultEncodingVersion = 1
+
+// keyProofResultRLP is the wire form of a KeyProofResult. ValueLength is
+// offset by one so the Orchid -1 encodes as an unsigned integer; the audit
+// is its own MarshalBinary encoding, empty if absent.
+type keyProofResultRLP struct {
+	Status      uint64
+	Value       []byte
+	ValueHash   []byte
+	ValueLength uint64
+	Audit       []byte
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, so results can be
+// cached outside the process (and are encoded this way by encoding/gob).
+func (r *KeyProofResult) MarshalBinary() ([]byte, error) {
+	audit, err := r.Audit.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	enc := keyProofResultRLP{
+		Status:      uint64(r.Status),
+		Value:       r.Value,
+		ValueHash:   r.ValueHash,
+		ValueLength: uint64(r.ValueLength + 1),
+		Audit:       audit,
+	}
+	return marshalVersioned(&enc)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (r *KeyProofResult) UnmarshalBinary(data []byte) error {
+	var dec keyProofResultRLP
+	if err := unmarshalVersioned("key proof result", data, &dec); err != nil {
+		return err
+	}
+	var audit *ProofAudit
+	if len(dec.Audit) > 0 {
+		audit = new(ProofAudit)
+		if err := audit.UnmarshalBinary(dec.Audit); err != nil {
+			return err
+		}
+	}
+	*r = KeyProofResult{
+		Status:      ProofStatus(dec.Status),
+		Value:       nilIfEmpty(dec.Value),
+		ValueHash:   nilIfEmpty(dec.ValueHash),
+		ValueLength: int(dec.ValueLength) - 1,
+		Audit:       audit,
+	}
+	return nil
+}
+
+type proofAuditRLP struct {
+	NodeHashes        [][]byte
+	ResidualBits      string
+	TerminalValueHash []byte
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. A nil audit encodes as
+// no bytes.
+func (a *ProofAudit) MarshalBinary() ([]byte, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return marshalVersioned(&proofAuditRLP{
+		NodeHashes:        a.NodeHashes,
+		ResidualBits:      a.ResidualBits,
+		TerminalValueHash: a.TerminalValueHash,
+	})
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (a *ProofAudit) UnmarshalBinary(data []byte) error {
+	var dec proofAuditRLP
+	if err := unmarshalVersioned("proof audit", data, &dec); err != nil {
+		return err
+	}
+	*a = ProofAudit{
+		NodeHashes:        dec.NodeHashes,
+		ResidualBits:      dec.ResidualBits,
+		TerminalValueHash: nilIfEmpty(dec.TerminalValueHash),
+	}
+	return nil
+}
+
+// marshalVersioned encodes v as a version byte followed by its RLP encoding.
+func marshalVersioned(v any) ([]byte, error) {
+	body, err := rlp.EncodeToBytes(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{ResultEncodingVersion}, body...), nil
+}
+
+func unmarshalVersioned(what string, data []byte, v any) error {
+	if len(data) == 0 {
+		return fmt.Errorf("empty %s", what)
+	}
+	if data[0] != ResultEncodingVersion {
+		return fmt.Errorf("unsupported %s version %d", what, data[0])
+	}
+	if err := rlp.DecodeBytes(data[1:], v); err != nil {
+		return fmt.Errorf("decode %s: %w", what, err)
+	}
+	return nil
+}
+
+// nilIfEmpty undoes RLP decoding nil byte slices as empty ones.
+func nilIfEmpty(b []byte) []byte {
+	if len(b) == 0 {
+		return nil
+	}
+	return b
+}
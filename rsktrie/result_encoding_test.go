@@ -0,0 +1,52 @@
+package rsktrie
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"testing"
+)
+
+func TestKeyProofResultBinary(t *testing.T) {
+	trie := NewTrie(nil).Put([]byte{0x01}, []byte{0x2a}).Put([]byte{0x02}, bytes.Repeat([]byte{0x07}, 40))
+	root := trie.GetHash()
+
+	var results []*KeyProofResult
+	for _, key := range [][]byte{{0x01}, {0x02}, {0x03}} {
+		result, err := VerifyKeyProof(root, key, testProof(t, trie, key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, result)
+	}
+	results = append(results,
+		&KeyProofResult{Status: ProofPresent, ValueHash: Keccak256([]byte{0x01}), ValueLength: -1},
+		&KeyProofResult{Status: ProofInvalid})
+
+	for i, want := range results {
+		data, err := want.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := new(KeyProofResult)
+		if err := got.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Result %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	// gob picks up the BinaryMarshaler implementation
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(results[0]); err != nil {
+		t.Fatal(err)
+	}
+	var got KeyProofResult
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil || !reflect.DeepEqual(&got, results[0]) {
+		t.Errorf("Expected a gob round trip, got %+v, %v", got, err)
+	}
+	if err := got.UnmarshalBinary([]byte{ResultEncodingVersion + 1}); err == nil {
+		t.Error("Expected an unknown version to be rejected")
+	}
+}
@@ -0,0 +1,134 @@
+package rskjvectors
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+)
+
+// Mismatch is one vector on which rsktrie disagrees with the fixture.
+type Mismatch struct {
+	// Section is "nodes", "tries", "keys" or "proofs".
+	Section string
+	Name    string
+	Field   string
+	Want    string
+	Got     string
+}
+
+func (m Mismatch) Error() string {
+	return fmt.Sprintf("%s/%s: %s: want %s, got %s", m.Section, m.Name, m.Field, m.Want, m.Got)
+}
+
+// Check runs every vector and returns the mismatches, in fixture order.
+func Check(v *Vectors) []Mismatch {
+	var out []Mismatch
+	for _, n := range v.Nodes {
+		out = append(out, checkNode(n)...)
+	}
+	for _, tv := range v.Tries {
+		out = append(out, checkTrie(tv)...)
+	}
+	mapper := rsktrie.NewTrieKeyMapper()
+	for _, k := range v.Keys {
+		out = append(out, checkKey(mapper, k)...)
+	}
+	for _, p := range v.Proofs {
+		out = append(out, checkProof(p)...)
+	}
+	return out
+}
+
+// Run checks v and reports each mismatch as a test error.
+func Run(t testing.TB, v *Vectors) {
+	t.Helper()
+	for _, m := range Check(v) {
+		t.Error(m)
+	}
+}
+
+func checkNode(n NodeVector) []Mismatch {
+	mismatch := func(field, want, got string) []Mismatch {
+		return []Mismatch{{Section: "nodes", Name: n.Name, Field: field, Want: want, Got: got}}
+	}
+	if got := rsktrie.Keccak256(n.Message); !bytes.Equal(got, n.Hash[:]) {
+		return mismatch("hash", n.Hash.Hex(), fmt.Sprintf("%#x", got))
+	}
+	node, err := rsktrie.FromMessage(n.Message, nil)
+	if err != nil {
+		return mismatch("decode", "ok", err.Error())
+	}
+	if n.Orchid {
+		return nil
+	}
+	if got := node.ToMessage(); !bytes.Equal(got, n.Message) {
+		return mismatch("message", fmt.Sprintf("%#x", []byte(n.Message)), fmt.Sprintf("%#x", got))
+	}
+	if got := node.GetHash(); !bytes.Equal(got, n.Hash[:]) {
+		return mismatch("node hash", n.Hash.Hex(), fmt.Sprintf("%#x", got))
+	}
+	return nil
+}
+
+func checkTrie(tv TrieVector) []Mismatch {
+	trie := rsktrie.NewTrie(nil)
+	for _, e := range tv.Entries {
+		trie = trie.Put(e.Key, e.Value)
+	}
+	if got := trie.GetHash(); !bytes.Equal(got, tv.Root[:]) {
+		return []Mismatch{{Section: "tries", Name: tv.Name, Field: "root", Want: tv.Root.Hex(), Got: fmt.Sprintf("%#x", got)}}
+	}
+	return nil
+}
+
+func checkKey(m *rsktrie.TrieKeyMapper, k KeyVector) []Mismatch {
+	var got []byte
+	switch k.Kind {
+	case KindAccount:
+		got = m.GetAccountKey(k.Address)
+	case KindCode:
+		got = m.GetCodeKey(k.Address)
+	case KindStoragePrefix:
+		got = m.GetAccountStoragePrefixKey(k.Address)
+	case KindStorage:
+		got = m.GetAccountStorageKey(k.Address, k.Slot)
+	default:
+		return []Mismatch{{Section: "keys", Name: k.Name, Field: "kind", Want: "known kind", Got: k.Kind}}
+	}
+	if !bytes.Equal(got, k.Key) {
+		return []Mismatch{{Section: "keys", Name: k.Name, Field: "key", Want: fmt.Sprintf("%#x", []byte(k.Key)), Got: fmt.Sprintf("%#x", got)}}
+	}
+	return nil
+}
+
+func checkProof(p ProofVector) []Mismatch {
+	nodes := make([][]byte, len(p.Proof))
+	for i, n := range p.Proof {
+		nodes[i] = n
+	}
+	var out []Mismatch
+	mismatch := func(field, want, got string) {
+		out = append(out, Mismatch{Section: "proofs", Name: p.Name, Field: field, Want: want, Got: got})
+	}
+	result, _ := rsktrie.VerifyKeyProof(p.Root[:], p.Key, nodes)
+	status := rsktrie.ProofInvalid
+	if result != nil {
+		status = result.Status
+	}
+	if status.String() != p.Status {
+		mismatch("status", p.Status, status.String())
+		return out
+	}
+	if status != rsktrie.ProofPresent {
+		return out
+	}
+	if p.Value != nil && !bytes.Equal(result.Value, p.Value) {
+		mismatch("value", fmt.Sprintf("%#x", []byte(p.Value)), fmt.Sprintf("%#x", result.Value))
+	}
+	if p.ValueHash != nil && !bytes.Equal(result.ValueHash, p.ValueHash) {
+		mismatch("valueHash", fmt.Sprintf("%#x", []byte(p.ValueHash)), fmt.Sprintf("%#x", result.ValueHash))
+	}
+	return out
+}
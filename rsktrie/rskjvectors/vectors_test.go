@@ -0,0 +1,75 @@
+package rskjvectors
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestRskjVectors checks rsktrie against nodes an rskj node served.
+func TestRskjVectors(t *testing.T) {
+	v, err := Load("testdata/rskj-vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Nodes) == 0 {
+		t.Fatal("fixture has no nodes")
+	}
+	Run(t, v)
+}
+
+// TestRegressionSnapshot checks rsktrie against a snapshot of its own earlier
+// output. It catches changes in behaviour, not disagreements with rskj.
+func TestRegressionSnapshot(t *testing.T) {
+	v, err := Load("testdata/regression-snapshot.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Nodes) == 0 || len(v.Tries) == 0 || len(v.Keys) == 0 || len(v.Proofs) == 0 {
+		t.Fatal("fixture is missing a section")
+	}
+	Run(t, v)
+}
+
+func TestCheckReportsMismatches(t *testing.T) {
+	v, err := Load("testdata/regression-snapshot.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Nodes[0].Message = append([]byte(nil), v.Nodes[0].Message...)
+	v.Nodes[0].Message[len(v.Nodes[0].Message)-1] ^= 1
+	v.Tries[0].Root[0] ^= 1
+	v.Keys[0].Key = append([]byte(nil), v.Keys[0].Key...)
+	v.Keys[0].Key[0] ^= 1
+	v.Proofs[0].Status = "proven-absent"
+
+	got := map[string]string{}
+	for _, m := range Check(v) {
+		got[m.Section+"/"+m.Name] = m.Field
+	}
+	want := map[string]string{
+		"nodes/" + v.Nodes[0].Name:   "hash",
+		"tries/" + v.Tries[0].Name:   "root",
+		"keys/" + v.Keys[0].Name:     "key",
+		"proofs/" + v.Proofs[0].Name: "status",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d mismatches, got %v", len(want), got)
+	}
+	for name, field := range want {
+		if got[name] != field {
+			t.Errorf("%s: expected %s mismatch, got %q", name, field, got[name])
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for name, input := range map[string]string{
+		"unknown field": `{"receipts": []}`,
+		"unknown kind":  `{"keys": [{"name": "k", "kind": "balance", "address": "0x0000000000000000000000000000000000000001", "key": "0x00"}]}`,
+		"bad hex":       `{"nodes": [{"name": "n", "message": "zz", "hash": "0x00"}]}`,
+	} {
+		if _, err := Parse(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package rsktrie
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultSecurePrefixCacheSize is the number of secure prefixes a
+// TrieKeyMapper memoizes by default.
+const DefaultSecurePrefixCacheSize = 4096
+
+// securePrefixCache is an LRU of keccak-derived secure prefixes keyed by their
+// input (an address or a storage slot).
+type securePrefixCache struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+
+	hits   uint64
+	misses uint64
+}
+
+type securePrefixEntry struct {
+	key    string
+	prefix []byte
+}
+
+func newSecurePrefixCache(size int) *securePrefixCache {
+	return &securePrefixCache{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+func (c *securePrefixCache) get(key []byte) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[string(key)]; ok {
+		c.ll.MoveToFront(el)
+		c.hits++
+		return el.Value.(*securePrefixEntry).prefix, true
+	}
+	c.misses++
+	return nil, false
+}
+
+func (c *securePrefixCache) add(key []byte, prefix []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[string(key)]; ok {
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[string(key)] = c.ll.PushFront(&securePrefixEntry{key: string(key), prefix: prefix})
+	if c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*securePrefixEntry).key)
+	}
+}
+
+// SecurePrefixCacheStats reports the effectiveness of a mapper's cache.
+type SecurePrefixCacheStats struct {
+	Size   int
+	Hits   uint64
+	Misses uint64
+}
+
+func (c *securePrefixCache) stats() SecurePrefixCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return SecurePrefixCacheStats{Size: c.ll.Len(), Hits: c.hits, Misses: c.misses}
+}
@@ -0,0 +1,39 @@
+package rsktrie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestKeyMapperMemoizesSecurePrefixes(t *testing.T) {
+	cached := NewTrieKeyMapperWithCacheSize(2)
+	plain := NewTrieKeyMapperWithCacheSize(0)
+	addr := common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051")
+
+	for i := 0; i < 3; i++ {
+		slot := common.BigToHash(common.Big1)
+		if !bytes.Equal(cached.GetAccountStorageKey(addr, slot), plain.GetAccountStorageKey(addr, slot)) {
+			t.Fatal("Cached and uncached keys differ")
+		}
+	}
+	// Address and slot miss once each, then hit on the next two calls.
+	stats := cached.CacheStats()
+	if stats.Misses != 2 || stats.Hits != 4 || stats.Size != 2 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	// A third entry evicts the least recently used one.
+	cached.SecureKeyPrefix([]byte("other"))
+	if stats := cached.CacheStats(); stats.Size != 2 {
+		t.Errorf("Expected cache bounded at 2, got %d", stats.Size)
+	}
+
+	// Mutating a returned prefix must not affect later results.
+	p := cached.SecureKeyPrefix(addr.Bytes())
+	p[0] ^= 0xff
+	if !bytes.Equal(cached.SecureKeyPrefix(addr.Bytes()), plain.SecureKeyPrefix(addr.Bytes())) {
+		t.Error("Cached prefix was corrupted by caller")
+	}
+}
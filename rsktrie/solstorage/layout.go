@@ -0,0 +1,287 @@
+package solstorage
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Kind classifies a Type by how Solidity lays it out in storage.
+type Kind int
+
+const (
+	KindValue Kind = iota
+	KindMapping
+	KindStruct
+	KindStaticArray
+	KindDynamicArray
+	// KindBytes covers string and bytes.
+	KindBytes
+)
+
+// Type is a declarative description of a Solidity type's storage shape, e.g.
+//
+//	// mapping(address => mapping(uint256 => Order))
+//	order := solstorage.Struct(
+//		solstorage.F("maker", solstorage.Address),
+//		solstorage.F("amount", solstorage.Uint(128)),
+//		solstorage.F("filled", solstorage.Uint(128)),
+//	)
+//	orders := solstorage.MappingOf(solstorage.MappingOf(order))
+type Type struct {
+	Kind   Kind
+	Size   int     // bytes, for KindValue
+	Elem   *Type   // mapping value or array element
+	Length uint64  // KindStaticArray
+	Fields []Field // KindStruct
+}
+
+// Field is a named struct member.
+type Field struct {
+	Name string
+	Type *Type
+}
+
+// F is shorthand for a Field.
+func F(name string, t *Type) Field {
+	return Field{Name: name, Type: t}
+}
+
+var (
+	Address = Value(20)
+	Bool    = Value(1)
+	Uint256 = Value(32)
+	Bytes32 = Value(32)
+	String  = &Type{Kind: KindBytes}
+	Bytes   = &Type{Kind: KindBytes}
+)
+
+// Value returns a value type of size bytes (1..32).
+func Value(size int) *Type {
+	return &Type{Kind: KindValue, Size: size}
+}
+
+// Uint returns uintN (or intN); bits must be a multiple of 8.
+func Uint(bits int) *Type {
+	return Value(bits / 8)
+}
+
+func MappingOf(value *Type) *Type {
+	return &Type{Kind: KindMapping, Elem: value}
+}
+
+func Struct(fields ...Field) *Type {
+	return &Type{Kind: KindStruct, Fields: fields}
+}
+
+func StaticArray(elem *Type, length uint64) *Type {
+	return &Type{Kind: KindStaticArray, Elem: elem, Length: length}
+}
+
+func DynamicArray(elem *Type) *Type {
+	return &Type{Kind: KindDynamicArray, Elem: elem}
+}
+
+// storageSize returns the bytes t occupies in place: its value size for value
+// types, a whole number of slots for everything else.
+func (t *Type) storageSize() (int, error) {
+	switch t.Kind {
+	case KindValue:
+		if t.Size <= 0 || t.Size > 32 {
+			return 0, fmt.Errorf("value size %d out of range 1..32", t.Size)
+		}
+		return t.Size, nil
+	case KindMapping, KindDynamicArray, KindBytes:
+		return 32, nil
+	case KindStruct:
+		layout, err := t.structLayout()
+		if err != nil {
+			return 0, err
+		}
+		if len(layout) == 0 {
+			return 0, fmt.Errorf("empty struct")
+		}
+		last := layout[len(layout)-1]
+		return int(last.SlotOffset)*32 + roundSlots(last.ByteOffset+last.Size), nil
+	case KindStaticArray:
+		elemSize, err := t.Elem.storageSize()
+		if err != nil {
+			return 0, err
+		}
+		if t.Length == 0 {
+			return 0, fmt.Errorf("zero-length static array")
+		}
+		if elemSize <= 16 {
+			perSlot := uint64(32 / elemSize)
+			return int((t.Length+perSlot-1)/perSlot) * 32, nil
+		}
+		return int(t.Length) * roundSlots(elemSize), nil
+	default:
+		return 0, fmt.Errorf("unknown kind %d", t.Kind)
+	}
+}
+
+func roundSlots(size int) int {
+	return (size + 31) / 32 * 32
+}
+
+func (t *Type) structLayout() ([]FieldOffset, error) {
+	sizes := make([]int, len(t.Fields))
+	for i, f := range t.Fields {
+		size, err := f.Type.storageSize()
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		sizes[i] = size
+	}
+	return StructLayout(sizes...)
+}
+
+// Step navigates one level into a composite type.
+type Step struct {
+	key   Key
+	field string
+	index uint64
+	kind  stepKind
+}
+
+type stepKind int
+
+const (
+	stepKey stepKind = iota
+	stepField
+	stepIndex
+)
+
+// AtKey selects a mapping entry.
+func AtKey(k Key) Step {
+	return Step{kind: stepKey, key: k}
+}
+
+// AtField selects a struct member.
+func AtField(name string) Step {
+	return Step{kind: stepField, field: name}
+}
+
+// AtIndex selects an array element.
+func AtIndex(i uint64) Step {
+	return Step{kind: stepIndex, index: i}
+}
+
+// Locate resolves a path through a variable of type t declared at base and
+// returns where the selected value lives. For composite results the location
+// is the first slot, with Size 32.
+//
+//	loc, err := solstorage.Locate(solstorage.Slot(3), orders,
+//		solstorage.AtKey(solstorage.AddressKey(maker)),
+//		solstorage.AtKey(solstorage.UintKey(id)),
+//		solstorage.AtField("amount"))
+func Locate(base common.Hash, t *Type, steps ...Step) (Location, error) {
+	loc := Location{Slot: base}
+	for i, step := range steps {
+		next, err := locateStep(loc, t, step)
+		if err != nil {
+			return Location{}, fmt.Errorf("step %d: %w", i, err)
+		}
+		loc = next
+		t = nextType(t, step)
+	}
+
+	loc.Size = 32
+	if t.Kind == KindValue {
+		if _, err := t.storageSize(); err != nil {
+			return Location{}, err
+		}
+		loc.Size = t.Size
+	}
+	return loc, nil
+}
+
+func nextType(t *Type, step Step) *Type {
+	if step.kind == stepField {
+		for _, f := range t.Fields {
+			if f.Name == step.field {
+				return f.Type
+			}
+		}
+	}
+	return t.Elem
+}
+
+func locateStep(loc Location, t *Type, step Step) (Location, error) {
+	switch {
+	case t.Kind == KindMapping && step.kind == stepKey:
+		return Location{Slot: Mapping(loc.Slot, step.key)}, nil
+
+	case t.Kind == KindStruct && step.kind == stepField:
+		layout, err := t.structLayout()
+		if err != nil {
+			return Location{}, err
+		}
+		for i, f := range t.Fields {
+			if f.Name == step.field {
+				return Location{Slot: Add(loc.Slot, layout[i].SlotOffset), Offset: layout[i].ByteOffset}, nil
+			}
+		}
+		return Location{}, fmt.Errorf("struct has no field %q", step.field)
+
+	case (t.Kind == KindStaticArray || t.Kind == KindDynamicArray) && step.kind == stepIndex:
+		if t.Kind == KindStaticArray && step.index >= t.Length {
+			return Location{}, fmt.Errorf("index %d out of bounds for length %d", step.index, t.Length)
+		}
+		start := loc.Slot
+		if t.Kind == KindDynamicArray {
+			start = DataSlot(loc.Slot)
+		}
+		elemSize, err := t.Elem.storageSize()
+		if err != nil {
+			return Location{}, err
+		}
+		if elemSize <= 16 {
+			perSlot := uint64(32 / elemSize)
+			return Location{
+				Slot:   Add(start, step.index/perSlot),
+				Offset: int(step.index%perSlot) * elemSize,
+			}, nil
+		}
+		return Location{Slot: Add(start, step.index*uint64(roundSlots(elemSize)/32))}, nil
+
+	default:
+		return Location{}, fmt.Errorf("cannot apply step to %s", kindName(t.Kind))
+	}
+}
+
+func kindName(k Kind) string {
+	switch k {
+	case KindValue:
+		return "value type"
+	case KindMapping:
+		return "mapping"
+	case KindStruct:
+		return "struct"
+	case KindStaticArray:
+		return "static array"
+	case KindDynamicArray:
+		return "dynamic array"
+	case KindBytes:
+		return "string/bytes"
+	default:
+		return fmt.Sprintf("kind %d", int(k))
+	}
+}
+
+// Slots returns every slot a value of type t at loc occupies in place, which
+// are the storage keys to request from eth_getProof to read it in full.
+// Mapping entries and dynamic array data live elsewhere and are not included.
+func Slots(loc Location, t *Type) ([]common.Hash, error) {
+	size, err := t.storageSize()
+	if err != nil {
+		return nil, err
+	}
+	n := roundSlots(loc.Offset+size) / 32
+	slots := make([]common.Hash, n)
+	for i := range slots {
+		slots[i] = Add(loc.Slot, uint64(i))
+	}
+	return slots, nil
+}
@@ -0,0 +1,206 @@
+// Package solstorage computes the storage slots Solidity assigns to state
+// variables, mapping entries, dynamic array elements and struct fields, so
+// contract state can be located and proven with
+// rsktrie.TrieKeyMapper.GetAccountStorageKey.
+//
+//	// balances is a mapping(address => uint256) declared at slot 0.
+//	slot := solstorage.Mapping(solstorage.Slot(0), solstorage.AddressKey(holder))
+//	key := rsktrie.NewTrieKeyMapper().GetAccountStorageKey(token, slot)
+//
+// The rules follow the Solidity documentation, "Layout of State Variables in
+// Storage".
+package solstorage
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/crypto/sha3"
+)
+
+var two256 = new(big.Int).Lsh(big.NewInt(1), 256)
+
+// Slot returns the slot of a state variable declared at position n.
+func Slot(n uint64) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(n))
+}
+
+// Add returns slot+n, wrapping modulo 2^256 as the EVM does.
+func Add(slot common.Hash, n uint64) common.Hash {
+	sum := new(big.Int).Add(slot.Big(), new(big.Int).SetUint64(n))
+	return common.BigToHash(sum.Mod(sum, two256))
+}
+
+func keccak(parts ...[]byte) common.Hash {
+	h := sha3.NewLegacyKeccak256()
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return common.BytesToHash(h.Sum(nil))
+}
+
+// Key is a mapping key encoded the way Solidity hashes it: value types are
+// padded to 32 bytes, strings and bytes are used as-is.
+type Key []byte
+
+// AddressKey encodes an address mapping key.
+func AddressKey(addr common.Address) Key {
+	return common.LeftPadBytes(addr.Bytes(), 32)
+}
+
+// UintKey encodes an unsigned integer mapping key (any uintN).
+func UintKey(v *big.Int) Key {
+	return common.LeftPadBytes(v.Bytes(), 32)
+}
+
+// IntKey encodes a signed integer mapping key (any intN) in two's complement.
+func IntKey(v *big.Int) Key {
+	if v.Sign() >= 0 {
+		return UintKey(v)
+	}
+	return common.LeftPadBytes(new(big.Int).Add(two256, v).Bytes(), 32)
+}
+
+// BoolKey encodes a bool mapping key.
+func BoolKey(b bool) Key {
+	k := make(Key, 32)
+	if b {
+		k[31] = 1
+	}
+	return k
+}
+
+// FixedBytesKey encodes a bytesN mapping key; bytesN values are left-aligned.
+func FixedBytesKey(b []byte) Key {
+	return common.RightPadBytes(b, 32)
+}
+
+// Bytes32Key encodes a bytes32 mapping key.
+func Bytes32Key(h common.Hash) Key {
+	return h.Bytes()
+}
+
+// StringKey encodes a string mapping key.
+func StringKey(s string) Key {
+	return Key(s)
+}
+
+// BytesKey encodes a dynamic bytes mapping key.
+func BytesKey(b []byte) Key {
+	return Key(b)
+}
+
+// Mapping returns the slot of mapping[key] for a mapping rooted at base:
+// keccak256(key . base).
+func Mapping(base common.Hash, key Key) common.Hash {
+	return keccak(key, base.Bytes())
+}
+
+// NestedMapping returns the slot of mapping[k0][k1]... for nested mappings
+// rooted at base.
+func NestedMapping(base common.Hash, keys ...Key) common.Hash {
+	slot := base
+	for _, k := range keys {
+		slot = Mapping(slot, k)
+	}
+	return slot
+}
+
+// DataSlot returns the first slot of the data area of a dynamic array, or of
+// a string or bytes value longer than 31 bytes, whose length is kept at base.
+func DataSlot(base common.Hash) common.Hash {
+	return keccak(base.Bytes())
+}
+
+// ArrayElement returns the first slot of element index of a dynamic array
+// rooted at base whose elements each occupy elemSlots full slots (1 for
+// 32-byte value types, more for structs or static arrays).
+func ArrayElement(base common.Hash, index uint64, elemSlots uint64) common.Hash {
+	return Add(DataSlot(base), index*elemSlots)
+}
+
+// Location is a value's position in storage: Size bytes at byte Offset within
+// Slot, counting from the low-order (rightmost) end as Solidity packs them.
+type Location struct {
+	Slot   common.Hash
+	Offset int
+	Size   int
+}
+
+// Extract returns the bytes of the located value from the word stored in its
+// slot.
+func (l Location) Extract(word common.Hash) []byte {
+	end := 32 - l.Offset
+	return word[end-l.Size : end]
+}
+
+// PackedArrayElement locates element index of a dynamic array rooted at base
+// whose elements are elemSize bytes (elemSize <= 16 packs several per slot).
+func PackedArrayElement(base common.Hash, index uint64, elemSize int) (Location, error) {
+	if elemSize <= 0 || elemSize > 32 {
+		return Location{}, fmt.Errorf("element size %d out of range 1..32", elemSize)
+	}
+	perSlot := uint64(32 / elemSize)
+	return Location{
+		Slot:   Add(DataSlot(base), index/perSlot),
+		Offset: int(index%perSlot) * elemSize,
+		Size:   elemSize,
+	}, nil
+}
+
+// FieldOffset is a struct field's position relative to the struct's first
+// slot.
+type FieldOffset struct {
+	SlotOffset uint64
+	ByteOffset int
+	Size       int
+}
+
+// StructLayout computes where Solidity places struct fields (or consecutive
+// state variables) of the given byte sizes. Value types are 1..32 bytes and
+// share a slot while they fit; sizes above 32 must be multiples of 32 and
+// stand for nested structs or static arrays, which always start and end a
+// slot.
+func StructLayout(sizes ...int) ([]FieldOffset, error) {
+	fields := make([]FieldOffset, len(sizes))
+	var slot uint64
+	used := 0
+	for i, size := range sizes {
+		switch {
+		case size <= 0:
+			return nil, fmt.Errorf("field %d: invalid size %d", i, size)
+		case size > 32:
+			if size%32 != 0 {
+				return nil, fmt.Errorf("field %d: size %d is not a multiple of 32", i, size)
+			}
+			if used > 0 {
+				slot++
+				used = 0
+			}
+			fields[i] = FieldOffset{SlotOffset: slot, Size: size}
+			slot += uint64(size / 32)
+			continue
+		}
+		if used+size > 32 {
+			slot++
+			used = 0
+		}
+		fields[i] = FieldOffset{SlotOffset: slot, ByteOffset: used, Size: size}
+		used += size
+		if used == 32 {
+			slot++
+			used = 0
+		}
+	}
+	return fields, nil
+}
+
+// Locate returns the field's location for a struct whose first slot is base.
+func (f FieldOffset) Locate(base common.Hash) Location {
+	size := f.Size
+	if size > 32 {
+		size = 32
+	}
+	return Location{Slot: Add(base, f.SlotOffset), Offset: f.ByteOffset, Size: size}
+}
@@ -0,0 +1,134 @@
+package solstorage
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestMappingSlot(t *testing.T) {
+	holder := common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
+	want := crypto.Keccak256Hash(common.LeftPadBytes(holder.Bytes(), 32), common.LeftPadBytes([]byte{3}, 32))
+	if got := Mapping(Slot(3), AddressKey(holder)); got != want {
+		t.Errorf("Expected %x, got %x", want, got)
+	}
+
+	// allowance[owner][spender]
+	spender := common.HexToAddress("0x77045E71a7A2c50903d88e564cD72fab11e82051")
+	inner := crypto.Keccak256Hash(common.LeftPadBytes(holder.Bytes(), 32), Slot(4).Bytes())
+	want = crypto.Keccak256Hash(common.LeftPadBytes(spender.Bytes(), 32), inner.Bytes())
+	if got := NestedMapping(Slot(4), AddressKey(holder), AddressKey(spender)); got != want {
+		t.Errorf("Expected nested %x, got %x", want, got)
+	}
+
+	// String keys are hashed unpadded.
+	want = crypto.Keccak256Hash([]byte("rsk"), Slot(1).Bytes())
+	if got := Mapping(Slot(1), StringKey("rsk")); got != want {
+		t.Errorf("Expected string key %x, got %x", want, got)
+	}
+
+	if k := IntKey(big.NewInt(-1)); !bytes.Equal(k, bytes.Repeat([]byte{0xff}, 32)) {
+		t.Errorf("Expected -1 as all ones, got %x", k)
+	}
+}
+
+func TestArrayElements(t *testing.T) {
+	data := crypto.Keccak256Hash(Slot(2).Bytes())
+	if got := ArrayElement(Slot(2), 5, 2); got != Add(data, 10) {
+		t.Errorf("Unexpected element slot %x", got)
+	}
+
+	// uint64[]: four per slot.
+	loc, err := PackedArrayElement(Slot(2), 6, 8)
+	if err != nil {
+		t.Fatalf("PackedArrayElement failed: %v", err)
+	}
+	if loc.Slot != Add(data, 1) || loc.Offset != 16 || loc.Size != 8 {
+		t.Errorf("Unexpected location %+v", loc)
+	}
+	word := common.HexToHash("0x0000000000000000000000000000002a00000000000000000000000000000000")
+	if v := new(big.Int).SetBytes(loc.Extract(word)); v.Int64() != 0x2a {
+		t.Errorf("Expected 0x2a, got %x", v)
+	}
+
+	max := common.HexToHash("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+	if Add(max, 1) != (common.Hash{}) {
+		t.Error("Expected slot arithmetic to wrap")
+	}
+}
+
+func TestStructLayout(t *testing.T) {
+	// struct { uint128 a; uint128 b; uint256 c; uint8 d; address e; uint256[2] f; bool g; }
+	fields, err := StructLayout(16, 16, 32, 1, 20, 64, 1)
+	if err != nil {
+		t.Fatalf("StructLayout failed: %v", err)
+	}
+	want := []FieldOffset{
+		{0, 0, 16}, {0, 16, 16}, {1, 0, 32}, {2, 0, 1}, {2, 1, 20}, {3, 0, 64}, {5, 0, 1},
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Errorf("Field %d: expected %+v, got %+v", i, want[i], fields[i])
+		}
+	}
+
+	loc := fields[4].Locate(Slot(7))
+	if loc.Slot != Slot(9) || loc.Offset != 1 || loc.Size != 20 {
+		t.Errorf("Unexpected field location %+v", loc)
+	}
+
+	if _, err := StructLayout(40); err == nil {
+		t.Error("Expected error for size not a multiple of 32")
+	}
+}
+
+func TestLocateNestedMappingStructField(t *testing.T) {
+	order := Struct(
+		F("maker", Address),
+		F("amount", Uint(128)),
+		F("filled", Uint(128)),
+		F("fills", DynamicArray(Uint(64))),
+	)
+	orders := MappingOf(MappingOf(order))
+	maker := common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
+	id := big.NewInt(9)
+
+	entry := NestedMapping(Slot(3), AddressKey(maker), UintKey(id))
+
+	// maker fills slot 0 (20 bytes), amount cannot fit and starts slot 1,
+	// filled shares slot 1, fills starts slot 2.
+	loc, err := Locate(Slot(3), orders, AtKey(AddressKey(maker)), AtKey(UintKey(id)), AtField("filled"))
+	if err != nil {
+		t.Fatalf("Locate failed: %v", err)
+	}
+	if want := (Location{Slot: Add(entry, 1), Offset: 16, Size: 16}); loc != want {
+		t.Errorf("Expected %+v, got %+v", want, loc)
+	}
+
+	loc, err = Locate(Slot(3), orders, AtKey(AddressKey(maker)), AtKey(UintKey(id)), AtField("fills"), AtIndex(5))
+	if err != nil {
+		t.Fatalf("Locate failed: %v", err)
+	}
+	if want := (Location{Slot: Add(DataSlot(Add(entry, 2)), 1), Offset: 8, Size: 8}); loc != want {
+		t.Errorf("Expected %+v, got %+v", want, loc)
+	}
+
+	loc, err = Locate(Slot(3), orders, AtKey(AddressKey(maker)), AtKey(UintKey(id)))
+	if err != nil {
+		t.Fatalf("Locate failed: %v", err)
+	}
+	slots, err := Slots(loc, order)
+	if err != nil || len(slots) != 3 || slots[0] != entry || slots[2] != Add(entry, 2) {
+		t.Errorf("Unexpected struct slots %x, %v", slots, err)
+	}
+
+	if _, err := Locate(Slot(3), orders, AtField("maker")); err == nil {
+		t.Error("Expected error selecting a field of a mapping")
+	}
+	if _, err := Locate(Slot(0), StaticArray(Uint256, 2), AtIndex(2)); err == nil {
+		t.Error("Expected out-of-bounds error")
+	}
+}
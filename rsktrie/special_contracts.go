@@ -0,0 +1,90 @@
+package rsktrie
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Addresses of RSK's native (precompiled) contracts, as defined in rskj's
+// PrecompiledContracts.
+var (
+	ECRecoverAddress           = common.HexToAddress("0x0000000000000000000000000000000000000001")
+	SHA256Address              = common.HexToAddress("0x0000000000000000000000000000000000000002")
+	RIPEMD160Address           = common.HexToAddress("0x0000000000000000000000000000000000000003")
+	IdentityAddress            = common.HexToAddress("0x0000000000000000000000000000000000000004")
+	BigIntModExpAddress        = common.HexToAddress("0x0000000000000000000000000000000000000005")
+	AltBN128AddAddress         = common.HexToAddress("0x0000000000000000000000000000000000000006")
+	AltBN128MulAddress         = common.HexToAddress("0x0000000000000000000000000000000000000007")
+	AltBN128PairingAddress     = common.HexToAddress("0x0000000000000000000000000000000000000008")
+	Blake2FAddress             = common.HexToAddress("0x0000000000000000000000000000000000000009")
+	BridgeAddress              = common.HexToAddress("0x0000000000000000000000000000000001000006")
+	RemascAddress              = common.HexToAddress("0x0000000000000000000000000000000001000008")
+	HDWalletUtilsAddress       = common.HexToAddress("0x0000000000000000000000000000000001000009")
+	BlockHeaderContractAddress = common.HexToAddress("0x0000000000000000000000000000000001000010")
+	EnvironmentAddress         = common.HexToAddress("0x0000000000000000000000000000000001000011")
+)
+
+// SpecialContract names one of RSK's native contracts.
+type SpecialContract struct {
+	Name    string
+	Address common.Address
+}
+
+// SpecialContracts lists RSK's native contracts.
+var SpecialContracts = []SpecialContract{
+	{"ECRecover", ECRecoverAddress},
+	{"SHA256", SHA256Address},
+	{"RIPEMD160", RIPEMD160Address},
+	{"Identity", IdentityAddress},
+	{"BigIntModExp", BigIntModExpAddress},
+	{"AltBN128Add", AltBN128AddAddress},
+	{"AltBN128Mul", AltBN128MulAddress},
+	{"AltBN128Pairing", AltBN128PairingAddress},
+	{"Blake2F", Blake2FAddress},
+	{"Bridge", BridgeAddress},
+	{"Remasc", RemascAddress},
+	{"HDWalletUtils", HDWalletUtilsAddress},
+	{"BlockHeader", BlockHeaderContractAddress},
+	{"Environment", EnvironmentAddress},
+}
+
+// LookupSpecialContract returns the native contract at addr, if any.
+func LookupSpecialContract(addr common.Address) (SpecialContract, bool) {
+	for _, c := range SpecialContracts {
+		if c.Address == addr {
+			return c, true
+		}
+	}
+	return SpecialContract{}, false
+}
+
+// IsSpecialContract reports whether addr is one of RSK's native contracts.
+func IsSpecialContract(addr common.Address) bool {
+	_, ok := LookupSpecialContract(addr)
+	return ok
+}
+
+// ContractKeys holds the trie keys of a contract account.
+type ContractKeys struct {
+	Account       []byte
+	Code          []byte
+	StoragePrefix []byte
+}
+
+// ContractKeys returns the account, code and storage prefix keys of addr.
+func (m *TrieKeyMapper) ContractKeys(addr common.Address) ContractKeys {
+	return ContractKeys{
+		Account:       m.GetAccountKey(addr),
+		Code:          m.GetCodeKey(addr),
+		StoragePrefix: m.GetAccountStoragePrefixKey(addr),
+	}
+}
+
+// BridgeStorageKey returns the trie key of a Bridge storage slot.
+func (m *TrieKeyMapper) BridgeStorageKey(slot common.Hash) []byte {
+	return m.GetAccountStorageKey(BridgeAddress, slot)
+}
+
+// RemascStorageKey returns the trie key of a REMASC storage slot.
+func (m *TrieKeyMapper) RemascStorageKey(slot common.Hash) []byte {
+	return m.GetAccountStorageKey(RemascAddress, slot)
+}
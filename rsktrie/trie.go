@@ -2,6 +2,7 @@ package rsktrie
 
 import (
 	"bytes"
+	"fmt"
 
 	"golang.org/x/crypto/sha3"
 )
@@ -65,9 +66,12 @@ func (t *Trie) GetByKeySlice(key *TrieKeySlice) []byte {
 }
 
 func (t *Trie) GetValue() []byte {
-	if t.value == nil && t.valueLength > 0 {
-		// retrieve long value
-		// TODO: Implement long value retrieval logic
+	if t.value == nil && t.hasStoredValue() {
+		val, err := t.checkLongValue(t.store.RetrieveValue(t.valueHash))
+		if err != nil {
+			return nil
+		}
+		return val
 	}
 	if t.value == nil {
 		return nil
@@ -77,6 +81,28 @@ func (t *Trie) GetValue() []byte {
 	return val
 }
 
+// hasStoredValue reports whether the node's value is a long value kept in
+// the store rather than in the node.
+func (t *Trie) hasStoredValue() bool {
+	return t.valueLength > 0 && t.valueHash != nil && t.store != nil
+}
+
+// checkLongValue checks a long value loaded from the store against the
+// node's value length and hash, and returns a copy. Loaded values are not
+// cached in the node, which concurrent readers may share.
+func (t *Trie) checkLongValue(val []byte) ([]byte, error) {
+	if val == nil {
+		return nil, fmt.Errorf("missing long value %x", t.valueHash)
+	}
+	if Uint24(len(val)) != t.valueLength {
+		return nil, fmt.Errorf("long value %x has %d bytes, want %d", t.valueHash, len(val), t.valueLength)
+	}
+	if !bytes.Equal(Keccak256(val), t.valueHash) {
+		return nil, fmt.Errorf("long value does not hash to %x", t.valueHash)
+	}
+	return append([]byte(nil), val...), nil
+}
+
 func (t *Trie) Find(key *TrieKeySlice) *Trie {
 	if t.sharedPath.Length() > key.Length() {
 		return nil
@@ -214,6 +240,47 @@ func (t *Trie) Delete(key []byte) *Trie {
 	return t.Put(key, nil)
 }
 
+// Save persists this node and every unsaved node below it into the trie's
+// store through a single WriteBatch. Children are queued before their parents
+// and nothing becomes visible until the batch is written, so an interrupted
+// save never leaves a stored node referencing a missing child.
+func (t *Trie) Save() error {
+	if t.store == nil {
+		return fmt.Errorf("trie has no store")
+	}
+	batch := t.store.NewBatch()
+	t.saveInto(batch, true)
+	if batch.Len() == 0 {
+		return nil
+	}
+	return batch.Write()
+}
+
+func (t *Trie) saveInto(batch WriteBatch, isRoot bool) {
+	if t.saved {
+		return
+	}
+
+	// Only children held in memory can be unsaved; hash-only references were
+	// loaded from the store in the first place.
+	if n := t.left.lazyNode; n != nil {
+		n.saveInto(batch, false)
+	}
+	if n := t.right.lazyNode; n != nil {
+		n.saveInto(batch, false)
+	}
+
+	if t.HasLongValue() && t.value != nil {
+		batch.PutValue(t.GetValueHash(), t.value)
+	}
+
+	// Embedded nodes live inside their parent's serialization (as in rskj).
+	if !isRoot && t.IsEmbeddable() {
+		return
+	}
+	batch.Put(t)
+}
+
 const (
 	MaxEmbeddedNodeSizeInBytes = 44
 )
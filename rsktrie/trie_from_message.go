@@ -3,6 +3,7 @@ package rsktrie
 import (
 	"bytes"
 	"fmt"
+	"io"
 
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -14,14 +15,20 @@ func FromMessage(message []byte, store TrieStore) (*Trie, error) {
 		return nil, fmt.Errorf("empty message")
 	}
 
-	// Check if it's the old Orchid format (first byte == 2 means arity)
-	if message[0] == 2 {
+	if isOrchidMessage(message) {
 		return fromMessageOrchid(message, store)
 	}
 
 	return fromMessageRSKIP107(message, store)
 }
 
+// isOrchidMessage reports whether message is in the old Orchid format, whose
+// first byte is the arity, 2. RSKIP-107 flags always have the version bits
+// set, so the formats cannot be confused.
+func isOrchidMessage(message []byte) bool {
+	return len(message) > 0 && message[0] == 2
+}
+
 // fromMessageRSKIP107 deserializes using the RSKIP-107 format
 func fromMessageRSKIP107(message []byte, store TrieStore) (*Trie, error) {
 	if len(message) < 1 {
@@ -276,8 +283,11 @@ func deserializeSharedPath(buf *bytes.Reader) (*TrieKeySlice, error) {
 			return nil, fmt.Errorf("read varint for path length: %w", err)
 		}
 		pathLen = int(vi.Value)
-		// Put back the remaining bytes after VarInt
-		buf = bytes.NewReader(remaining[vi.Size:])
+		// Put back the remaining bytes after VarInt. The reader is shared
+		// with the caller, so rewind it rather than replacing it.
+		if _, err := buf.Seek(int64(vi.Size-len(remaining)), io.SeekCurrent); err != nil {
+			return nil, err
+		}
 	}
 
 	encodedLen := calculateEncodedLength(pathLen)
@@ -0,0 +1,33 @@
+package rsktrie
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestFromMessageVarIntSharedPath round-trips nodes whose shared path
+// length does not fit the one-byte forms (1-32 and 160-382 bits) and is
+// encoded as 255 followed by a VarInt.
+func TestFromMessageVarIntSharedPath(t *testing.T) {
+	for _, sharedBytes := range []int{12, 50} {
+		prefix := bytes.Repeat([]byte{0xaa}, sharedBytes)
+		trie := NewTrie(NewMemTrieStore())
+		trie = trie.Put(append(append([]byte{}, prefix...), 0x00), []byte("left"))
+		trie = trie.Put(append(append([]byte{}, prefix...), 0x80), []byte("right"))
+
+		wantBits := sharedBytes * 8
+		if got := trie.GetSharedPath().Length(); got != wantBits {
+			t.Fatalf("Shared path of %d bits, want %d", got, wantBits)
+		}
+		decoded, err := FromMessage(trie.ToMessage(), NewMemTrieStore())
+		if err != nil {
+			t.Fatalf("%d-bit shared path: %v", wantBits, err)
+		}
+		if got := decoded.GetSharedPath().Length(); got != wantBits {
+			t.Errorf("Decoded shared path of %d bits, want %d", got, wantBits)
+		}
+		if !bytes.Equal(decoded.GetHash(), trie.GetHash()) {
+			t.Errorf("%d-bit shared path: decoded hash %x, want %x", wantBits, decoded.GetHash(), trie.GetHash())
+		}
+	}
+}
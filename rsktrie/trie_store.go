@@ -2,15 +2,36 @@ package rsktrie
 
 import (
 	"encoding/hex"
+	"sync"
 )
 
 type TrieStore interface {
 	Save(t *Trie)
 	Retrieve(hash []byte) *Trie
 	RetrieveValue(hash []byte) []byte
+
+	// NewBatch returns a WriteBatch whose writes become visible in the store
+	// only once Write succeeds.
+	NewBatch() WriteBatch
+}
+
+// WriteBatch collects node and long-value writes so they can be committed to
+// a TrieStore as one atomic unit. A batch is not safe for concurrent use.
+type WriteBatch interface {
+	// Put queues a trie node, keyed by its hash.
+	Put(t *Trie)
+	// PutValue queues a long value, keyed by its hash.
+	PutValue(hash []byte, value []byte)
+	// Len returns the number of queued writes.
+	Len() int
+	// Write commits all queued writes and resets the batch.
+	Write() error
+	// Reset discards all queued writes.
+	Reset()
 }
 
 type MemTrieStore struct {
+	mu     sync.RWMutex
 	nodes  map[string]*Trie
 	values map[string][]byte
 }
@@ -28,7 +49,9 @@ func (s *MemTrieStore) Save(t *Trie) {
 	}
 	hash := t.GetHash()
 	key := hex.EncodeToString(hash)
+	s.mu.Lock()
 	s.nodes[key] = t
+	s.mu.Unlock()
 	t.saved = true
 }
 
@@ -37,6 +60,8 @@ func (s *MemTrieStore) Retrieve(hash []byte) *Trie {
 		return nil
 	}
 	key := hex.EncodeToString(hash)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.nodes[key]
 }
 
@@ -45,7 +70,9 @@ func (s *MemTrieStore) RetrieveValue(hash []byte) []byte {
 		return nil
 	}
 	key := hex.EncodeToString(hash)
+	s.mu.RLock()
 	val := s.values[key]
+	s.mu.RUnlock()
 	// Return copy?
 	if val == nil {
 		return nil
@@ -59,5 +86,67 @@ func (s *MemTrieStore) AddValue(hash []byte, val []byte) {
 	key := hex.EncodeToString(hash)
 	v := make([]byte, len(val))
 	copy(v, val)
+	s.mu.Lock()
 	s.values[key] = v
+	s.mu.Unlock()
+}
+
+func (s *MemTrieStore) NewBatch() WriteBatch {
+	return &memWriteBatch{store: s}
+}
+
+// memWriteBatch buffers writes for a MemTrieStore and applies them under a
+// single lock acquisition.
+type memWriteBatch struct {
+	store  *MemTrieStore
+	nodes  []*Trie
+	values map[string][]byte
+}
+
+func (b *memWriteBatch) Put(t *Trie) {
+	if t == nil {
+		return
+	}
+	b.nodes = append(b.nodes, t)
+}
+
+func (b *memWriteBatch) PutValue(hash []byte, value []byte) {
+	if b.values == nil {
+		b.values = make(map[string][]byte)
+	}
+	v := make([]byte, len(value))
+	copy(v, value)
+	b.values[hex.EncodeToString(hash)] = v
+}
+
+func (b *memWriteBatch) Len() int {
+	return len(b.nodes) + len(b.values)
+}
+
+func (b *memWriteBatch) Write() error {
+	// Hash outside the lock; GetHash may serialize the whole subtree.
+	keys := make([]string, len(b.nodes))
+	for i, t := range b.nodes {
+		keys[i] = hex.EncodeToString(t.GetHash())
+	}
+
+	b.store.mu.Lock()
+	for k, v := range b.values {
+		b.store.values[k] = v
+	}
+	for i, t := range b.nodes {
+		b.store.nodes[keys[i]] = t
+	}
+	b.store.mu.Unlock()
+
+	for _, t := range b.nodes {
+		t.saved = true
+	}
+	b.Reset()
+	return nil
+}
+
+func (b *memWriteBatch) Reset() {
+	b.nodes = nil
+	b.values = nil
 }
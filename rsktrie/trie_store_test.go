@@ -0,0 +1,90 @@
+package rsktrie
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSaveWritesSubtreeInOneBatch(t *testing.T) {
+	store := NewMemTrieStore()
+	trie := NewTrie(store)
+	for k := 0; k < 50; k++ {
+		key := []byte{byte(k), byte(k * 7)}
+		trie = trie.Put(key, makeValue(40+k))
+	}
+
+	if err := trie.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	root := store.Retrieve(trie.GetHash())
+	if root == nil {
+		t.Fatal("Root not found in store after Save")
+	}
+
+	// Every non-embedded node reachable from the root must be in the store.
+	it := trie.GetPreOrderIterator()
+	for it.HasNext() {
+		node := it.Next().GetNode()
+		if node != trie && node.IsEmbeddable() {
+			continue
+		}
+		if store.Retrieve(node.GetHash()) == nil {
+			t.Errorf("Node %x missing from store", node.GetHash())
+		}
+		if node.HasLongValue() && !bytes.Equal(store.RetrieveValue(node.GetValueHash()), node.GetValue()) {
+			t.Errorf("Long value for node %x missing from store", node.GetHash())
+		}
+	}
+}
+
+func TestSaveIsNoopWhenAlreadySaved(t *testing.T) {
+	store := NewMemTrieStore()
+	trie := NewTrie(store).Put([]byte("foo"), []byte("bar"))
+
+	if err := trie.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	batch := store.NewBatch()
+	trie.saveInto(batch, true)
+	if batch.Len() != 0 {
+		t.Errorf("Expected empty batch for saved trie, got %d writes", batch.Len())
+	}
+}
+
+func TestWriteBatchNotVisibleBeforeWrite(t *testing.T) {
+	store := NewMemTrieStore()
+	trie := NewTrie(store).Put([]byte("foo"), []byte("bar"))
+
+	batch := store.NewBatch()
+	batch.Put(trie)
+	batch.PutValue([]byte{0x01}, []byte("value"))
+
+	if store.Retrieve(trie.GetHash()) != nil {
+		t.Error("Node visible before batch was written")
+	}
+	if store.RetrieveValue([]byte{0x01}) != nil {
+		t.Error("Value visible before batch was written")
+	}
+
+	if err := batch.Write(); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if store.Retrieve(trie.GetHash()) == nil {
+		t.Error("Node not visible after batch was written")
+	}
+	if !bytes.Equal(store.RetrieveValue([]byte{0x01}), []byte("value")) {
+		t.Error("Value not visible after batch was written")
+	}
+	if batch.Len() != 0 {
+		t.Error("Batch not reset after Write")
+	}
+}
+
+func TestSaveWithoutStore(t *testing.T) {
+	trie := NewTrie(nil).Put([]byte("foo"), []byte("bar"))
+	if err := trie.Save(); err == nil {
+		t.Error("Expected error saving a trie without a store")
+	}
+}
@@ -0,0 +1,142 @@
+// Package triemetrics instruments rsktrie stores with Prometheus metrics.
+//
+// Wrap a store and register its StoreMetrics with a Prometheus registry:
+//
+//	metrics := triemetrics.NewStoreMetrics("gorsk", "state")
+//	prometheus.MustRegister(metrics)
+//	kv := triemetrics.NewMeteredKeyValueStore(backend, metrics)
+//	store := rsktrie.NewKVTrieStore(kv)
+//
+// Nodes loaded from a store resolve their children through the store that
+// loaded them. For KV-backed stores, wrapping the KeyValueStore therefore
+// covers every read, while wrapping the TrieStore only sees top-level calls.
+package triemetrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Operation label values.
+const (
+	OpGet           = "get"
+	OpPut           = "put"
+	OpDelete        = "delete"
+	OpBatchWrite    = "batch_write"
+	OpRetrieve      = "retrieve"
+	OpRetrieveValue = "retrieve_value"
+	OpSave          = "save"
+)
+
+// StoreMetrics holds the counters and histograms shared by the metered store
+// wrappers. It implements prometheus.Collector.
+type StoreMetrics struct {
+	reads        *prometheus.CounterVec
+	misses       *prometheus.CounterVec
+	errors       *prometheus.CounterVec
+	bytesRead    prometheus.Counter
+	bytesWritten prometheus.Counter
+	batchSize    prometheus.Histogram
+	latency      *prometheus.HistogramVec
+}
+
+// NewStoreMetrics creates the metrics for one store. The store name is
+// attached as a constant "store" label so several stores can share a
+// registry.
+func NewStoreMetrics(namespace, store string) *StoreMetrics {
+	labels := prometheus.Labels{"store": store}
+	return &StoreMetrics{
+		reads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "triestore",
+			Name:        "reads_total",
+			Help:        "Number of store reads, by operation.",
+			ConstLabels: labels,
+		}, []string{"op"}),
+		misses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "triestore",
+			Name:        "misses_total",
+			Help:        "Number of store reads that found nothing, by operation.",
+			ConstLabels: labels,
+		}, []string{"op"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "triestore",
+			Name:        "errors_total",
+			Help:        "Number of failed store operations, by operation.",
+			ConstLabels: labels,
+		}, []string{"op"}),
+		bytesRead: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "triestore",
+			Name:        "read_bytes_total",
+			Help:        "Bytes returned by store reads.",
+			ConstLabels: labels,
+		}),
+		bytesWritten: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "triestore",
+			Name:        "written_bytes_total",
+			Help:        "Key and value bytes written to the store.",
+			ConstLabels: labels,
+		}),
+		batchSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   "triestore",
+			Name:        "batch_size",
+			Help:        "Number of writes per committed batch.",
+			ConstLabels: labels,
+			Buckets:     prometheus.ExponentialBuckets(1, 4, 8),
+		}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   "triestore",
+			Name:        "operation_duration_seconds",
+			Help:        "Store operation latency, by operation.",
+			ConstLabels: labels,
+			Buckets:     prometheus.ExponentialBuckets(0.00001, 4, 10),
+		}, []string{"op"}),
+	}
+}
+
+func (m *StoreMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.reads.Describe(ch)
+	m.misses.Describe(ch)
+	m.errors.Describe(ch)
+	m.bytesRead.Describe(ch)
+	m.bytesWritten.Describe(ch)
+	m.batchSize.Describe(ch)
+	m.latency.Describe(ch)
+}
+
+func (m *StoreMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.reads.Collect(ch)
+	m.misses.Collect(ch)
+	m.errors.Collect(ch)
+	m.bytesRead.Collect(ch)
+	m.bytesWritten.Collect(ch)
+	m.batchSize.Collect(ch)
+	m.latency.Collect(ch)
+}
+
+// observeRead records a read of n bytes; a nil result counts as a miss.
+func (m *StoreMetrics) observeRead(op string, start time.Time, found bool, n int) {
+	m.latency.WithLabelValues(op).Observe(time.Since(start).Seconds())
+	m.reads.WithLabelValues(op).Inc()
+	if !found {
+		m.misses.WithLabelValues(op).Inc()
+		return
+	}
+	m.bytesRead.Add(float64(n))
+}
+
+func (m *StoreMetrics) observeWrite(op string, start time.Time, n int, err error) {
+	m.latency.WithLabelValues(op).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.errors.WithLabelValues(op).Inc()
+		return
+	}
+	m.bytesWritten.Add(float64(n))
+}
@@ -0,0 +1,214 @@
+package triemetrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+)
+
+// MeteredKeyValueStore wraps a rsktrie.KeyValueStore and records every
+// operation in a StoreMetrics.
+type MeteredKeyValueStore struct {
+	kv      rsktrie.KeyValueStore
+	metrics *StoreMetrics
+}
+
+var _ rsktrie.KeyValueStore = (*MeteredKeyValueStore)(nil)
+
+func NewMeteredKeyValueStore(kv rsktrie.KeyValueStore, metrics *StoreMetrics) *MeteredKeyValueStore {
+	return &MeteredKeyValueStore{kv: kv, metrics: metrics}
+}
+
+func (s *MeteredKeyValueStore) Get(key []byte) ([]byte, error) {
+	start := time.Now()
+	val, err := s.kv.Get(key)
+	if err != nil {
+		s.metrics.errors.WithLabelValues(OpGet).Inc()
+		return nil, err
+	}
+	s.metrics.observeRead(OpGet, start, val != nil, len(val))
+	return val, nil
+}
+
+func (s *MeteredKeyValueStore) Put(key []byte, value []byte) error {
+	start := time.Now()
+	err := s.kv.Put(key, value)
+	s.metrics.observeWrite(OpPut, start, len(key)+len(value), err)
+	return err
+}
+
+func (s *MeteredKeyValueStore) Delete(key []byte) error {
+	start := time.Now()
+	err := s.kv.Delete(key)
+	s.metrics.observeWrite(OpDelete, start, 0, err)
+	return err
+}
+
+func (s *MeteredKeyValueStore) NewBatch() rsktrie.KeyValueBatch {
+	return &meteredKVBatch{batch: s.kv.NewBatch(), metrics: s.metrics}
+}
+
+func (s *MeteredKeyValueStore) Close() error {
+	return s.kv.Close()
+}
+
+type meteredKVBatch struct {
+	batch   rsktrie.KeyValueBatch
+	metrics *StoreMetrics
+	bytes   int
+}
+
+func (b *meteredKVBatch) Put(key []byte, value []byte) {
+	b.batch.Put(key, value)
+	b.bytes += len(key) + len(value)
+}
+
+func (b *meteredKVBatch) Delete(key []byte) {
+	b.batch.Delete(key)
+}
+
+func (b *meteredKVBatch) Len() int {
+	return b.batch.Len()
+}
+
+func (b *meteredKVBatch) Write() error {
+	n := b.batch.Len()
+	start := time.Now()
+	err := b.batch.Write()
+	b.metrics.observeWrite(OpBatchWrite, start, b.bytes, err)
+	if err == nil {
+		b.metrics.batchSize.Observe(float64(n))
+		b.bytes = 0
+	}
+	return err
+}
+
+func (b *meteredKVBatch) Reset() {
+	b.batch.Reset()
+	b.bytes = 0
+}
+
+// MeteredTrieStore wraps a rsktrie.TrieStore and records every operation in
+// a StoreMetrics. Node sizes are their serialized message lengths.
+type MeteredTrieStore struct {
+	store   rsktrie.TrieStore
+	metrics *StoreMetrics
+}
+
+var _ rsktrie.ContextTrieStore = (*MeteredTrieStore)(nil)
+
+func NewMeteredTrieStore(store rsktrie.TrieStore, metrics *StoreMetrics) *MeteredTrieStore {
+	return &MeteredTrieStore{store: store, metrics: metrics}
+}
+
+func (s *MeteredTrieStore) Save(t *rsktrie.Trie) {
+	if t == nil {
+		return
+	}
+	start := time.Now()
+	s.store.Save(t)
+	s.metrics.observeWrite(OpSave, start, t.GetMessageLength(), nil)
+}
+
+func (s *MeteredTrieStore) Retrieve(hash []byte) *rsktrie.Trie {
+	start := time.Now()
+	t := s.store.Retrieve(hash)
+	n := 0
+	if t != nil {
+		n = t.GetMessageLength()
+	}
+	s.metrics.observeRead(OpRetrieve, start, t != nil, n)
+	return t
+}
+
+func (s *MeteredTrieStore) RetrieveValue(hash []byte) []byte {
+	start := time.Now()
+	val := s.store.RetrieveValue(hash)
+	s.metrics.observeRead(OpRetrieveValue, start, val != nil, len(val))
+	return val
+}
+
+func (s *MeteredTrieStore) SaveContext(ctx context.Context, t *rsktrie.Trie) error {
+	if t == nil {
+		return nil
+	}
+	start := time.Now()
+	var err error
+	if cs, ok := s.store.(rsktrie.ContextTrieStore); ok {
+		err = cs.SaveContext(ctx, t)
+	} else if err = ctx.Err(); err == nil {
+		s.store.Save(t)
+	}
+	s.metrics.observeWrite(OpSave, start, t.GetMessageLength(), err)
+	return err
+}
+
+func (s *MeteredTrieStore) RetrieveContext(ctx context.Context, hash []byte) (*rsktrie.Trie, error) {
+	start := time.Now()
+	t, err := rsktrie.RetrieveContext(ctx, s.store, hash)
+	if err != nil {
+		s.metrics.errors.WithLabelValues(OpRetrieve).Inc()
+		return nil, err
+	}
+	n := 0
+	if t != nil {
+		n = t.GetMessageLength()
+	}
+	s.metrics.observeRead(OpRetrieve, start, t != nil, n)
+	return t, nil
+}
+
+func (s *MeteredTrieStore) RetrieveValueContext(ctx context.Context, hash []byte) ([]byte, error) {
+	start := time.Now()
+	val, err := rsktrie.RetrieveValueContext(ctx, s.store, hash)
+	if err != nil {
+		s.metrics.errors.WithLabelValues(OpRetrieveValue).Inc()
+		return nil, err
+	}
+	s.metrics.observeRead(OpRetrieveValue, start, val != nil, len(val))
+	return val, nil
+}
+
+func (s *MeteredTrieStore) NewBatch() rsktrie.WriteBatch {
+	return &meteredTrieBatch{batch: s.store.NewBatch(), metrics: s.metrics}
+}
+
+type meteredTrieBatch struct {
+	batch   rsktrie.WriteBatch
+	metrics *StoreMetrics
+	bytes   int
+}
+
+func (b *meteredTrieBatch) Put(t *rsktrie.Trie) {
+	b.batch.Put(t)
+	if t != nil {
+		b.bytes += t.GetMessageLength()
+	}
+}
+
+func (b *meteredTrieBatch) PutValue(hash []byte, value []byte) {
+	b.batch.PutValue(hash, value)
+	b.bytes += len(value)
+}
+
+func (b *meteredTrieBatch) Len() int {
+	return b.batch.Len()
+}
+
+func (b *meteredTrieBatch) Write() error {
+	n := b.batch.Len()
+	start := time.Now()
+	err := b.batch.Write()
+	b.metrics.observeWrite(OpBatchWrite, start, b.bytes, err)
+	if err == nil {
+		b.metrics.batchSize.Observe(float64(n))
+		b.bytes = 0
+	}
+	return err
+}
+
+func (b *meteredTrieBatch) Reset() {
+	b.batch.Reset()
+	b.bytes = 0
+}
@@ -0,0 +1,109 @@
+package triemetrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ethereum-optimism/optimism/op-service/rsk/gorsk/rsktrie"
+)
+
+func gatherValues(t *testing.T, reg *prometheus.Registry) map[string]float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather failed: %v", err)
+	}
+	values := make(map[string]float64)
+	for _, mf := range families {
+		for _, m := range mf.GetMetric() {
+			name := mf.GetName()
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "op" {
+					name += "/" + l.GetValue()
+				}
+			}
+			switch {
+			case m.GetCounter() != nil:
+				values[name] = m.GetCounter().GetValue()
+			case m.GetHistogram() != nil:
+				values[name] = float64(m.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	return values
+}
+
+func TestMeteredKeyValueStoreRecordsOperations(t *testing.T) {
+	metrics := NewStoreMetrics("test", "state")
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(metrics)
+
+	kv := NewMeteredKeyValueStore(rsktrie.NewMemKeyValueStore(), metrics)
+	store := rsktrie.NewKVTrieStore(kv)
+
+	trie := rsktrie.NewTrie(store)
+	for i := 0; i < 20; i++ {
+		trie = trie.Put([]byte{byte(i), 0xaa}, bytes.Repeat([]byte{byte(i)}, 40))
+	}
+	if err := trie.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded := store.Retrieve(trie.GetHash())
+	if loaded == nil {
+		t.Fatal("Saved root not found")
+	}
+	if !bytes.Equal(loaded.Get([]byte{7, 0xaa}), bytes.Repeat([]byte{7}, 40)) {
+		t.Error("Unexpected value read through metered store")
+	}
+	if store.Retrieve([]byte("missing")) != nil {
+		t.Error("Expected nil for missing node")
+	}
+
+	values := gatherValues(t, reg)
+	if values["test_triestore_batch_size"] != 1 {
+		t.Errorf("Expected 1 batch observed, got %v", values["test_triestore_batch_size"])
+	}
+	if values["test_triestore_written_bytes_total"] == 0 {
+		t.Error("Expected written bytes to be recorded")
+	}
+	// The root, the traversed path and the long value are all read through
+	// the KV store, plus the miss.
+	if values["test_triestore_reads_total/get"] < 3 {
+		t.Errorf("Expected at least 3 gets, got %v", values["test_triestore_reads_total/get"])
+	}
+	if values["test_triestore_misses_total/get"] != 1 {
+		t.Errorf("Expected 1 miss, got %v", values["test_triestore_misses_total/get"])
+	}
+}
+
+func TestMeteredTrieStoreRecordsOperations(t *testing.T) {
+	metrics := NewStoreMetrics("test", "mem")
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(metrics)
+
+	store := NewMeteredTrieStore(rsktrie.NewMemTrieStore(), metrics)
+	trie := rsktrie.NewTrie(store).Put([]byte("key"), bytes.Repeat([]byte{1}, 64))
+	if err := trie.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if store.Retrieve(trie.GetHash()) == nil {
+		t.Fatal("Saved root not found")
+	}
+	if store.RetrieveValue(trie.GetValueHash()) == nil {
+		t.Fatal("Saved long value not found")
+	}
+
+	values := gatherValues(t, reg)
+	if values["test_triestore_reads_total/retrieve"] != 1 {
+		t.Errorf("Expected 1 retrieve, got %v", values["test_triestore_reads_total/retrieve"])
+	}
+	if values["test_triestore_reads_total/retrieve_value"] != 1 {
+		t.Errorf("Expected 1 value retrieve, got %v", values["test_triestore_reads_total/retrieve_value"])
+	}
+	if values["test_triestore_operation_duration_seconds/batch_write"] != 1 {
+		t.Errorf("Expected 1 batch write, got %v", values["test_triestore_operation_duration_seconds/batch_write"])
+	}
+}
@@ -0,0 +1,98 @@
+package rsktrie
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// VerificationTrace records the byte-level operations of verifying one key
+// proof: every node walked with the exact bytes hashed, the hash produced,
+// and the key bits consumed. It is meant for zk circuit builders proving RSK
+// state, who must replay the verification inside a circuit; it encodes to
+// JSON with hex byte strings.
+//
+// A circuit checks that Steps[0].Hash is Root, that each step's Hash is
+// keccak256 of its Message, that each Message's child reference for ChildBit
+// is the next step's Hash (or embeds the next Message), and that the shared
+// paths and child bits spell out KeyBits up to the terminal step.
+type VerificationTrace struct {
+	Root    hexutil.Bytes `json:"root"`
+	Key     hexutil.Bytes `json:"key"`
+	KeyBits string        `json:"keyBits"`
+	Status  string        `json:"status"`
+	Steps   []TraceStep   `json:"steps"`
+	// Value and ValueHash are as in KeyProofResult.
+	Value     hexutil.Bytes `json:"value,omitempty"`
+	ValueHash hexutil.Bytes `json:"valueHash,omitempty"`
+	// Error is the verification failure, for an invalid proof.
+	Error string `json:"error,omitempty"`
+}
+
+// TraceStep is one node of a VerificationTrace.
+type TraceStep struct {
+	// NodeIndex is the node's position in the proof, or -1 for a node
+	// embedded in its parent.
+	NodeIndex int `json:"nodeIndex"`
+	// Message is the serialized node: the hash input.
+	Message hexutil.Bytes `json:"message"`
+	// Hash is keccak256(Message): the hash output.
+	Hash hexutil.Bytes `json:"hash"`
+	// KeyPosition is the number of key bits consumed on reaching the node.
+	KeyPosition int    `json:"keyPosition"`
+	SharedPath  string `json:"sharedPath"`
+	// ChildBit is the key bit selecting the next node, or -1 at the last.
+	ChildBit int `json:"childBit"`
+}
+
+// TraceKeyProof verifies a key proof as VerifyKeyProof does, recording a
+// VerificationTrace. An invalid proof yields a trace of the steps walked
+// before the failure, along with the error.
+func TraceKeyProof(expectedHash []byte, key []byte, proofNodes [][]byte) (*VerificationTrace, error) {
+	trace := &VerificationTrace{
+		Root:    expectedHash,
+		Key:     key,
+		KeyBits: FormatBits(TrieKeySliceFromKey(key)),
+		Status:  ProofInvalid.String(),
+	}
+	nodes, err := decodeProofNodes(key, proofNodes, Keccak256, 1)
+	if err != nil {
+		trace.Error = err.Error()
+		return trace, err
+	}
+	result, steps, err := nodes.walk(expectedHash, key, 0)
+	var node *Trie
+	for i, step := range steps {
+		t := TraceStep{
+			NodeIndex:   step.NodeIndex,
+			Hash:        step.Hash,
+			KeyPosition: step.KeyPosition,
+			SharedPath:  step.SharedPath,
+			ChildBit:    -1,
+		}
+		if i+1 < len(steps) {
+			t.ChildBit = int(trace.KeyBits[steps[i+1].KeyPosition-1] - '0')
+		}
+		if step.NodeIndex >= 0 {
+			node = nodes.byHash[string(step.Hash)].node
+			if err := rlp.DecodeBytes(proofNodes[step.NodeIndex], (*[]byte)(&t.Message)); err != nil {
+				return trace, err
+			}
+		} else {
+			// Embedded in the previous node, under the previous child bit
+			ref := node.left
+			if trace.Steps[i-1].ChildBit == 1 {
+				ref = node.right
+			}
+			node = nodes.child(ref)
+			t.Message = node.ToMessage()
+		}
+		trace.Steps = append(trace.Steps, t)
+	}
+	if err != nil {
+		trace.Error = err.Error()
+		return trace, err
+	}
+	trace.Status = result.Status.String()
+	trace.Value, trace.ValueHash = result.Value, result.ValueHash
+	return trace, nil
+}
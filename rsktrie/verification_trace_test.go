@@ -0,0 +1,71 @@
+package rsktrie
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestTraceKeyProof(t *testing.T) {
+	trie := NewTrie(nil)
+	for i := 0; i < 32; i++ {
+		// Short values keep some nodes small enough to be embedded
+		trie = trie.Put([]byte{byte(i * 8), 0x01}, []byte{byte(i + 1)})
+	}
+	root := trie.GetHash()
+
+	embedded := 0
+	present, absent := []byte{0x20, 0x01}, []byte{0x21, 0x01}
+	// The leaf is embedded in its parent, so the proof holds without it
+	embeddedProof := testProof(t, trie, present)[1:]
+	for _, tt := range []struct {
+		key   []byte
+		proof [][]byte
+	}{
+		{present, testProof(t, trie, present)},
+		{present, embeddedProof},
+		{absent, testProof(t, trie, absent)},
+	} {
+		key := tt.key
+		trace, err := TraceKeyProof(root, key, tt.proof)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(trace.Steps[0].Hash, root) {
+			t.Errorf("%x: expected the first step at the root", key)
+		}
+		path := ""
+		for i, step := range trace.Steps {
+			if step.NodeIndex < 0 {
+				embedded++
+			}
+			if !bytes.Equal(Keccak256(step.Message), step.Hash) {
+				t.Errorf("%x: step %d hash is not keccak256 of its message", key, i)
+			}
+			path += step.SharedPath
+			if step.ChildBit >= 0 {
+				path += string(rune('0' + step.ChildBit))
+			}
+		}
+		// The last node's shared path may diverge from an absent key
+		last := trace.Steps[len(trace.Steps)-1].KeyPosition
+		if path[:last] != trace.KeyBits[:last] {
+			t.Errorf("%x: steps spell %s, key is %s", key, path, trace.KeyBits)
+		}
+		if trace.Status == ProofPresent.String() && !bytes.Equal(trace.Value, trie.Get(key)) {
+			t.Errorf("%x: expected value %x, got %x", key, trie.Get(key), trace.Value)
+		}
+		if _, err := json.Marshal(trace); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if embedded == 0 {
+		t.Error("Expected an embedded node to be traced")
+	}
+
+	trace, err := TraceKeyProof(Keccak256([]byte{0x01}), []byte{0x20, 0x01}, testProof(t, trie, []byte{0x20, 0x01}))
+	if err == nil || trace.Status != ProofInvalid.String() || trace.Error == "" {
+		t.Errorf("Expected an invalid trace, got %+v, %v", trace, err)
+	}
+}
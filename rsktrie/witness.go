@@ -0,0 +1,93 @@
+package rsktrie
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// WitnessVersion is the encoding version written by Witness.Marshal.
+const WitnessVersion = 1
+
+// Witness is a self-contained multiproof: a deduplicated node set proving
+// Keys against Root, the state root of block BlockHash. It can be cached or
+// shipped between services without resending shared upper-level nodes per
+// key.
+type Witness struct {
+	Root      common.Hash
+	BlockHash common.Hash
+	Keys      [][]byte
+	// Nodes are in eth_getProof format (RLP strings of serialized nodes),
+	// root last.
+	Nodes [][]byte
+}
+
+// NewWitness builds a witness for keys from the trie t, the state of block
+// blockHash.
+func NewWitness(t *Trie, blockHash common.Hash, keys [][]byte) (*Witness, error) {
+	nodes, err := t.GenerateMultiproof(keys)
+	if err != nil {
+		return nil, err
+	}
+	return &Witness{
+		Root:      common.BytesToHash(t.GetHash()),
+		BlockHash: blockHash,
+		Keys:      keys,
+		Nodes:     nodes,
+	}, nil
+}
+
+// Verify verifies every key of the witness against its root, as
+// VerifyMultiproof does.
+func (w *Witness) Verify() ([]*KeyProofResult, error) {
+	return VerifyMultiproof(w.Root[:], w.Keys, w.Nodes)
+}
+
+// witnessRLP is the wire form. Nodes are stored as their serialized messages,
+// without the per-node RLP string header of the proof format.
+type witnessRLP struct {
+	Root      common.Hash
+	BlockHash common.Hash
+	Keys      [][]byte
+	Nodes     [][]byte
+}
+
+// Marshal encodes the witness as a version byte followed by an RLP list.
+func (w *Witness) Marshal() ([]byte, error) {
+	enc := witnessRLP{Root: w.Root, BlockHash: w.BlockHash, Keys: w.Keys, Nodes: make([][]byte, len(w.Nodes))}
+	for i, node := range w.Nodes {
+		if err := rlp.DecodeBytes(node, &enc.Nodes[i]); err != nil {
+			return nil, fmt.Errorf("witness node %d: %w", i, err)
+		}
+	}
+	body, err := rlp.EncodeToBytes(&enc)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{WitnessVersion}, body...), nil
+}
+
+// UnmarshalWitness decodes a witness written by Marshal. It does not verify
+// it; call Verify for that.
+func UnmarshalWitness(data []byte) (*Witness, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty witness")
+	}
+	if data[0] != WitnessVersion {
+		return nil, fmt.Errorf("unsupported witness version %d", data[0])
+	}
+	var dec witnessRLP
+	if err := rlp.DecodeBytes(data[1:], &dec); err != nil {
+		return nil, fmt.Errorf("decode witness: %w", err)
+	}
+	w := &Witness{Root: dec.Root, BlockHash: dec.BlockHash, Keys: dec.Keys, Nodes: make([][]byte, len(dec.Nodes))}
+	for i, node := range dec.Nodes {
+		enc, err := rlp.EncodeToBytes(node)
+		if err != nil {
+			return nil, err
+		}
+		w.Nodes[i] = enc
+	}
+	return w, nil
+}
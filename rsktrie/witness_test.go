@@ -0,0 +1,70 @@
+package rsktrie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestWitnessRoundTrip(t *testing.T) {
+	m := NewTrieKeyMapper()
+	contract := common.HexToAddress("0x77045e71a7a2c50903d88e564cd72fab11e82051")
+	trie := NewTrie(nil).Put(m.GetAccountKey(contract), []byte{0x01})
+	var keys [][]byte
+	for i := 0; i < 20; i++ {
+		key := m.GetAccountStorageKey(contract, common.BytesToHash([]byte{byte(i)}))
+		keys = append(keys, key)
+		trie = trie.Put(key, []byte{byte(i + 1)})
+	}
+	blockHash := common.HexToHash("0x1234")
+
+	w, err := NewWitness(trie, blockHash, keys)
+	if err != nil {
+		t.Fatalf("NewWitness failed: %v", err)
+	}
+	data, err := w.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var total int
+	for _, key := range keys {
+		proof, _ := trie.GenerateProof(key)
+		for _, node := range proof {
+			total += len(node)
+		}
+	}
+	if len(data) >= total {
+		t.Errorf("Expected witness (%d bytes) smaller than separate proofs (%d bytes)", len(data), total)
+	}
+
+	got, err := UnmarshalWitness(data)
+	if err != nil {
+		t.Fatalf("UnmarshalWitness failed: %v", err)
+	}
+	if got.Root != w.Root || got.BlockHash != blockHash || len(got.Keys) != len(keys) || len(got.Nodes) != len(w.Nodes) {
+		t.Fatalf("Unexpected witness %+v", got)
+	}
+	for i := range w.Nodes {
+		if !bytes.Equal(got.Nodes[i], w.Nodes[i]) {
+			t.Errorf("Node %d differs after round trip", i)
+		}
+	}
+	results, err := got.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	for i, r := range results {
+		if r.Status != ProofPresent || !bytes.Equal(r.Value, []byte{byte(i + 1)}) {
+			t.Errorf("Key %d: unexpected result %+v", i, r)
+		}
+	}
+
+	data[0] = 9
+	if _, err := UnmarshalWitness(data); err == nil {
+		t.Error("Expected error for unknown version")
+	}
+	if _, err := UnmarshalWitness([]byte{WitnessVersion, 0xc1}); err == nil {
+		t.Error("Expected error for truncated witness")
+	}
+}